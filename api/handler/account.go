@@ -61,7 +61,7 @@ func Who(c *gin.Context) {
 func Quota(c *gin.Context) {
 	pcs := pcscommand.GetBaiduPCS()
 	// QuotaInfo 返回 (quota, used int64, pcsError pcserror.Error)
-	quota, used, err := pcs.QuotaInfo()
+	quota, used, err := pcs.QuotaInfoContext(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -82,23 +82,24 @@ func Quota(c *gin.Context) {
 // UserList 列出所有已登录账号
 // UserList 列出所有已登录账号
 // @Summary 列出所有已登录账号
-// @Description 获取本服务中保存的所有登录用户列表
+// @Description 获取本服务中保存的所有登录用户列表, credential 为定期检查的凭证状态: valid 有效, expired 已失效, need_stoken 缺少 STOKEN, 空为尚未检查
 // @Tags 账号管理
 // @Accept json
 // @Produce json
 // @Success 200 {object} model.Response
 // @Router /api/account/list [get]
 func UserList(c *gin.Context) {
-	users := pcsconfig.Config.BaiduUserList
+	users := pcsconfig.Config.Users()
 
 	// 为了安全，不返回敏感信息如 BDUSS
 	var simpleUsers []map[string]interface{}
 	for _, u := range users {
 		simpleUsers = append(simpleUsers, map[string]interface{}{
-			"uid":  u.UID,
-			"name": u.Name,
-			"age":  u.Age,
-			"sex":  u.Sex,
+			"uid":        u.UID,
+			"name":       u.Name,
+			"age":        u.Age,
+			"sex":        u.Sex,
+			"credential": u.Credential(),
 		})
 	}
 
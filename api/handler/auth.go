@@ -1,20 +1,26 @@
 package handler
 
 import (
-	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	baidulogin "github.com/qjfoidnh/Baidu-Login"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/cookiefile"
+)
+
+const (
+	// MaxCookieFileSize 导入的 cookies 文件的最大大小, HAR 文件可能较大
+	MaxCookieFileSize = 64 << 20
 )
 
 // Login 登录账号
 // Login 登录账号
 // @Summary 登录账号
-// @Description 使用 BDUSS 或 用户名/密码 登录百度网盘
+// @Description 使用 BDUSS 或 用户名/密码 登录百度网盘. 用户名密码登录需要图片验证码或手机/邮箱验证时, 返回 status 为 need_captcha 或 need_verify 及 session_id
 // @Tags 账号管理
 // @Accept json
 // @Produce json
@@ -38,7 +44,7 @@ func Login(c *gin.Context) {
 		appID := pcsconfig.Config.AppID
 		pcs := baidupcs.NewPCS(appID, bduss)
 
-		uk, err := pcs.UK()
+		uk, err := pcs.UKContext(c.Request.Context())
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, model.ErrorResponse(401, "BDUSS 无效"))
 			return
@@ -62,51 +68,71 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// 2. 用户名密码登录
+	// 2. 用户名密码登录, 需要验证时返回会话ID, 通过 /api/auth/login/captcha 和 /api/auth/login/verify 继续
 	if req.Username != "" && req.Password != "" {
-		bc := baidulogin.NewBaiduClinet()
-		lj := bc.BaiduLogin(req.Username, req.Password, req.VCode, req.VCodeStr)
-
-		switch lj.ErrInfo.No {
-		case "0": // 成功
-			// SetupUserByBDUSS (bduss, ptoken, stoken, cookies)
-			_, err := pcsconfig.Config.SetupUserByBDUSS(lj.Data.BDUSS, lj.Data.PToken, lj.Data.SToken, lj.Data.CookieString)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "保存用户失败: "+err.Error()))
-				return
-			}
-			pcsconfig.Config.Save()
-
-			activeUser := pcsconfig.Config.ActiveUser()
-
-			c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-				"message": "登录成功",
-				"uid":     activeUser.UID,
-			}))
-			return
-
-		case "400023", "400101": // 需要验证手机/邮箱
-			// API 暂时不支持复杂的二次验证流程
-			c.JSON(http.StatusForbidden, model.ErrorResponse(403, "需要手机或邮箱验证，请使用 BDUSS 登录或命令行登录"))
+		sess, err := newPasswordLoginSession(req.Username, req.Password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "创建登录会话失败: "+err.Error()))
 			return
+		}
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		sess.vcodestr = req.VCodeStr
+		passwordLogin(c, sess, req.VCode)
+		return
+	}
 
-		case "500001", "500002": // 需要验证码
-			verifyImgURL := "https://wappass.baidu.com/cgi-bin/genimage?" + lj.Data.CodeString
-			c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-				"status":   "need_captcha",
-				"message":  lj.ErrInfo.Msg,
-				"vcodestr": lj.Data.CodeString,
-				"img_url":  verifyImgURL,
-			}))
-			return
+	c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "请提供 BDUSS 或 用户名/密码"))
+}
 
-		default:
-			c.JSON(http.StatusUnauthorized, model.ErrorResponse(401, fmt.Sprintf("登录失败: %s (%s)", lj.ErrInfo.Msg, lj.ErrInfo.No)))
+// ImportCookies 导入浏览器导出的 cookies 登录
+// @Summary 导入 cookies 登录
+// @Description 上传浏览器导出的 Netscape cookies.txt, 扩展导出的 JSON 或 HAR 文件, 提取 BDUSS, STOKEN, BAIDUID, PTOKEN 等 cookies, 验证有效后登录.
+// @Description 文件通过 multipart 表单的 file 字段上传, 或直接作为请求体
+// @Tags 账号管理
+// @Accept multipart/form-data
+// @Accept plain
+// @Produce json
+// @Param file formData file false "cookies 文件"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Router /api/auth/import-cookies [post]
+func ImportCookies(c *gin.Context) {
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "缺少 file 参数: "+err.Error()))
 			return
 		}
+		defer file.Close()
+		body = file
 	}
 
-	c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "请提供 BDUSS 或 用户名/密码"))
+	data, err := io.ReadAll(io.LimitReader(body, MaxCookieFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "读取 cookies 文件失败: "+err.Error()))
+		return
+	}
+	cookies, err := cookiefile.Parse(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "解析 cookies 文件失败: "+err.Error()))
+		return
+	}
+
+	baidu, err := pcsconfig.Config.SetupUserByCookies(cookies)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse(401, err.Error()))
+		return
+	}
+	pcsconfig.Config.Save()
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "登录成功",
+		"uid":     baidu.UID,
+		"name":    baidu.Name,
+	}))
 }
 
 // Logout 退出账号
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcschanges"
+)
+
+// Changes 获取网盘文件变更
+// @Summary 获取网盘文件变更
+// @Description 获取整个网盘在 cursor 之后的一页文件变更, 返回的 cursor 按账号保存. 不传 cursor 时使用上次保存的 cursor, 首次调用只返回网盘当前的 cursor. has_more 为 true 时需继续请求, reset 为 true 时需重新全量列出文件
+// @Tags 文件管理
+// @Produce json
+// @Param cursor query string false "上次返回的 cursor"
+// @Param reset query bool false "丢弃保存的 cursor, 从网盘当前状态开始"
+// @Success 200 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/changes [get]
+func Changes(c *gin.Context) {
+	activeUser := pcsconfig.Config.ActiveUser()
+	if activeUser == nil {
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse(401, "未登录"))
+		return
+	}
+
+	if c.Query("reset") == "true" {
+		err := pcschanges.SaveCursor(activeUser.UID, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+			return
+		}
+	}
+
+	diff, err := pcschanges.Fetch(c.Request.Context(), pcscommand.GetBaiduPCS(), activeUser.UID, c.Query("cursor"))
+	if diff == nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"cursor":   diff.Cursor,
+		"has_more": diff.HasMore,
+		"reset":    diff.Reset,
+		"added":    toFileInfos(diff.Added),
+		"modified": toFileInfos(diff.Modified),
+		"deleted":  toFileInfos(diff.Deleted),
+	}))
+}
+
+// toFileInfos 辅助函数：转换为接口返回的文件信息
+func toFileInfos(fds baidupcs.FileDirectoryList) []model.FileInfo {
+	fileInfos := make([]model.FileInfo, 0, len(fds))
+	for _, f := range fds {
+		fileInfos = append(fileInfos, model.FileInfo{
+			Path:     f.Path,
+			Filename: f.Filename,
+			IsDir:    f.Isdir,
+			Size:     f.Size,
+			MD5:      f.MD5,
+			MTime:    f.Mtime,
+		})
+	}
+	return fileInfos
+}
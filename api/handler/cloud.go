@@ -23,6 +23,7 @@ import (
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/cloud/add [post]
 func CloudDlAdd(c *gin.Context) {
 	var req model.CloudAddRequest
@@ -33,7 +34,7 @@ func CloudDlAdd(c *gin.Context) {
 
 	savePath := req.SavePath
 	// 使用 matchPath 处理 savePath, 支持相对路径
-	finalSavePath, err := matchPath(savePath)
+	finalSavePath, err := matchPath(c.Request.Context(), savePath)
 	if err != nil {
 		// 如果路径不存在，尝试作为相对路径拼接到工作目录
 		user := pcsconfig.Config.ActiveUser()
@@ -45,7 +46,7 @@ func CloudDlAdd(c *gin.Context) {
 	var errors []string
 
 	for _, url := range req.SourceURLs {
-		taskID, err := pcs.CloudDlAddTask(url, finalSavePath+baidupcs.PathSeparator)
+		taskID, err := pcs.CloudDlAddTaskContext(c.Request.Context(), url, finalSavePath+baidupcs.PathSeparator)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("%s: %s", url, err.Error()))
 		} else {
@@ -76,6 +77,7 @@ func CloudDlAdd(c *gin.Context) {
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/cloud/query [post]
 func CloudDlQuery(c *gin.Context) {
 	var req model.CloudQueryRequest
@@ -85,7 +87,7 @@ func CloudDlQuery(c *gin.Context) {
 	}
 
 	pcs := pcscommand.GetBaiduPCS()
-	tasks, err := pcs.CloudDlQueryTask(req.TaskIDs)
+	tasks, err := pcs.CloudDlQueryTaskContext(c.Request.Context(), req.TaskIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -105,10 +107,11 @@ func CloudDlQuery(c *gin.Context) {
 // @Produce json
 // @Success 200 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/cloud/list [get]
 func CloudDlList(c *gin.Context) {
 	pcs := pcscommand.GetBaiduPCS()
-	tasks, err := pcs.CloudDlListTask()
+	tasks, err := pcs.CloudDlListTaskContext(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -130,6 +133,7 @@ func CloudDlList(c *gin.Context) {
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/cloud/cancel [post]
 func CloudDlCancel(c *gin.Context) {
 	var req model.CloudTaskIDsRequest
@@ -141,7 +145,7 @@ func CloudDlCancel(c *gin.Context) {
 	pcs := pcscommand.GetBaiduPCS()
 	var cancelled []int64
 	for _, id := range req.TaskIDs {
-		err := pcs.CloudDlCancelTask(id)
+		err := pcs.CloudDlCancelTaskContext(c.Request.Context(), id)
 		if err == nil {
 			cancelled = append(cancelled, id)
 		}
@@ -163,6 +167,7 @@ func CloudDlCancel(c *gin.Context) {
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/cloud/delete [post]
 func CloudDlDelete(c *gin.Context) {
 	var req model.CloudTaskIDsRequest
@@ -174,7 +179,7 @@ func CloudDlDelete(c *gin.Context) {
 	pcs := pcscommand.GetBaiduPCS()
 	var deleted []int64
 	for _, id := range req.TaskIDs {
-		err := pcs.CloudDlDeleteTask(id)
+		err := pcs.CloudDlDeleteTaskContext(c.Request.Context(), id)
 		if err == nil {
 			deleted = append(deleted, id)
 		}
@@ -194,10 +199,11 @@ func CloudDlDelete(c *gin.Context) {
 // @Produce json
 // @Success 200 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/cloud/clear [post]
 func CloudDlClear(c *gin.Context) {
 	pcs := pcscommand.GetBaiduPCS()
-	total, err := pcs.CloudDlClearTask()
+	total, err := pcs.CloudDlClearTaskContext(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
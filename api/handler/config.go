@@ -2,61 +2,58 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 )
 
+// ConfigSchema 获取配置项定义
+// @Summary 获取配置项定义
+// @Description 返回所有配置项的名称, 类型, 默认值, 取值范围, 描述, 是否需要重启及对应的环境变量
+// @Tags 配置管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Response
+// @Router /api/config/schema [get]
+func ConfigSchema(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"items": pcsconfig.Schema,
+	}))
+}
+
 // ConfigGet 获取当前配置
 // ConfigGet 获取当前配置
 // @Summary 获取当前配置
-// @Description 获取 API 服务的所有配置项
+// @Description 获取 API 服务的所有配置项, 配置项定义见 /api/config/schema, 密码类型的配置项已设置时返回掩码, 代理地址不返回其中的用户名和密码.
+// @Description env_overrides 为由环境变量覆盖的配置项, read_only 表示是否开启只读配置模式
 // @Tags 配置管理
 // @Accept json
 // @Produce json
 // @Success 200 {object} model.Response
 // @Router /api/config [get]
 func ConfigGet(c *gin.Context) {
-	// 返回除了用户列表之外的配置
-	// 这里直接返回 Config 结构体可能包含过多信息，可以筛选一下
-	// 为了简单，先返回一些核心配置
 	cfg := pcsconfig.Config
 
-	response := gin.H{
-		"appid":               cfg.AppID,
-		"cache_size":          cfg.CacheSize,
-		"max_parallel":        cfg.MaxParallel,
-		"max_download_load":   cfg.MaxDownloadLoad,
-		"max_upload_parallel": cfg.MaxUploadParallel,
-		"user_agent":          cfg.UserAgent,
-		"pcs_ua":              cfg.PCSUA,
-		"pan_ua":              cfg.PanUA,
-		"enable_https":        cfg.EnableHTTPS,
-		// "proxy":              cfg.Proxy, // 敏感?
+	response := gin.H{}
+	for name, value := range cfg.Values() {
+		response[name] = value
 	}
+	response["crypt_enabled"] = cfg.CryptDir != "" && cfg.CryptPassword != ""
+	response["env_overrides"] = cfg.EnvOverridden() // 由环境变量覆盖的配置项
+	response["read_only"] = pcsconfig.ReadOnly()
 
 	c.JSON(http.StatusOK, model.SuccessResponse(response))
 }
 
-// ConfigSet 设置配置
-// 这里为了简化，假设接收一个 JSON body，包含要修改的字段
-// 实际 pcsconfig.Config 是全局变量，可以直接修改后 Save
-type ConfigSetRequest struct {
-	AppID             int    `json:"appid"`
-	CacheSize         int    `json:"cache_size"`
-	MaxParallel       int    `json:"max_parallel"`
-	MaxDownloadLoad   int    `json:"max_download_load"`
-	MaxUploadParallel int    `json:"max_upload_parallel"`
-	UserAgent         string `json:"user_agent"`
-	PCSUA             string `json:"pcs_ua"`
-	PanUA             string `json:"pan_ua"`
-	EnableHTTPS       *bool  `json:"enable_https"`
-}
+// ConfigSetRequest 设置配置, 键为配置项名称, 值为字符串, 数字或布尔值, 配置项定义见 /api/config/schema.
+// 大小和速率类型支持带单位的字符串, 如 "64KB", "2MB/s". 密码类型的值为掩码, 或代理地址为隐藏了用户名和密码的值时不修改
+type ConfigSetRequest map[string]interface{}
 
 // ConfigSet 设置配置
 // @Summary 设置配置
-// @Description 修改 API 服务的配置项
+// @Description 修改 API 服务的配置项, 先检查所有配置项再修改, 修改立即对当前帐号的客户端生效并保存到配置文件
 // @Tags 配置管理
 // @Accept json
 // @Produce json
@@ -72,58 +69,91 @@ func ConfigSet(c *gin.Context) {
 		return
 	}
 
-	cfg := pcsconfig.Config
-
-	if req.AppID != 0 {
-		cfg.AppID = req.AppID
-	}
-	if req.CacheSize != 0 {
-		cfg.CacheSize = req.CacheSize
-	}
-	if req.MaxParallel != 0 {
-		cfg.MaxParallel = req.MaxParallel
-	}
-	if req.MaxDownloadLoad != 0 {
-		cfg.MaxDownloadLoad = req.MaxDownloadLoad
-	}
-	if req.MaxUploadParallel != 0 {
-		cfg.MaxUploadParallel = req.MaxUploadParallel
+	values := make(map[string]string, len(req))
+	for name, v := range req {
+		value, ok := configValueString(v)
+		if !ok {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "配置项 "+name+" 的值类型不正确"))
+			return
+		}
+		values[name] = value
 	}
-	if req.UserAgent != "" {
-		cfg.UserAgent = req.UserAgent
-	}
-	if req.PCSUA != "" {
-		cfg.PCSUA = req.PCSUA
-	}
-	if req.PanUA != "" {
-		cfg.PanUA = req.PanUA
-	}
-	if req.EnableHTTPS != nil {
-		cfg.EnableHTTPS = *req.EnableHTTPS
+
+	// 先检查所有配置项, 有错误时不修改任何配置项
+	cfg := pcsconfig.Config
+	err := cfg.SetItems(values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
 	}
 
-	err := cfg.Save()
+	err = cfg.Save()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
+	message := "配置已更新"
+	if pcsconfig.ReadOnly() {
+		message = "配置已更新, 只读配置模式下不会保存到配置文件"
+	}
 	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-		"message": "配置已更新",
+		"message":   message,
+		"read_only": pcsconfig.ReadOnly(),
 	}))
 }
 
+// configValueString 将 json 中的配置项值转为字符串
+func configValueString(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	}
+	return "", false
+}
+
 // Health 健康检查
 // Health 健康检查
 // @Summary 健康检查
-// @Description 检查 API 服务是否存活
+// @Description 检查 API 服务是否存活, 并返回各帐号的凭证状态. 有帐号凭证失效时 credentials 为 degraded.
+// @Description storage 为使用的存储后端, unsupported 为该后端不支持的接口
 // @Tags 系统
 // @Accept json
 // @Produce json
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Router /api/health [get]
 func Health(c *gin.Context) {
+	credentials := "ok"
+	users := pcsconfig.Config.Users()
+	accounts := make([]gin.H, 0, len(users))
+	for _, u := range users {
+		state := u.Credential()
+		if state.Status.Bad() {
+			credentials = "degraded"
+		}
+		accounts = append(accounts, gin.H{
+			"uid":        u.UID,
+			"name":       u.Name,
+			"credential": state,
+		})
+	}
+
+	unsupported := []string{}
+	if !isBaiduStorage() {
+		unsupported = BaiduOnlyAPIs
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
+		"status":      "ok",
+		"credentials": credentials,
+		"accounts":    accounts,
+		"storage": gin.H{
+			"backend":     storage.Name(),
+			"unsupported": unsupported,
+		},
 	})
 }
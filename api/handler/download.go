@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"path"
 	"path/filepath"
 	"sort"
 
@@ -12,17 +14,14 @@ import (
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
-	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
-	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
-	"github.com/qjfoidnh/BaiduPCS-Go/requester/downloader"
-	"github.com/qjfoidnh/BaiduPCS-Go/requester/transfer"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
 )
 
 // Locate 获取下载直链
 // Locate 获取下载直链
 // @Summary 获取下载直链
-// @Description 获取指定文件的下载直链
+// @Description 获取指定文件的下载直链, 加密目录中的文件内容为密文, 不提供直链
 // @Tags 上传下载
 // @Accept json
 // @Produce json
@@ -38,18 +37,26 @@ func Locate(c *gin.Context) {
 		return
 	}
 
-	paths, err := matchPaths(req.Paths...)
+	paths, err := matchPaths(c.Request.Context(), req.Paths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
 	var links []map[string]interface{}
 
 	// 获取文件ID (fid)
 	for _, p := range paths {
-		f, err := pcs.FilesDirectoriesMeta(p)
+		if inCryptDir(p) {
+			// 直链下载到的是密文
+			links = append(links, map[string]interface{}{
+				"path":  pcscrypt.PlainPath(p),
+				"error": cryptNoDirectLink,
+			})
+			continue
+		}
+
+		f, err := storage.Meta(c.Request.Context(), p)
 		if err != nil {
 			links = append(links, map[string]interface{}{
 				"path":  p,
@@ -58,9 +65,8 @@ func Locate(c *gin.Context) {
 			continue
 		}
 
-		// 获取下载链接
-		// Func: LocateDownload(pcspath string) (info *URLInfo, pcsError pcserror.Error)
-		info, err := pcs.LocateDownload(p)
+		// 获取下载链接, 第一条为首选链接
+		urls, err := storage.DownloadURL(c.Request.Context(), p)
 		if err != nil {
 			links = append(links, map[string]interface{}{
 				"path":  p,
@@ -69,18 +75,11 @@ func Locate(c *gin.Context) {
 			continue
 		}
 
-		// 选取最佳链接（通常是第一个）
-		urlStr := ""
-		u := info.SingleURL(true) // true for HTTPS
-		if u != nil {
-			urlStr = u.String()
-		}
-
 		links = append(links, map[string]interface{}{
 			"path":     p,
 			"fs_id":    f.FsID,
-			"url":      urlStr,
-			"urls":     info.URLs, // 包含所有备选链接
+			"url":      urls[0],
+			"urls":     urls, // 包含所有备选链接
 			"filename": f.Filename,
 			"size":     f.Size,
 		})
@@ -94,7 +93,8 @@ func Locate(c *gin.Context) {
 // Download 下载文件到服务器本地
 // Download 下载文件到服务器本地
 // @Summary 下载文件
-// @Description 将网盘文件下载到服务器本地
+// @Description 将网盘文件下载到服务器本地, 在后台执行并返回传输任务id.
+// @Description 存储后端不是百度网盘时同步复制, 返回复制成功的 files 和失败的 failed
 // @Tags 上传下载
 // @Accept json
 // @Produce json
@@ -110,11 +110,15 @@ func Download(c *gin.Context) {
 	}
 
 	// 1. 匹配路径
-	paths, err := matchPaths(req.Paths...)
+	paths, err := matchPaths(c.Request.Context(), req.Paths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
+	if !isBaiduStorage() {
+		downloadFromStorage(c, paths, req.SaveTo, req.Overwrite)
+		return
+	}
 
 	// 2. 配置选项
 	// 为了简化 API，使用默认配置或部分可配置
@@ -137,7 +141,7 @@ func Download(c *gin.Context) {
 	var fileDirList []*baidupcs.FileDirectory
 
 	for _, p := range paths {
-		pcs.FilesDirectoriesRecurseList(p, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		pcs.FilesDirectoriesRecurseListContext(c.Request.Context(), p, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
 			if pcsError != nil {
 				return true
 			}
@@ -146,21 +150,11 @@ func Download(c *gin.Context) {
 		})
 	}
 
-	// 4. 配置下载器
-	cfg := &downloader.Config{
-		Mode:      transfer.RangeGenMode_BlockSize,
-		CacheSize: pcsconfig.Config.CacheSize,
-		BlockSize: baidupcs.InitRangeSize,
-		MaxRate:   pcsconfig.Config.MaxDownloadRate,
-		TryHTTP:   !pcsconfig.Config.EnableHTTPS,
-	}
-
-	// 5. 任务执行器
-	executor := taskframework.TaskExecutor{
-		IsFailedDeque: true,
-	}
-
-	statistic := &pcsdownload.DownloadStatistic{}
+	// 4. 传输任务, 下载到服务器本地, 可通过 /api/transfers 暂停和恢复
+	t := pcstransfer.Default.New(pcstransfer.KindDownload, pcstransfer.Options{
+		Parallel:  parallel,
+		Overwrite: req.Overwrite,
+	})
 
 	// 排序小文件优先
 	sort.Slice(fileDirList, func(i, j int) bool {
@@ -175,68 +169,120 @@ func Download(c *gin.Context) {
 		}
 
 		// 计算本地保存路径
-		var localSavePath string
-		if saveTo != "" {
-			// 如果指定了 SaveTo，则所有文件下载到该目录下，保持目录结构??
-			// 简单起见，如果指定了 SaveTo，则视为根目录
-			// 实际上 RunDownload 的逻辑很复杂，涉及 FullPath 选项
-			// 这里简化：下载到 SaveTo / Filename
-			localSavePath = filepath.Join(saveTo, v.Filename)
-		} else {
-			// 使用默认保存路径逻辑
-			localSavePath = pcsconfig.Config.ActiveUser().GetSavePath(v.Path)
-		}
-
-		newCfg := *cfg
-		unit := pcsdownload.DownloadTaskUnit{
-			Cfg: &newCfg,
-			PCS: pcs,
-			// VerbosePrinter:     nil, // 设为nil以尝试静默? 代码中可能会 panic 如果调用 Warnf
-			// 只能希望它不要打印太多
-			PrintFormat:          "", // 禁用打印格式?
-			ParentTaskExecutor:   &executor,
-			DownloadStatistic:    statistic,
-			IsPrintStatus:        false,
-			IsExecutedPermission: false,
-			IsOverwrite:          req.Save, // 复用 Save 字段表示 overwrite? 不，DownloadRequest.Save 只是 flag
-			// 我们假设 overwrite = false
-			NoCheck:      pcsconfig.Config.NoCheck,
-			DownloadMode: pcsdownload.DownloadModePCS,
-			PcsPath:      v.Path,
-			FileInfo:     v,
-			SavePath:     localSavePath,
-		}
-
-		executor.SetParallel(parallel)
-		executor.Append(&unit, 3) // MaxRetry 3
+		localSavePath := downloadSavePath(saveTo, v)
+		t.AppendDownload(v.Path, localSavePath, v)
 		startedTasks = append(startedTasks, fmt.Sprintf("%s -> %s", v.Path, localSavePath))
 	}
 
 	if len(startedTasks) == 0 {
+		pcstransfer.Default.Remove(t.ID)
 		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "没有可下载的文件"))
 		return
 	}
 
-	// 6. 执行 (同步执行，可能会阻塞很久!)
-	// 建议放入 goroutine?
-	// 但如果放入 goroutine，API 立即返回，用户不知道什么时候完成。
-	// 为了简单，本次实现为**同步**。如果文件大，会超时。
-	// 但这是 "Server-Side Download"，通常希望它是异步的。
-	// 可是没有任务管理系统，我无法返回 Task ID 供查询。
-	// 妥协：同步执行，但仅建议下载小文件或用于测试。
-	// 或者：返回 "Started" 并后台运行。
+	// 5. 后台执行, 返回传输任务id
+	t.Start()
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message":     "下载任务已在后台启动",
+		"transfer_id": t.ID,
+		"files":       startedTasks,
+	}))
+}
 
-	// 决定：后台运行，返回 Started。因为这可能需要很久。
-	go func() {
-		statistic.StartTimer()
-		executor.Execute()
-		// TODO: 记录日志或回调?
-		fmt.Printf("API Download finished: %d files\n", len(startedTasks))
-	}()
+// downloadSavePath 计算文件下载到服务器本地的保存路径.
+// 指定了 saveTo 时下载到 saveTo / 文件名, 否则使用默认保存路径逻辑
+func downloadSavePath(saveTo string, fd *baidupcs.FileDirectory) string {
+	filename, pcsPath := fd.Filename, fd.Path
+	if isBaiduStorage() {
+		filename, pcsPath = pcscrypt.PlainName(filename), pcscrypt.PlainPath(pcsPath)
+	}
+	if saveTo != "" {
+		return filepath.Join(saveTo, filename)
+	}
+	return pcsconfig.Config.ActiveUser().GetSavePath(pcsPath)
+}
+
+// downloadFromStorage 通过存储后端同步下载文件到服务器本地, 用于百度网盘以外的存储后端
+func downloadFromStorage(c *gin.Context, paths []string, saveTo string, overwrite bool) {
+	ctx := c.Request.Context()
+	var (
+		tasks  []string
+		failed []string
+	)
+	for _, p := range paths {
+		files, err := listStorageFiles(ctx, p)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", p, err))
+			continue
+		}
+
+		for _, fd := range files {
+			localSavePath := downloadSavePath(saveTo, fd)
+			err = storage.Download(ctx, fd.Path, localSavePath, overwrite)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", fd.Path, err))
+				continue
+			}
+			tasks = append(tasks, fmt.Sprintf("%s -> %s", fd.Path, localSavePath))
+		}
+	}
+
+	if len(tasks) == 0 && len(failed) == 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "没有可下载的文件"))
+		return
+	}
 
 	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-		"message":        "下载任务已在后台启动",
-		"files":          startedTasks,
-		"total_size_str": converter.ConvertFileSize(statistic.TotalSize()), // 此时可能还是0
+		"message": "下载完成",
+		"files":   tasks,
+		"failed":  failed,
 	}))
 }
+
+// listStorageFiles 递归列出存储后端中 p 下的所有文件, p 为文件时返回其本身
+func listStorageFiles(ctx context.Context, p string) (baidupcs.FileDirectoryList, error) {
+	if p != "/" {
+		siblings, err := storage.List(ctx, path.Dir(p), nil)
+		if err != nil {
+			return nil, err
+		}
+		var fd *baidupcs.FileDirectory
+		for _, sibling := range siblings {
+			if sibling.Path == p {
+				fd = sibling
+				break
+			}
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("path not found")
+		}
+		if !fd.Isdir {
+			return baidupcs.FileDirectoryList{fd}, nil
+		}
+	}
+
+	files := baidupcs.FileDirectoryList{}
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		list, err := storage.List(ctx, dir, nil)
+		if err != nil {
+			return err
+		}
+		for _, fd := range list {
+			if !fd.Isdir {
+				files = append(files, fd)
+				continue
+			}
+			if err = walk(fd.Path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err := walk(p)
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
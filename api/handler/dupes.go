@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdupes"
+)
+
+// dupesError 输出查找任务的错误
+func dupesError(c *gin.Context, err error) {
+	switch err {
+	case pcsdupes.ErrJobNotFound:
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+	case pcsdupes.ErrJobNotReported:
+		c.JSON(http.StatusConflict, model.ErrorResponse(409, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+	}
+}
+
+// StartDupes 查找重复文件
+// @Summary 查找重复文件
+// @Description 在后台按 md5 和大小查找目录中的重复文件, 生成报告, 不删除文件. 通过 /api/dupes/{id} 查看报告, 确认后调用 /api/dupes/{id}/apply 将多余的副本移入回收站
+// @Tags 重复文件
+// @Accept json
+// @Produce json
+// @Param request body model.DupesRequest true "查找请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/dupes [post]
+func StartDupes(c *gin.Context) {
+	var req model.DupesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	opt := pcsdupes.Options{
+		Path:     joinPath(req.Path),
+		MinSize:  req.MinSize,
+		Rule:     pcsdupes.Rule(req.Rule),
+		UseIndex: req.UseIndex,
+	}
+	if req.Prefer != "" {
+		opt.Prefer = joinPath(req.Prefer)
+	}
+	job, err := pcsdupes.DefaultJobs.Start(pcscommand.GetBaiduPCS(), pcsconfig.Config.ActiveUser().UID, opt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "正在后台查找重复文件",
+		"job_id":  job.ID,
+	}))
+}
+
+// ListDupes 列出查找任务
+// @Summary 列出查找任务
+// @Description 列出查找重复文件的任务及其统计结果, 不包含分组
+// @Tags 重复文件
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/dupes [get]
+func ListDupes(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"jobs": pcsdupes.DefaultJobs.List(),
+	}))
+}
+
+// GetDupes 获取查找报告
+// @Summary 获取查找报告
+// @Description 获取查找任务的状态和报告, 每组包含保留的文件和将移入回收站的文件, 按可节省的空间从大到小排列
+// @Tags 重复文件
+// @Produce json
+// @Param id path string true "查找任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/dupes/{id} [get]
+func GetDupes(c *gin.Context) {
+	job, err := pcsdupes.DefaultJobs.Get(c.Param("id"))
+	if err != nil {
+		dupesError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(job))
+}
+
+// ApplyDupes 删除多余的副本
+// @Summary 删除多余的副本
+// @Description 按已生成的报告在后台将多余的副本移入回收站, 每个报告只能执行一次
+// @Tags 重复文件
+// @Produce json
+// @Param id path string true "查找任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/dupes/{id}/apply [post]
+func ApplyDupes(c *gin.Context) {
+	job, err := pcsdupes.DefaultJobs.Apply(c.Param("id"))
+	if err != nil {
+		dupesError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "正在将多余的副本移入回收站",
+		"job_id":  job.ID,
+	}))
+}
+
+// CancelDupes 取消查找任务
+// @Summary 取消查找任务
+// @Description 取消正在扫描或移入回收站的任务, 已移入回收站的文件不会恢复
+// @Tags 重复文件
+// @Produce json
+// @Param id path string true "查找任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/dupes/{id}/cancel [post]
+func CancelDupes(c *gin.Context) {
+	err := pcsdupes.DefaultJobs.Cancel(c.Param("id"))
+	if err != nil {
+		dupesError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "查找任务已取消",
+	}))
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 )
 
@@ -37,7 +36,7 @@ func ListFiles(c *gin.Context) {
 		return
 	}
 
-	targetPath, err := matchPath(req.Path)
+	targetPath, err := matchPath(c.Request.Context(), req.Path)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -56,8 +55,7 @@ func ListFiles(c *gin.Context) {
 		orderOpt.Order = baidupcs.OrderDesc
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	files, err := pcs.FilesDirectoriesList(targetPath, orderOpt)
+	files, err := storage.List(c.Request.Context(), targetPath, orderOpt)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
@@ -65,7 +63,7 @@ func ListFiles(c *gin.Context) {
 	}
 
 	var fileInfos []model.FileInfo
-	for _, f := range files {
+	for _, f := range plainFiles(files) {
 		fileInfos = append(fileInfos, model.FileInfo{
 			Path:     f.Path,
 			Filename: f.Filename,
@@ -101,21 +99,21 @@ func Meta(c *gin.Context) {
 		return
 	}
 
-	paths, err := matchPaths(req.Paths...)
+	paths, err := matchPaths(c.Request.Context(), req.Paths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
 	var fileInfos []model.FileInfo
 
 	for _, p := range paths {
-		f, err := pcs.FilesDirectoriesMeta(p)
+		f, err := storage.Meta(c.Request.Context(), p)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 			return
 		}
+		f = plainFiles(baidupcs.FileDirectoryList{f})[0]
 		fileInfos = append(fileInfos, model.FileInfo{
 			Path:     f.Path,
 			Filename: f.Filename,
@@ -156,14 +154,13 @@ func Search(c *gin.Context) {
 		req.Path = "."
 	}
 
-	targetPath, err := matchPath(req.Path)
+	targetPath, err := matchPath(c.Request.Context(), req.Path)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	files, err := pcs.Search(targetPath, req.Keyword, req.Recurse)
+	files, err := storage.Search(c.Request.Context(), targetPath, req.Keyword, req.Recurse)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
@@ -171,7 +168,7 @@ func Search(c *gin.Context) {
 	}
 
 	var fileInfos []model.FileInfo
-	for _, f := range files {
+	for _, f := range plainFiles(files) {
 		fileInfos = append(fileInfos, model.FileInfo{
 			Path:     f.Path,
 			Filename: f.Filename,
@@ -227,14 +224,13 @@ func Cd(c *gin.Context) {
 		return
 	}
 
-	targetPath, err := matchPath(path)
+	targetPath, err := matchPath(c.Request.Context(), path)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	f, err := pcs.FilesDirectoriesMeta(targetPath)
+	f, err := storage.Meta(c.Request.Context(), targetPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "目录不存在"))
 		return
@@ -7,8 +7,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 )
 
 // MakeDir 创建目录
@@ -30,11 +28,9 @@ func MakeDir(c *gin.Context) {
 		return
 	}
 
-	user := pcsconfig.Config.ActiveUser()
-	targetPath := user.PathJoin(req.Path)
+	targetPath := joinPath(req.Path)
 
-	pcs := pcscommand.GetBaiduPCS()
-	err := pcs.Mkdir(targetPath)
+	err := storage.Mkdir(c.Request.Context(), targetPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -64,14 +60,13 @@ func Remove(c *gin.Context) {
 		return
 	}
 
-	paths, err := matchPaths(req.Paths...)
+	paths, err := matchPaths(c.Request.Context(), req.Paths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	err = pcs.Remove(paths...)
+	err = storage.Remove(c.Request.Context(), paths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -84,17 +79,15 @@ func Remove(c *gin.Context) {
 
 // handleCopyMove 处理复制或移动逻辑 (内部使用)
 func handleCopyMove(c *gin.Context, op string, fromPaths []string, toPath string) {
-	sources, err := matchPaths(fromPaths...)
+	sources, err := matchPaths(c.Request.Context(), fromPaths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	user := pcsconfig.Config.ActiveUser()
-	dest := user.PathJoin(toPath)
-	pcs := pcscommand.GetBaiduPCS()
+	dest := joinPath(toPath)
 
-	destInfo, err := pcs.FilesDirectoriesMeta(dest)
+	destInfo, err := storage.Meta(c.Request.Context(), dest)
 	// 如果目标存在且是目录，则将所有源文件移动/复制到该目录下
 	if err == nil && destInfo.Isdir {
 		var cj []*baidupcs.CpMvJSON
@@ -106,18 +99,18 @@ func handleCopyMove(c *gin.Context, op string, fromPaths []string, toPath string
 		}
 
 		if op == "copy" {
-			err = pcs.Copy(cj...)
+			err = storage.Copy(c.Request.Context(), cj...)
 		} else {
-			err = pcs.Move(cj...)
+			err = storage.Move(c.Request.Context(), cj...)
 		}
 	} else {
 		// 目标不存在或不是目录
 		if len(sources) == 1 {
 			// 单个文件，视为重命名
 			if op == "copy" {
-				err = pcs.Copy(&baidupcs.CpMvJSON{From: sources[0], To: dest})
+				err = storage.Copy(c.Request.Context(), &baidupcs.CpMvJSON{From: sources[0], To: dest})
 			} else {
-				err = pcs.Rename(sources[0], dest)
+				err = storage.Rename(c.Request.Context(), sources[0], dest)
 			}
 		} else {
 			// 多个文件但目标不是目录，报错
@@ -1,17 +1,84 @@
 package handler
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"path"
 
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsstorage"
 )
 
-// matchPath 辅助函数：匹配单条路径
-func matchPath(pattern string) (string, error) {
-	pcs := pcscommand.GetBaiduPCS()
+// storage API 使用的存储后端, 默认为当前登录的百度帐号
+var storage pcsstorage.Storage = pcsstorage.NewBaidu(nil)
+
+// SetStorage 设置 API 使用的存储后端
+func SetStorage(st pcsstorage.Storage) {
+	storage = st
+}
+
+// BaiduOnlyAPIs 只支持百度网盘存储后端的接口, 使用其他存储后端时返回 501.
+// 转存, 离线下载, 文件变更和 xpan 接口依赖百度网盘的服务端功能, 同步, 监听, 索引, 查重和定时任务直接使用百度帐号执行
+var BaiduOnlyAPIs = []string{
+	"/api/changes",
+	"/api/sync",
+	"/api/watch",
+	"/api/index",
+	"/api/dupes",
+	"/api/schedules",
+	"/api/transfer",
+	"/api/cloud",
+	"/api/xpan",
+}
+
+// isBaiduStorage 存储后端是否为百度网盘
+func isBaiduStorage() bool {
+	return storage.Name() == pcsstorage.NameBaidu
+}
+
+// BaiduOnly 中间件：存储后端不是百度网盘时返回 501, 接口需在 BaiduOnlyAPIs 中列出
+func BaiduOnly(c *gin.Context) {
+	if isBaiduStorage() {
+		return
+	}
+	c.JSON(http.StatusNotImplemented, model.ErrorResponse(501, fmt.Sprintf("存储后端 %s 不支持 %s, 该接口只支持百度网盘, 见 /api/health 的 storage.unsupported", storage.Name(), c.FullPath())))
+	c.Abort()
+}
+
+// cryptNoDirectLink 加密目录中的文件内容为密文, 不提供直链和代理下载
+const cryptNoDirectLink = "加密目录中的文件内容已加密, 无法获取直链或代理下载, 请使用 /api/download 下载到服务器后解密"
+
+// inCryptDir 辅助函数：百度网盘中的路径是否位于加密目录中
+func inCryptDir(p string) bool {
+	return isBaiduStorage() && pcscrypt.InDir(p)
+}
+
+// joinPath 辅助函数：将相对路径转换为基于工作目录的绝对路径, 加密目录中的文件名转换为网盘中的文件名
+func joinPath(p string) string {
 	user := pcsconfig.Config.ActiveUser()
-	paths, err := pcs.MatchPathByShellPattern(user.PathJoin(pattern))
+	p = path.Clean("/" + user.PathJoin(p))
+	if isBaiduStorage() {
+		p = pcscrypt.RemotePath(p)
+	}
+	return p
+}
+
+// plainFiles 辅助函数：将加密目录中的文件信息转换为明文
+func plainFiles(files baidupcs.FileDirectoryList) baidupcs.FileDirectoryList {
+	if !isBaiduStorage() {
+		return files
+	}
+	return pcscrypt.DecryptList(files)
+}
+
+// matchPath 辅助函数：匹配单条路径, ctx 通常为请求的 context
+func matchPath(ctx context.Context, pattern string) (string, error) {
+	paths, err := storage.Match(ctx, joinPath(pattern))
 	if err != nil {
 		return "", err
 	}
@@ -22,12 +89,10 @@ func matchPath(pattern string) (string, error) {
 }
 
 // matchPaths 辅助函数：匹配多条路径
-func matchPaths(patterns ...string) ([]string, error) {
-	pcs := pcscommand.GetBaiduPCS()
-	user := pcsconfig.Config.ActiveUser()
+func matchPaths(ctx context.Context, patterns ...string) ([]string, error) {
 	var result []string
 	for _, p := range patterns {
-		paths, err := pcs.MatchPathByShellPattern(user.PathJoin(p))
+		paths, err := storage.Match(ctx, joinPath(p))
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsindex"
+)
+
+// indexError 输出索引的错误
+func indexError(c *gin.Context, err error) {
+	switch err {
+	case pcsindex.ErrIndexNotBuilt:
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+	case pcsindex.ErrIndexBusy:
+		c.JSON(http.StatusConflict, model.ErrorResponse(409, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+	}
+}
+
+// IndexInfo 获取索引状态
+// @Summary 获取索引状态
+// @Description 获取当前账号的本地文件索引的文件数量, 建立和更新时间, 以及后台建立索引的进度
+// @Tags 索引
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/index [get]
+func IndexInfo(c *gin.Context) {
+	uid := pcsconfig.Config.ActiveUser().UID
+	resp := gin.H{
+		"built": false,
+		"build": pcsindex.GetBuild(uid),
+	}
+	idx, err := pcsindex.Load(uid)
+	if err != nil && err != pcsindex.ErrIndexNotBuilt {
+		indexError(c, err)
+		return
+	}
+	if idx != nil {
+		resp["built"] = true
+		resp["info"] = idx.Info
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(resp))
+}
+
+// BuildIndex 建立索引
+// @Summary 建立索引
+// @Description 在后台列出整个网盘, 建立当前账号的本地文件索引, 保存在配置目录的 index 中. 通过 /api/index 查询进度
+// @Tags 索引
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/index/build [post]
+func BuildIndex(c *gin.Context) {
+	status, err := pcsindex.StartBuild(pcscommand.GetBaiduPCS(), pcsconfig.Config.ActiveUser().UID)
+	if err != nil {
+		indexError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "正在后台建立索引",
+		"build":   status,
+	}))
+}
+
+// RefreshIndex 增量更新索引
+// @Summary 增量更新索引
+// @Description 通过网盘的增量变更检测更新索引, 服务器要求重新全量列出时会重新建立索引
+// @Tags 索引
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/index/refresh [post]
+func RefreshIndex(c *gin.Context) {
+	result, err := pcsindex.Refresh(c.Request.Context(), pcscommand.GetBaiduPCS(), pcsconfig.Config.ActiveUser().UID)
+	if err != nil {
+		indexError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"result": result,
+	}))
+}
+
+// SearchIndex 在索引中查询
+// @Summary 在索引中查询
+// @Description 在本地文件索引中按文件名通配符, 正则表达式, 扩展名, 大小, 修改时间, md5 和目录查询, 不请求网盘
+// @Tags 索引
+// @Produce json
+// @Param name query string false "文件名通配符"
+// @Param regex query string false "匹配完整路径的正则表达式"
+// @Param ext query string false "扩展名, 多个用逗号分隔"
+// @Param min_size query int false "最小文件大小"
+// @Param max_size query int false "最大文件大小"
+// @Param mtime_after query int false "修改时间不早于"
+// @Param mtime_before query int false "修改时间早于"
+// @Param md5 query string false "文件md5"
+// @Param prefix query string false "只查询该目录下的文件"
+// @Param type query string false "file 或 dir"
+// @Param sort query string false "path, name, size 或 mtime"
+// @Param desc query bool false "倒序"
+// @Param offset query int false "跳过的数量"
+// @Param limit query int false "返回的数量, 默认100"
+// @Param refresh query bool false "查询前先增量更新索引"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/index/search [get]
+func SearchIndex(c *gin.Context) {
+	var req model.IndexSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	uid := pcsconfig.Config.ActiveUser().UID
+	if req.Refresh {
+		_, err := pcsindex.Refresh(c.Request.Context(), pcscommand.GetBaiduPCS(), uid)
+		if err != nil {
+			indexError(c, err)
+			return
+		}
+	}
+
+	q := &pcsindex.Query{
+		Name:        req.Name,
+		Regex:       req.Regex,
+		MinSize:     req.MinSize,
+		MaxSize:     req.MaxSize,
+		MtimeAfter:  req.MtimeAfter,
+		MtimeBefore: req.MtimeBefore,
+		MD5:         req.MD5,
+		Type:        req.Type,
+		Sort:        req.Sort,
+		Desc:        req.Desc,
+		Offset:      req.Offset,
+		Limit:       req.Limit,
+	}
+	if req.Ext != "" {
+		q.Exts = strings.Split(req.Ext, ",")
+	}
+	if req.Prefix != "" {
+		q.Prefix = joinPath(req.Prefix)
+	}
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+
+	idx, err := pcsindex.Load(uid)
+	if err != nil {
+		indexError(c, err)
+		return
+	}
+	result, err := idx.Search(q)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	files := make([]model.FileInfo, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		files = append(files, model.FileInfo{
+			Path:     e.Path,
+			Filename: path.Base(e.Path),
+			IsDir:    e.IsDir,
+			Size:     e.Size,
+			MD5:      e.MD5,
+			MTime:    e.Mtime,
+		})
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"total":      result.Total,
+		"files":      files,
+		"updated_at": idx.UpdatedAt,
+	}))
+}
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	baidulogin "github.com/qjfoidnh/Baidu-Login"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+)
+
+const (
+	// passwordLoginTTL 用户名密码登录会话的有效期
+	passwordLoginTTL = 10 * time.Minute
+	// captchaImageURL 百度图片验证码地址
+	captchaImageURL = "https://wappass.baidu.com/cgi-bin/genimage?"
+)
+
+// PasswordLoginSession 用户名密码登录会话, 保存多步登录过程中的 cookie 和验证信息
+type PasswordLoginSession struct {
+	ID       string
+	ExpireAt time.Time
+
+	client     *baidulogin.BaiduClient
+	username   string
+	password   string
+	vcodestr   string                // 图片验证码对应的字串
+	captcha    []byte                // 图片验证码
+	verify     *baidulogin.LoginJSON // 需要手机/邮箱验证时的登录结果
+	verifyType string                // 已发送验证码的验证方式
+	mu         sync.Mutex
+}
+
+var (
+	// pwdSessions 保存进行中的用户名密码登录会话
+	pwdSessions = make(map[string]*PasswordLoginSession)
+	pwdMutex    sync.Mutex
+)
+
+// newPasswordLoginSession 创建登录会话
+func newPasswordLoginSession(username, password string) (*PasswordLoginSession, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	sess := &PasswordLoginSession{
+		ID:       hex.EncodeToString(b),
+		ExpireAt: time.Now().Add(passwordLoginTTL),
+		client:   baidulogin.NewBaiduClinet(),
+		username: username,
+		password: password,
+	}
+
+	pwdMutex.Lock()
+	defer pwdMutex.Unlock()
+	now := time.Now()
+	for id, s := range pwdSessions {
+		if now.After(s.ExpireAt) {
+			delete(pwdSessions, id)
+		}
+	}
+	pwdSessions[sess.ID] = sess
+	return sess, nil
+}
+
+// getPasswordLoginSession 获取未过期的登录会话, 不存在时返回 404
+func getPasswordLoginSession(c *gin.Context, id string) (*PasswordLoginSession, bool) {
+	pwdMutex.Lock()
+	sess, exists := pwdSessions[id]
+	if exists && time.Now().After(sess.ExpireAt) {
+		delete(pwdSessions, id)
+		exists = false
+	}
+	pwdMutex.Unlock()
+	if !exists {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, "登录会话不存在或已过期"))
+		return nil, false
+	}
+	return sess, true
+}
+
+func removePasswordLoginSession(id string) {
+	pwdMutex.Lock()
+	delete(pwdSessions, id)
+	pwdMutex.Unlock()
+}
+
+// passwordLogin 发送登录请求并返回下一步, 调用方需持有 sess.mu
+func passwordLogin(c *gin.Context, sess *PasswordLoginSession, vcode string) {
+	lj := sess.client.BaiduLogin(sess.username, sess.password, vcode, sess.vcodestr)
+	switch lj.ErrInfo.No {
+	case "0": // 成功
+		finishPasswordLogin(c, sess, lj)
+
+	case "400023", "400101": // 需要验证手机/邮箱
+		sess.verify = lj
+		sess.verifyType = ""
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+			"status":     "need_verify",
+			"message":    "需要验证手机或邮箱才能登录, 请选择验证方式发送验证码",
+			"session_id": sess.ID,
+			"phone":      lj.Data.Phone,
+			"email":      lj.Data.Email,
+			"expire_at":  sess.ExpireAt.Format(time.RFC3339),
+		}))
+
+	case "500001", "500002": // 需要图片验证码
+		sess.vcodestr = lj.Data.CodeString
+		if sess.vcodestr == "" {
+			removePasswordLoginSession(sess.ID)
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "未找到codeString"))
+			return
+		}
+
+		respData := gin.H{
+			"status":      "need_captcha",
+			"message":     lj.ErrInfo.Msg,
+			"session_id":  sess.ID,
+			"captcha_url": "/api/auth/login/captcha/" + sess.ID,
+			"expire_at":   sess.ExpireAt.Format(time.RFC3339),
+		}
+		// 使用会话的 cookie 获取验证码图片
+		img, err := sess.client.Fetch(http.MethodGet, captchaImageURL+sess.vcodestr, nil, nil)
+		if err != nil {
+			sess.captcha = nil
+			respData["captcha_error"] = "获取验证码失败: " + err.Error()
+		} else {
+			sess.captcha = img
+			respData["captcha_base64"] = "data:" + http.DetectContentType(img) + ";base64," + base64.StdEncoding.EncodeToString(img)
+		}
+		c.JSON(http.StatusOK, model.SuccessResponse(respData))
+
+	default:
+		removePasswordLoginSession(sess.ID)
+		c.JSON(http.StatusUnauthorized, model.ErrorResponse(401, fmt.Sprintf("登录失败: %s (%s)", lj.ErrInfo.Msg, lj.ErrInfo.No)))
+	}
+}
+
+// finishPasswordLogin 保存登录获得的凭证
+func finishPasswordLogin(c *gin.Context, sess *PasswordLoginSession, lj *baidulogin.LoginJSON) {
+	removePasswordLoginSession(sess.ID)
+	baidu, err := pcsconfig.Config.SetupUserByBDUSS(lj.Data.BDUSS, lj.Data.PToken, lj.Data.SToken, lj.Data.CookieString)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "保存用户失败: "+err.Error()))
+		return
+	}
+	pcsconfig.Config.Save()
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"status":  "success",
+		"message": "登录成功",
+		"uid":     baidu.UID,
+		"name":    baidu.Name,
+	}))
+}
+
+// LoginCaptchaImage 获取登录验证码图片
+// @Summary 获取登录验证码图片
+// @Description 返回用户名密码登录会话当前的图片验证码
+// @Tags 账号管理
+// @Produce png
+// @Param session_id path string true "登录会话ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} model.Response
+// @Router /api/auth/login/captcha/{session_id} [get]
+func LoginCaptchaImage(c *gin.Context) {
+	sess, ok := getPasswordLoginSession(c, c.Param("session_id"))
+	if !ok {
+		return
+	}
+	sess.mu.Lock()
+	img := sess.captcha
+	sess.mu.Unlock()
+	if img == nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, "当前登录会话没有验证码"))
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, http.DetectContentType(img), img)
+}
+
+// LoginCaptcha 提交登录图片验证码
+// @Summary 提交登录图片验证码
+// @Description 提交图片验证码继续用户名密码登录, 返回登录结果或下一步 (need_captcha, need_verify)
+// @Tags 账号管理
+// @Accept json
+// @Produce json
+// @Param request body model.LoginCaptchaRequest true "验证码"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/auth/login/captcha [post]
+func LoginCaptcha(c *gin.Context) {
+	var req model.LoginCaptchaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	sess, ok := getPasswordLoginSession(c, req.SessionID)
+	if !ok {
+		return
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.vcodestr == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "当前登录会话不需要图片验证码"))
+		return
+	}
+	passwordLogin(c, sess, req.VCode)
+}
+
+// LoginVerifySend 发送手机/邮箱验证码
+// @Summary 发送手机/邮箱验证码
+// @Description 登录需要验证手机或邮箱时, 向选择的方式发送验证码
+// @Tags 账号管理
+// @Accept json
+// @Produce json
+// @Param request body model.LoginVerifySendRequest true "验证方式"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/auth/login/verify/send [post]
+func LoginVerifySend(c *gin.Context) {
+	var req model.LoginVerifySendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	if req.Type != "mobile" && req.Type != "email" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "验证方式不合法, 可选 mobile, email"))
+		return
+	}
+	sess, ok := getPasswordLoginSession(c, req.SessionID)
+	if !ok {
+		return
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	lj := sess.verify
+	if lj == nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "当前登录会话不需要验证手机或邮箱"))
+		return
+	}
+
+	var msg string
+	if lj.Data.AuthID != "" {
+		msg = sess.client.SendCodeToUser(req.Type, lj.Data.VerifyURL, lj.Data.AuthID)
+	} else {
+		msg = sess.client.SendCodeToUser2(req.Type, lj.Data.Token)
+	}
+	if strings.Contains(msg, "系统出错") {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "发送验证码失败: "+msg))
+		return
+	}
+	sess.verifyType = req.Type
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"status":     "code_sent",
+		"message":    msg,
+		"session_id": sess.ID,
+	}))
+}
+
+// LoginVerify 提交手机/邮箱验证码
+// @Summary 提交手机/邮箱验证码
+// @Description 提交收到的验证码继续用户名密码登录, 返回登录结果或下一步 (need_captcha, need_verify)
+// @Tags 账号管理
+// @Accept json
+// @Produce json
+// @Param request body model.LoginVerifyRequest true "验证码"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/auth/login/verify [post]
+func LoginVerify(c *gin.Context) {
+	var req model.LoginVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	sess, ok := getPasswordLoginSession(c, req.SessionID)
+	if !ok {
+		return
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	lj := sess.verify
+	if lj == nil || sess.verifyType == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "请先发送验证码"))
+		return
+	}
+
+	var nlj *baidulogin.LoginJSON
+	if lj.Data.AuthID != "" {
+		// 仅完成了手机/邮箱验证, 需要重新登录获取 BDUSS
+		nlj = sess.client.VerifyCode(req.VCode, sess.verifyType, lj.Data.VerifyURL, lj.Data.AuthID, lj.Data.LoginProxy, lj.Data.AuthSID)
+	} else {
+		// BDUSS 等信息在验证结果中返回
+		nlj = sess.client.VerifyCode2(sess.verifyType, lj.Data.Token, req.VCode, lj.Data.U)
+	}
+	if nlj.ErrInfo.No != "0" {
+		if nlj.ErrInfo.No == "-2" { // 需要重发验证码
+			sess.verifyType = ""
+		}
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, fmt.Sprintf("验证失败: %s (%s)", nlj.ErrInfo.Msg, nlj.ErrInfo.No)))
+		return
+	}
+	if nlj.Data.BDUSS != "" {
+		finishPasswordLogin(c, sess, nlj)
+		return
+	}
+
+	sess.verify = nil
+	sess.verifyType = ""
+	sess.vcodestr = ""
+	passwordLogin(c, sess, "")
+}
@@ -1,29 +1,37 @@
 package handler
 
 import (
+	"bytes"
+	"io"
 	"net/http"
-	"sync"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 )
 
+const (
+	// MaxQRStatusWait 长轮询扫码状态的最长等待时间
+	MaxQRStatusWait = 60 * time.Second
+	// DefaultQRCodeImageSize 二维码图片的默认边长
+	DefaultQRCodeImageSize = 256
+	// MaxQRCodeImageSize 二维码图片的最大边长
+	MaxQRCodeImageSize = 1024
+)
+
 // QRCodeSession 二维码会话缓存
 type QRCodeSession struct {
-	Client   *pcscommand.QRLoginClient
-	QRInfo   *pcscommand.QRCodeInfo
-	ExpireAt time.Time
+	Client   *pcscommand.QRLoginClient `json:"-"`
+	QRInfo   *pcscommand.QRCodeInfo    `json:"qr_info"`
+	ExpireAt time.Time                 `json:"expire_at"`
 }
 
-var (
-	// qrSessions 保存活跃的二维码会话
-	qrSessions = make(map[string]*QRCodeSession)
-	qrMutex    sync.RWMutex
-)
-
 // QRCodeGetRequest 获取二维码请求
 type QRCodeGetRequest struct {
 	IncludeASCII bool `json:"include_ascii"` // 是否包含 ASCII 格式二维码
@@ -52,21 +60,18 @@ func QRCodeGet(c *gin.Context) {
 	}
 
 	// 缓存会话
-	qrMutex.Lock()
-	qrSessions[qrInfo.Sign] = &QRCodeSession{
+	qrSessions.Put(&QRCodeSession{
 		Client:   client,
 		QRInfo:   qrInfo,
 		ExpireAt: qrInfo.ExpireAt,
-	}
-	qrMutex.Unlock()
-
-	// 清理过期会话
-	go cleanExpiredSessions()
+	})
 
-	// 构建响应
+	// 构建响应, png_url 和 svg_url 由本服务渲染, 无需访问百度
 	respData := gin.H{
 		"sign":      qrInfo.Sign,
 		"img_url":   qrInfo.ImgURL,
+		"png_url":   "/api/auth/qrcode/" + qrInfo.Sign + ".png",
+		"svg_url":   "/api/auth/qrcode/" + qrInfo.Sign + ".svg",
 		"expire_at": qrInfo.ExpireAt.Format(time.RFC3339),
 	}
 
@@ -78,12 +83,75 @@ func QRCodeGet(c *gin.Context) {
 	c.JSON(http.StatusOK, model.SuccessResponse(respData))
 }
 
+// QRCodeImage 获取本地渲染的二维码图片
+// @Summary 获取二维码图片
+// @Description 根据二维码内容在本地渲染 PNG 或 SVG 图片, 文件名为 {sign}.png 或 {sign}.svg
+// @Tags 账号管理
+// @Produce png
+// @Produce image/svg+xml
+// @Param file path string true "{sign}.png 或 {sign}.svg"
+// @Param size query int false "图片最小边长, 默认 256, 最大 1024"
+// @Success 200 {file} binary
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/auth/qrcode/{file} [get]
+func QRCodeImage(c *gin.Context) {
+	file := c.Param("file")
+	ext := path.Ext(file)
+	sign := strings.TrimSuffix(file, ext)
+	if sign == "" || (ext != ".png" && ext != ".svg") {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "文件名应为 {sign}.png 或 {sign}.svg"))
+		return
+	}
+
+	session, exists := qrSessions.Get(sign)
+	if !exists || time.Now().After(session.ExpireAt) {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, "二维码会话不存在或已过期"))
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(DefaultQRCodeImageSize)))
+	if err != nil || size <= 0 || size > MaxQRCodeImageSize {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "size 参数无效"))
+		return
+	}
+
+	content := session.QRInfo.Content
+	if content == "" {
+		content = pcscommand.QRCodeContent(sign)
+	}
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+	)
+	if ext == ".png" {
+		err = pcscommand.RenderQRCodePNG(&buf, content, size)
+		contentType = "image/png"
+	} else {
+		err = pcscommand.RenderQRCodeSVG(&buf, content, size)
+		contentType = "image/svg+xml"
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
 // QRCodeStatus 查询扫码状态
 // @Summary 查询扫码状态
-// @Description 查询二维码的扫码状态
+// @Description 查询二维码的扫码状态. 指定 wait 时长轮询, 状态与 last 不同或超时后返回;
+// @Description 请求头 Accept 为 text/event-stream 或 stream=true 时以 Server-Sent Events 推送状态变化, 直到确认, 过期或出错
 // @Tags 账号管理
 // @Produce json
+// @Produce text/event-stream
 // @Param sign query string true "二维码标识符"
+// @Param wait query int false "长轮询的最长等待秒数, 最大 60"
+// @Param last query string false "客户端已知的状态, 默认 waiting"
+// @Param stream query bool false "是否以 Server-Sent Events 推送"
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 404 {object} model.Response
@@ -96,48 +164,111 @@ func QRCodeStatus(c *gin.Context) {
 	}
 
 	// 获取会话
-	qrMutex.RLock()
-	session, exists := qrSessions[sign]
-	qrMutex.RUnlock()
-
+	session, exists := qrSessions.Get(sign)
 	if !exists {
 		c.JSON(http.StatusNotFound, model.ErrorResponse(404, "二维码会话不存在或已过期"))
 		return
 	}
 
-	// 检查是否过期
-	if time.Now().After(session.ExpireAt) {
-		qrMutex.Lock()
-		delete(qrSessions, sign)
-		qrMutex.Unlock()
-		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-			"status":  pcscommand.QRStatusExpired.String(),
-			"message": "二维码已过期",
-		}))
+	if c.Query("stream") == "true" || strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		streamQRStatus(c, session, sign)
 		return
 	}
 
-	// 查询状态
+	wait, _ := strconv.Atoi(c.Query("wait"))
+	deadline := time.Now().Add(min(time.Duration(wait)*time.Second, MaxQRStatusWait))
+	last := c.DefaultQuery("last", pcscommand.QRStatusWaiting.String())
+	for {
+		result := checkQRSession(session, sign)
+		if result.Status.String() != last || isQRStatusFinal(result.Status) || time.Now().Add(pcscommand.PollInterval).After(deadline) {
+			c.JSON(http.StatusOK, model.SuccessResponse(qrStatusData(result)))
+			return
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-time.After(pcscommand.PollInterval):
+		}
+	}
+}
+
+// streamQRStatus 以 Server-Sent Events 推送扫码状态变化
+func streamQRStatus(c *gin.Context, session *QRCodeSession, sign string) {
+	// 事件流不受服务器写超时限制
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	var (
+		last     pcscommand.QRCodeStatus = -1
+		lastSent time.Time
+		done     bool
+	)
+	c.Stream(func(w io.Writer) bool {
+		if done {
+			return false
+		}
+		result := checkQRSession(session, sign)
+		if result.Status != last {
+			c.Render(-1, sse.Event{
+				Event: "status",
+				Data:  qrStatusData(result),
+			})
+			last, lastSent = result.Status, time.Now()
+			if isQRStatusFinal(result.Status) {
+				return false
+			}
+		} else if time.Since(lastSent) >= 30*time.Second {
+			io.WriteString(w, ": keepalive\n\n")
+			lastSent = time.Now()
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			done = true
+		case <-time.After(pcscommand.PollInterval):
+		}
+		return !done
+	})
+}
+
+// checkQRSession 查询会话的扫码状态, 查询失败时视为等待扫码
+func checkQRSession(session *QRCodeSession, sign string) *pcscommand.QRLoginResult {
+	if time.Now().After(session.ExpireAt) {
+		qrSessions.Delete(sign)
+		return &pcscommand.QRLoginResult{
+			Status:  pcscommand.QRStatusExpired,
+			Message: "二维码已过期",
+		}
+	}
+
 	result, err := session.Client.CheckQRStatus(sign)
 	if err != nil {
-		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-			"status":  pcscommand.QRStatusWaiting.String(),
-			"message": "等待扫码",
-		}))
-		return
+		return &pcscommand.QRLoginResult{
+			Status:  pcscommand.QRStatusWaiting,
+			Message: "等待扫码",
+		}
 	}
+	return result
+}
 
+// qrStatusData 扫码状态的响应数据, 已确认时包含临时 BDUSS
+func qrStatusData(result *pcscommand.QRLoginResult) gin.H {
 	respData := gin.H{
 		"status":  result.Status.String(),
 		"message": result.Message,
 	}
-
-	// 如果已确认，返回临时 BDUSS
 	if result.Status == pcscommand.QRStatusConfirmed && result.TempBDUSS != "" {
 		respData["temp_bduss"] = result.TempBDUSS
 	}
+	return respData
+}
 
-	c.JSON(http.StatusOK, model.SuccessResponse(respData))
+// isQRStatusFinal 状态是否不会再变化
+func isQRStatusFinal(status pcscommand.QRCodeStatus) bool {
+	return status == pcscommand.QRStatusConfirmed || status == pcscommand.QRStatusExpired || status == pcscommand.QRStatusError
 }
 
 // QRCodeLoginRequest 扫码登录请求
@@ -165,9 +296,7 @@ func QRCodeLogin(c *gin.Context) {
 	}
 
 	// 获取会话
-	qrMutex.RLock()
-	session, exists := qrSessions[req.Sign]
-	qrMutex.RUnlock()
+	session, exists := qrSessions.Get(req.Sign)
 
 	var client *pcscommand.QRLoginClient
 	if exists {
@@ -193,9 +322,7 @@ func QRCodeLogin(c *gin.Context) {
 	pcsconfig.Config.Save()
 
 	// 清理会话
-	qrMutex.Lock()
-	delete(qrSessions, req.Sign)
-	qrMutex.Unlock()
+	qrSessions.Delete(req.Sign)
 
 	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
 		"message": "登录成功",
@@ -206,16 +333,3 @@ func QRCodeLogin(c *gin.Context) {
 		"cookies": cookies,
 	}))
 }
-
-// cleanExpiredSessions 清理过期的二维码会话
-func cleanExpiredSessions() {
-	qrMutex.Lock()
-	defer qrMutex.Unlock()
-
-	now := time.Now()
-	for sign, session := range qrSessions {
-		if now.After(session.ExpireAt) {
-			delete(qrSessions, sign)
-		}
-	}
-}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+)
+
+const (
+	// QRSessionFileName 二维码会话的持久化文件名, 位于配置目录
+	QRSessionFileName = "qrcode_sessions.json"
+	// MaxQRSessions 最多保存的二维码会话数量, 超出时淘汰最早过期的会话
+	MaxQRSessions = 32
+	// qrSessionCleanInterval 过期会话的清理间隔
+	qrSessionCleanInterval = time.Minute
+)
+
+// qrSessionStore 有容量上限的二维码会话存储, 会话保存到配置目录, 重启后可继续轮询
+type qrSessionStore struct {
+	path     string
+	max      int
+	sessions map[string]*QRCodeSession
+	mu       sync.Mutex
+	once     sync.Once
+}
+
+var (
+	// qrSessions 保存活跃的二维码会话
+	qrSessions = &qrSessionStore{
+		max: MaxQRSessions,
+	}
+)
+
+// init 从文件加载会话, 并启动过期会话的定时清理
+func (s *qrSessionStore) init() {
+	s.once.Do(func() {
+		if s.path == "" {
+			s.path = filepath.Join(pcsconfig.GetConfigDir(), QRSessionFileName)
+		}
+		s.sessions = make(map[string]*QRCodeSession)
+		s.load()
+
+		go func() {
+			ticker := time.NewTicker(qrSessionCleanInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				s.mu.Lock()
+				if s.cleanExpired(time.Now()) {
+					s.save()
+				}
+				s.mu.Unlock()
+			}
+		}()
+	})
+}
+
+// Get 获取会话, 从文件恢复的会话会重新创建登录客户端
+func (s *qrSessionStore) Get(sign string) (*QRCodeSession, bool) {
+	s.init()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sign]
+	if !ok {
+		return nil, false
+	}
+	if session.Client == nil {
+		session.Client = pcscommand.NewQRLoginClient()
+	}
+	return session, true
+}
+
+// Put 保存会话
+func (s *qrSessionStore) Put(session *QRCodeSession) {
+	s.init()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanExpired(time.Now())
+	for len(s.sessions) >= s.max {
+		var oldest string
+		for sign, v := range s.sessions {
+			if oldest == "" || v.ExpireAt.Before(s.sessions[oldest].ExpireAt) {
+				oldest = sign
+			}
+		}
+		delete(s.sessions, oldest)
+	}
+	s.sessions[session.QRInfo.Sign] = session
+	s.save()
+}
+
+// Delete 删除会话
+func (s *qrSessionStore) Delete(sign string) {
+	s.init()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[sign]; !ok {
+		return
+	}
+	delete(s.sessions, sign)
+	s.save()
+}
+
+// cleanExpired 清理过期的会话, 调用方需持有锁, 返回是否有会话被清理
+func (s *qrSessionStore) cleanExpired(now time.Time) (cleaned bool) {
+	for sign, session := range s.sessions {
+		if now.After(session.ExpireAt) {
+			delete(s.sessions, sign)
+			cleaned = true
+		}
+	}
+	return
+}
+
+// load 从文件加载会话, 忽略已过期的会话
+func (s *qrSessionStore) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return
+	}
+	var sessions []*QRCodeSession
+	if err = json.Unmarshal(data, &sessions); err != nil {
+		return
+	}
+	now := time.Now()
+	for _, session := range sessions {
+		if session.QRInfo == nil || session.QRInfo.Sign == "" || now.After(session.ExpireAt) {
+			continue
+		}
+		s.sessions[session.QRInfo.Sign] = session
+	}
+}
+
+// save 保存会话到文件, 调用方需持有锁
+func (s *qrSessionStore) save() {
+	if len(s.sessions) == 0 {
+		os.Remove(s.path)
+		return
+	}
+	sessions := make([]*QRCodeSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	data, err := json.Marshal(sessions)
+	if err != nil {
+		return
+	}
+
+	// 先写临时文件再重命名, 避免写入中断导致文件损坏
+	tmp := s.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	if err = os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+	}
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
 )
 
 // RecycleList 列出回收站
@@ -28,8 +27,7 @@ func RecycleList(c *gin.Context) {
 		}
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	files, err := pcs.RecycleList(page)
+	files, err := storage.RecycleList(c.Request.Context(), page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -60,8 +58,7 @@ func RecycleRestore(c *gin.Context) {
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	_, err := pcs.RecycleRestore(req.FsIDs...)
+	err := storage.RecycleRestore(c.Request.Context(), req.FsIDs...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -91,8 +88,7 @@ func RecycleDelete(c *gin.Context) {
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	err := pcs.RecycleDelete(req.FsIDs...)
+	err := storage.RecycleDelete(c.Request.Context(), req.FsIDs...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -114,8 +110,7 @@ func RecycleDelete(c *gin.Context) {
 // @Failure 500 {object} model.Response
 // @Router /api/recycle/clear [post]
 func RecycleClear(c *gin.Context) {
-	pcs := pcscommand.GetBaiduPCS()
-	num, err := pcs.RecycleClear()
+	num, err := storage.RecycleClear(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
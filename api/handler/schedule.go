@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsschedule"
+)
+
+// scheduleSpec 将请求转换为定时任务设置
+func scheduleSpec(req *model.ScheduleRequest) pcsschedule.Spec {
+	paths := make([]string, 0, len(req.Paths))
+	for _, p := range req.Paths {
+		paths = append(paths, joinPath(p))
+	}
+	spec := pcsschedule.Spec{
+		Name:     req.Name,
+		Cron:     req.Cron,
+		Kind:     pcsschedule.Kind(req.Kind),
+		Disabled: req.Disabled,
+		Options: pcsschedule.JobOptions{
+			LocalPaths: req.LocalPaths,
+			Policy:     req.Policy,
+			Parallel:   req.Parallel,
+			Paths:      paths,
+			SavePath:   req.SavePath,
+			Recursive:  req.Recursive,
+			LinkFormat: req.LinkFormat,
+			Threshold:  req.Threshold,
+		},
+	}
+	if req.RemoteDir != "" {
+		spec.Options.RemoteDir = joinPath(req.RemoteDir)
+	}
+	return spec
+}
+
+// scheduleError 输出定时任务的错误
+func scheduleError(c *gin.Context, err error) {
+	switch err {
+	case pcsschedule.ErrScheduleNotFound:
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+	case pcsschedule.ErrScheduleRunning:
+		c.JSON(http.StatusConflict, model.ErrorResponse(409, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+	}
+}
+
+// ListSchedules 列出定时任务
+// @Summary 列出定时任务
+// @Description 列出定时任务及其最近一次执行结果和下次执行时间, 执行记录通过 /api/schedules/{id} 查询
+// @Tags 定时任务
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/schedules [get]
+func ListSchedules(c *gin.Context) {
+	list, err := pcsschedule.Default.List()
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	for _, s := range list {
+		s.History = nil
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"schedules": list,
+	}))
+}
+
+// CreateSchedule 添加定时任务
+// @Summary 添加定时任务
+// @Description 添加按 cron 表达式执行的任务, 支持上传 (默认 rsync 策略), 导出, 离线下载查询和清空, 清空回收站和空间使用率检查. 任务保存在配置目录的 pcs_schedules.json 中
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Param request body model.ScheduleRequest true "定时任务"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/schedules [post]
+func CreateSchedule(c *gin.Context) {
+	var req model.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedule, err := pcsschedule.Default.Create(scheduleSpec(&req))
+	if schedule == nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	resp := gin.H{
+		"message":  "已添加定时任务",
+		"schedule": schedule,
+	}
+	if err != nil {
+		resp["warning"] = "保存定时任务失败: " + err.Error()
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(resp))
+}
+
+// GetSchedule 定时任务详情
+// @Summary 定时任务详情
+// @Description 获取定时任务的设置, 下次执行时间和最近的执行记录
+// @Tags 定时任务
+// @Produce json
+// @Param id path string true "定时任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/schedules/{id} [get]
+func GetSchedule(c *gin.Context) {
+	schedule, err := pcsschedule.Default.Get(c.Param("id"))
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"schedule": schedule,
+	}))
+}
+
+// UpdateSchedule 修改定时任务
+// @Summary 修改定时任务
+// @Description 替换定时任务的设置并重新计算下次执行时间, 执行记录保留
+// @Tags 定时任务
+// @Accept json
+// @Produce json
+// @Param id path string true "定时任务id"
+// @Param request body model.ScheduleRequest true "定时任务"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/schedules/{id} [put]
+func UpdateSchedule(c *gin.Context) {
+	var req model.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	schedule, err := pcsschedule.Default.Update(c.Param("id"), scheduleSpec(&req))
+	if err == pcsschedule.ErrScheduleNotFound {
+		scheduleError(c, err)
+		return
+	}
+	if schedule == nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	resp := gin.H{
+		"message":  "已修改定时任务",
+		"schedule": schedule,
+	}
+	if err != nil {
+		resp["warning"] = "保存定时任务失败: " + err.Error()
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(resp))
+}
+
+// DeleteSchedule 删除定时任务
+// @Summary 删除定时任务
+// @Description 删除定时任务, 正在执行的任务会被取消
+// @Tags 定时任务
+// @Produce json
+// @Param id path string true "定时任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/schedules/{id} [delete]
+func DeleteSchedule(c *gin.Context) {
+	err := pcsschedule.Default.Delete(c.Param("id"))
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "已删除定时任务",
+	}))
+}
+
+// RunSchedule 立即执行定时任务
+// @Summary 立即执行定时任务
+// @Description 在后台立即执行一次, 不影响下次执行时间, 结果记录在执行记录中
+// @Tags 定时任务
+// @Produce json
+// @Param id path string true "定时任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/schedules/{id}/run [post]
+func RunSchedule(c *gin.Context) {
+	schedule, err := pcsschedule.Default.Trigger(pcscommand.GetBaiduPCS(), c.Param("id"))
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message":  "定时任务已在后台执行",
+		"schedule": schedule,
+	}))
+}
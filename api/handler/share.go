@@ -34,7 +34,7 @@ func ShareSet(c *gin.Context) {
 		return
 	}
 
-	pcspaths, err := matchPaths(req.Paths...)
+	pcspaths, err := matchPaths(c.Request.Context(), req.Paths...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -45,8 +45,7 @@ func ShareSet(c *gin.Context) {
 		Period:   req.Period,
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	shared, err := pcs.ShareSet(pcspaths, option)
+	shared, err := storage.ShareSet(c.Request.Context(), pcspaths, option)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -78,23 +77,13 @@ func ShareList(c *gin.Context) {
 		}
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	records, err := pcs.ShareList(page)
+	// 私密分享的提取码由存储后端补充
+	records, err := storage.ShareList(c.Request.Context(), page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
 	}
 
-	// 补充获取密码逻辑
-	for _, record := range records {
-		if record.Public == 0 && record.ExpireType != -1 {
-			info, err := pcs.ShareSURLInfo(record.ShareID)
-			if err == nil {
-				record.Passwd = strings.TrimSpace(info.Pwd)
-			}
-		}
-	}
-
 	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
 		"page":    page,
 		"records": records,
@@ -125,8 +114,7 @@ func ShareCancel(c *gin.Context) {
 		return
 	}
 
-	pcs := pcscommand.GetBaiduPCS()
-	err := pcs.ShareCancel(req.ShareIDs)
+	err := storage.ShareCancel(c.Request.Context(), req.ShareIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
 		return
@@ -149,6 +137,7 @@ func ShareCancel(c *gin.Context) {
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
 // @Failure 500 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/transfer [post]
 func Transfer(c *gin.Context) {
 	var req model.TransferRequest
@@ -192,7 +181,7 @@ func Transfer(c *gin.Context) {
 	pcs := pcscommand.GetBaiduPCS()
 
 	// 1. 访问页面获取 tokens
-	tokens := pcs.AccessSharePage(featureStr, true)
+	tokens := pcs.AccessSharePageContext(c.Request.Context(), featureStr, true)
 	if tokens["ErrMsg"] != "0" {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, tokens["ErrMsg"]))
 		return
@@ -205,7 +194,7 @@ func Transfer(c *gin.Context) {
 		"clienttype": "1",
 		"uk":         tokens["share_uk"],
 	}).String()
-	res := pcs.PostShareQuery(verifyUrl, req.ShareURL, map[string]string{
+	res := pcs.PostShareQueryContext(c.Request.Context(), verifyUrl, req.ShareURL, map[string]string{
 		"pwd":       extractCode,
 		"vcode":     "null",
 		"vcode_str": "null",
@@ -219,7 +208,7 @@ func Transfer(c *gin.Context) {
 	pcs.UpdatePCSCookies(true)
 
 	// 3. 再次获取 tokens
-	tokens = pcs.AccessSharePage(featureStr, false)
+	tokens = pcs.AccessSharePageContext(c.Request.Context(), featureStr, false)
 	if tokens["ErrMsg"] != "0" {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, tokens["ErrMsg"]))
 		return
@@ -235,7 +224,7 @@ func Transfer(c *gin.Context) {
 		"channel":  "chunlei",
 	}
 	queryShareInfoUrl := pcs.GenerateShareQueryURL("list", featureMap).String()
-	transMetas := pcs.ExtractShareInfo(queryShareInfoUrl, tokens["shareid"], tokens["share_uk"], tokens["bdstoken"])
+	transMetas := pcs.ExtractShareInfoContext(c.Request.Context(), queryShareInfoUrl, tokens["shareid"], tokens["share_uk"], tokens["bdstoken"])
 
 	if transMetas["ErrMsg"] != "success" {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, transMetas["ErrMsg"]))
@@ -250,14 +239,14 @@ func Transfer(c *gin.Context) {
 	if transMetas["item_num"] != "1" && req.Collect {
 		transMetas["filename"] += "等文件"
 		transMetas["path"] = path.Join(savePath, transMetas["filename"])
-		pcs.Mkdir(transMetas["path"])
+		pcs.MkdirContext(c.Request.Context(), transMetas["path"])
 	}
 
 	transMetas["referer"] = "https://pan.baidu.com/s/" + featureStr
 	pcs.UpdatePCSCookies(true)
 
 	// 6. 执行转存
-	resp := pcs.GenerateRequestQuery("POST", transMetas)
+	resp := pcs.GenerateRequestQueryContext(c.Request.Context(), "POST", transMetas)
 	if resp["ErrNo"] != "0" {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, resp["ErrMsg"]))
 		return
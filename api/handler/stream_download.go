@@ -24,7 +24,7 @@ const (
 
 // StreamDownload 流式代理下载
 // @Summary 流式下载文件
-// @Description 代理下载网盘文件，解决浏览器防盗链问题。后端使用正确的 User-Agent 请求百度服务器，然后流式转发给浏览器。
+// @Description 代理下载网盘文件，解决浏览器防盗链问题。后端使用正确的 User-Agent 请求百度服务器，然后流式转发给浏览器。加密目录中的文件不支持代理下载。
 // @Tags 上传下载
 // @Produce octet-stream
 // @Param path query string true "网盘文件路径，如 /视频/电影.mp4"
@@ -39,6 +39,11 @@ func StreamDownload(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "path 参数必填"))
 		return
 	}
+	if inCryptDir(path) {
+		// 代理转发的是密文, 不能在流中解密
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, cryptNoDirectLink))
+		return
+	}
 
 	cookie := c.Query("cookie")
 	if cookie == "" {
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcssync"
+)
+
+// StartSync 开始同步任务
+// @Summary 开始同步任务
+// @Description 在后台同步服务器本地目录和网盘目录, 通过 /api/sync/{id} 查询结果. dry_run 时只列出需要执行的操作, interval 大于0时周期执行直到取消
+// @Tags 同步
+// @Accept json
+// @Produce json
+// @Param request body model.SyncRequest true "同步请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/sync [post]
+func StartSync(c *gin.Context) {
+	var req model.SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	job, err := pcssync.DefaultJobs.Start(pcscommand.GetBaiduPCS(), pcssync.Options{
+		Direction: pcssync.Direction(req.Direction),
+		LocalDir:  req.LocalDir,
+		RemoteDir: joinPath(req.RemoteDir),
+		CheckMD5:  req.CheckMD5,
+		Delete:    req.Delete,
+		DryRun:    req.DryRun,
+		Parallel:  req.Parallel,
+		Interval:  req.Interval,
+		Force:     req.Force,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "同步任务已在后台启动",
+		"job_id":  job.ID,
+	}))
+}
+
+// ListSync 列出同步任务
+// @Summary 列出同步任务
+// @Description 列出同步任务及其统计结果
+// @Tags 同步
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/sync [get]
+func ListSync(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"jobs": pcssync.DefaultJobs.List(),
+	}))
+}
+
+// GetSync 获取同步任务详情
+// @Summary 获取同步任务详情
+// @Description 获取同步任务的状态和每个文件的操作
+// @Tags 同步
+// @Produce json
+// @Param id path string true "同步任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/sync/{id} [get]
+func GetSync(c *gin.Context) {
+	job, err := pcssync.DefaultJobs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(job))
+}
+
+// CancelSync 取消同步任务
+// @Summary 取消同步任务
+// @Description 取消正在执行的同步任务, 已完成的文件不会回滚
+// @Tags 同步
+// @Produce json
+// @Param id path string true "同步任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/sync/{id}/cancel [post]
+func CancelSync(c *gin.Context) {
+	err := pcssync.DefaultJobs.Cancel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "同步任务已取消",
+	}))
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+)
+
+// ListTransfers 列出后台传输任务
+// @Summary 列出传输任务
+// @Description 列出通过 /api/download 和 /api/upload 发起的后台传输任务
+// @Tags 上传下载
+// @Produce json
+// @Success 200 {object} model.Response
+// @Router /api/transfers [get]
+func ListTransfers(c *gin.Context) {
+	list := pcstransfer.Default.List()
+	infos := make([]*pcstransfer.TransferInfo, 0, len(list))
+	for _, t := range list {
+		infos = append(infos, t.Info(false))
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"transfers": infos,
+	}))
+}
+
+// GetTransfer 获取传输任务详情
+// @Summary 获取传输任务详情
+// @Description 获取传输任务及其中各个文件的状态
+// @Tags 上传下载
+// @Produce json
+// @Param id path string true "传输任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/transfers/{id} [get]
+func GetTransfer(c *gin.Context) {
+	t, err := pcstransfer.Default.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(t.Info(true)))
+}
+
+// PauseTransfer 暂停传输任务
+// @Summary 暂停传输任务
+// @Description 暂停整个传输批次, 或指定 file_id 只暂停单个文件. 暂停的下载会立即保存断点信息
+// @Tags 上传下载
+// @Accept json
+// @Produce json
+// @Param id path string true "传输任务id"
+// @Param request body model.TransferControlRequest false "暂停请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/transfers/{id}/pause [post]
+func PauseTransfer(c *gin.Context) {
+	controlTransfer(c, (*pcstransfer.Transfer).Pause)
+}
+
+// ResumeTransfer 恢复传输任务
+// @Summary 恢复传输任务
+// @Description 恢复整个传输批次, 或指定 file_id 只恢复单个文件
+// @Tags 上传下载
+// @Accept json
+// @Produce json
+// @Param id path string true "传输任务id"
+// @Param request body model.TransferControlRequest false "恢复请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/transfers/{id}/resume [post]
+func ResumeTransfer(c *gin.Context) {
+	controlTransfer(c, (*pcstransfer.Transfer).Resume)
+}
+
+// CancelTransfer 取消传输任务
+// @Summary 取消传输任务
+// @Description 取消整个传输批次, 或指定 file_id 只取消单个文件. 正在传输的文件会立即中断
+// @Tags 上传下载
+// @Accept json
+// @Produce json
+// @Param id path string true "传输任务id"
+// @Param request body model.TransferControlRequest false "取消请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/transfers/{id}/cancel [post]
+func CancelTransfer(c *gin.Context) {
+	controlTransfer(c, (*pcstransfer.Transfer).Cancel)
+}
+
+// TopTransfer 优先传输文件
+// @Summary 优先传输文件
+// @Description 将等待中的文件移到队列最前面, 下一个开始传输
+// @Tags 上传下载
+// @Accept json
+// @Produce json
+// @Param id path string true "传输任务id"
+// @Param request body model.TransferControlRequest true "文件id"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/transfers/{id}/top [post]
+func TopTransfer(c *gin.Context) {
+	controlTransfer(c, (*pcstransfer.Transfer).MoveToFront)
+}
+
+// SetTransferParallel 修改传输并发量
+// @Summary 修改传输并发量
+// @Description 修改传输批次同时传输的文件数量, 执行中修改立即生效
+// @Tags 上传下载
+// @Accept json
+// @Produce json
+// @Param id path string true "传输任务id"
+// @Param request body model.TransferParallelRequest true "并发量"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /api/transfers/{id}/parallel [post]
+func SetTransferParallel(c *gin.Context) {
+	var req model.TransferParallelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	t, err := pcstransfer.Default.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+
+	t.SetParallel(req.Parallel)
+	c.JSON(http.StatusOK, model.SuccessResponse(t.Info(false)))
+}
+
+func controlTransfer(c *gin.Context, control func(t *pcstransfer.Transfer, fileID string) error) {
+	var req model.TransferControlRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+			return
+		}
+	}
+
+	t, err := pcstransfer.Default.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+
+	err = control(t, req.FileID)
+	switch err {
+	case nil:
+	case pcstransfer.ErrItemNotFound:
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	default:
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse(t.Info(req.FileID != "")))
+}
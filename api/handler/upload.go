@@ -11,12 +11,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
-	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsupload"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
-	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
-	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
 )
 
 // Upload 上传服务器本地文件到网盘
@@ -72,38 +69,28 @@ func handleServerSideUpload(c *gin.Context) {
 	}
 
 	// 路径处理
-	targetDir, err := matchPath(req.TargetDir)
+	targetDir, err := matchPath(c.Request.Context(), req.TargetDir)
 	if err != nil {
 		// 目标必须存在？或者如果是新目录？
 		// 为了简单，我们尝试创建或使用
 		// 如果 matchPath 失败（不存在），我们使用 PathJoin
-		user := pcsconfig.Config.ActiveUser()
-		targetDir = user.PathJoin(req.TargetDir)
-		pcscommand.GetBaiduPCS().Mkdir(targetDir) // 尝试创建
+		targetDir = joinPath(req.TargetDir)
+		storage.Mkdir(c.Request.Context(), targetDir) // 尝试创建
 	}
 
-	// 准备上传
-	pcs := pcscommand.GetBaiduPCS()
-	uploadDatabase, err := pcsupload.NewUploadingDatabase()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "无法初始化上传数据库"))
-		return
-	}
-	// defer uploadDatabase.Close() // 不能在这里关闭，因为要在 goroutine 使用？
-	// 不，TaskExecutor 执行完才关闭。
-	// 如果异步，需要小心。
-
-	executor := &taskframework.TaskExecutor{
-		IsFailedDeque: true,
-	}
-	statistic := &pcsupload.UploadStatistic{}
-
-	optParallel := pcsconfig.Config.MaxUploadParallel
-	optLoad := pcsconfig.Config.MaxUploadLoad
+	// 准备上传, 可通过 /api/transfers 暂停和恢复
 	optPolicy := req.Policy
 	if optPolicy == "" {
 		optPolicy = pcsconfig.Config.UPolicy
 	}
+	if !isBaiduStorage() {
+		uploadToStorage(c, req.LocalPaths, targetDir, optPolicy)
+		return
+	}
+	t := pcstransfer.Default.New(pcstransfer.KindUpload, pcstransfer.Options{
+		Parallel: pcsconfig.Config.MaxUploadLoad,
+		Policy:   optPolicy,
+	})
 
 	var tasks []string
 
@@ -119,42 +106,29 @@ func handleServerSideUpload(c *gin.Context) {
 			relPath, _ := filepath.Rel(filepath.Dir(localPath), file)
 			savePath := path.Clean(targetDir + baidupcs.PathSeparator + filepath.ToSlash(relPath))
 
-			unit := pcsupload.UploadTaskUnit{
-				LocalFileChecksum: checksum.NewLocalFileChecksum(file, int(baidupcs.SliceMD5Size)),
-				SavePath:          savePath,
-				PCS:               pcs,
-				UploadingDatabase: uploadDatabase,
-				Parallel:          optParallel,
-				PrintFormat:       "", // Silent
-				NoRapidUpload:     false,
-				NoSplitFile:       false,
-				UploadStatistic:   statistic,
-				Policy:            optPolicy,
+			_, err = t.AppendUpload(file, savePath)
+			if err != nil {
+				pcstransfer.Default.Remove(t.ID)
+				c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "无法初始化上传数据库"))
+				return
 			}
-
-			executor.Append(&unit, 3)
 			tasks = append(tasks, fmt.Sprintf("%s -> %s", file, savePath))
 		}
 	}
 
 	if len(tasks) == 0 {
-		uploadDatabase.Close()
+		pcstransfer.Default.Remove(t.ID)
 		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "没有可上传的文件"))
 		return
 	}
 
 	// 异步执行
-	go func() {
-		defer uploadDatabase.Close()
-		executor.SetParallel(optLoad)
-		statistic.StartTimer()
-		executor.Execute()
-		fmt.Printf("API Upload finished: %d files\n", len(tasks))
-	}()
+	t.Start()
 
 	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-		"message": "上传任务已在后台启动",
-		"files":   tasks,
+		"message":     "上传任务已在后台启动",
+		"transfer_id": t.ID,
+		"files":       tasks,
 	}))
 }
 
@@ -189,53 +163,82 @@ func handleMultipartUpload(c *gin.Context) {
 
 	// 为了简单，我们这里进行**同步上传**
 
-	pcs := pcscommand.GetBaiduPCS()
-	user := pcsconfig.Config.ActiveUser()
-	finalTargetDir := user.PathJoin(targetDir)
+	finalTargetDir := joinPath(targetDir)
 	savePath := path.Join(finalTargetDir, header.Filename)
 
-	// 调用 PCS 上传
-	// 我们可以复用 pcsupload，或者直接调用 pcs.Upload (更底层)
-	// pcs.Upload(savePath, func(uploadURL string, jar http.CookieJar) ...)
-	// 这比较复杂。
-	// 复用 pcsupload.UploadTaskUnit 是最稳妥的
-
-	uploadDatabase, _ := pcsupload.NewUploadingDatabase()
-	defer uploadDatabase.Close()
-
-	statistic := &pcsupload.UploadStatistic{}
-	unit := pcsupload.UploadTaskUnit{
-		LocalFileChecksum: checksum.NewLocalFileChecksum(tempPath, int(baidupcs.SliceMD5Size)),
-		SavePath:          savePath,
-		PCS:               pcs,
-		UploadingDatabase: uploadDatabase,
-		Parallel:          pcsconfig.Config.MaxUploadParallel,
-		NoRapidUpload:     false,
-		NoSplitFile:       false,
-		UploadStatistic:   statistic,
-		Policy:            baidupcs.OverWritePolicy, // 默认覆盖?
+	if !isBaiduStorage() {
+		err = storage.Upload(c.Request.Context(), tempPath, savePath, baidupcs.OverWritePolicy)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "上传失败: "+err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+			"path": savePath,
+			"size": header.Size,
+		}))
+		return
 	}
 
-	// 执行上传 (TaskUnit 实现了 TaskUnit 接口，可以直接运行 Run?)
-	// 不，TaskExecutor 调用 Run。
-	// 我们手动运行 Run 逻辑?
-	// pcsupload 的 Run 逻辑稍微复杂。
-	// 让我们构建一个只包含一个任务的 Executor 并同步运行
+	// 复用 pcsupload.UploadTaskUnit, 临时文件上传完成即删除, 不持久化
+	t := pcstransfer.Default.New(pcstransfer.KindUpload, pcstransfer.Options{
+		Policy:    baidupcs.OverWritePolicy, // 默认覆盖?
+		NoPersist: true,
+	})
+	_, err = t.AppendUpload(tempPath, savePath)
+	if err != nil {
+		pcstransfer.Default.Remove(t.ID)
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "无法初始化上传数据库"))
+		return
+	}
 
-	executor := &taskframework.TaskExecutor{}
-	executor.Append(&unit, 3)
-	executor.Execute()
+	// 同步执行
+	t.Execute()
 
-	// 检查结果
-	// 获取状态? unit 没有直接导出 Result
-	// executor.FailedDeque() check
-	if executor.FailedDeque().Size() > 0 {
+	if t.Failed() > 0 || t.Skipped() > 0 {
 		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "上传失败"))
 		return
 	}
 
 	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
-		"path": savePath,
-		"size": header.Size,
+		"transfer_id": t.ID,
+		"path":        savePath,
+		"size":        header.Size,
+	}))
+}
+
+// uploadToStorage 通过存储后端同步上传服务器本地文件, 用于百度网盘以外的存储后端
+func uploadToStorage(c *gin.Context, localPaths []string, targetDir, policy string) {
+	var (
+		tasks  []string
+		failed []string
+	)
+	for _, localPath := range localPaths {
+		walkedFiles, err := pcsutil.WalkDir(localPath, "")
+		if err != nil {
+			continue
+		}
+
+		for _, file := range walkedFiles {
+			relPath, _ := filepath.Rel(filepath.Dir(localPath), file)
+			savePath := path.Clean(targetDir + baidupcs.PathSeparator + filepath.ToSlash(relPath))
+
+			err = storage.Upload(c.Request.Context(), file, savePath, policy)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", file, err))
+				continue
+			}
+			tasks = append(tasks, fmt.Sprintf("%s -> %s", file, savePath))
+		}
+	}
+
+	if len(tasks) == 0 && len(failed) == 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "没有可上传的文件"))
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "上传完成",
+		"files":   tasks,
+		"failed":  failed,
 	}))
 }
@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcswatch"
+)
+
+// StartRemoteWatch 开始监听网盘目录
+// @Summary 开始监听网盘目录
+// @Description 定期检测网盘目录中新增, 修改和删除的文件, 文件稳定后将事件 POST 到 callbacks, 同时可通过 /api/watch/events 订阅
+// @Tags 监听
+// @Accept json
+// @Produce json
+// @Param request body model.RemoteWatchRequest true "监听请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/remote [post]
+func StartRemoteWatch(c *gin.Context) {
+	var req model.RemoteWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	dirs := make([]string, 0, len(req.Dirs))
+	for _, dir := range req.Dirs {
+		dirs = append(dirs, joinPath(dir))
+	}
+	watch, err := pcswatch.Default.StartRemote(pcscommand.GetBaiduPCS(), pcswatch.RemoteOptions{
+		Dirs:      dirs,
+		Callbacks: req.Callbacks,
+		Mode:      pcswatch.RemoteMode(req.Mode),
+		Recurse:   req.Recurse,
+		Interval:  req.Interval,
+		Debounce:  req.Debounce,
+	})
+	if watch == nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	resp := gin.H{
+		"message": "已开始监听",
+		"watch":   watch,
+	}
+	if err != nil {
+		resp["warning"] = "保存监听配置失败: " + err.Error()
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(resp))
+}
+
+// ListRemoteWatch 列出网盘目录监听任务
+// @Summary 列出网盘目录监听任务
+// @Description 列出网盘目录监听任务及其状态
+// @Tags 监听
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/remote [get]
+func ListRemoteWatch(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"watches": pcswatch.Default.ListRemote(),
+	}))
+}
+
+// StopRemoteWatch 停止网盘目录监听任务
+// @Summary 停止网盘目录监听任务
+// @Description 停止并删除网盘目录监听任务, 尚未稳定的变化不会发出事件
+// @Tags 监听
+// @Produce json
+// @Param id path string true "监听任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/remote/{id}/stop [post]
+func StopRemoteWatch(c *gin.Context) {
+	err := pcswatch.Default.StopRemote(c.Param("id"))
+	if err == pcswatch.ErrWatchNotFound {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "已停止监听",
+	}))
+}
+
+// StartLocalWatch 监听本地目录并自动上传
+// @Summary 监听本地目录并自动上传
+// @Description 监听服务器本地目录, 新增或修改的文件大小稳定后上传到网盘, 可在校验后删除或移动本地文件. 开始时扫描整个目录, 重启后自动恢复
+// @Tags 监听
+// @Accept json
+// @Produce json
+// @Param request body model.LocalWatchRequest true "监听请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/local [post]
+func StartLocalWatch(c *gin.Context) {
+	var req model.LocalWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	watch, err := pcswatch.Default.StartLocal(pcscommand.GetBaiduPCS(), pcswatch.LocalOptions{
+		LocalDir:  req.LocalDir,
+		RemoteDir: joinPath(req.RemoteDir),
+		Include:   req.Include,
+		Exclude:   req.Exclude,
+		Policy:    req.Policy,
+		Settle:    req.Settle,
+		Parallel:  req.Parallel,
+		After:     pcswatch.AfterUpload(req.After),
+		MoveTo:    req.MoveTo,
+	})
+	if watch == nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	resp := gin.H{
+		"message": "已开始监听",
+		"watch":   watch,
+	}
+	if err != nil {
+		resp["warning"] = "保存监听配置失败: " + err.Error()
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(resp))
+}
+
+// ListLocalWatch 列出本地目录监听任务
+// @Summary 列出本地目录监听任务
+// @Description 列出本地目录监听任务及其上传统计
+// @Tags 监听
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/local [get]
+func ListLocalWatch(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"watches": pcswatch.Default.ListLocal(),
+	}))
+}
+
+// StopLocalWatch 停止本地目录监听任务
+// @Summary 停止本地目录监听任务
+// @Description 停止并删除本地目录监听任务, 正在上传的文件会被取消
+// @Tags 监听
+// @Produce json
+// @Param id path string true "监听任务id"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/local/{id}/stop [post]
+func StopLocalWatch(c *gin.Context) {
+	err := pcswatch.Default.StopLocal(c.Param("id"))
+	if err == pcswatch.ErrWatchNotFound {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"message": "已停止监听",
+	}))
+}
+
+// WatchEvents 订阅文件变化事件
+// @Summary 订阅文件变化事件
+// @Description 以 Server-Sent Events 推送文件变化事件, 事件 id 递增. 通过 after 参数或 Last-Event-ID 请求头补发缓存中该 id 之后的事件
+// @Tags 监听
+// @Produce text/event-stream
+// @Param after query int false "补发该 id 之后的事件"
+// @Param watch_id query string false "只推送该监听任务的事件"
+// @Success 200 {object} pcswatch.Event
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/watch/events [get]
+func WatchEvents(c *gin.Context) {
+	after := c.Query("after")
+	if after == "" {
+		after = c.GetHeader("Last-Event-ID")
+	}
+	afterID, _ := strconv.ParseInt(after, 10, 64)
+	watchID := c.Query("watch_id")
+
+	backlog, ch, cancel := pcswatch.Default.Hub.Subscribe(afterID)
+	defer cancel()
+
+	// 事件流不受服务器写超时限制
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	send := func(e *pcswatch.Event) {
+		if watchID != "" && e.WatchID != watchID {
+			return
+		}
+		c.Render(-1, sse.Event{
+			Id:    strconv.FormatInt(e.ID, 10),
+			Event: string(e.Type),
+			Data:  e,
+		})
+	}
+	for _, e := range backlog {
+		send(e)
+	}
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case e := <-ch:
+			send(e)
+		case <-keepalive.C:
+			io.WriteString(w, ": keepalive\n\n")
+		}
+		return true
+	})
+}
@@ -1,70 +1,155 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
-	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 )
 
+// xpanClient 辅助函数：使用请求中的 access_token 或当前帐号保存的 accesstoken 创建 xpan 客户端,
+// 保存的 accesstoken 即将过期时会先刷新
+func xpanClient(c *gin.Context, accessToken string) (*xpan.Client, bool) {
+	if accessToken == "" {
+		accessToken = c.Query("access_token")
+	}
+	if accessToken == "" {
+		var err error
+		accessToken, err = pcsconfig.Config.ActiveUser().XpanAccessToken(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, model.ErrorResponse(401, err.Error()))
+			return nil, false
+		}
+	}
+	return xpan.NewClient(accessToken, pcsconfig.Config.HTTPClient()), true
+}
+
+// xpanError 辅助函数：按 xpan 错误码返回对应的状态码
+func xpanError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case xpan.IsAccessTokenInvalid(err):
+		code = http.StatusUnauthorized
+	case xpan.IsNotExist(err):
+		code = http.StatusNotFound
+	default:
+		if xe, ok := err.(*xpan.ErrorInfo); ok && xe.ErrNo == xpan.ErrnoRateLimited {
+			code = http.StatusTooManyRequests
+		}
+	}
+	c.JSON(code, model.ErrorResponse(code, err.Error()))
+}
+
 // XpanListFiles 获取文件列表
 // @Summary 获取百度网盘文件列表
-// @Description 使用 AccessToken 通过 xpan API 获取指定目录的文件列表
+// @Description 使用 AccessToken 通过 xpan API 获取指定目录的文件列表, 自动翻页, recursion 为 true 时递归列出子目录
 // @Tags 文件管理
 // @Accept json
 // @Produce json
-// @Param access_token query string true "百度 AccessToken"
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
 // @Param dir query string false "目录路径" default("/")
+// @Param order query string false "排序字段: name, time 或 size"
+// @Param desc query bool false "倒序"
+// @Param recursion query bool false "递归列出子目录"
+// @Param start query int false "起始位置, 递归时为上次返回的 cursor"
+// @Param limit query int false "返回的数量, 0 为全部"
+// @Param mtime query int false "递归时只返回修改时间晚于该时间的文件"
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/xpan/files [get]
 func XpanListFiles(c *gin.Context) {
-	accessToken := c.Query("access_token")
-	if accessToken == "" {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "缺少 access_token 参数"))
+	var req model.XpanListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
 		return
 	}
+	client, ok := xpanClient(c, req.AccessToken)
+	if !ok {
+		return
+	}
+	if req.Dir == "" {
+		req.Dir = "/"
+	}
 
-	dir := c.DefaultQuery("dir", "/")
-
-	// 调用百度 xpan API
-	apiURL := fmt.Sprintf("https://pan.baidu.com/rest/2.0/xpan/file?method=list&dir=%s&access_token=%s",
-		url.QueryEscape(dir), accessToken)
-
-	req, err := http.NewRequest("GET", apiURL, nil)
+	order := xpan.OrderOptions{Order: req.Order, Desc: req.Desc}
+	var (
+		list xpan.FileList
+		err  error
+	)
+	if req.Recursion {
+		list, err = client.ListAll(c.Request.Context(), req.Dir, &xpan.ListAllOptions{
+			OrderOptions: order,
+			Recursion:    true,
+			Start:        req.Start,
+			Limit:        req.Limit,
+			Mtime:        req.Mtime,
+		})
+	} else {
+		list, err = client.List(c.Request.Context(), req.Dir, &xpan.ListOptions{
+			OrderOptions: order,
+			Start:        req.Start,
+			Limit:        req.Limit,
+		})
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "创建请求失败: "+err.Error()))
+		xpanError(c, err)
 		return
 	}
 
-	req.Header.Set("User-Agent", "pan.baidu.com")
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"dir":   req.Dir,
+		"list":  list,
+		"total": len(list),
+	}))
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "请求失败: "+err.Error()))
+// XpanSearch 搜索文件
+// @Summary 搜索百度网盘文件
+// @Description 使用 AccessToken 通过 xpan API 按关键字搜索文件, 自动翻页
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param key query string true "关键字"
+// @Param dir query string false "搜索的目录" default("/")
+// @Param recursion query bool false "搜索子目录"
+// @Param limit query int false "返回的数量, 0 为全部"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/search [get]
+func XpanSearch(c *gin.Context) {
+	var req model.XpanSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
 		return
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "读取响应失败: "+err.Error()))
+	client, ok := xpanClient(c, req.AccessToken)
+	if !ok {
 		return
 	}
 
-	// 解析百度返回的 JSON
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "解析响应失败: "+err.Error()))
+	list, err := client.Search(c.Request.Context(), req.Key, &xpan.SearchOptions{
+		Dir:       req.Dir,
+		Recursion: req.Recursion,
+		Limit:     req.Limit,
+	})
+	if err != nil {
+		xpanError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, model.SuccessResponse(result))
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"list":  list,
+		"total": len(list),
+	}))
 }
 
 // XpanFileMetadata 获取文件元数据（含下载链接）
@@ -73,56 +158,283 @@ func XpanListFiles(c *gin.Context) {
 // @Tags 文件管理
 // @Accept json
 // @Produce json
-// @Param access_token query string true "百度 AccessToken"
-// @Param fs_id query string true "文件 fs_id"
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param fs_id query string true "文件 fs_id, 多个用逗号分隔"
+// @Param dlink query bool false "返回下载链接" default(true)
 // @Success 200 {object} model.Response
 // @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
 // @Router /api/xpan/file/meta [get]
 func XpanFileMetadata(c *gin.Context) {
-	accessToken := c.Query("access_token")
-	if accessToken == "" {
-		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "缺少 access_token 参数"))
+	client, ok := xpanClient(c, "")
+	if !ok {
 		return
 	}
 
-	fsID := c.Query("fs_id")
-	if fsID == "" {
+	var fsIDs []uint64
+	for _, s := range strings.Split(c.Query("fs_id"), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "fs_id 格式错误: "+s))
+			return
+		}
+		fsIDs = append(fsIDs, id)
+	}
+	if len(fsIDs) == 0 {
 		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "缺少 fs_id 参数"))
 		return
 	}
 
-	// 调用百度 xpan API
-	apiURL := fmt.Sprintf("https://pan.baidu.com/rest/2.0/xpan/multimedia?method=filemetas&dlink=1&fsids=%%5B%s%%5D&access_token=%s",
-		fsID, accessToken)
+	list, err := client.FileMetas(c.Request.Context(), fsIDs, &xpan.MetaOptions{
+		Dlink: c.DefaultQuery("dlink", "true") != "false",
+	})
+	if err != nil {
+		xpanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"list": list,
+	}))
+}
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+// XpanCategoryInfo 获取分类文件统计
+// @Summary 获取分类文件统计
+// @Description 使用 AccessToken 通过 xpan API 获取目录下指定分类的文件个数和总大小
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param category query int true "分类: 1视频 2音频 3图片 4文档 5应用 6其他 7种子"
+// @Param parent_path query string false "目录" default("/")
+// @Param recursion query bool false "包含子目录"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/category/info [get]
+func XpanCategoryInfo(c *gin.Context) {
+	var req model.XpanCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	client, ok := xpanClient(c, req.AccessToken)
+	if !ok {
+		return
+	}
+
+	category := xpan.Category(req.Category)
+	count, err := client.CategoryInfo(c.Request.Context(), category, req.ParentPath, req.Recursion)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "创建请求失败: "+err.Error()))
+		xpanError(c, err)
 		return
 	}
 
-	req.Header.Set("User-Agent", "pan.baidu.com")
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"category": req.Category,
+		"name":     category.String(),
+		"total":    count.Total,
+		"size":     count.Size,
+		"count":    count.Count,
+	}))
+}
+
+// XpanCategoryList 获取分类文件列表
+// @Summary 获取分类文件列表
+// @Description 使用 AccessToken 通过 xpan API 获取目录下指定分类的文件列表, 自动翻页
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param category query int true "分类: 1视频 2音频 3图片 4文档 5应用 6其他 7种子"
+// @Param parent_path query string false "目录" default("/")
+// @Param recursion query bool false "包含子目录"
+// @Param ext query string false "扩展名, 多个用逗号分隔"
+// @Param order query string false "排序字段: name, time 或 size"
+// @Param desc query bool false "倒序"
+// @Param start query int false "起始位置"
+// @Param limit query int false "返回的数量, 0 为全部"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/category/list [get]
+func XpanCategoryList(c *gin.Context) {
+	var req model.XpanCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	client, ok := xpanClient(c, req.AccessToken)
+	if !ok {
+		return
+	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	opt := &xpan.CategoryListOptions{
+		OrderOptions: xpan.OrderOptions{Order: req.Order, Desc: req.Desc},
+		ParentPath:   req.ParentPath,
+		Recursion:    req.Recursion,
+		Start:        req.Start,
+		Limit:        req.Limit,
+	}
+	if req.Ext != "" {
+		opt.Ext = strings.Split(req.Ext, ",")
+	}
+	list, err := client.CategoryList(c.Request.Context(), xpan.Category(req.Category), opt)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "请求失败: "+err.Error()))
+		xpanError(c, err)
 		return
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"list":  list,
+		"total": len(list),
+	}))
+}
+
+// xpanManageResult 辅助函数：返回文件管理操作的结果, 部分失败时同时返回失败的文件
+func xpanManageResult(c *gin.Context, results xpan.ManageResults, err error) {
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "读取响应失败: "+err.Error()))
+		if failed := results.Failed(); len(failed) > 0 {
+			c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+				"message": err.Error(),
+				"results": results,
+				"failed":  failed,
+			}))
+			return
+		}
+		xpanError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"results": results,
+	}))
+}
+
+// xpanCopyMove 复制或移动
+func xpanCopyMove(c *gin.Context, move bool) {
+	var req model.XpanCopyMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	client, ok := xpanClient(c, "")
+	if !ok {
 		return
 	}
 
-	// 解析百度返回的 JSON
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "解析响应失败: "+err.Error()))
+	items := make([]*xpan.CopyMoveItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = xpan.NewCopyMoveItem(item.From, item.To)
+	}
+	var (
+		results xpan.ManageResults
+		err     error
+	)
+	if move {
+		results, err = client.Move(c.Request.Context(), xpan.Ondup(req.Ondup), items...)
+	} else {
+		results, err = client.Copy(c.Request.Context(), xpan.Ondup(req.Ondup), items...)
+	}
+	xpanManageResult(c, results, err)
+}
+
+// XpanCopy 复制文件
+// @Summary 复制文件
+// @Description 使用 AccessToken 通过 xpan API 批量复制文件/目录
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param request body model.XpanCopyMoveRequest true "复制请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/copy [post]
+func XpanCopy(c *gin.Context) {
+	xpanCopyMove(c, false)
+}
+
+// XpanMove 移动文件
+// @Summary 移动文件
+// @Description 使用 AccessToken 通过 xpan API 批量移动文件/目录
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param request body model.XpanCopyMoveRequest true "移动请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/move [post]
+func XpanMove(c *gin.Context) {
+	xpanCopyMove(c, true)
+}
+
+// XpanRename 重命名文件
+// @Summary 重命名文件
+// @Description 使用 AccessToken 通过 xpan API 批量重命名文件/目录
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param request body model.XpanRenameRequest true "重命名请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/rename [post]
+func XpanRename(c *gin.Context) {
+	var req model.XpanRenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	client, ok := xpanClient(c, "")
+	if !ok {
+		return
+	}
+
+	items := make([]*xpan.RenameItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = &xpan.RenameItem{Path: item.Path, Newname: item.Newname}
+	}
+	results, err := client.Rename(c.Request.Context(), items...)
+	xpanManageResult(c, results, err)
+}
+
+// XpanDelete 删除文件
+// @Summary 删除文件
+// @Description 使用 AccessToken 通过 xpan API 批量删除文件/目录, 删除的文件进入回收站
+// @Tags 文件管理
+// @Accept json
+// @Produce json
+// @Param access_token query string false "百度 AccessToken, 默认使用当前帐号设置的 accesstoken"
+// @Param request body model.XpanDeleteRequest true "删除请求"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 401 {object} model.Response
+// @Failure 501 {object} model.Response "存储后端不是百度网盘"
+// @Router /api/xpan/delete [post]
+func XpanDelete(c *gin.Context) {
+	var req model.XpanDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	client, ok := xpanClient(c, "")
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, model.SuccessResponse(result))
+	results, err := client.Delete(c.Request.Context(), req.Paths...)
+	xpanManageResult(c, results, err)
 }
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/model"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+)
+
+// XpanDeviceSession xpan 设备码授权会话
+type XpanDeviceSession struct {
+	DeviceCode *xpan.DeviceCode
+	UID        uint64 // 授权完成后令牌保存到的帐号
+	Name       string
+	Interval   time.Duration
+	NextPoll   time.Time
+	ExpireAt   time.Time
+	mu         sync.Mutex
+}
+
+var (
+	// xpanDeviceSessions 保存进行中的设备码授权会话
+	xpanDeviceSessions = make(map[string]*XpanDeviceSession)
+	xpanDeviceMutex    sync.Mutex
+)
+
+// XpanDeviceStart 开始 xpan 设备码授权
+// @Summary 开始 xpan 授权
+// @Description 获取开放平台设备码, 用户打开 verification_url 输入 user_code 或扫描 qrcode_url 后, 轮询 /api/auth/xpan/device/status 获取令牌, 令牌保存到当前帐号
+// @Tags 账号管理
+// @Produce json
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/auth/xpan/device [post]
+func XpanDeviceStart(c *gin.Context) {
+	au := pcsconfig.Config.ActiveUser()
+	if au.UID == 0 {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "请先登录百度帐号"))
+		return
+	}
+	oauth, err := pcsconfig.Config.XpanOAuth()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+
+	dc, err := oauth.DeviceCode(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "获取设备码失败: "+err.Error()))
+		return
+	}
+
+	now := time.Now()
+	interval := time.Duration(dc.Interval) * time.Second
+	xpanDeviceMutex.Lock()
+	cleanExpiredXpanDeviceSessions(now)
+	xpanDeviceSessions[dc.DeviceCode] = &XpanDeviceSession{
+		DeviceCode: dc,
+		UID:        au.UID,
+		Name:       au.Name,
+		Interval:   interval,
+		NextPoll:   now.Add(interval),
+		ExpireAt:   now.Add(time.Duration(dc.ExpiresIn) * time.Second),
+	}
+	xpanDeviceMutex.Unlock()
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"device_code":      dc.DeviceCode,
+		"user_code":        dc.UserCode,
+		"verification_url": dc.VerificationURL,
+		"qrcode_url":       dc.QrcodeURL,
+		"expires_in":       dc.ExpiresIn,
+		"interval":         dc.Interval,
+	}))
+}
+
+// XpanDeviceStatus 查询 xpan 设备码授权状态
+// @Summary 查询 xpan 授权状态
+// @Description 按设备码的轮询间隔向开放平台查询授权结果, 状态: pending 等待授权, authorized 已授权, expired 设备码已过期, denied 用户拒绝授权
+// @Tags 账号管理
+// @Produce json
+// @Param device_code query string true "设备码"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api/auth/xpan/device/status [get]
+func XpanDeviceStatus(c *gin.Context) {
+	deviceCode := c.Query("device_code")
+	if deviceCode == "" {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, "缺少 device_code 参数"))
+		return
+	}
+
+	xpanDeviceMutex.Lock()
+	session, exists := xpanDeviceSessions[deviceCode]
+	xpanDeviceMutex.Unlock()
+	if !exists {
+		c.JSON(http.StatusNotFound, model.ErrorResponse(404, "授权会话不存在或已过期"))
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	now := time.Now()
+	if now.After(session.ExpireAt) {
+		removeXpanDeviceSession(deviceCode)
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{"status": "expired"}))
+		return
+	}
+	// 未到轮询间隔, 不请求开放平台
+	if now.Before(session.NextPoll) {
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+			"status":      "pending",
+			"retry_after": int64(session.NextPoll.Sub(now).Seconds()) + 1,
+		}))
+		return
+	}
+
+	oauth, err := pcsconfig.Config.XpanOAuth()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.ErrorResponse(400, err.Error()))
+		return
+	}
+	tok, err := oauth.PollToken(c.Request.Context(), deviceCode)
+	switch {
+	case err == nil:
+	case errors.Is(err, xpan.ErrAuthorizationPending), errors.Is(err, xpan.ErrSlowDown):
+		if errors.Is(err, xpan.ErrSlowDown) {
+			session.Interval += 5 * time.Second
+		}
+		session.NextPoll = now.Add(session.Interval)
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+			"status":      "pending",
+			"retry_after": int64(session.Interval.Seconds()),
+		}))
+		return
+	case errors.Is(err, xpan.ErrDeviceCodeExpired):
+		removeXpanDeviceSession(deviceCode)
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{"status": "expired"}))
+		return
+	case errors.Is(err, xpan.ErrAccessDenied):
+		removeXpanDeviceSession(deviceCode)
+		c.JSON(http.StatusOK, model.SuccessResponse(gin.H{"status": "denied"}))
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "查询授权状态失败: "+err.Error()))
+		return
+	}
+
+	baidu, err := pcsconfig.Config.GetBaiduUser(&pcsconfig.BaiduBase{UID: session.UID, Name: session.Name})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "帐号不存在: "+err.Error()))
+		return
+	}
+	baidu.SetXpanToken(tok)
+	if err = pcsconfig.Config.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, model.ErrorResponse(500, "保存配置失败: "+err.Error()))
+		return
+	}
+	removeXpanDeviceSession(deviceCode)
+
+	c.JSON(http.StatusOK, model.SuccessResponse(gin.H{
+		"status":     "authorized",
+		"uid":        baidu.UID,
+		"name":       baidu.Name,
+		"expires_at": tok.ExpiresAt,
+	}))
+}
+
+func removeXpanDeviceSession(deviceCode string) {
+	xpanDeviceMutex.Lock()
+	delete(xpanDeviceSessions, deviceCode)
+	xpanDeviceMutex.Unlock()
+}
+
+// cleanExpiredXpanDeviceSessions 清理过期的设备码会话, 调用方需持有 xpanDeviceMutex
+func cleanExpiredXpanDeviceSessions(now time.Time) {
+	for code, session := range xpanDeviceSessions {
+		if now.After(session.ExpireAt) {
+			delete(xpanDeviceSessions, code)
+		}
+	}
+}
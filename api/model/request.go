@@ -69,9 +69,10 @@ type SearchRequest struct {
 
 // DownloadRequest 下载请求
 type DownloadRequest struct {
-	Paths  []string `json:"paths" binding:"required,min=1"` // 要下载的路径列表
-	Save   bool     `json:"save"`                           // 是否保存到本地
-	SaveTo string   `json:"save_to"`                        // 保存路径
+	Paths     []string `json:"paths" binding:"required,min=1"` // 要下载的路径列表
+	Save      bool     `json:"save"`                           // 是否保存到本地
+	SaveTo    string   `json:"save_to"`                        // 保存路径
+	Overwrite bool     `json:"overwrite"`                      // 覆盖本地已存在的文件
 }
 
 // RecycleRestoreRequest 回收站恢复请求
@@ -128,6 +129,24 @@ type LoginRequest struct {
 	VCodeStr string `json:"vcodestr"`
 }
 
+// LoginCaptchaRequest 提交登录图片验证码请求
+type LoginCaptchaRequest struct {
+	SessionID string `json:"session_id" binding:"required"` // 登录会话ID
+	VCode     string `json:"vcode" binding:"required"`      // 图片验证码
+}
+
+// LoginVerifySendRequest 发送手机/邮箱验证码请求
+type LoginVerifySendRequest struct {
+	SessionID string `json:"session_id" binding:"required"` // 登录会话ID
+	Type      string `json:"type" binding:"required"`       // 验证方式: mobile 或 email
+}
+
+// LoginVerifyRequest 提交手机/邮箱验证码请求
+type LoginVerifyRequest struct {
+	SessionID string `json:"session_id" binding:"required"` // 登录会话ID
+	VCode     string `json:"vcode" binding:"required"`      // 收到的验证码
+}
+
 type UserSwitchRequest struct {
 	UID uint64 `json:"uid" binding:"required"`
 }
@@ -138,3 +157,150 @@ type ServerUploadRequest struct {
 	TargetDir  string   `json:"target_dir" binding:"required"`
 	Policy     string   `json:"policy"`
 }
+
+// TransferControlRequest 暂停/恢复/取消传输任务请求
+type TransferControlRequest struct {
+	FileID string `json:"file_id"` // 文件id, 为空时操作整个批次
+}
+
+// TransferParallelRequest 修改传输并发量请求
+type TransferParallelRequest struct {
+	Parallel int `json:"parallel" binding:"required,min=1"` // 同时传输的文件数量
+}
+
+// SyncRequest 同步请求
+type SyncRequest struct {
+	Direction string `json:"direction"`                     // 同步方向: push, pull 或 bisync, 默认 push
+	LocalDir  string `json:"local_dir" binding:"required"`  // 服务器本地目录
+	RemoteDir string `json:"remote_dir" binding:"required"` // 网盘目录
+	CheckMD5  bool   `json:"check_md5"`                     // 大小相同时比较 md5
+	Delete    bool   `json:"delete"`                        // 删除目标中多余的文件, push 时网盘文件进入回收站
+	DryRun    bool   `json:"dry_run"`                       // 只列出需要执行的操作
+	Parallel  int    `json:"parallel"`                      // 同时传输的文件数量
+	Interval  int    `json:"interval"`                      // 周期执行的间隔 (秒), 为0时只执行一次
+	Force     bool   `json:"force"`                         // 允许源目录为空或删除超过半数文件时的删除
+}
+
+// RemoteWatchRequest 监听网盘目录请求
+type RemoteWatchRequest struct {
+	Dirs      []string `json:"dirs" binding:"required,min=1"` // 监听的网盘目录
+	Callbacks []string `json:"callbacks"`                     // 事件 POST 到的地址
+	Mode      string   `json:"mode"`                          // 检测方式: diff 或 list, 默认 diff
+	Recurse   bool     `json:"recurse"`                       // 包含子目录中的文件
+	Interval  int      `json:"interval"`                      // 检测间隔 (秒), 默认 30
+	Debounce  int      `json:"debounce"`                      // 文件在该时间内没有再变化才发出事件 (秒), 默认与检测间隔相同
+}
+
+// LocalWatchRequest 监听本地目录并自动上传请求
+type LocalWatchRequest struct {
+	LocalDir  string   `json:"local_dir" binding:"required"`  // 服务器本地目录
+	RemoteDir string   `json:"remote_dir" binding:"required"` // 上传到的网盘目录
+	Include   []string `json:"include"`                       // 只上传匹配的文件, 匹配相对路径或文件名
+	Exclude   []string `json:"exclude"`                       // 不上传匹配的文件
+	Policy    string   `json:"policy"`                        // 重名文件策略: skip, overwrite 或 rsync, 默认使用配置
+	Settle    int      `json:"settle"`                        // 文件大小在该时间内不变才上传 (秒), 默认 5
+	Parallel  int      `json:"parallel"`                      // 同时上传的文件数量
+	After     string   `json:"after"`                         // 上传并校验后: delete 删除, move 移动, 默认保留
+	MoveTo    string   `json:"move_to"`                       // after 为 move 时移动到的本地目录
+}
+
+// ScheduleRequest 定时任务请求
+type ScheduleRequest struct {
+	Name       string   `json:"name"`                    // 任务名称
+	Cron       string   `json:"cron" binding:"required"` // cron 表达式, 按东八区时间, 例如 "0 3 * * *", "@daily", "@every 2h"
+	Kind       string   `json:"kind" binding:"required"` // 任务类型: upload, export, offlinedl_query, offlinedl_clear, recycle_clear 或 quota
+	Disabled   bool     `json:"disabled"`                // 暂停执行
+	LocalPaths []string `json:"local_paths"`             // upload: 服务器本地的文件或目录
+	RemoteDir  string   `json:"remote_dir"`              // upload: 网盘保存目录
+	Policy     string   `json:"policy"`                  // upload: 重名文件策略, 默认 rsync
+	Parallel   int      `json:"parallel"`                // upload: 同时上传的文件数量
+	Paths      []string `json:"paths"`                   // export: 网盘文件或目录
+	SavePath   string   `json:"save_path"`               // export: 服务器上的导出文件路径, 默认在配置目录的 exports 中
+	Recursive  bool     `json:"recursive"`               // export: 包含子目录
+	LinkFormat bool     `json:"link_format"`             // export: 以秒传链接格式导出
+	Threshold  float64  `json:"threshold"`               // quota: 使用率达到该百分比时视为失败
+}
+
+// IndexSearchRequest 索引查询请求
+type IndexSearchRequest struct {
+	Name        string `form:"name"`         // 文件名通配符, 例如 *.mp4, 不区分大小写
+	Regex       string `form:"regex"`        // 匹配完整路径的正则表达式
+	Ext         string `form:"ext"`          // 扩展名, 多个用逗号分隔, 例如 mp4,mkv
+	MinSize     int64  `form:"min_size"`     // 最小文件大小 (字节)
+	MaxSize     int64  `form:"max_size"`     // 最大文件大小 (字节)
+	MtimeAfter  int64  `form:"mtime_after"`  // 修改时间不早于 (unix 时间戳)
+	MtimeBefore int64  `form:"mtime_before"` // 修改时间早于 (unix 时间戳)
+	MD5         string `form:"md5"`          // 文件 md5
+	Prefix      string `form:"prefix"`       // 只查询该目录下的文件
+	Type        string `form:"type"`         // file 或 dir, 默认都查询
+	Sort        string `form:"sort"`         // 排序: path, name, size 或 mtime, 默认 path
+	Desc        bool   `form:"desc"`         // 倒序
+	Offset      int    `form:"offset"`       // 跳过的数量
+	Limit       int    `form:"limit"`        // 返回的数量, 默认 100
+	Refresh     bool   `form:"refresh"`      // 查询前先增量更新索引
+}
+
+// DupesRequest 查找重复文件请求
+type DupesRequest struct {
+	Path     string `json:"path"`      // 网盘目录, 默认为根目录
+	MinSize  int64  `json:"min_size"`  // 忽略小于该大小的文件 (字节)
+	Rule     string `json:"rule"`      // 保留规则: oldest, shortest 或 prefer, 默认 oldest
+	Prefer   string `json:"prefer"`    // 优先保留的目录, 用于 prefer 规则
+	UseIndex bool   `json:"use_index"` // 从本地索引读取文件列表, 不请求网盘
+}
+
+// XpanListRequest xpan 文件列表请求
+type XpanListRequest struct {
+	AccessToken string `form:"access_token"` // 百度 AccessToken, 默认使用当前帐号设置的 accesstoken
+	Dir         string `form:"dir"`          // 目录路径, 默认为根目录
+	Order       string `form:"order"`        // 排序字段: name, time 或 size
+	Desc        bool   `form:"desc"`         // 倒序
+	Recursion   bool   `form:"recursion"`    // 递归列出子目录, 使用 listall 接口
+	Start       int    `form:"start"`        // 起始位置, 递归时为上次返回的 cursor
+	Limit       int    `form:"limit"`        // 返回的数量, 0 为全部
+	Mtime       int64  `form:"mtime"`        // 递归时只返回修改时间晚于该时间的文件
+}
+
+// XpanSearchRequest xpan 搜索请求
+type XpanSearchRequest struct {
+	AccessToken string `form:"access_token"`
+	Key         string `form:"key" binding:"required"` // 关键字
+	Dir         string `form:"dir"`                    // 搜索的目录, 默认为根目录
+	Recursion   bool   `form:"recursion"`              // 搜索子目录
+	Limit       int    `form:"limit"`                  // 返回的数量, 0 为全部
+}
+
+// XpanCategoryRequest xpan 分类文件请求
+type XpanCategoryRequest struct {
+	AccessToken string `form:"access_token"`
+	Category    int    `form:"category" binding:"required,min=1,max=7"` // 分类: 1视频 2音频 3图片 4文档 5应用 6其他 7种子
+	ParentPath  string `form:"parent_path"`                             // 目录, 默认为根目录
+	Recursion   bool   `form:"recursion"`                               // 包含子目录
+	Ext         string `form:"ext"`                                     // 扩展名, 多个用逗号分隔, 仅用于列表
+	Order       string `form:"order"`                                   // 排序字段: name, time 或 size, 仅用于列表
+	Desc        bool   `form:"desc"`                                    // 倒序, 仅用于列表
+	Start       int    `form:"start"`                                   // 起始位置, 仅用于列表
+	Limit       int    `form:"limit"`                                   // 返回的数量, 0 为全部, 仅用于列表
+}
+
+// XpanCopyMoveRequest xpan 复制或移动请求
+type XpanCopyMoveRequest struct {
+	Items []struct {
+		From string `json:"from" binding:"required"` // 源路径
+		To   string `json:"to" binding:"required"`   // 目标路径, 包含文件名
+	} `json:"items" binding:"required,min=1,dive"`
+	Ondup string `json:"ondup"` // 目标已存在时: fail, newcopy, overwrite 或 skip, 默认 fail
+}
+
+// XpanRenameRequest xpan 重命名请求
+type XpanRenameRequest struct {
+	Items []struct {
+		Path    string `json:"path" binding:"required"`    // 文件路径
+		Newname string `json:"newname" binding:"required"` // 新文件名
+	} `json:"items" binding:"required,min=1,dive"`
+}
+
+// XpanDeleteRequest xpan 删除请求
+type XpanDeleteRequest struct {
+	Paths []string `json:"paths" binding:"required,min=1"` // 要删除的路径
+}
@@ -42,6 +42,9 @@ func SetupRouter(username, password string, enableAuth bool) *gin.Engine {
 		api.POST("/meta", handler.Meta)     // 获取元数据
 		api.GET("/search", handler.Search)  // 搜索文件
 
+		// 网盘文件变更
+		api.GET("/changes", handler.BaiduOnly, handler.Changes) // 获取 cursor 之后的变更
+
 		// 工作目录管理
 		api.GET("/pwd", handler.Pwd) // 获取当前目录
 		api.POST("/cd", handler.Cd)  // 切换目录
@@ -52,6 +55,69 @@ func SetupRouter(username, password string, enableAuth bool) *gin.Engine {
 		api.POST("/locate", handler.Locate)                 // 获取直链
 		api.GET("/stream-download", handler.StreamDownload) // 流式代理下载
 
+		// 传输任务管理
+		transfers := api.Group("/transfers")
+		{
+			transfers.GET("", handler.ListTransfers)                     // 列出传输任务
+			transfers.GET("/:id", handler.GetTransfer)                   // 传输任务详情
+			transfers.POST("/:id/pause", handler.PauseTransfer)          // 暂停传输
+			transfers.POST("/:id/resume", handler.ResumeTransfer)        // 恢复传输
+			transfers.POST("/:id/cancel", handler.CancelTransfer)        // 取消传输
+			transfers.POST("/:id/top", handler.TopTransfer)              // 优先传输
+			transfers.POST("/:id/parallel", handler.SetTransferParallel) // 修改并发量
+		}
+
+		// 同步任务
+		syncJobs := api.Group("/sync", handler.BaiduOnly)
+		{
+			syncJobs.POST("", handler.StartSync)             // 开始同步
+			syncJobs.GET("", handler.ListSync)               // 列出同步任务
+			syncJobs.GET("/:id", handler.GetSync)            // 同步任务详情
+			syncJobs.POST("/:id/cancel", handler.CancelSync) // 取消同步
+		}
+
+		// 文件监听
+		watch := api.Group("/watch", handler.BaiduOnly)
+		{
+			watch.POST("/remote", handler.StartRemoteWatch)         // 监听网盘目录
+			watch.GET("/remote", handler.ListRemoteWatch)           // 列出网盘目录监听任务
+			watch.POST("/remote/:id/stop", handler.StopRemoteWatch) // 停止监听
+			watch.POST("/local", handler.StartLocalWatch)           // 监听本地目录并自动上传
+			watch.GET("/local", handler.ListLocalWatch)             // 列出本地目录监听任务
+			watch.POST("/local/:id/stop", handler.StopLocalWatch)   // 停止监听
+			watch.GET("/events", handler.WatchEvents)               // 订阅文件变化事件 (SSE)
+		}
+
+		// 本地文件索引
+		index := api.Group("/index", handler.BaiduOnly)
+		{
+			index.GET("", handler.IndexInfo)             // 索引状态
+			index.POST("/build", handler.BuildIndex)     // 建立索引
+			index.POST("/refresh", handler.RefreshIndex) // 增量更新索引
+			index.GET("/search", handler.SearchIndex)    // 在索引中查询
+		}
+
+		// 重复文件
+		dupes := api.Group("/dupes", handler.BaiduOnly)
+		{
+			dupes.POST("", handler.StartDupes)             // 查找重复文件
+			dupes.GET("", handler.ListDupes)               // 列出查找任务
+			dupes.GET("/:id", handler.GetDupes)            // 查找报告
+			dupes.POST("/:id/apply", handler.ApplyDupes)   // 将多余的副本移入回收站
+			dupes.POST("/:id/cancel", handler.CancelDupes) // 取消
+		}
+
+		// 定时任务
+		schedules := api.Group("/schedules", handler.BaiduOnly)
+		{
+			schedules.GET("", handler.ListSchedules)         // 列出定时任务
+			schedules.POST("", handler.CreateSchedule)       // 添加定时任务
+			schedules.GET("/:id", handler.GetSchedule)       // 定时任务详情及执行记录
+			schedules.PUT("/:id", handler.UpdateSchedule)    // 修改定时任务
+			schedules.DELETE("/:id", handler.DeleteSchedule) // 删除定时任务
+			schedules.POST("/:id/run", handler.RunSchedule)  // 立即执行
+		}
+
 		recycle := api.Group("/recycle")
 		{
 			recycle.GET("/list", handler.RecycleList)        // 列出回收站
@@ -69,10 +135,10 @@ func SetupRouter(username, password string, enableAuth bool) *gin.Engine {
 		}
 
 		// 转存接口
-		api.POST("/transfer", handler.Transfer) // 转存分享链接
+		api.POST("/transfer", handler.BaiduOnly, handler.Transfer) // 转存分享链接
 
 		// 离线下载接口
-		cloud := api.Group("/cloud")
+		cloud := api.Group("/cloud", handler.BaiduOnly)
 		{
 			cloud.POST("/add", handler.CloudDlAdd)       // 添加离线任务
 			cloud.POST("/query", handler.CloudDlQuery)   // 查询离线任务
@@ -85,12 +151,21 @@ func SetupRouter(username, password string, enableAuth bool) *gin.Engine {
 		// 账号管理接口
 		auth := api.Group("/auth")
 		{
-			auth.POST("/login", handler.Login)   // 登录
-			auth.POST("/logout", handler.Logout) // 登出
+			auth.POST("/login", handler.Login)                                // 登录
+			auth.GET("/login/captcha/:session_id", handler.LoginCaptchaImage) // 获取登录验证码图片
+			auth.POST("/login/captcha", handler.LoginCaptcha)                 // 提交图片验证码
+			auth.POST("/login/verify/send", handler.LoginVerifySend)          // 发送手机/邮箱验证码
+			auth.POST("/login/verify", handler.LoginVerify)                   // 提交手机/邮箱验证码
+			auth.POST("/import-cookies", handler.ImportCookies)               // 导入浏览器 cookies 登录
+			auth.POST("/logout", handler.Logout)                              // 登出
 			// 扫码登录
 			auth.POST("/qrcode", handler.QRCodeGet)          // 获取二维码
 			auth.GET("/qrcode/status", handler.QRCodeStatus) // 查询扫码状态
+			auth.GET("/qrcode/:file", handler.QRCodeImage)   // 获取本地渲染的二维码图片
 			auth.POST("/qrcode/login", handler.QRCodeLogin)  // 完成扫码登录
+			// xpan 设备码授权
+			auth.POST("/xpan/device", handler.XpanDeviceStart)        // 获取设备码
+			auth.GET("/xpan/device/status", handler.XpanDeviceStatus) // 查询授权状态并保存令牌
 		}
 
 		account := api.Group("/account")
@@ -104,15 +179,23 @@ func SetupRouter(username, password string, enableAuth bool) *gin.Engine {
 		// 配置管理接口
 		config := api.Group("/config")
 		{
-			config.GET("", handler.ConfigGet)      // 获取配置
-			config.POST("/set", handler.ConfigSet) // 设置配置
+			config.GET("", handler.ConfigGet)           // 获取配置
+			config.POST("/set", handler.ConfigSet)      // 设置配置
+			config.GET("/schema", handler.ConfigSchema) // 配置项定义
 		}
 
 		// xpan API 接口（基于 AccessToken）
-		xpan := api.Group("/xpan")
+		xpan := api.Group("/xpan", handler.BaiduOnly)
 		{
-			xpan.GET("/files", handler.XpanListFiles)        // 获取文件列表
-			xpan.GET("/file/meta", handler.XpanFileMetadata) // 获取文件元数据
+			xpan.GET("/files", handler.XpanListFiles)            // 获取文件列表
+			xpan.GET("/search", handler.XpanSearch)              // 搜索文件
+			xpan.GET("/file/meta", handler.XpanFileMetadata)     // 获取文件元数据
+			xpan.GET("/category/info", handler.XpanCategoryInfo) // 获取分类文件统计
+			xpan.GET("/category/list", handler.XpanCategoryList) // 获取分类文件列表
+			xpan.POST("/copy", handler.XpanCopy)                 // 复制文件
+			xpan.POST("/move", handler.XpanMove)                 // 移动文件
+			xpan.POST("/rename", handler.XpanRename)             // 重命名文件
+			xpan.POST("/delete", handler.XpanDelete)             // 删除文件
 		}
 
 		// 健康检查
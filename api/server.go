@@ -11,6 +11,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/qjfoidnh/BaiduPCS-Go/api/handler"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsnotify"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsschedule"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsstorage"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcswatch"
+)
+
+const (
+	// CredentialCheckInterval 检查帐号凭证的间隔
+	CredentialCheckInterval = time.Hour
 )
 
 // Server API 服务器
@@ -21,6 +34,7 @@ type Server struct {
 	username string
 	password string
 	auth     bool
+	storage  pcsstorage.Storage
 }
 
 // NewServer 创建新的 API 服务器
@@ -33,10 +47,57 @@ func NewServer(port int, username, password string, enableAuth bool) *Server {
 	}
 }
 
+// SetStorage 设置存储后端, 默认为当前登录的百度帐号
+func (s *Server) SetStorage(st pcsstorage.Storage) {
+	s.storage = st
+}
+
 // Start 启动服务器
 func (s *Server) Start() error {
+	if s.storage != nil {
+		handler.SetStorage(s.storage)
+	}
+
 	// 设置路由
 	s.router = SetupRouter(s.username, s.password, s.auth)
+
+	// 恢复上次未完成的传输任务
+	n, err := pcstransfer.Default.Restore()
+	if err != nil {
+		log.Printf("恢复传输任务失败: %v", err)
+	} else if n > 0 {
+		log.Printf("已恢复 %d 个未完成的传输任务", n)
+	}
+
+	// 恢复文件监听任务
+	if s.storage == nil || s.storage.Name() == pcsstorage.NameBaidu {
+		n, err = pcswatch.Default.Restore(pcscommand.GetBaiduPCS())
+		if err != nil {
+			log.Printf("恢复文件监听任务失败: %v", err)
+		} else if n > 0 {
+			log.Printf("已恢复 %d 个文件监听任务", n)
+		}
+
+		// 启动定时任务
+		n, err = pcsschedule.Default.Start(pcscommand.GetBaiduPCS)
+		if err != nil {
+			log.Printf("读取定时任务失败: %v", err)
+		} else if n > 0 {
+			log.Printf("已加载 %d 个定时任务", n)
+		}
+	}
+
+	// 定期刷新即将过期的 xpan accesstoken
+	refreshCtx, stopRefresh := context.WithCancel(context.Background())
+	defer stopRefresh()
+	pcsconfig.Config.StartXpanTokenRefresher(refreshCtx, time.Hour)
+
+	// 定期检查帐号凭证, 失效时发出通知
+	pcsconfig.Config.StartCredentialChecker(refreshCtx, CredentialCheckInterval, func(baidu *pcsconfig.Baidu) {
+		state := baidu.Credential()
+		log.Printf("⚠️ 帐号 %s (uid: %d) 凭证状态: %s, %s", baidu.Name, baidu.UID, state.Status, state.Message)
+		pcsnotify.Send(pcsnotify.CredentialInvalid(baidu))
+	})
 	
 	// 创建 HTTP 服务器
 	s.httpSrv = &http.Server{
@@ -53,6 +114,9 @@ func (s *Server) Start() error {
 		if s.auth {
 			log.Printf("🔐 Basic Auth 已启用 (用户名: %s)", s.username)
 		}
+		if s.storage != nil && s.storage.Name() != pcsstorage.NameBaidu {
+			log.Printf("📁 存储后端: %s", s.storage.Name())
+		}
 		log.Printf("📖 API 文档: http://localhost:%d/swagger/index.html", s.port)
 		
 		if err := s.httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
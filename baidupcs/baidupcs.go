@@ -2,6 +2,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/cookiejar"
@@ -455,7 +456,12 @@ func (pcs *BaiduPCS) generatePanURL(subPath string, param map[string]string) *ur
 
 // UK 获取用户 UK
 func (pcs *BaiduPCS) UK() (uk int64, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareUK()
+	return pcs.UKContext(context.Background())
+}
+
+// UKContext 同 UK, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) UKContext(ctx context.Context) (uk int64, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareUKContext(ctx)
 	if pcsError != nil {
 		return
 	}
@@ -482,7 +488,12 @@ func (pcs *BaiduPCS) UK() (uk int64, pcsError pcserror.Error) {
 }
 
 func (pcs *BaiduPCS) BDSToken() (bdstoken string, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareBDStoken()
+	return pcs.BDSTokenContext(context.Background())
+}
+
+// BDSTokenContext 同 BDSToken, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) BDSTokenContext(ctx context.Context) (bdstoken string, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareBDStokenContext(ctx)
 	if pcsError != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/expires"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"time"
@@ -20,8 +21,13 @@ func (pcs *BaiduPCS) deleteCache(dirs []string) {
 
 // CacheFilesDirectoriesList 缓存获取
 func (pcs *BaiduPCS) CacheFilesDirectoriesList(path string, options *OrderOptions) (fdl FileDirectoryList, pcsError pcserror.Error) {
+	return pcs.CacheFilesDirectoriesListContext(context.Background(), path, options)
+}
+
+// CacheFilesDirectoriesListContext 同 CacheFilesDirectoriesList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CacheFilesDirectoriesListContext(ctx context.Context, path string, options *OrderOptions) (fdl FileDirectoryList, pcsError pcserror.Error) {
 	data := pcs.cacheOpMap.CacheOperation(OperationFilesDirectoriesList, path+"_"+string(options.By)+string(options.Order), func() expires.DataExpires {
-		fdl, pcsError = pcs.FilesDirectoriesList(path, options)
+		fdl, pcsError = pcs.FilesDirectoriesListContext(ctx, path, options)
 		if pcsError != nil {
 			return nil
 		}
@@ -35,8 +41,13 @@ func (pcs *BaiduPCS) CacheFilesDirectoriesList(path string, options *OrderOption
 
 // CacheUK 缓存获取
 func (pcs *BaiduPCS) CacheUK() (uk int64, pcsError pcserror.Error) {
+	return pcs.CacheUKContext(context.Background())
+}
+
+// CacheUKContext 同 CacheUK, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CacheUKContext(ctx context.Context) (uk int64, pcsError pcserror.Error) {
 	data := pcs.cacheOpMap.CacheOperation(OperationGetUK, pcs.GetBDUSS(), func() expires.DataExpires {
-		uk, pcsError = pcs.UK()
+		uk, pcsError = pcs.UKContext(ctx)
 		if pcsError != nil {
 			return nil
 		}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
@@ -117,7 +118,12 @@ func (ci *cloudDlTaskInfo) convert() *CloudDlTaskInfo {
 
 // CloudDlAddTask 添加离线下载任务
 func (pcs *BaiduPCS) CloudDlAddTask(sourceURL, savePath string) (taskID int64, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareCloudDlAddTask(sourceURL, savePath)
+	return pcs.CloudDlAddTaskContext(context.Background(), sourceURL, savePath)
+}
+
+// CloudDlAddTaskContext 同 CloudDlAddTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CloudDlAddTaskContext(ctx context.Context, sourceURL, savePath string) (taskID int64, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareCloudDlAddTaskContext(ctx, sourceURL, savePath)
 	if pcsError != nil {
 		return
 	}
@@ -137,7 +143,7 @@ func (pcs *BaiduPCS) CloudDlAddTask(sourceURL, savePath string) (taskID int64, p
 	return taskInfo.TaskID, nil
 }
 
-func (pcs *BaiduPCS) cloudDlQueryTask(op string, taskIDs []int64) (cl CloudDlTaskList, pcsError pcserror.Error) {
+func (pcs *BaiduPCS) cloudDlQueryTask(ctx context.Context, op string, taskIDs []int64) (cl CloudDlTaskList, pcsError pcserror.Error) {
 	errInfo := pcserror.NewPCSErrorInfo(op)
 	if len(taskIDs) == 0 {
 		errInfo.ErrType = pcserror.ErrTypeOthers
@@ -155,7 +161,7 @@ func (pcs *BaiduPCS) cloudDlQueryTask(op string, taskIDs []int64) (cl CloudDlTas
 		taskStrIDs[k] = strconv.FormatInt(taskIDs[k], 10)
 	}
 
-	dataReadCloser, pcsError := pcs.PrepareCloudDlQueryTask(strings.Join(taskStrIDs, ","))
+	dataReadCloser, pcsError := pcs.PrepareCloudDlQueryTaskContext(ctx, strings.Join(taskStrIDs, ","))
 	if pcsError != nil {
 		return
 	}
@@ -196,12 +202,22 @@ func (pcs *BaiduPCS) cloudDlQueryTask(op string, taskIDs []int64) (cl CloudDlTas
 
 // CloudDlQueryTask 精确查询离线下载任务
 func (pcs *BaiduPCS) CloudDlQueryTask(taskIDs []int64) (cl CloudDlTaskList, pcsError pcserror.Error) {
-	return pcs.cloudDlQueryTask(OperationCloudDlQueryTask, taskIDs)
+	return pcs.CloudDlQueryTaskContext(context.Background(), taskIDs)
+}
+
+// CloudDlQueryTaskContext 同 CloudDlQueryTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CloudDlQueryTaskContext(ctx context.Context, taskIDs []int64) (cl CloudDlTaskList, pcsError pcserror.Error) {
+	return pcs.cloudDlQueryTask(ctx, OperationCloudDlQueryTask, taskIDs)
 }
 
 // CloudDlListTask 查询离线下载任务列表
 func (pcs *BaiduPCS) CloudDlListTask() (cl CloudDlTaskList, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareCloudDlListTask()
+	return pcs.CloudDlListTaskContext(context.Background())
+}
+
+// CloudDlListTaskContext 同 CloudDlListTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CloudDlListTaskContext(ctx context.Context) (cl CloudDlTaskList, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareCloudDlListTaskContext(ctx)
 	if pcsError != nil {
 		return
 	}
@@ -241,14 +257,14 @@ func (pcs *BaiduPCS) CloudDlListTask() (cl CloudDlTaskList, pcsError pcserror.Er
 	return cl, nil
 }
 
-func (pcs *BaiduPCS) cloudDlManipTask(op string, taskID int64) (pcsError pcserror.Error) {
+func (pcs *BaiduPCS) cloudDlManipTask(ctx context.Context, op string, taskID int64) (pcsError pcserror.Error) {
 	var dataReadCloser io.ReadCloser
 
 	switch op {
 	case OperationCloudDlCancelTask:
-		dataReadCloser, pcsError = pcs.PrepareCloudDlCancelTask(taskID)
+		dataReadCloser, pcsError = pcs.PrepareCloudDlCancelTaskContext(ctx, taskID)
 	case OperationCloudDlDeleteTask:
-		dataReadCloser, pcsError = pcs.PrepareCloudDlDeleteTask(taskID)
+		dataReadCloser, pcsError = pcs.PrepareCloudDlDeleteTaskContext(ctx, taskID)
 	default:
 		panic("unknown op, " + op)
 	}
@@ -264,17 +280,32 @@ func (pcs *BaiduPCS) cloudDlManipTask(op string, taskID int64) (pcsError pcserro
 
 // CloudDlCancelTask 取消离线下载任务
 func (pcs *BaiduPCS) CloudDlCancelTask(taskID int64) (pcsError pcserror.Error) {
-	return pcs.cloudDlManipTask(OperationCloudDlCancelTask, taskID)
+	return pcs.CloudDlCancelTaskContext(context.Background(), taskID)
+}
+
+// CloudDlCancelTaskContext 同 CloudDlCancelTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CloudDlCancelTaskContext(ctx context.Context, taskID int64) (pcsError pcserror.Error) {
+	return pcs.cloudDlManipTask(ctx, OperationCloudDlCancelTask, taskID)
 }
 
 // CloudDlDeleteTask 删除离线下载任务
 func (pcs *BaiduPCS) CloudDlDeleteTask(taskID int64) (pcsError pcserror.Error) {
-	return pcs.cloudDlManipTask(OperationCloudDlDeleteTask, taskID)
+	return pcs.CloudDlDeleteTaskContext(context.Background(), taskID)
+}
+
+// CloudDlDeleteTaskContext 同 CloudDlDeleteTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CloudDlDeleteTaskContext(ctx context.Context, taskID int64) (pcsError pcserror.Error) {
+	return pcs.cloudDlManipTask(ctx, OperationCloudDlDeleteTask, taskID)
 }
 
 // CloudDlClearTask 清空离线下载任务记录
 func (pcs *BaiduPCS) CloudDlClearTask() (total int, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareCloudDlClearTask()
+	return pcs.CloudDlClearTaskContext(context.Background())
+}
+
+// CloudDlClearTaskContext 同 CloudDlClearTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CloudDlClearTaskContext(ctx context.Context) (total int, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareCloudDlClearTaskContext(ctx)
 	if pcsError != nil {
 		return
 	}
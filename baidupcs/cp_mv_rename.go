@@ -1,13 +1,19 @@
 package baidupcs
 
 import (
+	"context"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"unsafe"
 )
 
 // Rename 重命名文件/目录
 func (pcs *BaiduPCS) Rename(from, to string) (pcsError pcserror.Error) {
-	return pcs.cpmvOp(OperationRename, &CpMvJSON{
+	return pcs.RenameContext(context.Background(), from, to)
+}
+
+// RenameContext 同 Rename, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RenameContext(ctx context.Context, from, to string) (pcsError pcserror.Error) {
+	return pcs.cpmvOp(ctx, OperationRename, &CpMvJSON{
 		From: from,
 		To:   to,
 	})
@@ -15,16 +21,26 @@ func (pcs *BaiduPCS) Rename(from, to string) (pcsError pcserror.Error) {
 
 // Copy 批量拷贝文件/目录
 func (pcs *BaiduPCS) Copy(cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
-	return pcs.cpmvOp(OperationCopy, cpmvJSON...)
+	return pcs.CopyContext(context.Background(), cpmvJSON...)
+}
+
+// CopyContext 同 Copy, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CopyContext(ctx context.Context, cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
+	return pcs.cpmvOp(ctx, OperationCopy, cpmvJSON...)
 }
 
 // Move 批量移动文件/目录
 func (pcs *BaiduPCS) Move(cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
-	return pcs.cpmvOp(OperationMove, cpmvJSON...)
+	return pcs.MoveContext(context.Background(), cpmvJSON...)
+}
+
+// MoveContext 同 Move, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) MoveContext(ctx context.Context, cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
+	return pcs.cpmvOp(ctx, OperationMove, cpmvJSON...)
 }
 
-func (pcs *BaiduPCS) cpmvOp(op string, cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
-	dataReadCloser, err := pcs.prepareCpMvOp(op, cpmvJSON...)
+func (pcs *BaiduPCS) cpmvOp(ctx context.Context, op string, cpmvJSON ...*CpMvJSON) (pcsError pcserror.Error) {
+	dataReadCloser, err := pcs.prepareCpMvOp(ctx, op, cpmvJSON...)
 	if err != nil {
 		return
 	}
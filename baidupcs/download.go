@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
@@ -126,7 +127,12 @@ func (pcs *BaiduPCS) DownloadStreamFile(path string, downloadFunc DownloadFunc)
 
 // LocateDownloadWithUserAgent 获取下载链接
 func (pcs *BaiduPCS) LocateDownload(pcspath string) (info *URLInfo, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareLocateDownload(pcspath)
+	return pcs.LocateDownloadContext(context.Background(), pcspath)
+}
+
+// LocateDownloadContext 同 LocateDownload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) LocateDownloadContext(ctx context.Context, pcspath string) (info *URLInfo, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareLocateDownloadContext(ctx, pcspath)
 	if dataReadCloser != nil {
 		defer dataReadCloser.Close()
 	}
@@ -149,7 +155,12 @@ func (pcs *BaiduPCS) LocateDownload(pcspath string) (info *URLInfo, pcsError pcs
 
 // LocatePanAPIDownload 从百度网盘首页获取下载链接
 func (pcs *BaiduPCS) LocatePanAPIDownload(fidList ...int64) (dlinkInfoList APIDownloadDlinkInfoList, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareLocatePanAPIDownload(fidList...)
+	return pcs.LocatePanAPIDownloadContext(context.Background(), fidList...)
+}
+
+// LocatePanAPIDownloadContext 同 LocatePanAPIDownload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) LocatePanAPIDownloadContext(ctx context.Context, fidList ...int64) (dlinkInfoList APIDownloadDlinkInfoList, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareLocatePanAPIDownloadContext(ctx, fidList...)
 	if dataReadCloser != nil {
 		defer dataReadCloser.Close()
 	}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"errors"
@@ -45,8 +46,8 @@ var (
 	ErrFileTooLarge = errors.New("文件大于20GB, 无法秒传")
 )
 
-func (pcs *BaiduPCS) getLocateDownloadLink(pcspath string) (link string, pcsError pcserror.Error) {
-	info, pcsError := pcs.LocateDownload(pcspath)
+func (pcs *BaiduPCS) getLocateDownloadLink(ctx context.Context, pcspath string) (link string, pcsError pcserror.Error) {
+	info, pcsError := pcs.LocateDownloadContext(ctx, pcspath)
 	if pcsError != nil {
 		return
 	}
@@ -64,6 +65,11 @@ func (pcs *BaiduPCS) getLocateDownloadLink(pcspath string) (link string, pcsErro
 
 // ExportByFileInfo 通过文件信息对象, 导出文件信息
 func (pcs *BaiduPCS) ExportByFileInfo(finfo *FileDirectory) (rinfo *RapidUploadInfo, pcsError pcserror.Error) {
+	return pcs.ExportByFileInfoContext(context.Background(), finfo)
+}
+
+// ExportByFileInfoContext 同 ExportByFileInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) ExportByFileInfoContext(ctx context.Context, finfo *FileDirectory) (rinfo *RapidUploadInfo, pcsError pcserror.Error) {
 	errInfo := pcserror.NewPCSErrorInfo(OperationExportFileInfo)
 	errInfo.ErrType = pcserror.ErrTypeOthers
 	if finfo.Size > MaxUploadSize {
@@ -71,7 +77,7 @@ func (pcs *BaiduPCS) ExportByFileInfo(finfo *FileDirectory) (rinfo *RapidUploadI
 		return nil, errInfo
 	}
 
-	rinfo, pcsError = pcs.GetRapidUploadInfoByFileInfo(finfo)
+	rinfo, pcsError = pcs.GetRapidUploadInfoByFileInfoContext(ctx, finfo)
 	if pcsError != nil {
 		return nil, pcsError
 	}
@@ -84,6 +90,11 @@ func (pcs *BaiduPCS) ExportByFileInfo(finfo *FileDirectory) (rinfo *RapidUploadI
 
 // GetRapidUploadInfoByFileInfo 通过文件信息对象, 获取秒传信息
 func (pcs *BaiduPCS) GetRapidUploadInfoByFileInfo(finfo *FileDirectory) (rinfo *RapidUploadInfo, pcsError pcserror.Error) {
+	return pcs.GetRapidUploadInfoByFileInfoContext(context.Background(), finfo)
+}
+
+// GetRapidUploadInfoByFileInfoContext 同 GetRapidUploadInfoByFileInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) GetRapidUploadInfoByFileInfoContext(ctx context.Context, finfo *FileDirectory) (rinfo *RapidUploadInfo, pcsError pcserror.Error) {
 	if finfo.Size <= SliceMD5Size && len(finfo.BlockList) == 1 && finfo.BlockList[0] == finfo.MD5 {
 		// 可直接秒传
 		return &RapidUploadInfo{
@@ -95,7 +106,7 @@ func (pcs *BaiduPCS) GetRapidUploadInfoByFileInfo(finfo *FileDirectory) (rinfo *
 		}, nil
 	}
 
-	link, pcsError := pcs.getLocateDownloadLink(finfo.Path)
+	link, pcsError := pcs.getLocateDownloadLink(ctx, finfo.Path)
 	if pcsError != nil {
 		return nil, pcsError
 	}
@@ -103,14 +114,14 @@ func (pcs *BaiduPCS) GetRapidUploadInfoByFileInfo(finfo *FileDirectory) (rinfo *
 	// 只有ContentLength可以比较
 	// finfo记录的ContentMD5不一定是正确的
 	// finfo记录的Filename不一定与获取到的一致
-	rinfo, pcsError = pcs.GetRapidUploadInfoByLink(link, &RapidUploadInfo{
+	rinfo, pcsError = pcs.GetRapidUploadInfoByLinkContext(ctx, link, &RapidUploadInfo{
 		ContentLength: finfo.Size,
 	})
 
 	// 如果是没获取到MD5, 可尝试新接口(测试中), 新接口调用频率有限制且文件大小不能超过约3.9G
 	if pcsError != nil && pcsError.GetError() == ErrGetRapidUploadInfoMD5NotFound && finfo.Size < 4*converter.GB {
 		link, pcsError = pcs.GetDirectDownloadLink(finfo.Path)
-		rinfo, pcsError = pcs.GetRapidUploadInfoByLink(link, &RapidUploadInfo{
+		rinfo, pcsError = pcs.GetRapidUploadInfoByLinkContext(ctx, link, &RapidUploadInfo{
 			ContentLength: finfo.Size,
 		})
 	}
@@ -128,6 +139,11 @@ func (pcs *BaiduPCS) GetDirectDownloadLink(path string) (link string, pcsError p
 
 // GetRapidUploadInfoByLink 通过下载链接, 获取文件秒传信息
 func (pcs *BaiduPCS) GetRapidUploadInfoByLink(link string, compareRInfo *RapidUploadInfo) (rinfo *RapidUploadInfo, pcsError pcserror.Error) {
+	return pcs.GetRapidUploadInfoByLinkContext(context.Background(), link, compareRInfo)
+}
+
+// GetRapidUploadInfoByLinkContext 同 GetRapidUploadInfoByLink, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) GetRapidUploadInfoByLinkContext(ctx context.Context, link string, compareRInfo *RapidUploadInfo) (rinfo *RapidUploadInfo, pcsError pcserror.Error) {
 	errInfo := pcserror.NewPCSErrorInfo(OperationGetRapidUploadInfo)
 	errInfo.ErrType = pcserror.ErrTypeOthers
 
@@ -139,7 +155,7 @@ func (pcs *BaiduPCS) GetRapidUploadInfoByLink(link string, compareRInfo *RapidUp
 		header["Range"] = "bytes=0-" + strconv.FormatInt(SliceMD5Size-1, 10)
 	}
 
-	resp, err := pcs.client.Req(http.MethodGet, link, nil, header)
+	resp, err := pcs.client.ReqContext(ctx, http.MethodGet, link, nil, header)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -269,6 +285,11 @@ func (pcs *BaiduPCS) GetRapidUploadInfoByLink(link string, compareRInfo *RapidUp
 
 // FixMD5ByFileInfo 尝试修复文件的md5, 通过文件信息对象
 func (pcs *BaiduPCS) FixMD5ByFileInfo(finfo *FileDirectory) (pcsError pcserror.Error) {
+	return pcs.FixMD5ByFileInfoContext(context.Background(), finfo)
+}
+
+// FixMD5ByFileInfoContext 同 FixMD5ByFileInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FixMD5ByFileInfoContext(ctx context.Context, finfo *FileDirectory) (pcsError pcserror.Error) {
 	errInfo := pcserror.NewPCSErrorInfo(OperationFixMD5)
 	errInfo.ErrType = pcserror.ErrTypeOthers
 	if finfo == nil {
@@ -292,7 +313,7 @@ func (pcs *BaiduPCS) FixMD5ByFileInfo(finfo *FileDirectory) (pcsError pcserror.E
 		return nil
 	}
 
-	link, pcsError := pcs.getLocateDownloadLink(finfo.Path)
+	link, pcsError := pcs.getLocateDownloadLink(ctx, finfo.Path)
 	if pcsError != nil {
 		return pcsError
 	}
@@ -303,7 +324,7 @@ func (pcs *BaiduPCS) FixMD5ByFileInfo(finfo *FileDirectory) (pcsError pcserror.E
 			ContentLength: finfo.Size,
 		}
 	)
-	rinfo, pcsError := pcs.GetRapidUploadInfoByLink(link, cmpInfo)
+	rinfo, pcsError := pcs.GetRapidUploadInfoByLinkContext(ctx, link, cmpInfo)
 	if pcsError != nil {
 		switch pcsError.GetError() {
 		case ErrGetRapidUploadInfoMD5NotFound, ErrGetRapidUploadInfoCrc32NotFound:
@@ -315,20 +336,25 @@ func (pcs *BaiduPCS) FixMD5ByFileInfo(finfo *FileDirectory) (pcsError pcserror.E
 	}
 
 	// 开始修复
-	return pcs.RapidUploadNoCheckDir(finfo.Path, rinfo.ContentMD5, rinfo.SliceMD5, rinfo.ContentCrc32, rinfo.ContentLength)
+	return pcs.RapidUploadNoCheckDirContext(ctx, finfo.Path, rinfo.ContentMD5, rinfo.SliceMD5, rinfo.ContentCrc32, rinfo.ContentLength)
 }
 
 // FixMD5 尝试修复文件的md5
 func (pcs *BaiduPCS) FixMD5(pcspath string) (pcsError pcserror.Error) {
-	finfo, pcsError := pcs.FilesDirectoriesMeta(pcspath)
+	return pcs.FixMD5Context(context.Background(), pcspath)
+}
+
+// FixMD5Context 同 FixMD5, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FixMD5Context(ctx context.Context, pcspath string) (pcsError pcserror.Error) {
+	finfo, pcsError := pcs.FilesDirectoriesMetaContext(ctx, pcspath)
 	if pcsError != nil {
 		return
 	}
 
-	return pcs.FixMD5ByFileInfo(finfo)
+	return pcs.FixMD5ByFileInfoContext(ctx, finfo)
 }
 
-func (pcs *BaiduPCS) recurseMatchPathByShellPattern(index int, patternSlice *[]string, ps *[]string, pcspaths *[]string) {
+func (pcs *BaiduPCS) recurseMatchPathByShellPattern(ctx context.Context, index int, patternSlice *[]string, ps *[]string, pcspaths *[]string) {
 	if index == len(*patternSlice) {
 		*pcspaths = append(*pcspaths, strings.Join(*ps, PathSeparator))
 		return
@@ -336,11 +362,11 @@ func (pcs *BaiduPCS) recurseMatchPathByShellPattern(index int, patternSlice *[]s
 
 	if !strings.ContainsAny((*patternSlice)[index], ShellPatternCharacters) {
 		(*ps)[index] = (*patternSlice)[index]
-		pcs.recurseMatchPathByShellPattern(index+1, patternSlice, ps, pcspaths)
+		pcs.recurseMatchPathByShellPattern(ctx, index+1, patternSlice, ps, pcspaths)
 		return
 	}
 
-	fds, pcsError := pcs.FilesDirectoriesList(strings.Join((*ps)[:index], PathSeparator), DefaultOrderOptions)
+	fds, pcsError := pcs.FilesDirectoriesListContext(ctx, strings.Join((*ps)[:index], PathSeparator), DefaultOrderOptions)
 	if pcsError != nil {
 		panic(pcsError) // 抛出异常
 	}
@@ -348,7 +374,7 @@ func (pcs *BaiduPCS) recurseMatchPathByShellPattern(index int, patternSlice *[]s
 	for k := range fds {
 		if matched, _ := path.Match((*patternSlice)[index], fds[k].Filename); matched {
 			(*ps)[index] = fds[k].Filename
-			pcs.recurseMatchPathByShellPattern(index+1, patternSlice, ps, pcspaths)
+			pcs.recurseMatchPathByShellPattern(ctx, index+1, patternSlice, ps, pcspaths)
 		}
 	}
 	return
@@ -356,6 +382,11 @@ func (pcs *BaiduPCS) recurseMatchPathByShellPattern(index int, patternSlice *[]s
 
 // MatchPathByShellPattern 通配符匹配文件路径, pattern 为绝对路径
 func (pcs *BaiduPCS) MatchPathByShellPattern(pattern string) (pcspaths []string, pcsError pcserror.Error) {
+	return pcs.MatchPathByShellPatternContext(context.Background(), pattern)
+}
+
+// MatchPathByShellPatternContext 同 MatchPathByShellPattern, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) MatchPathByShellPatternContext(ctx context.Context, pattern string) (pcspaths []string, pcsError pcserror.Error) {
 	errInfo := pcserror.NewPCSErrorInfo(OperationMatchPathByShellPattern)
 	errInfo.ErrType = pcserror.ErrTypeOthers
 
@@ -372,6 +403,6 @@ func (pcs *BaiduPCS) MatchPathByShellPattern(pattern string) (pcspaths []string,
 			pcsError = err.(pcserror.Error)
 		}
 	}()
-	pcs.recurseMatchPathByShellPattern(1, &patternSlice, &ps, &pcspaths)
+	pcs.recurseMatchPathByShellPattern(ctx, 1, &patternSlice, &ps, &pcspaths)
 	return pcspaths, nil
 }
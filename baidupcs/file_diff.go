@@ -0,0 +1,105 @@
+package baidupcs
+
+import (
+	"context"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// FileDirectoryDiff 网盘在 cursor 之后的文件变更
+	FileDirectoryDiff struct {
+		Added    FileDirectoryList // 新增的文件或目录
+		Modified FileDirectoryList // 修改的文件或目录
+		Deleted  FileDirectoryList // 删除的文件或目录
+		Cursor   string            // 下一次请求使用的 cursor
+		HasMore  bool              // 还有更多变更, 需要使用 Cursor 继续请求
+		Reset    bool              // 服务器要求丢弃本地的文件列表, 重新全量获取
+	}
+
+	// fdDiffJSON 用于解析 batch/filediff 返回的单个变更
+	fdDiffJSON struct {
+		FsID        int64  `json:"fs_id"`
+		Path        string `json:"path"`
+		Filename    string `json:"server_filename"`
+		Ctime       int64  `json:"ctime"`
+		Mtime       int64  `json:"mtime"`
+		ServerCtime int64  `json:"server_ctime"`
+		ServerMtime int64  `json:"server_mtime"`
+		MD5         string `json:"md5"`
+		Size        int64  `json:"size"`
+		IsdirInt    int8   `json:"isdir"`
+		IsdeleteInt int8   `json:"isdelete"`
+	}
+
+	fdDiffData struct {
+		*pcserror.PCSErrInfo
+		Entries map[string]*fdDiffJSON `json:"entries"`
+		HasMore bool                   `json:"has_more"`
+		Reset   bool                   `json:"reset"`
+		Cursor  string                 `json:"cursor"`
+	}
+)
+
+// FilesDirectoriesDiff 获取整个网盘在 cursor 之后的文件变更, cursor 为空时从头开始列出所有文件.
+// 服务器不区分新增和修改, 创建时间与修改时间相同的视为新增
+func (pcs *BaiduPCS) FilesDirectoriesDiff(cursor string) (diff *FileDirectoryDiff, pcsError pcserror.Error) {
+	return pcs.FilesDirectoriesDiffContext(context.Background(), cursor)
+}
+
+// FilesDirectoriesDiffContext 同 FilesDirectoriesDiff, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FilesDirectoriesDiffContext(ctx context.Context, cursor string) (diff *FileDirectoryDiff, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareFilesDirectoriesDiffContext(ctx, cursor)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+
+	defer dataReadCloser.Close()
+
+	jsonData := fdDiffData{
+		PCSErrInfo: pcserror.NewPCSErrorInfo(OperationGetCursorDiff),
+	}
+	pcsError = pcserror.HandleJSONParse(OperationGetCursorDiff, dataReadCloser, &jsonData)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+
+	diff = &FileDirectoryDiff{
+		Cursor:  jsonData.Cursor,
+		HasMore: jsonData.HasMore,
+		Reset:   jsonData.Reset,
+	}
+	for _, e := range jsonData.Entries {
+		fd := e.convert()
+		switch {
+		case e.IsdeleteInt != 0:
+			diff.Deleted = append(diff.Deleted, fd)
+		case fd.Ctime == fd.Mtime:
+			diff.Added = append(diff.Added, fd)
+		default:
+			diff.Modified = append(diff.Modified, fd)
+		}
+	}
+	return diff, nil
+}
+
+// convert 转换为 FileDirectory, 优先使用服务器时间
+func (e *fdDiffJSON) convert() *FileDirectory {
+	fd := &FileDirectory{
+		FsID:     e.FsID,
+		Path:     e.Path,
+		Filename: e.Filename,
+		Ctime:    e.ServerCtime,
+		Mtime:    e.ServerMtime,
+		MD5:      DecryptMD5(e.MD5),
+		Size:     e.Size,
+		Isdir:    e.IsdirInt != 0,
+	}
+	if fd.Ctime == 0 {
+		fd.Ctime = e.Ctime
+	}
+	if fd.Mtime == 0 {
+		fd.Mtime = e.Mtime
+	}
+	return fd
+}
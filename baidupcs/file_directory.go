@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/olekukonko/tablewriter"
@@ -108,11 +109,16 @@ var (
 
 // FilesDirectoriesMeta 获取单个文件/目录的元信息
 func (pcs *BaiduPCS) FilesDirectoriesMeta(path string) (data *FileDirectory, pcsError pcserror.Error) {
+	return pcs.FilesDirectoriesMetaContext(context.Background(), path)
+}
+
+// FilesDirectoriesMetaContext 同 FilesDirectoriesMeta, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FilesDirectoriesMetaContext(ctx context.Context, path string) (data *FileDirectory, pcsError pcserror.Error) {
 	if path == "" {
 		path = PathSeparator
 	}
 
-	fds, err := pcs.FilesDirectoriesBatchMeta(path)
+	fds, err := pcs.FilesDirectoriesBatchMetaContext(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +136,12 @@ func (pcs *BaiduPCS) FilesDirectoriesMeta(path string) (data *FileDirectory, pcs
 
 // FilesDirectoriesBatchMeta 获取多个文件/目录的元信息
 func (pcs *BaiduPCS) FilesDirectoriesBatchMeta(paths ...string) (data FileDirectoryList, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareFilesDirectoriesBatchMeta(paths...)
+	return pcs.FilesDirectoriesBatchMetaContext(context.Background(), paths...)
+}
+
+// FilesDirectoriesBatchMetaContext 同 FilesDirectoriesBatchMeta, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FilesDirectoriesBatchMetaContext(ctx context.Context, paths ...string) (data FileDirectoryList, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareFilesDirectoriesBatchMetaContext(ctx, paths...)
 	if pcsError != nil {
 		return nil, pcsError
 	}
@@ -157,7 +168,12 @@ func (pcs *BaiduPCS) FilesDirectoriesBatchMeta(paths ...string) (data FileDirect
 
 // FilesDirectoriesList 获取目录下的文件和目录列表
 func (pcs *BaiduPCS) FilesDirectoriesList(path string, options *OrderOptions) (data FileDirectoryList, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareFilesDirectoriesList(path, options)
+	return pcs.FilesDirectoriesListContext(context.Background(), path, options)
+}
+
+// FilesDirectoriesListContext 同 FilesDirectoriesList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FilesDirectoriesListContext(ctx context.Context, path string, options *OrderOptions) (data FileDirectoryList, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareFilesDirectoriesListContext(ctx, path, options)
 	if pcsError != nil {
 		return nil, pcsError
 	}
@@ -182,11 +198,16 @@ func (pcs *BaiduPCS) FilesDirectoriesList(path string, options *OrderOptions) (d
 
 // Search 按文件名搜索文件, 不支持查找目录
 func (pcs *BaiduPCS) Search(targetPath, keyword string, recursive bool) (fdl FileDirectoryList, pcsError pcserror.Error) {
+	return pcs.SearchContext(context.Background(), targetPath, keyword, recursive)
+}
+
+// SearchContext 同 Search, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) SearchContext(ctx context.Context, targetPath, keyword string, recursive bool) (fdl FileDirectoryList, pcsError pcserror.Error) {
 	if targetPath == "" {
 		targetPath = PathSeparator
 	}
 
-	dataReadCloser, pcsError := pcs.PrepareSearch(targetPath, keyword, recursive)
+	dataReadCloser, pcsError := pcs.PrepareSearchContext(ctx, targetPath, keyword, recursive)
 	if pcsError != nil {
 		return nil, pcsError
 	}
@@ -210,14 +231,18 @@ func (pcs *BaiduPCS) Search(targetPath, keyword string, recursive bool) (fdl Fil
 	return
 }
 
-func (pcs *BaiduPCS) recurseList(path string, depth int, options *OrderOptions, prebase string, handleFileDirectoryFunc HandleFileDirectoryFunc) (fdl FileDirectoryList, ok bool) {
-	fdl, pcsError := pcs.FilesDirectoriesList(path, options)
+func (pcs *BaiduPCS) recurseList(ctx context.Context, path string, depth int, options *OrderOptions, prebase string, handleFileDirectoryFunc HandleFileDirectoryFunc) (fdl FileDirectoryList, ok bool) {
+	fdl, pcsError := pcs.FilesDirectoriesListContext(ctx, path, options)
 	if pcsError != nil {
 		ok := handleFileDirectoryFunc(depth, path, nil, pcsError) // 传递错误
 		return nil, ok
 	}
 
 	for k := range fdl {
+		if ctx.Err() != nil {
+			// 已取消, 不再继续
+			return fdl, false
+		}
 		fdl[k].PreBase = prebase
 		ok = handleFileDirectoryFunc(depth+1, fdl[k].Path, fdl[k], nil)
 		if !ok {
@@ -228,7 +253,7 @@ func (pcs *BaiduPCS) recurseList(path string, depth int, options *OrderOptions,
 			continue
 		}
 
-		fdl[k].Children, ok = pcs.recurseList(fdl[k].Path, depth+1, options, filepath.Join(prebase, filepath.Base(fdl[k].Path)), handleFileDirectoryFunc)
+		fdl[k].Children, ok = pcs.recurseList(ctx, fdl[k].Path, depth+1, options, filepath.Join(prebase, filepath.Base(fdl[k].Path)), handleFileDirectoryFunc)
 		if !ok {
 			return
 		}
@@ -239,7 +264,12 @@ func (pcs *BaiduPCS) recurseList(path string, depth int, options *OrderOptions,
 
 // FilesDirectoriesRecurseList 递归获取目录下的文件和目录列表
 func (pcs *BaiduPCS) FilesDirectoriesRecurseList(path string, options *OrderOptions, handleFileDirectoryFunc HandleFileDirectoryFunc) (data FileDirectoryList) {
-	fd, pcsError := pcs.FilesDirectoriesMeta(path)
+	return pcs.FilesDirectoriesRecurseListContext(context.Background(), path, options, handleFileDirectoryFunc)
+}
+
+// FilesDirectoriesRecurseListContext 同 FilesDirectoriesRecurseList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FilesDirectoriesRecurseListContext(ctx context.Context, path string, options *OrderOptions, handleFileDirectoryFunc HandleFileDirectoryFunc) (data FileDirectoryList) {
+	fd, pcsError := pcs.FilesDirectoriesMetaContext(ctx, path)
 	if pcsError != nil {
 		handleFileDirectoryFunc(0, path, nil, pcsError) // 传递错误
 		return nil
@@ -252,7 +282,7 @@ func (pcs *BaiduPCS) FilesDirectoriesRecurseList(path string, options *OrderOpti
 		handleFileDirectoryFunc(0, path, fd, nil)
 	}
 
-	data, _ = pcs.recurseList(path, 0, options, filepath.Base(path), handleFileDirectoryFunc)
+	data, _ = pcs.recurseList(ctx, path, 0, options, filepath.Base(path), handleFileDirectoryFunc)
 	return data
 }
 
@@ -2,6 +2,7 @@ package baidupcs
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -49,7 +50,7 @@ func handleRespStatusError(operation string, resp *http.Response) pcserror.Error
 	return nil
 }
 
-func (pcs *BaiduPCS) sendReqReturnResp(rt reqType, op, method, urlStr string, post interface{}, header map[string]string) (resp *http.Response, pcsError pcserror.Error) {
+func (pcs *BaiduPCS) sendReqReturnResp(ctx context.Context, rt reqType, op, method, urlStr string, post interface{}, header map[string]string) (resp *http.Response, pcsError pcserror.Error) {
 	if header == nil {
 		header = map[string]string{}
 	}
@@ -67,7 +68,7 @@ func (pcs *BaiduPCS) sendReqReturnResp(rt reqType, op, method, urlStr string, po
 		}
 	}
 
-	resp, err := pcs.client.Req(method, urlStr, post, header)
+	resp, err := pcs.client.ReqContext(ctx, method, urlStr, post, header)
 	if err != nil {
 		handleRespClose(resp)
 		switch rt {
@@ -89,8 +90,8 @@ func (pcs *BaiduPCS) sendReqReturnResp(rt reqType, op, method, urlStr string, po
 	return resp, nil
 }
 
-func (pcs *BaiduPCS) sendReqReturnReadCloser(rt reqType, op, method, urlStr string, post interface{}, header map[string]string) (readCloser io.ReadCloser, pcsError pcserror.Error) {
-	resp, pcsError := pcs.sendReqReturnResp(rt, op, method, urlStr, post, header)
+func (pcs *BaiduPCS) sendReqReturnReadCloser(ctx context.Context, rt reqType, op, method, urlStr string, post interface{}, header map[string]string) (readCloser io.ReadCloser, pcsError pcserror.Error) {
+	resp, pcsError := pcs.sendReqReturnResp(ctx, rt, op, method, urlStr, post, header)
 	if pcsError != nil {
 		return
 	}
@@ -99,6 +100,11 @@ func (pcs *BaiduPCS) sendReqReturnReadCloser(rt reqType, op, method, urlStr stri
 
 // PrepareUK 获取用户 UK, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareUK() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareUKContext(context.Background())
+}
+
+// PrepareUKContext 同 PrepareUK, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareUKContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 
 	query := url.Values{}
@@ -111,12 +117,17 @@ func (pcs *BaiduPCS) PrepareUK() (dataReadCloser io.ReadCloser, pcsError pcserro
 		RawQuery: query.Encode(),
 	}
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationGetUK, http.MethodGet, panURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationGetUK, http.MethodGet, panURL.String(), nil, nil)
 	return
 }
 
 // PreparePCSServers 获取推荐的pcs服务器URL
 func (pcs *BaiduPCS) PreparePCSServers() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PreparePCSServersContext(context.Background())
+}
+
+// PreparePCSServersContext 同 PreparePCSServers, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PreparePCSServersContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL := pcs.generatePCSURL("file", "locateupload", map[string]string{
 		"upload_version": "2.0",
@@ -124,22 +135,32 @@ func (pcs *BaiduPCS) PreparePCSServers() (dataReadCloser io.ReadCloser, pcsError
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationGetPCSServer, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationGetPCSServer, http.MethodGet, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationGetPCSServer, http.MethodGet, pcsURL.String(), nil, nil)
 	return
 }
 
 // PrepareQuotaInfo 获取当前用户空间配额信息, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareQuotaInfo() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareQuotaInfoContext(context.Background())
+}
+
+// PrepareQuotaInfoContext 同 PrepareQuotaInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareQuotaInfoContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL := pcs.generatePCSURL("quota", "info")
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationQuotaInfo, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationQuotaInfo, http.MethodGet, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationQuotaInfo, http.MethodGet, pcsURL.String(), nil, nil)
 	return
 }
 
 // PrepareFilesDirectoriesBatchMeta 获取多个文件/目录的元信息, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareFilesDirectoriesBatchMeta(paths ...string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareFilesDirectoriesBatchMetaContext(context.Background(), paths...)
+}
+
+// PrepareFilesDirectoriesBatchMetaContext 同 PrepareFilesDirectoriesBatchMeta, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareFilesDirectoriesBatchMetaContext(ctx context.Context, paths ...string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	sendData, err := (&PathsListJSON{}).JSON(paths...)
 	if err != nil {
@@ -154,12 +175,17 @@ func (pcs *BaiduPCS) PrepareFilesDirectoriesBatchMeta(paths ...string) (dataRead
 	mr.AddFormField("param", bytes.NewReader(sendData))
 	mr.CloseMultipart()
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationFilesDirectoriesMeta, http.MethodPost, pcsURL.String(), mr, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationFilesDirectoriesMeta, http.MethodPost, pcsURL.String(), mr, nil)
 	return
 }
 
 // PrepareFilesDirectoriesList 获取目录下的文件和目录列表, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareFilesDirectoriesList(path string, options *OrderOptions) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareFilesDirectoriesListContext(context.Background(), path, options)
+}
+
+// PrepareFilesDirectoriesListContext 同 PrepareFilesDirectoriesList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareFilesDirectoriesListContext(ctx context.Context, path string, options *OrderOptions) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	if options == nil {
 		options = DefaultOrderOptions
@@ -176,13 +202,18 @@ func (pcs *BaiduPCS) PrepareFilesDirectoriesList(path string, options *OrderOpti
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationFilesDirectoriesList, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationFilesDirectoriesList, http.MethodGet, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationFilesDirectoriesList, http.MethodGet, pcsURL.String(), nil, nil)
 	return
 }
 
 func (pcs *BaiduPCS) PrepareFilesDirectoriesDiff(cursor string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareFilesDirectoriesDiffContext(context.Background(), cursor)
+}
+
+// PrepareFilesDirectoriesDiffContext 同 PrepareFilesDirectoriesDiff, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareFilesDirectoriesDiffContext(ctx context.Context, cursor string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
-	//bdstoken, pcsError := pcs.BDSToken()
+	//bdstoken, pcsError := pcs.BDSTokenContext(ctx)
 	//if pcsError != nil {
 	//	return
 	//}
@@ -196,23 +227,33 @@ func (pcs *BaiduPCS) PrepareFilesDirectoriesDiff(cursor string) (dataReadCloser
 		"clienttype": "1",
 	})
 	paramsURL := ns.URLParam()
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationGetCursorDiff, http.MethodGet, pcsURL.String()+"&"+paramsURL, nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationGetCursorDiff, http.MethodGet, pcsURL.String()+"&"+paramsURL, nil, nil)
 	return
 }
 
 func (pcs *BaiduPCS) PrepareBDStoken() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareBDStokenContext(context.Background())
+}
+
+// PrepareBDStokenContext 同 PrepareBDStoken, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareBDStokenContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL := pcs.generatePanURL("gettemplatevariable", map[string]string{
 		"clienttype": "0",
-		"app_id":     string(pcs.appID),
+		"app_id":     strconv.Itoa(pcs.appID),
 		"fields":     `["bdstoken"]`,
 	})
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationGetBDSToken, http.MethodGet, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationGetBDSToken, http.MethodGet, pcsURL.String(), nil, nil)
 	return
 }
 
 // PrepareSearch 按文件名搜索文件, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareSearch(targetPath, keyword string, recursive bool) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareSearchContext(context.Background(), targetPath, keyword, recursive)
+}
+
+// PrepareSearchContext 同 PrepareSearch, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareSearchContext(ctx context.Context, targetPath, keyword string, recursive bool) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	var re string
 	if recursive {
@@ -227,12 +268,17 @@ func (pcs *BaiduPCS) PrepareSearch(targetPath, keyword string, recursive bool) (
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationSearch, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationSearch, http.MethodGet, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationSearch, http.MethodGet, pcsURL.String(), nil, nil)
 	return
 }
 
 // PrepareRemove 批量删除文件/目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRemove(paths ...string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareRemoveContext(context.Background(), paths...)
+}
+
+// PrepareRemoveContext 同 PrepareRemove, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRemoveContext(ctx context.Context, paths ...string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	sendData, err := (&PathsListJSON{}).JSON(paths...)
 	if err != nil {
@@ -247,23 +293,28 @@ func (pcs *BaiduPCS) PrepareRemove(paths ...string) (dataReadCloser io.ReadClose
 	mr.AddFormField("param", bytes.NewReader(sendData))
 	mr.CloseMultipart()
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationRemove, http.MethodPost, pcsURL.String(), mr, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationRemove, http.MethodPost, pcsURL.String(), mr, nil)
 	return
 }
 
 // PrepareMkdir 创建目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareMkdir(pcspath string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareMkdirContext(context.Background(), pcspath)
+}
+
+// PrepareMkdirContext 同 PrepareMkdir, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareMkdirContext(ctx context.Context, pcspath string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL := pcs.generatePCSURL("file", "mkdir", map[string]string{
 		"path": pcspath,
 	})
 	baiduPCSVerbose.Infof("%s URL: %s", OperationMkdir, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationMkdir, http.MethodPost, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationMkdir, http.MethodPost, pcsURL.String(), nil, nil)
 	return
 }
 
-func (pcs *BaiduPCS) prepareCpMvOp(op string, cpmvJSON ...*CpMvJSON) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+func (pcs *BaiduPCS) prepareCpMvOp(ctx context.Context, op string, cpmvJSON ...*CpMvJSON) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	var method string
 	switch op {
@@ -291,13 +342,18 @@ func (pcs *BaiduPCS) prepareCpMvOp(op string, cpmvJSON ...*CpMvJSON) (dataReadCl
 	mr.AddFormField("param", bytes.NewReader(sendData))
 	mr.CloseMultipart()
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, op, http.MethodPost, pcsURL.String(), mr, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, op, http.MethodPost, pcsURL.String(), mr, nil)
 	return
 }
 
 // PrepareRename 重命名文件/目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRename(from, to string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
-	return pcs.prepareCpMvOp(OperationRename, &CpMvJSON{
+	return pcs.PrepareRenameContext(context.Background(), from, to)
+}
+
+// PrepareRenameContext 同 PrepareRename, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRenameContext(ctx context.Context, from, to string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.prepareCpMvOp(ctx, OperationRename, &CpMvJSON{
 		From: from,
 		To:   to,
 	})
@@ -305,17 +361,27 @@ func (pcs *BaiduPCS) PrepareRename(from, to string) (dataReadCloser io.ReadClose
 
 // PrepareCopy 批量拷贝文件/目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareCopy(cpmvJSON ...*CpMvJSON) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
-	return pcs.prepareCpMvOp(OperationCopy, cpmvJSON...)
+	return pcs.PrepareCopyContext(context.Background(), cpmvJSON...)
+}
+
+// PrepareCopyContext 同 PrepareCopy, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCopyContext(ctx context.Context, cpmvJSON ...*CpMvJSON) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.prepareCpMvOp(ctx, OperationCopy, cpmvJSON...)
 }
 
 // PrepareMove 批量移动文件/目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareMove(cpmvJSON ...*CpMvJSON) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
-	return pcs.prepareCpMvOp(OperationMove, cpmvJSON...)
+	return pcs.PrepareMoveContext(context.Background(), cpmvJSON...)
+}
+
+// PrepareMoveContext 同 PrepareMove, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareMoveContext(ctx context.Context, cpmvJSON ...*CpMvJSON) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.prepareCpMvOp(ctx, OperationMove, cpmvJSON...)
 }
 
 // prepareRapidUpload 秒传文件, 不进行文件夹检查
-func (pcs *BaiduPCS) prepareRapidUpload(targetPath, contentMD5, sliceMD5, crc32 string, length int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
-	//bdstoken, pcsError := pcs.BDSToken()
+func (pcs *BaiduPCS) prepareRapidUpload(ctx context.Context, targetPath, contentMD5, sliceMD5, crc32 string, length int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	//bdstoken, pcsError := pcs.BDSTokenContext(ctx)
 	//if pcsError != nil {
 	//	return
 	//}
@@ -333,14 +399,14 @@ func (pcs *BaiduPCS) prepareRapidUpload(targetPath, contentMD5, sliceMD5, crc32
 	}
 	baiduPCSVerbose.Infof("%s URL: %s, Post: %v\n", OperationRapidUpload, pcsURL, post)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePan, OperationRapidUpload, http.MethodPost, pcsURL.String(), post, map[string]string{
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationRapidUpload, http.MethodPost, pcsURL.String(), post, map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
 	})
 	return
 }
 
 // prepareRapidUploadV2 秒传文件接口2, 不进行文件夹检查
-func (pcs *BaiduPCS) prepareRapidUploadV2(targetPath, uploadid, policy, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+func (pcs *BaiduPCS) prepareRapidUploadV2(ctx context.Context, targetPath, uploadid, policy, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcsURL := pcs.generatePanURL("precreate", nil)
 	post := map[string]string{
 		"uploadid":     uploadid,
@@ -368,7 +434,7 @@ func (pcs *BaiduPCS) prepareRapidUploadV2(targetPath, uploadid, policy, contentM
 		delete(post, "uploadid")
 	}
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePan, OperationRapidUpload, http.MethodPost, pcsURL.String(), post, map[string]string{
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationRapidUpload, http.MethodPost, pcsURL.String(), post, map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
 		"Accept":       "*/*",
 		"Connection":   "keep-alive",
@@ -376,7 +442,7 @@ func (pcs *BaiduPCS) prepareRapidUploadV2(targetPath, uploadid, policy, contentM
 	return
 }
 
-func (pcs *BaiduPCS) prepareFakeRapidUploadV2(targetPath, policy string, dateTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+func (pcs *BaiduPCS) prepareFakeRapidUploadV2(ctx context.Context, targetPath, policy string, dateTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcsURL := pcs.generatePanURL("precreate", map[string]string{
 		"app_id":  PanAppID,
 		"channel": "1",
@@ -392,7 +458,7 @@ func (pcs *BaiduPCS) prepareFakeRapidUploadV2(targetPath, policy string, dateTim
 	}
 	baiduPCSVerbose.Infof("%s URL: %s, Post: %v\n", OperationRapidUpload, pcsURL, post)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePan, OperationRapidUpload, http.MethodPost, pcsURL.String(), post, map[string]string{
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationRapidUpload, http.MethodPost, pcsURL.String(), post, map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
 		"Accept":       "*/*",
 		"Connection":   "keep-alive",
@@ -402,38 +468,58 @@ func (pcs *BaiduPCS) prepareFakeRapidUploadV2(targetPath, policy string, dateTim
 
 // PrepareRapidUpload 秒传文件旧接口, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRapidUpload(targetPath, contentMD5, sliceMD5, crc32 string, length int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareRapidUploadContext(context.Background(), targetPath, contentMD5, sliceMD5, crc32, length)
+}
+
+// PrepareRapidUploadContext 同 PrepareRapidUpload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRapidUploadContext(ctx context.Context, targetPath, contentMD5, sliceMD5, crc32 string, length int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
-	pcsError = pcs.CheckIsdir(OperationRapidUpload, targetPath, "", length)
+	pcsError = pcs.CheckIsdirContext(ctx, OperationRapidUpload, targetPath, "", length)
 	if pcsError != nil {
 		return nil, pcsError
 	}
 
-	return pcs.prepareRapidUpload(targetPath, contentMD5, sliceMD5, crc32, length)
+	return pcs.prepareRapidUpload(ctx, targetPath, contentMD5, sliceMD5, crc32, length)
 }
 
 // PrepareRapidUploadV2 秒传文件新接口, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRapidUploadV2(targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareRapidUploadV2Context(context.Background(), targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
+}
+
+// PrepareRapidUploadV2Context 同 PrepareRapidUploadV2, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRapidUploadV2Context(ctx context.Context, targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
-	pcsError = pcs.CheckIsdir(OperationRapidUpload, targetPath, policy, totalSize)
+	pcsError = pcs.CheckIsdirContext(ctx, OperationRapidUpload, targetPath, policy, totalSize)
 	if pcsError != nil {
 		return nil, pcsError
 	}
 	rtype := pcs.policyTortype(policy)
-	return pcs.prepareRapidUploadV2(targetPath, uploadid, rtype, contentMD5, sliceMD5, dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
+	return pcs.prepareRapidUploadV2(ctx, targetPath, uploadid, rtype, contentMD5, sliceMD5, dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
 }
 
 func (pcs *BaiduPCS) PrepareFakeRapidUploadV2(targetPath, policy string, length, dataTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareFakeRapidUploadV2Context(context.Background(), targetPath, policy, length, dataTime, blockListMD5)
+}
+
+// PrepareFakeRapidUploadV2Context 同 PrepareFakeRapidUploadV2, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareFakeRapidUploadV2Context(ctx context.Context, targetPath, policy string, length, dataTime int64, blockListMD5 []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
-	pcsError = pcs.CheckIsdir(OperationRapidUpload, targetPath, policy, length)
+	pcsError = pcs.CheckIsdirContext(ctx, OperationRapidUpload, targetPath, policy, length)
 	if pcsError != nil {
 		return nil, pcsError
 	}
 	rtype := pcs.policyTortype(policy)
-	return pcs.prepareFakeRapidUploadV2(targetPath, rtype, dataTime, blockListMD5)
+	return pcs.prepareFakeRapidUploadV2(ctx, targetPath, rtype, dataTime, blockListMD5)
 }
 
 // PrepareLocateDownload 获取下载链接, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareLocateDownload(pcspath string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareLocateDownloadContext(context.Background(), pcspath)
+}
+
+// PrepareLocateDownloadContext 同 PrepareLocateDownload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareLocateDownloadContext(ctx context.Context, pcspath string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	bduss := pcs.GetBDUSS()
 	// 检测uid
@@ -473,12 +559,17 @@ func (pcs *BaiduPCS) PrepareLocateDownload(pcspath string) (dataReadCloser io.Re
 	}
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationLocateDownload, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationLocateDownload, http.MethodPost, pcsURL.String(), nil, pcs.getPanUAHeader())
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationLocateDownload, http.MethodPost, pcsURL.String(), nil, pcs.getPanUAHeader())
 	return
 }
 
 // PrepareLocatePanAPIDownload 从百度网盘首页获取下载链接, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareLocatePanAPIDownload(fidList ...int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareLocatePanAPIDownloadContext(context.Background(), fidList...)
+}
+
+// PrepareLocatePanAPIDownloadContext 同 PrepareLocatePanAPIDownload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareLocatePanAPIDownloadContext(ctx context.Context, fidList ...int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 	// 初始化
 	var (
@@ -504,7 +595,7 @@ func (pcs *BaiduPCS) PrepareLocatePanAPIDownload(fidList ...int64) (dataReadClos
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationLocatePanAPIDownload, panURL)
 
 	// try to get bdstoken
-	bdstoken, _ := pcs.BDSToken()
+	bdstoken, _ := pcs.BDSTokenContext(ctx)
 
 	params := map[string]string{
 		"fidlist": mergeInt64List(fidList...),
@@ -520,7 +611,7 @@ func (pcs *BaiduPCS) PrepareLocatePanAPIDownload(fidList ...int64) (dataReadClos
 		}
 	}
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationLocatePanAPIDownload, http.MethodPost, panURL.String(), params, map[string]string{
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationLocatePanAPIDownload, http.MethodPost, panURL.String(), params, map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
 	})
 	return
@@ -582,13 +673,18 @@ func (pcs *BaiduPCS) PrepareUploadTmpFile(uploadFunc UploadFunc) (dataReadCloser
 
 // PrepareUploadCreateSuperFile 分片上传—合并分片文件, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareUploadCreateSuperFile(uploadid, rtype string, fileSize int64, targetPath string, blockList []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareUploadCreateSuperFileContext(context.Background(), uploadid, rtype, fileSize, targetPath, blockList)
+}
+
+// PrepareUploadCreateSuperFileContext 同 PrepareUploadCreateSuperFile, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareUploadCreateSuperFileContext(ctx context.Context, uploadid, rtype string, fileSize int64, targetPath string, blockList []string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 
 	panURL := pcs.generatePanURL("create", nil)
 
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationUploadCreateSuperFile, panURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePan, OperationUploadCreateSuperFile, http.MethodPost, panURL.String(), map[string]string{
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationUploadCreateSuperFile, http.MethodPost, panURL.String(), map[string]string{
 		"uploadid": uploadid,
 		"path":     targetPath,
 		"size":     strconv.FormatInt(fileSize, 10),
@@ -607,6 +703,11 @@ func (pcs *BaiduPCS) PrepareUploadCreateSuperFile(uploadid, rtype string, fileSi
 
 // PrepareUploadPrecreate 分片上传—Precreate, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareUploadPrecreate(targetPath, contentMD5, sliceMD5, crc32 string, size int64, blockList []string) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareUploadPrecreateContext(context.Background(), targetPath, contentMD5, sliceMD5, crc32, size, blockList)
+}
+
+// PrepareUploadPrecreateContext 同 PrepareUploadPrecreate, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareUploadPrecreateContext(ctx context.Context, targetPath, contentMD5, sliceMD5, crc32 string, size int64, blockList []string) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 	panURL := &url.URL{
 		Scheme: "https",
@@ -615,7 +716,7 @@ func (pcs *BaiduPCS) PrepareUploadPrecreate(targetPath, contentMD5, sliceMD5, cr
 	}
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationUploadPrecreate, panURL)
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationUploadPrecreate, http.MethodPost, panURL.String(), map[string]string{
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationUploadPrecreate, http.MethodPost, panURL.String(), map[string]string{
 		"path":         targetPath,
 		"size":         strconv.FormatInt(size, 10),
 		"isdir":        "0",
@@ -663,6 +764,11 @@ func (pcs *BaiduPCS) PrepareUploadSuperfile2(uploadid, targetPath string, partse
 
 // PrepareCloudDlAddTask 添加离线下载任务, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareCloudDlAddTask(sourceURL, savePath string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareCloudDlAddTaskContext(context.Background(), sourceURL, savePath)
+}
+
+// PrepareCloudDlAddTaskContext 同 PrepareCloudDlAddTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCloudDlAddTaskContext(ctx context.Context, sourceURL, savePath string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL2 := pcs.generatePCSURL2("services/cloud_dl", "add_task", map[string]string{
 		"app_id":       PanAppID,
@@ -673,13 +779,18 @@ func (pcs *BaiduPCS) PrepareCloudDlAddTask(sourceURL, savePath string) (dataRead
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationCloudDlAddTask, pcsURL2)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationCloudDlAddTask, http.MethodPost, pcsURL2.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationCloudDlAddTask, http.MethodPost, pcsURL2.String(), nil, nil)
 	return
 }
 
 // PrepareCloudDlQueryTask 精确查询离线下载任务, 只返回服务器响应数据和错误信息,
 // taskids 例子: 12123,234234,2344, 用逗号隔开多个 task_id
 func (pcs *BaiduPCS) PrepareCloudDlQueryTask(taskIDs string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareCloudDlQueryTaskContext(context.Background(), taskIDs)
+}
+
+// PrepareCloudDlQueryTaskContext 同 PrepareCloudDlQueryTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCloudDlQueryTaskContext(ctx context.Context, taskIDs string) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL2 := pcs.generatePCSURL2("services/cloud_dl", "query_task", map[string]string{
 		"app_id":   PanAppID,
@@ -688,12 +799,17 @@ func (pcs *BaiduPCS) PrepareCloudDlQueryTask(taskIDs string) (dataReadCloser io.
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationCloudDlQueryTask, pcsURL2)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationCloudDlQueryTask, http.MethodGet, pcsURL2.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationCloudDlQueryTask, http.MethodGet, pcsURL2.String(), nil, nil)
 	return
 }
 
 // PrepareCloudDlListTask 查询离线下载任务列表, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareCloudDlListTask() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareCloudDlListTaskContext(context.Background())
+}
+
+// PrepareCloudDlListTaskContext 同 PrepareCloudDlListTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCloudDlListTaskContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL2 := pcs.generatePCSURL2("services/cloud_dl", "list_task", map[string]string{
 		"need_task_info": "1",
@@ -704,11 +820,11 @@ func (pcs *BaiduPCS) PrepareCloudDlListTask() (dataReadCloser io.ReadCloser, pcs
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationCloudDlListTask, pcsURL2)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationCloudDlListTask, http.MethodPost, pcsURL2.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationCloudDlListTask, http.MethodPost, pcsURL2.String(), nil, nil)
 	return
 }
 
-func (pcs *BaiduPCS) prepareCloudDlCDTask(operation, method string, taskID int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+func (pcs *BaiduPCS) prepareCloudDlCDTask(ctx context.Context, operation, method string, taskID int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL2 := pcs.generatePCSURL2("services/cloud_dl", method, map[string]string{
 		"app_id":  PanAppID,
@@ -716,32 +832,52 @@ func (pcs *BaiduPCS) prepareCloudDlCDTask(operation, method string, taskID int64
 	})
 	baiduPCSVerbose.Infof("%s URL: %s\n", operation, pcsURL2)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, operation, http.MethodPost, pcsURL2.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, operation, http.MethodPost, pcsURL2.String(), nil, nil)
 	return
 }
 
 // PrepareCloudDlCancelTask 取消离线下载任务, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareCloudDlCancelTask(taskID int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
-	return pcs.prepareCloudDlCDTask(OperationCloudDlCancelTask, "cancel_task", taskID)
+	return pcs.PrepareCloudDlCancelTaskContext(context.Background(), taskID)
+}
+
+// PrepareCloudDlCancelTaskContext 同 PrepareCloudDlCancelTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCloudDlCancelTaskContext(ctx context.Context, taskID int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.prepareCloudDlCDTask(ctx, OperationCloudDlCancelTask, "cancel_task", taskID)
 }
 
 // PrepareCloudDlDeleteTask 取消离线下载任务, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareCloudDlDeleteTask(taskID int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
-	return pcs.prepareCloudDlCDTask(OperationCloudDlDeleteTask, "delete_task", taskID)
+	return pcs.PrepareCloudDlDeleteTaskContext(context.Background(), taskID)
+}
+
+// PrepareCloudDlDeleteTaskContext 同 PrepareCloudDlDeleteTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCloudDlDeleteTaskContext(ctx context.Context, taskID int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.prepareCloudDlCDTask(ctx, OperationCloudDlDeleteTask, "delete_task", taskID)
 }
 
 // PrepareCloudDlClearTask 清空离线下载任务记录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareCloudDlClearTask() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareCloudDlClearTaskContext(context.Background())
+}
+
+// PrepareCloudDlClearTaskContext 同 PrepareCloudDlClearTask, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareCloudDlClearTaskContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 	pcsURL2 := pcs.generatePCSURL2("services/cloud_dl", "clear_task")
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationCloudDlClearTask, pcsURL2)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationCloudDlClearTask, http.MethodPost, pcsURL2.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationCloudDlClearTask, http.MethodPost, pcsURL2.String(), nil, nil)
 	return
 }
 
 // PrepareSharePSet 私密分享文件, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareSharePSet(paths []string, pwd string, period int) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareSharePSetContext(context.Background(), paths, pwd, period)
+}
+
+// PrepareSharePSetContext 同 PrepareSharePSet, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareSharePSetContext(ctx context.Context, paths []string, pwd string, period int) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 	panURL := &url.URL{
 		Scheme: "https",
@@ -750,7 +886,7 @@ func (pcs *BaiduPCS) PrepareSharePSet(paths []string, pwd string, period int) (d
 	}
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationShareSet, panURL)
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationShareSet, http.MethodPost, panURL.String(), map[string]string{
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareSet, http.MethodPost, panURL.String(), map[string]string{
 		"path_list":    mergeStringList(paths...),
 		"schannel":     "4",
 		"channel_list": "[]",
@@ -765,6 +901,11 @@ func (pcs *BaiduPCS) PrepareSharePSet(paths []string, pwd string, period int) (d
 
 // PrepareShareCancel 取消分享, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareShareCancel(shareIDs []int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareShareCancelContext(context.Background(), shareIDs)
+}
+
+// PrepareShareCancelContext 同 PrepareShareCancel, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareShareCancelContext(ctx context.Context, shareIDs []int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 	panURL := &url.URL{
 		Scheme: "https",
@@ -775,7 +916,7 @@ func (pcs *BaiduPCS) PrepareShareCancel(shareIDs []int64) (dataReadCloser io.Rea
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationShareCancel, panURL)
 
 	ss := converter.SliceInt64ToString(shareIDs)
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationShareCancel, http.MethodPost, panURL.String(), map[string]string{
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareCancel, http.MethodPost, panURL.String(), map[string]string{
 		"shareid_list": "[" + strings.Join(ss, ",") + "]",
 	}, map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
@@ -785,6 +926,11 @@ func (pcs *BaiduPCS) PrepareShareCancel(shareIDs []int64) (dataReadCloser io.Rea
 
 // PrepareShareList 列出分享列表, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareShareList(page int) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareShareListContext(context.Background(), page)
+}
+
+// PrepareShareListContext 同 PrepareShareList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareShareListContext(ctx context.Context, page int) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 
 	query := url.Values{}
@@ -800,12 +946,17 @@ func (pcs *BaiduPCS) PrepareShareList(page int) (dataReadCloser io.ReadCloser, p
 	}
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationShareList, panURL)
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationShareList, http.MethodGet, panURL.String(), nil, nil)
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareList, http.MethodGet, panURL.String(), nil, nil)
 	return
 }
 
 // PrepareShareSURLInfo 获取分享的详细信息, 包含密码, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareShareSURLInfo(shareID int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareShareSURLInfoContext(context.Background(), shareID)
+}
+
+// PrepareShareSURLInfoContext 同 PrepareShareSURLInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareShareSURLInfoContext(ctx context.Context, shareID int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 
 	query := url.Values{}
@@ -820,12 +971,17 @@ func (pcs *BaiduPCS) PrepareShareSURLInfo(shareID int64) (dataReadCloser io.Read
 	}
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationShareSURLInfo, panURL)
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationShareSURLInfo, http.MethodGet, panURL.String(), nil, nil)
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareSURLInfo, http.MethodGet, panURL.String(), nil, nil)
 	return
 }
 
 // PrepareRecycleList 列出回收站文件列表, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRecycleList(page int) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareRecycleListContext(context.Background(), page)
+}
+
+// PrepareRecycleListContext 同 PrepareRecycleList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRecycleListContext(ctx context.Context, page int) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 
 	panURL := pcs.generatePanURL("recycle/list", map[string]string{
@@ -835,12 +991,17 @@ func (pcs *BaiduPCS) PrepareRecycleList(page int) (dataReadCloser io.ReadCloser,
 
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationRecycleList, panURL)
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationRecycleList, http.MethodGet, panURL.String(), nil, nil)
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationRecycleList, http.MethodGet, panURL.String(), nil, nil)
 	return
 }
 
 // PrepareRecycleRestore 还原回收站文件或目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRecycleRestore(fidList ...int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareRecycleRestoreContext(context.Background(), fidList...)
+}
+
+// PrepareRecycleRestoreContext 同 PrepareRecycleRestore, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRecycleRestoreContext(ctx context.Context, fidList ...int64) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 
 	pcsURL := pcs.generatePCSURL("file", "restore")
@@ -866,18 +1027,23 @@ func (pcs *BaiduPCS) PrepareRecycleRestore(fidList ...int64) (dataReadCloser io.
 	mr.AddFormField("param", bytes.NewReader(sendData))
 	mr.CloseMultipart()
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationRecycleRestore, http.MethodPost, pcsURL.String(), mr, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationRecycleRestore, http.MethodPost, pcsURL.String(), mr, nil)
 	return
 }
 
 // PrepareRecycleDelete 删除回收站文件或目录, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRecycleDelete(fidList ...int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
+	return pcs.PrepareRecycleDeleteContext(context.Background(), fidList...)
+}
+
+// PrepareRecycleDeleteContext 同 PrepareRecycleDelete, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRecycleDeleteContext(ctx context.Context, fidList ...int64) (dataReadCloser io.ReadCloser, panError pcserror.Error) {
 	pcs.lazyInit()
 
 	panURL := pcs.generatePanURL("recycle/delete", nil)
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationRecycleDelete, panURL)
 
-	dataReadCloser, panError = pcs.sendReqReturnReadCloser(reqTypePan, OperationRecycleDelete, http.MethodPost, panURL.String(), map[string]string{
+	dataReadCloser, panError = pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationRecycleDelete, http.MethodPost, panURL.String(), map[string]string{
 		"fidlist": mergeInt64List(fidList...),
 	}, map[string]string{
 		"Content-Type": "application/x-www-form-urlencoded",
@@ -887,6 +1053,11 @@ func (pcs *BaiduPCS) PrepareRecycleDelete(fidList ...int64) (dataReadCloser io.R
 
 // PrepareRecycleClear 清空回收站, 只返回服务器响应数据和错误信息
 func (pcs *BaiduPCS) PrepareRecycleClear() (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
+	return pcs.PrepareRecycleClearContext(context.Background())
+}
+
+// PrepareRecycleClearContext 同 PrepareRecycleClear, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PrepareRecycleClearContext(ctx context.Context) (dataReadCloser io.ReadCloser, pcsError pcserror.Error) {
 	pcs.lazyInit()
 
 	pcsURL := pcs.generatePCSURL("file", "delete", map[string]string{
@@ -895,6 +1066,6 @@ func (pcs *BaiduPCS) PrepareRecycleClear() (dataReadCloser io.ReadCloser, pcsErr
 
 	baiduPCSVerbose.Infof("%s URL: %s\n", OperationRecycleClear, pcsURL)
 
-	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(reqTypePCS, OperationRecycleClear, http.MethodGet, pcsURL.String(), nil, nil)
+	dataReadCloser, pcsError = pcs.sendReqReturnReadCloser(ctx, reqTypePCS, OperationRecycleClear, http.MethodGet, pcsURL.String(), nil, nil)
 	return
 }
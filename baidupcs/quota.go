@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 )
 
@@ -12,7 +13,12 @@ type quotaInfo struct {
 
 // QuotaInfo 获取当前用户空间配额信息
 func (pcs *BaiduPCS) QuotaInfo() (quota, used int64, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareQuotaInfo()
+	return pcs.QuotaInfoContext(context.Background())
+}
+
+// QuotaInfoContext 同 QuotaInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) QuotaInfoContext(ctx context.Context) (quota, used int64, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareQuotaInfoContext(ctx)
 	if pcsError != nil {
 		return
 	}
@@ -33,7 +39,12 @@ func (pcs *BaiduPCS) QuotaInfo() (quota, used int64, pcsError pcserror.Error) {
 
 // SpaceLeftInfo 获取当前用户剩余空间
 func (pcs *BaiduPCS) SpaceLeftInfo() (free int64, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareQuotaInfo()
+	return pcs.SpaceLeftInfoContext(context.Background())
+}
+
+// SpaceLeftInfoContext 同 SpaceLeftInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) SpaceLeftInfoContext(ctx context.Context) (free int64, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareQuotaInfoContext(ctx)
 	if pcsError != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 )
 
@@ -45,7 +46,12 @@ type (
 
 // RecycleList 列出回收站文件列表
 func (pcs *BaiduPCS) RecycleList(page int) (fdl RecycleFDInfoList, panError pcserror.Error) {
-	dataReadCloser, panError := pcs.PrepareRecycleList(page)
+	return pcs.RecycleListContext(context.Background(), page)
+}
+
+// RecycleListContext 同 RecycleList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RecycleListContext(ctx context.Context, page int) (fdl RecycleFDInfoList, panError pcserror.Error) {
+	dataReadCloser, panError := pcs.PrepareRecycleListContext(ctx, page)
 	if panError != nil {
 		return
 	}
@@ -67,7 +73,12 @@ func (pcs *BaiduPCS) RecycleList(page int) (fdl RecycleFDInfoList, panError pcse
 
 // RecycleRestore 还原回收站文件或目录
 func (pcs *BaiduPCS) RecycleRestore(fidList ...int64) (sussFsIDList []*FsIDJSON, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareRecycleRestore(fidList...)
+	return pcs.RecycleRestoreContext(context.Background(), fidList...)
+}
+
+// RecycleRestoreContext 同 RecycleRestore, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RecycleRestoreContext(ctx context.Context, fidList ...int64) (sussFsIDList []*FsIDJSON, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareRecycleRestoreContext(ctx, fidList...)
 	if pcsError != nil {
 		return
 	}
@@ -85,7 +96,12 @@ func (pcs *BaiduPCS) RecycleRestore(fidList ...int64) (sussFsIDList []*FsIDJSON,
 
 // RecycleDelete 删除回收站文件或目录
 func (pcs *BaiduPCS) RecycleDelete(fidList ...int64) (panError pcserror.Error) {
-	dataReadCloser, panError := pcs.PrepareRecycleDelete(fidList...)
+	return pcs.RecycleDeleteContext(context.Background(), fidList...)
+}
+
+// RecycleDeleteContext 同 RecycleDelete, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RecycleDeleteContext(ctx context.Context, fidList ...int64) (panError pcserror.Error) {
+	dataReadCloser, panError := pcs.PrepareRecycleDeleteContext(ctx, fidList...)
 	if panError != nil {
 		return
 	}
@@ -98,7 +114,12 @@ func (pcs *BaiduPCS) RecycleDelete(fidList ...int64) (panError pcserror.Error) {
 
 // RecycleClear 清空回收站
 func (pcs *BaiduPCS) RecycleClear() (sussNum int, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareRecycleClear()
+	return pcs.RecycleClearContext(context.Background())
+}
+
+// RecycleClearContext 同 RecycleClear, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RecycleClearContext(ctx context.Context) (sussNum int, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareRecycleClearContext(ctx)
 	if pcsError != nil {
 		return
 	}
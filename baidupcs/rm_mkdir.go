@@ -1,13 +1,19 @@
 package baidupcs
 
 import (
+	"context"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"path"
 )
 
 // Remove 批量删除文件/目录
 func (pcs *BaiduPCS) Remove(paths ...string) (pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareRemove(paths...)
+	return pcs.RemoveContext(context.Background(), paths...)
+}
+
+// RemoveContext 同 Remove, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RemoveContext(ctx context.Context, paths ...string) (pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareRemoveContext(ctx, paths...)
 	if pcsError != nil {
 		return
 	}
@@ -26,7 +32,12 @@ func (pcs *BaiduPCS) Remove(paths ...string) (pcsError pcserror.Error) {
 
 // Mkdir 创建目录
 func (pcs *BaiduPCS) Mkdir(pcspath string) (pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareMkdir(pcspath)
+	return pcs.MkdirContext(context.Background(), pcspath)
+}
+
+// MkdirContext 同 Mkdir, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) MkdirContext(ctx context.Context, pcspath string) (pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareMkdirContext(ctx, pcspath)
 	if pcsError != nil {
 		return
 	}
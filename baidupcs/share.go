@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -70,11 +71,16 @@ var (
 
 // ShareSet 分享文件
 func (pcs *BaiduPCS) ShareSet(paths []string, option *ShareOption) (s *Shared, pcsError pcserror.Error) {
+	return pcs.ShareSetContext(context.Background(), paths, option)
+}
+
+// ShareSetContext 同 ShareSet, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) ShareSetContext(ctx context.Context, paths []string, option *ShareOption) (s *Shared, pcsError pcserror.Error) {
 	if option.Password == "" || len(option.Password) != 4 {
 		option = &ShareOption{CreatePasswd(), option.Period, option.IsCombined}
 	}
 
-	dataReadCloser, pcsError := pcs.PrepareSharePSet(paths, option.Password, option.Period)
+	dataReadCloser, pcsError := pcs.PrepareSharePSetContext(ctx, paths, option.Password, option.Period)
 	if pcsError != nil {
 		return
 	}
@@ -104,7 +110,12 @@ func (pcs *BaiduPCS) ShareSet(paths []string, option *ShareOption) (s *Shared, p
 
 // ShareCancel 取消分享
 func (pcs *BaiduPCS) ShareCancel(shareIDs []int64) (pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareShareCancel(shareIDs)
+	return pcs.ShareCancelContext(context.Background(), shareIDs)
+}
+
+// ShareCancelContext 同 ShareCancel, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) ShareCancelContext(ctx context.Context, shareIDs []int64) (pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareShareCancelContext(ctx, shareIDs)
 	if pcsError != nil {
 		return
 	}
@@ -117,7 +128,12 @@ func (pcs *BaiduPCS) ShareCancel(shareIDs []int64) (pcsError pcserror.Error) {
 
 // ShareList 列出分享列表
 func (pcs *BaiduPCS) ShareList(page int) (records ShareRecordInfoList, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareShareList(page)
+	return pcs.ShareListContext(context.Background(), page)
+}
+
+// ShareListContext 同 ShareList, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) ShareListContext(ctx context.Context, page int) (records ShareRecordInfoList, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareShareListContext(ctx, page)
 	if pcsError != nil {
 		return
 	}
@@ -154,7 +170,12 @@ func (pcs *BaiduPCS) ShareList(page int) (records ShareRecordInfoList, pcsError
 
 //ShareSURLInfo 获取分享的详细信息, 包含密码
 func (pcs *BaiduPCS) ShareSURLInfo(shareID int64) (info *ShareSURLInfo, pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.PrepareShareSURLInfo(shareID)
+	return pcs.ShareSURLInfoContext(context.Background(), shareID)
+}
+
+// ShareSURLInfoContext 同 ShareSURLInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) ShareSURLInfoContext(ctx context.Context, shareID int64) (info *ShareSURLInfo, pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.PrepareShareSURLInfoContext(ctx, shareID)
 	if pcsError != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester"
@@ -40,8 +41,13 @@ func (pcs *BaiduPCS) GenerateShareQueryURL(subPath string, params map[string]str
 }
 
 func (pcs *BaiduPCS) ExtractShareInfo(shareURL, shardID, shareUK, bdstoken string) (res map[string]string) {
+	return pcs.ExtractShareInfoContext(context.Background(), shareURL, shardID, shareUK, bdstoken)
+}
+
+// ExtractShareInfoContext 同 ExtractShareInfo, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) ExtractShareInfoContext(ctx context.Context, shareURL, shardID, shareUK, bdstoken string) (res map[string]string) {
 	res = make(map[string]string)
-	dataReadCloser, panError := pcs.sendReqReturnReadCloser(reqTypePan, OperationShareFileSavetoLocal, http.MethodGet, shareURL, nil, map[string]string{
+	dataReadCloser, panError := pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareFileSavetoLocal, http.MethodGet, shareURL, nil, map[string]string{
 		"User-Agent":   requester.UserAgent,
 		"Content-Type": "application/x-www-form-urlencoded; charset=UTF-8",
 	})
@@ -91,7 +97,12 @@ func (pcs *BaiduPCS) ExtractShareInfo(shareURL, shardID, shareUK, bdstoken strin
 }
 
 func (pcs *BaiduPCS) PostShareQuery(url string, referer string, data map[string]string) (res map[string]string) {
-	dataReadCloser, panError := pcs.sendReqReturnReadCloser(reqTypePan, OperationShareFileSavetoLocal, http.MethodPost, url, data, map[string]string{
+	return pcs.PostShareQueryContext(context.Background(), url, referer, data)
+}
+
+// PostShareQueryContext 同 PostShareQuery, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) PostShareQueryContext(ctx context.Context, url string, referer string, data map[string]string) (res map[string]string) {
+	dataReadCloser, panError := pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareFileSavetoLocal, http.MethodPost, url, data, map[string]string{
 		"User-Agent":   requester.UserAgent,
 		"Content-Type": "application/x-www-form-urlencoded; charset=UTF-8",
 		"Referer":      referer,
@@ -117,6 +128,11 @@ func (pcs *BaiduPCS) PostShareQuery(url string, referer string, data map[string]
 }
 
 func (pcs *BaiduPCS) AccessSharePage(featurestr string, first bool) (tokens map[string]string) {
+	return pcs.AccessSharePageContext(context.Background(), featurestr, first)
+}
+
+// AccessSharePageContext 同 AccessSharePage, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) AccessSharePageContext(ctx context.Context, featurestr string, first bool) (tokens map[string]string) {
 	tokens = make(map[string]string)
 	tokens["ErrMsg"] = "0"
 	headers := make(map[string]string)
@@ -127,7 +143,7 @@ func (pcs *BaiduPCS) AccessSharePage(featurestr string, first bool) (tokens map[
 	}
 	shareLink := fmt.Sprintf("https://pan.baidu.com/s/%s", featurestr)
 
-	dataReadCloser, panError := pcs.sendReqReturnReadCloser(reqTypePan, OperationShareFileSavetoLocal, http.MethodGet, shareLink, nil, headers)
+	dataReadCloser, panError := pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareFileSavetoLocal, http.MethodGet, shareLink, nil, headers)
 
 	if panError != nil {
 		tokens["ErrMsg"] = "访问分享页失败"
@@ -161,6 +177,11 @@ func (pcs *BaiduPCS) AccessSharePage(featurestr string, first bool) (tokens map[
 }
 
 func (pcs *BaiduPCS) GenerateRequestQuery(mode string, params map[string]string) (res map[string]string) {
+	return pcs.GenerateRequestQueryContext(context.Background(), mode, params)
+}
+
+// GenerateRequestQueryContext 同 GenerateRequestQuery, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) GenerateRequestQueryContext(ctx context.Context, mode string, params map[string]string) (res map[string]string) {
 	res = make(map[string]string)
 	res["ErrNo"] = "0"
 	headers := map[string]string{
@@ -173,7 +194,7 @@ func (pcs *BaiduPCS) GenerateRequestQuery(mode string, params map[string]string)
 	postdata := make(map[string]string)
 	postdata["fsidlist"] = params["fs_id"]
 	postdata["path"] = params["path"]
-	dataReadCloser, panError := pcs.sendReqReturnReadCloser(reqTypePan, OperationShareFileSavetoLocal, mode, params["shareUrl"], postdata, headers)
+	dataReadCloser, panError := pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareFileSavetoLocal, mode, params["shareUrl"], postdata, headers)
 	if panError != nil {
 		res["ErrNo"] = "1"
 		res["ErrMsg"] = "网络错误"
@@ -235,6 +256,11 @@ func (pcs *BaiduPCS) GenerateRequestQuery(mode string, params map[string]string)
 }
 
 func (pcs *BaiduPCS) SuperTransfer(params map[string]string, limit string) {
+	pcs.SuperTransferContext(context.Background(), params, limit)
+}
+
+// SuperTransferContext 同 SuperTransfer, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) SuperTransferContext(ctx context.Context, params map[string]string, limit string) {
 	//headers := map[string]string{
 	//	"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/76.0.3809.100 Safari/537.36",
 	//	"Referer":    params["referer"],
@@ -256,7 +282,7 @@ func (pcs *BaiduPCS) SuperTransfer(params map[string]string, limit string) {
 	//uv.Set("num", "100")
 	//uv.Set("shorturl", params["shorturl"])
 	//uv.Set("root", "1")
-	//dataReadCloser, panError := pcs.sendReqReturnReadCloser(reqTypePan, OperationShareFileSavetoLocal, http.MethodGet, listUrl.String(), nil, headers)
+	//dataReadCloser, panError := pcs.sendReqReturnReadCloser(ctx, reqTypePan, OperationShareFileSavetoLocal, http.MethodGet, listUrl.String(), nil, headers)
 	//if panError != nil {
 	//	res["ErrNo"] = "1"
 	//	res["ErrMsg"] = "网络错误"
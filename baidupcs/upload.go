@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"errors"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
@@ -103,18 +104,28 @@ type (
 
 // RapidUpload 秒传文件
 func (pcs *BaiduPCS) RapidUpload(targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
+	return pcs.RapidUploadContext(context.Background(), targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
+}
+
+// RapidUploadContext 同 RapidUpload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RapidUploadContext(ctx context.Context, targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
 	defer func() {
 		if pcsError == nil {
 			// 更新缓存
 			pcs.deleteCache([]string{path.Dir(targetPath)})
 		}
 	}()
-	pcsError, jsonData = pcs.rapidUploadV2(targetPath, policy, uploadid, strings.ToLower(contentMD5), strings.ToLower(sliceMD5), dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
+	pcsError, jsonData = pcs.rapidUploadV2(ctx, targetPath, policy, uploadid, strings.ToLower(contentMD5), strings.ToLower(sliceMD5), dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
 	return
 }
 
 // FakeRapidUpload 只precreate不进行秒传
 func (pcs *BaiduPCS) FakeRapidUpload(targetPath, policy string, length int64) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
+	return pcs.FakeRapidUploadContext(context.Background(), targetPath, policy, length)
+}
+
+// FakeRapidUploadContext 同 FakeRapidUpload, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) FakeRapidUploadContext(ctx context.Context, targetPath, policy string, length int64) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
 	defer func() {
 		if pcsError == nil {
 			// 更新缓存
@@ -122,15 +133,15 @@ func (pcs *BaiduPCS) FakeRapidUpload(targetPath, policy string, length int64) (p
 		}
 	}()
 	if length <= MinUploadBlockSize {
-		pcsError, jsonData = pcs.fakeRapidUploadV2(targetPath, policy, length, time.Now().Unix(), fakeBlockListMD5[0:1])
+		pcsError, jsonData = pcs.fakeRapidUploadV2(ctx, targetPath, policy, length, time.Now().Unix(), fakeBlockListMD5[0:1])
 		return
 	}
-	pcsError, jsonData = pcs.fakeRapidUploadV2(targetPath, policy, length, time.Now().Unix(), fakeBlockListMD5)
+	pcsError, jsonData = pcs.fakeRapidUploadV2(ctx, targetPath, policy, length, time.Now().Unix(), fakeBlockListMD5)
 	return
 }
 
-func (pcs *BaiduPCS) rapidUploadV2(targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
-	dataReadCloser, pcsError := pcs.PrepareRapidUploadV2(targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
+func (pcs *BaiduPCS) rapidUploadV2(ctx context.Context, targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32 string, offset, length, totalSize, dataTime int64, blockListMD5 []string) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
+	dataReadCloser, pcsError := pcs.PrepareRapidUploadV2Context(ctx, targetPath, policy, uploadid, contentMD5, sliceMD5, dataContent, crc32, offset, length, totalSize, dataTime, blockListMD5)
 	if pcsError != nil {
 		return
 	}
@@ -142,8 +153,8 @@ func (pcs *BaiduPCS) rapidUploadV2(targetPath, policy, uploadid, contentMD5, sli
 	return pcsError, jsonData
 }
 
-func (pcs *BaiduPCS) fakeRapidUploadV2(targetPath, policy string, length, dateTime int64, blockListMD5 []string) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
-	dataReadCloser, pcsError := pcs.PrepareFakeRapidUploadV2(targetPath, policy, length, dateTime, blockListMD5)
+func (pcs *BaiduPCS) fakeRapidUploadV2(ctx context.Context, targetPath, policy string, length, dateTime int64, blockListMD5 []string) (pcsError pcserror.Error, jsonData uploadPrecreateJSON) {
+	dataReadCloser, pcsError := pcs.PrepareFakeRapidUploadV2Context(ctx, targetPath, policy, length, dateTime, blockListMD5)
 	if pcsError != nil {
 		return
 	}
@@ -157,7 +168,12 @@ func (pcs *BaiduPCS) fakeRapidUploadV2(targetPath, policy string, length, dateTi
 
 // RapidUploadNoCheckDir 秒传文件, 不进行目录检查, 会覆盖掉同名的目录!
 func (pcs *BaiduPCS) RapidUploadNoCheckDir(targetPath, contentMD5, sliceMD5, crc32 string, length int64) (pcsError pcserror.Error) {
-	dataReadCloser, pcsError := pcs.prepareRapidUpload(targetPath, contentMD5, sliceMD5, crc32, length)
+	return pcs.RapidUploadNoCheckDirContext(context.Background(), targetPath, contentMD5, sliceMD5, crc32, length)
+}
+
+// RapidUploadNoCheckDirContext 同 RapidUploadNoCheckDir, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) RapidUploadNoCheckDirContext(ctx context.Context, targetPath, contentMD5, sliceMD5, crc32 string, length int64) (pcsError pcserror.Error) {
+	dataReadCloser, pcsError := pcs.prepareRapidUpload(ctx, targetPath, contentMD5, sliceMD5, crc32, length)
 	if pcsError != nil {
 		return
 	}
@@ -203,9 +219,14 @@ func (pcs *BaiduPCS) UploadTmpFile(uploadid, targetPath string, partseq int, par
 
 // UploadCreateSuperFile 分片上传—合并分片文件
 func (pcs *BaiduPCS) UploadCreateSuperFile(uploadid, policy string, fileSize int64, targetPath string, checksumMap map[int]string) (panError pcserror.Error) {
+	return pcs.UploadCreateSuperFileContext(context.Background(), uploadid, policy, fileSize, targetPath, checksumMap)
+}
+
+// UploadCreateSuperFileContext 同 UploadCreateSuperFile, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) UploadCreateSuperFileContext(ctx context.Context, uploadid, policy string, fileSize int64, targetPath string, checksumMap map[int]string) (panError pcserror.Error) {
 	blockList := sortBlockList(checksumMap)
 	rtype := pcs.policyTortype(policy)
-	dataReadCloser, pcsError := pcs.PrepareUploadCreateSuperFile(uploadid, rtype, fileSize, targetPath, blockList)
+	dataReadCloser, pcsError := pcs.PrepareUploadCreateSuperFileContext(ctx, uploadid, rtype, fileSize, targetPath, blockList)
 	if pcsError != nil {
 		return pcsError
 	}
@@ -224,10 +245,15 @@ func (pcs *BaiduPCS) UploadCreateSuperFile(uploadid, policy string, fileSize int
 
 // GetRandomPCSHost 随机获取一个可用的pcs地址
 func (pcs *BaiduPCS) GetRandomPCSHost() (pcsError pcserror.Error, pcsHost string) {
+	return pcs.GetRandomPCSHostContext(context.Background())
+}
+
+// GetRandomPCSHostContext 同 GetRandomPCSHost, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) GetRandomPCSHostContext(ctx context.Context) (pcsError pcserror.Error, pcsHost string) {
 	if pcs.fixPCSAddr {
 		return
 	}
-	dataReadCloser, pcsError := pcs.PreparePCSServers()
+	dataReadCloser, pcsError := pcs.PreparePCSServersContext(ctx)
 	if pcsError != nil {
 		return
 	}
@@ -1,6 +1,7 @@
 package baidupcs
 
 import (
+	"context"
 	"crypto/md5"
 	"errors"
 	"fmt"
@@ -35,11 +36,16 @@ func (pcs *BaiduPCS) policyTortype(policy string) string {
 
 // Isdir 检查路径在网盘中是否为目录
 func (pcs *BaiduPCS) Isdir(pcspath string) (fileSize int64, isdir bool, pcsError pcserror.Error) {
+	return pcs.IsdirContext(context.Background(), pcspath)
+}
+
+// IsdirContext 同 Isdir, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) IsdirContext(ctx context.Context, pcspath string) (fileSize int64, isdir bool, pcsError pcserror.Error) {
 	if path.Clean(pcspath) == PathSeparator {
 		return 0, true, nil
 	}
 
-	f, pcsError := pcs.FilesDirectoriesMeta(pcspath)
+	f, pcsError := pcs.FilesDirectoriesMetaContext(ctx, pcspath)
 	if pcsError != nil {
 		return 0, false, pcsError
 	}
@@ -48,9 +54,14 @@ func (pcs *BaiduPCS) Isdir(pcspath string) (fileSize int64, isdir bool, pcsError
 }
 
 func (pcs *BaiduPCS) CheckIsdir(op string, targetPath string, policy string, fileSize int64) pcserror.Error {
+	return pcs.CheckIsdirContext(context.Background(), op, targetPath, policy, fileSize)
+}
+
+// CheckIsdirContext 同 CheckIsdir, ctx 取消或超时时中断请求
+func (pcs *BaiduPCS) CheckIsdirContext(ctx context.Context, op string, targetPath string, policy string, fileSize int64) pcserror.Error {
 	// 检测文件是否存在于网盘路径
 	// 很重要, 如果文件存在会直接覆盖!!! 即使是根目录!
-	targetFileSize, isdir, pcsError := pcs.Isdir(targetPath)
+	targetFileSize, isdir, pcsError := pcs.IsdirContext(ctx, targetPath)
 	if pcsError != nil {
 		// 忽略远程服务端返回的错误
 		if pcsError.GetErrType() != pcserror.ErrTypeRemoteError {
@@ -0,0 +1,142 @@
+package xpan
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Category 文件分类
+	Category int
+
+	// CategoryCount 分类文件的统计
+	CategoryCount struct {
+		Total int64 `json:"total"`
+		Size  int64 `json:"size"`
+		Count int64 `json:"count"`
+	}
+
+	// CategoryListOptions CategoryList 的选项
+	CategoryListOptions struct {
+		OrderOptions
+		ParentPath string   // 目录, 默认为 /
+		Recursion  bool     // 是否递归
+		Ext        []string // 只返回指定扩展名的文件, 如 jpg
+		Start      int      // 起始位置
+		Limit      int      // 返回条目数, 0 为获取全部
+	}
+
+	categoryInfoResponse struct {
+		Response
+		Info map[string]*CategoryCount `json:"info"`
+	}
+)
+
+const (
+	// CategoryVideo 视频
+	CategoryVideo Category = 1
+	// CategoryAudio 音频
+	CategoryAudio Category = 2
+	// CategoryPicture 图片
+	CategoryPicture Category = 3
+	// CategoryDocument 文档
+	CategoryDocument Category = 4
+	// CategoryApp 应用
+	CategoryApp Category = 5
+	// CategoryOther 其他
+	CategoryOther Category = 6
+	// CategoryTorrent 种子
+	CategoryTorrent Category = 7
+)
+
+// String 返回分类名称
+func (ca Category) String() string {
+	switch ca {
+	case CategoryVideo:
+		return "视频"
+	case CategoryAudio:
+		return "音频"
+	case CategoryPicture:
+		return "图片"
+	case CategoryDocument:
+		return "文档"
+	case CategoryApp:
+		return "应用"
+	case CategoryOther:
+		return "其他"
+	case CategoryTorrent:
+		return "种子"
+	}
+	return "未知"
+}
+
+// CategoryInfo 获取目录下指定分类的文件总个数和总大小
+func (c *Client) CategoryInfo(ctx context.Context, category Category, parentPath string, recursion bool) (*CategoryCount, pcserror.Error) {
+	if parentPath == "" {
+		parentPath = "/"
+	}
+	param := map[string]string{
+		"category":    strconv.Itoa(int(category)),
+		"parent_path": parentPath,
+		"recursion":   boolParam(recursion),
+	}
+
+	resp := categoryInfoResponse{}
+	pcsError := c.get(ctx, OperationCategoryInfo, c.generateURL("api/categoryinfo", "", param), &resp)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	count := resp.Info[strconv.Itoa(int(category))]
+	if count == nil {
+		count = &CategoryCount{}
+	}
+	return count, nil
+}
+
+// CategoryList 获取目录下指定分类的文件列表, 自动翻页
+func (c *Client) CategoryList(ctx context.Context, category Category, opt *CategoryListOptions) (FileList, pcserror.Error) {
+	if opt == nil {
+		opt = &CategoryListOptions{}
+	}
+	parentPath := opt.ParentPath
+	if parentPath == "" {
+		parentPath = "/"
+	}
+
+	var list FileList
+	start := opt.Start
+	for {
+		n := pageLimit(opt.Limit, len(list), ListPageSize)
+		if n == 0 {
+			break
+		}
+		param := map[string]string{
+			"category":    strconv.Itoa(int(category)),
+			"parent_path": parentPath,
+			"recursion":   boolParam(opt.Recursion),
+			"start":       strconv.Itoa(start),
+			"limit":       strconv.Itoa(n),
+			"showdir":     "0",
+		}
+		if len(opt.Ext) > 0 {
+			param["ext"] = strings.Join(opt.Ext, ",")
+		}
+		opt.OrderOptions.params(param)
+
+		resp := listResponse{}
+		pcsError := c.get(ctx, OperationCategoryList, c.generateURL("rest/2.0/xpan/multimedia", "categorylist", param), &resp)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+		resp.List.normalize()
+		list = append(list, resp.List...)
+		if !resp.hasMore() || resp.Cursor <= start {
+			break
+		}
+		start = resp.Cursor
+	}
+	return list, nil
+}
@@ -0,0 +1,199 @@
+package xpan
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// ErrorInfo xpan 接口的错误, 实现 pcserror.Error
+	ErrorInfo struct {
+		Operation string
+		ErrType   pcserror.ErrType
+		Err       error
+		ErrNo     int
+		ErrMsg    string
+	}
+)
+
+const (
+	// ErrnoAccessDenied 身份验证失败, access_token 无效
+	ErrnoAccessDenied = -6
+	// ErrnoNoPermission 文件或目录名错误, 或无权访问
+	ErrnoNoPermission = -7
+	// ErrnoFileExists 文件或目录已存在
+	ErrnoFileExists = -8
+	// ErrnoFileNotExists 文件或目录不存在
+	ErrnoFileNotExists = -9
+	// ErrnoParam 参数错误
+	ErrnoParam = 2
+	// ErrnoPartialFailed 批量操作部分失败
+	ErrnoPartialFailed = 12
+	// ErrnoAccessTokenInvalid access_token 不正确或已过期
+	ErrnoAccessTokenInvalid = 110
+	// ErrnoAccessTokenExpired access_token 已过期
+	ErrnoAccessTokenExpired = 111
+	// ErrnoRateLimited 命中接口频控
+	ErrnoRateLimited = 31034
+)
+
+// NewErrorInfo 提供operation操作名称, 返回 *ErrorInfo
+func NewErrorInfo(operation string) *ErrorInfo {
+	return &ErrorInfo{
+		Operation: operation,
+		ErrType:   pcserror.ErrorTypeNoError,
+	}
+}
+
+// SetJSONError 设置JSON错误
+func (xe *ErrorInfo) SetJSONError(err error) {
+	xe.ErrType = pcserror.ErrTypeJSONParseError
+	xe.Err = err
+}
+
+// SetNetError 设置网络错误
+func (xe *ErrorInfo) SetNetError(err error) {
+	xe.ErrType = pcserror.ErrTypeNetError
+	xe.Err = err
+}
+
+// SetRemoteError 设置远端服务器错误
+func (xe *ErrorInfo) SetRemoteError() {
+	xe.ErrType = pcserror.ErrTypeRemoteError
+}
+
+// GetOperation 获取操作
+func (xe *ErrorInfo) GetOperation() string {
+	return xe.Operation
+}
+
+// GetErrType 获取错误类型
+func (xe *ErrorInfo) GetErrType() pcserror.ErrType {
+	return xe.ErrType
+}
+
+// GetRemoteErrCode 获取远端服务器错误代码
+func (xe *ErrorInfo) GetRemoteErrCode() int {
+	return xe.ErrNo
+}
+
+// GetRemoteErrMsg 获取远端服务器错误消息
+func (xe *ErrorInfo) GetRemoteErrMsg() string {
+	if xe.ErrMsg != "" {
+		return xe.ErrMsg
+	}
+	return FindErr(xe.ErrNo)
+}
+
+// GetError 获取原始错误
+func (xe *ErrorInfo) GetError() error {
+	return xe.Err
+}
+
+func (xe *ErrorInfo) Error() string {
+	switch xe.ErrType {
+	case pcserror.ErrTypeInternalError:
+		return fmt.Sprintf("%s: %s, %s", xe.Operation, pcserror.StrInternalError, xe.Err)
+	case pcserror.ErrTypeJSONParseError:
+		return fmt.Sprintf("%s: %s, %s", xe.Operation, pcserror.StrJSONParseError, xe.Err)
+	case pcserror.ErrTypeNetError:
+		return fmt.Sprintf("%s: %s, %s", xe.Operation, pcserror.StrNetError, xe.Err)
+	case pcserror.ErrTypeRemoteError:
+		return fmt.Sprintf("%s: 遇到错误, %s, 代码: %d, 消息: %s", xe.Operation, pcserror.StrRemoteError, xe.ErrNo, xe.GetRemoteErrMsg())
+	case pcserror.ErrTypeOthers:
+		if xe.Err == nil {
+			return fmt.Sprintf("%s: %s", xe.Operation, pcserror.StrSuccess)
+		}
+		return fmt.Sprintf("%s, 遇到错误, %s", xe.Operation, xe.Err)
+	default:
+		return fmt.Sprintf("%s: %s", xe.Operation, pcserror.StrSuccess)
+	}
+}
+
+// FindErr 根据 errno, 解析开放平台的错误信息
+func FindErr(errno int) string {
+	switch errno {
+	case 0:
+		return pcserror.StrSuccess
+	case ErrnoAccessDenied:
+		return "身份验证失败, access_token 无效"
+	case ErrnoNoPermission:
+		return "文件或目录名错误, 或无权访问"
+	case ErrnoFileExists:
+		return "文件或目录已存在"
+	case ErrnoFileNotExists:
+		return "文件或目录不存在"
+	case ErrnoParam:
+		return "参数错误"
+	case 3:
+		return "不支持此接口"
+	case 4:
+		return "没有权限执行此操作"
+	case 5:
+		return "IP未授权"
+	case 6:
+		return "不允许接入用户数据"
+	case 10:
+		return "转存文件已经存在"
+	case 11:
+		return "用户不存在"
+	case ErrnoPartialFailed:
+		return "批量操作部分失败"
+	case 31023:
+		return "参数错误"
+	case 31024:
+		return "app id为空"
+	case 31061:
+		return "文件已经存在"
+	case 31062:
+		return "文件名非法"
+	case 31063:
+		return "文件父目录不存在"
+	case 31064:
+		return "无权访问此文件"
+	case ErrnoRateLimited:
+		return "命中接口频控, 请稍后再试"
+	case 31066:
+		return "文件不存在"
+	case 42000:
+		return "访问过于频繁"
+	case 42211:
+		return "图片详细信息查询失败"
+	case 42212:
+		return "共享目录文件上传者信息查询失败"
+	case 42213:
+		return "共享目录鉴权失败"
+	case 42214:
+		return "文件基础信息查询失败"
+	case ErrnoAccessTokenInvalid:
+		return "access_token 不正确或已过期"
+	case ErrnoAccessTokenExpired:
+		return "access_token 已过期"
+	default:
+		return fmt.Sprintf("未知错误, errno: %d", errno)
+	}
+}
+
+// IsAccessTokenInvalid 错误是否由 access_token 无效或过期引起, 需要重新授权或刷新
+func IsAccessTokenInvalid(err error) bool {
+	var xe *ErrorInfo
+	if !errors.As(err, &xe) || xe.ErrType != pcserror.ErrTypeRemoteError {
+		return false
+	}
+	switch xe.ErrNo {
+	case ErrnoAccessDenied, ErrnoAccessTokenInvalid, ErrnoAccessTokenExpired:
+		return true
+	}
+	return false
+}
+
+// IsNotExist 错误是否为文件或目录不存在
+func IsNotExist(err error) bool {
+	var xe *ErrorInfo
+	if !errors.As(err, &xe) || xe.ErrType != pcserror.ErrTypeRemoteError {
+		return false
+	}
+	return xe.ErrNo == ErrnoFileNotExists || xe.ErrNo == 31066
+}
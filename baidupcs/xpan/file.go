@@ -0,0 +1,295 @@
+package xpan
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// File 文件或目录信息
+	File struct {
+		FsID           uint64            `json:"fs_id"`
+		Path           string            `json:"path"`
+		ServerFilename string            `json:"server_filename"`
+		Size           int64             `json:"size"`
+		ServerMtime    int64             `json:"server_mtime"`
+		ServerCtime    int64             `json:"server_ctime"`
+		LocalMtime     int64             `json:"local_mtime"`
+		LocalCtime     int64             `json:"local_ctime"`
+		Isdir          int               `json:"isdir"`
+		Category       int               `json:"category"`
+		MD5            string            `json:"md5"`
+		DirEmpty       int               `json:"dir_empty,omitempty"`
+		Thumbs         map[string]string `json:"thumbs,omitempty"`
+		Dlink          string            `json:"dlink,omitempty"` // 仅 FileMetas 指定 dlink 时返回
+
+		// Filename FileMetas 返回的文件名, 解析后会同时写入 ServerFilename
+		Filename string `json:"filename,omitempty"`
+	}
+
+	// FileList 文件列表
+	FileList []*File
+
+	// OrderOptions 列表排序选项
+	OrderOptions struct {
+		Order string // 排序字段: name, time, size, 默认 name
+		Desc  bool   // 是否降序
+	}
+
+	// ListOptions List 的选项
+	ListOptions struct {
+		OrderOptions
+		Start int // 起始位置
+		Limit int // 返回条目数, 0 为获取全部
+	}
+
+	// ListAllOptions ListAll 的选项
+	ListAllOptions struct {
+		OrderOptions
+		Recursion bool  // 是否递归
+		Start     int   // 起始位置 (上次返回的 cursor)
+		Limit     int   // 返回条目数, 0 为获取全部
+		Mtime     int64 // 只返回修改时间晚于该时间的文件
+	}
+
+	// SearchOptions Search 的选项
+	SearchOptions struct {
+		Dir       string // 搜索的目录, 默认为 /
+		Recursion bool   // 是否递归搜索子目录
+		Limit     int    // 返回条目数, 0 为获取全部
+	}
+
+	// MetaOptions FileMetas 的选项
+	MetaOptions struct {
+		Dlink     bool // 是否返回下载链接
+		Thumb     bool // 是否返回缩略图
+		Extra     bool // 是否返回图片拍摄信息等
+		NeedMedia bool // 是否返回视频时长等
+	}
+
+	listResponse struct {
+		Response
+		List    FileList `json:"list"`
+		HasMore any      `json:"has_more"` // 不同接口返回 bool 或 0/1
+		Cursor  int      `json:"cursor"`
+	}
+)
+
+const (
+	// ListPageSize 每页获取的条目数, 为接口允许的最大值
+	ListPageSize = 1000
+	// SearchPageSize 搜索每页的条目数
+	SearchPageSize = 500
+	// MaxFsIDs FileMetas 每次查询的最大文件数
+	MaxFsIDs = 100
+)
+
+// IsDir 是否为目录
+func (f *File) IsDir() bool {
+	return f.Isdir == 1
+}
+
+func (f *File) normalize() {
+	if f.ServerFilename == "" {
+		f.ServerFilename = f.Filename
+	}
+	if f.Filename == "" {
+		f.Filename = f.ServerFilename
+	}
+}
+
+func (fl FileList) normalize() {
+	for _, f := range fl {
+		f.normalize()
+	}
+}
+
+func (lr *listResponse) hasMore() bool {
+	switch v := lr.HasMore.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+func (o *OrderOptions) params(param map[string]string) {
+	if o.Order != "" {
+		param["order"] = o.Order
+	}
+	if o.Desc {
+		param["desc"] = "1"
+	}
+}
+
+func boolParam(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// pageLimit 返回本页应获取的条目数, 剩余条目数为 0 时返回 0
+func pageLimit(limit, got, pageSize int) int {
+	if limit <= 0 {
+		return pageSize
+	}
+	return min(limit-got, pageSize)
+}
+
+// List 获取目录下的文件列表, 自动翻页
+func (c *Client) List(ctx context.Context, dir string, opt *ListOptions) (FileList, pcserror.Error) {
+	if opt == nil {
+		opt = &ListOptions{}
+	}
+
+	var list FileList
+	start := opt.Start
+	for {
+		n := pageLimit(opt.Limit, len(list), ListPageSize)
+		if n == 0 {
+			break
+		}
+		param := map[string]string{
+			"dir":       dir,
+			"start":     strconv.Itoa(start),
+			"limit":     strconv.Itoa(n),
+			"web":       "0",
+			"showempty": "1",
+		}
+		opt.OrderOptions.params(param)
+
+		resp := listResponse{}
+		pcsError := c.get(ctx, OperationList, c.generateURL("rest/2.0/xpan/file", "list", param), &resp)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+		resp.List.normalize()
+		list = append(list, resp.List...)
+		if len(resp.List) < n {
+			break
+		}
+		start += len(resp.List)
+	}
+	return list, nil
+}
+
+// ListAll 获取目录下的所有文件, Recursion 时包含子目录, 自动翻页
+func (c *Client) ListAll(ctx context.Context, dir string, opt *ListAllOptions) (FileList, pcserror.Error) {
+	if opt == nil {
+		opt = &ListAllOptions{}
+	}
+
+	var list FileList
+	start := opt.Start
+	for {
+		n := pageLimit(opt.Limit, len(list), ListPageSize)
+		if n == 0 {
+			break
+		}
+		param := map[string]string{
+			"path":      dir,
+			"recursion": boolParam(opt.Recursion),
+			"start":     strconv.Itoa(start),
+			"limit":     strconv.Itoa(n),
+			"web":       "0",
+		}
+		if opt.Mtime > 0 {
+			param["mtime"] = strconv.FormatInt(opt.Mtime, 10)
+		}
+		opt.OrderOptions.params(param)
+
+		resp := listResponse{}
+		pcsError := c.get(ctx, OperationListAll, c.generateURL("rest/2.0/xpan/multimedia", "listall", param), &resp)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+		resp.List.normalize()
+		list = append(list, resp.List...)
+		if !resp.hasMore() || resp.Cursor <= start {
+			break
+		}
+		start = resp.Cursor
+	}
+	return list, nil
+}
+
+// Search 按关键字搜索文件, 自动翻页
+func (c *Client) Search(ctx context.Context, key string, opt *SearchOptions) (FileList, pcserror.Error) {
+	if opt == nil {
+		opt = &SearchOptions{}
+	}
+	dir := opt.Dir
+	if dir == "" {
+		dir = "/"
+	}
+
+	var list FileList
+	for page := 1; ; page++ {
+		n := pageLimit(opt.Limit, len(list), SearchPageSize)
+		if n == 0 {
+			break
+		}
+		param := map[string]string{
+			"key":       key,
+			"dir":       dir,
+			"recursion": boolParam(opt.Recursion),
+			"page":      strconv.Itoa(page),
+			"num":       strconv.Itoa(SearchPageSize),
+			"web":       "0",
+		}
+
+		resp := listResponse{}
+		pcsError := c.get(ctx, OperationSearch, c.generateURL("rest/2.0/xpan/file", "search", param), &resp)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+		resp.List.normalize()
+		if len(resp.List) > n {
+			resp.List = resp.List[:n]
+		}
+		list = append(list, resp.List...)
+		if !resp.hasMore() || len(resp.List) == 0 {
+			break
+		}
+	}
+	return list, nil
+}
+
+// FileMetas 查询文件信息, 每次最多查询 MaxFsIDs 个, 超过时分批查询
+func (c *Client) FileMetas(ctx context.Context, fsIDs []uint64, opt *MetaOptions) (FileList, pcserror.Error) {
+	if opt == nil {
+		opt = &MetaOptions{}
+	}
+
+	var list FileList
+	for len(fsIDs) > 0 {
+		batch := fsIDs[:min(len(fsIDs), MaxFsIDs)]
+		fsIDs = fsIDs[len(batch):]
+
+		ids := make([]string, len(batch))
+		for i, id := range batch {
+			ids[i] = strconv.FormatUint(id, 10)
+		}
+		param := map[string]string{
+			"fsids":     "[" + strings.Join(ids, ",") + "]",
+			"dlink":     boolParam(opt.Dlink),
+			"thumb":     boolParam(opt.Thumb),
+			"extra":     boolParam(opt.Extra),
+			"needmedia": boolParam(opt.NeedMedia),
+		}
+
+		resp := listResponse{}
+		pcsError := c.get(ctx, OperationFileMetas, c.generateURL("rest/2.0/xpan/multimedia", "filemetas", param), &resp)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+		resp.List.normalize()
+		list = append(list, resp.List...)
+	}
+	return list, nil
+}
@@ -0,0 +1,121 @@
+package xpan
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+type (
+	// Ondup 目标已存在时的处理方式
+	Ondup string
+
+	// CopyMoveItem 复制或移动的文件
+	CopyMoveItem struct {
+		Path    string `json:"path"`
+		Dest    string `json:"dest"`    // 目标目录
+		Newname string `json:"newname"` // 目标文件名
+		Ondup   Ondup  `json:"ondup,omitempty"`
+	}
+
+	// RenameItem 重命名的文件
+	RenameItem struct {
+		Path    string `json:"path"`
+		Newname string `json:"newname"`
+	}
+
+	// ManageResult 批量操作中单个文件的结果
+	ManageResult struct {
+		Errno int    `json:"errno"`
+		Path  string `json:"path"`
+	}
+
+	// ManageResults 批量操作的结果
+	ManageResults []*ManageResult
+
+	manageResponse struct {
+		Response
+		Info   ManageResults `json:"info"`
+		TaskID int64         `json:"taskid"`
+	}
+)
+
+const (
+	// OndupFail 目标已存在时失败
+	OndupFail Ondup = "fail"
+	// OndupNewCopy 目标已存在时重命名
+	OndupNewCopy Ondup = "newcopy"
+	// OndupOverwrite 目标已存在时覆盖
+	OndupOverwrite Ondup = "overwrite"
+	// OndupSkip 目标已存在时跳过
+	OndupSkip Ondup = "skip"
+)
+
+// Failed 返回失败的文件
+func (mr ManageResults) Failed() ManageResults {
+	var failed ManageResults
+	for _, r := range mr {
+		if r.Errno != 0 {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// NewCopyMoveItem 由源路径和目标路径生成 CopyMoveItem
+func NewCopyMoveItem(from, to string) *CopyMoveItem {
+	return &CopyMoveItem{
+		Path:    from,
+		Dest:    path.Dir(to),
+		Newname: path.Base(to),
+	}
+}
+
+// Copy 批量复制文件/目录, ondup 为空时使用 fail
+func (c *Client) Copy(ctx context.Context, ondup Ondup, items ...*CopyMoveItem) (ManageResults, pcserror.Error) {
+	return c.manage(ctx, OperationCopy, "copy", ondup, items)
+}
+
+// Move 批量移动文件/目录, ondup 为空时使用 fail
+func (c *Client) Move(ctx context.Context, ondup Ondup, items ...*CopyMoveItem) (ManageResults, pcserror.Error) {
+	return c.manage(ctx, OperationMove, "move", ondup, items)
+}
+
+// Rename 批量重命名文件/目录
+func (c *Client) Rename(ctx context.Context, items ...*RenameItem) (ManageResults, pcserror.Error) {
+	return c.manage(ctx, OperationRename, "rename", "", items)
+}
+
+// Delete 批量删除文件/目录, 删除的文件进入回收站
+func (c *Client) Delete(ctx context.Context, paths ...string) (ManageResults, pcserror.Error) {
+	return c.manage(ctx, OperationDelete, "delete", "", paths)
+}
+
+// manage 执行文件管理操作, 同步等待完成, 部分失败时同时返回结果和错误
+func (c *Client) manage(ctx context.Context, op, opera string, ondup Ondup, filelist any) (ManageResults, pcserror.Error) {
+	data, err := json.Marshal(filelist)
+	if err != nil {
+		errInfo := NewErrorInfo(op)
+		errInfo.ErrType = pcserror.ErrTypeInternalError
+		errInfo.Err = err
+		return nil, errInfo
+	}
+	if ondup == "" {
+		ondup = OndupFail
+	}
+	post := map[string]string{
+		"async":    "0",
+		"filelist": string(data),
+		"ondup":    string(ondup),
+	}
+
+	resp := manageResponse{}
+	u := c.generateURL("rest/2.0/xpan/file", "filemanager", map[string]string{
+		"opera": opera,
+	})
+	pcsError := c.request(ctx, op, http.MethodPost, u, post, &resp)
+	return resp.Info, pcsError
+}
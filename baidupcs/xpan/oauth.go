@@ -0,0 +1,236 @@
+package xpan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+type (
+	// OAuth 开放平台授权, 使用设备码模式获取 access_token
+	OAuth struct {
+		ClientID     string // 应用的 AppKey
+		ClientSecret string // 应用的 SecretKey
+		Scope        string // 授权范围, 默认为 basic,netdisk
+
+		client *requester.HTTPClient
+		addr   string
+	}
+
+	// DeviceCode 设备码, 用户打开 VerificationURL 输入 UserCode 或扫描 QrcodeURL 完成授权
+	DeviceCode struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURL string `json:"verification_url"`
+		QrcodeURL       string `json:"qrcode_url"`
+		ExpiresIn       int64  `json:"expires_in"` // 有效期, 秒
+		Interval        int64  `json:"interval"`   // 轮询间隔, 秒
+	}
+
+	// Token 授权获得的令牌
+	Token struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"` // 有效期, 秒
+		Scope        string `json:"scope"`
+		ExpiresAt    int64  `json:"expires_at"` // 过期时间, unix 时间戳
+	}
+
+	// UserInfo 网盘用户信息
+	UserInfo struct {
+		BaiduName   string `json:"baidu_name"`
+		NetdiskName string `json:"netdisk_name"`
+		AvatarURL   string `json:"avatar_url"`
+		VipType     int    `json:"vip_type"`
+		UK          uint64 `json:"uk"`
+	}
+
+	oauthResponse struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	userInfoResponse struct {
+		Response
+		UserInfo
+	}
+)
+
+const (
+	// DefaultOAuthAddr 默认的授权地址
+	DefaultOAuthAddr = "openapi.baidu.com"
+	// DefaultScope 默认的授权范围
+	DefaultScope = "basic,netdisk"
+)
+
+var (
+	// ErrAuthorizationPending 用户尚未完成授权
+	ErrAuthorizationPending = errors.New("authorization pending")
+	// ErrSlowDown 轮询过于频繁
+	ErrSlowDown = errors.New("slow down")
+	// ErrDeviceCodeExpired 设备码已过期
+	ErrDeviceCodeExpired = errors.New("device code expired")
+	// ErrAccessDenied 用户拒绝授权
+	ErrAccessDenied = errors.New("access denied")
+)
+
+// NewOAuth 初始化 OAuth, client 为 nil 时使用新的 HTTPClient
+func NewOAuth(clientID, clientSecret string, client *requester.HTTPClient) *OAuth {
+	if client == nil {
+		client = requester.NewHTTPClient()
+	}
+	return &OAuth{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scope:        DefaultScope,
+		client:       client,
+		addr:         DefaultOAuthAddr,
+	}
+}
+
+// SetAddr 设置授权地址
+func (o *OAuth) SetAddr(addr string) {
+	o.addr = addr
+}
+
+// Expired 令牌是否将在 d 时间内过期, 未知过期时间时返回 false
+func (t *Token) Expired(d time.Duration) bool {
+	if t.ExpiresAt == 0 {
+		return false
+	}
+	return time.Now().Add(d).Unix() >= t.ExpiresAt
+}
+
+func (o *OAuth) fetch(ctx context.Context, subPath string, param map[string]string, data any) error {
+	query := url.Values{}
+	for k, v := range param {
+		query.Set(k, v)
+	}
+	u := &url.URL{
+		Scheme:   "https",
+		Host:     o.addr,
+		Path:     "/" + subPath,
+		RawQuery: query.Encode(),
+	}
+
+	body, err := o.client.FetchContext(ctx, http.MethodGet, u.String(), nil, map[string]string{
+		"User-Agent": UserAgent,
+	})
+	if err != nil {
+		return err
+	}
+
+	errResp := oauthResponse{}
+	if jsonhelper.UnmarshalData(bytes.NewReader(body), &errResp) == nil && errResp.Error != "" {
+		switch errResp.Error {
+		case "authorization_pending":
+			return ErrAuthorizationPending
+		case "slow_down":
+			return ErrSlowDown
+		case "expired_token":
+			return ErrDeviceCodeExpired
+		case "access_denied":
+			return ErrAccessDenied
+		}
+		return fmt.Errorf("%s: %s", errResp.Error, errResp.ErrorDescription)
+	}
+	return jsonhelper.UnmarshalData(bytes.NewReader(body), data)
+}
+
+// DeviceCode 获取设备码
+func (o *OAuth) DeviceCode(ctx context.Context) (*DeviceCode, error) {
+	dc := &DeviceCode{}
+	err := o.fetch(ctx, "oauth/2.0/device/code", map[string]string{
+		"response_type": "device_code",
+		"client_id":     o.ClientID,
+		"scope":         o.Scope,
+	}, dc)
+	if err != nil {
+		return nil, err
+	}
+	if dc.DeviceCode == "" {
+		return nil, errors.New("empty device code")
+	}
+	if dc.Interval <= 0 {
+		dc.Interval = 5
+	}
+	return dc, nil
+}
+
+// PollToken 使用设备码获取令牌, 用户尚未授权时返回 ErrAuthorizationPending
+func (o *OAuth) PollToken(ctx context.Context, deviceCode string) (*Token, error) {
+	return o.token(ctx, map[string]string{
+		"grant_type":    "device_token",
+		"code":          deviceCode,
+		"client_id":     o.ClientID,
+		"client_secret": o.ClientSecret,
+	})
+}
+
+// WaitToken 按设备码的轮询间隔等待用户授权, 直到获取令牌, 设备码过期或 ctx 取消
+func (o *OAuth) WaitToken(ctx context.Context, dc *DeviceCode) (*Token, error) {
+	interval := time.Duration(dc.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(dc.ExpiresIn) * time.Second)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		tok, err := o.PollToken(ctx, dc.DeviceCode)
+		switch {
+		case err == nil:
+			return tok, nil
+		case errors.Is(err, ErrSlowDown):
+			interval += 5 * time.Second
+		case !errors.Is(err, ErrAuthorizationPending):
+			return nil, err
+		}
+		if dc.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, ErrDeviceCodeExpired
+		}
+	}
+}
+
+// Refresh 使用 refresh_token 获取新的令牌, 旧的 refresh_token 随之失效
+func (o *OAuth) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	return o.token(ctx, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+		"client_id":     o.ClientID,
+		"client_secret": o.ClientSecret,
+	})
+}
+
+func (o *OAuth) token(ctx context.Context, param map[string]string) (*Token, error) {
+	tok := &Token{}
+	err := o.fetch(ctx, "oauth/2.0/token", param, tok)
+	if err != nil {
+		return nil, err
+	}
+	if tok.AccessToken == "" {
+		return nil, errors.New("empty access token")
+	}
+	if tok.ExpiresIn > 0 {
+		tok.ExpiresAt = time.Now().Unix() + tok.ExpiresIn
+	}
+	return tok, nil
+}
+
+// UserInfo 获取 access_token 对应的网盘用户信息
+func (c *Client) UserInfo(ctx context.Context) (*UserInfo, error) {
+	resp := userInfoResponse{}
+	pcsError := c.get(ctx, "xpan获取用户信息", c.generateURL("rest/2.0/xpan/nas", "uinfo", nil), &resp)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return &resp.UserInfo, nil
+}
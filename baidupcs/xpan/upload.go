@@ -0,0 +1,169 @@
+package xpan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/multipartreader"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/rio"
+)
+
+type (
+	// Rtype 上传时遇到同名文件的处理方式
+	Rtype int
+
+	// PrecreateResult 预上传的结果
+	PrecreateResult struct {
+		UploadID   string `json:"uploadid"`
+		ReturnType int    `json:"return_type"` // 为 ReturnTypeRapid 时网盘中已存在相同内容的文件, 无需上传
+		BlockList  []int  `json:"block_list"`  // 需要上传的分片序号
+	}
+
+	precreateResponse struct {
+		Response
+		PrecreateResult
+	}
+
+	// uploadTmpFileResponse 分片上传的响应, 使用 pcs 接口的错误格式
+	uploadTmpFileResponse struct {
+		ErrorCode int    `json:"error_code"`
+		ErrorMsg  string `json:"error_msg"`
+		MD5       string `json:"md5"`
+	}
+
+	createResponse struct {
+		Response
+		File
+		Name string `json:"name"`
+	}
+)
+
+const (
+	// RtypeFail 存在同名文件时返回错误
+	RtypeFail Rtype = 0
+	// RtypeRename 存在同名文件时重命名
+	RtypeRename Rtype = 1
+	// RtypeRenameIfDiff 存在同名且内容不同的文件时重命名
+	RtypeRenameIfDiff Rtype = 2
+	// RtypeOverwrite 存在同名文件时覆盖
+	RtypeOverwrite Rtype = 3
+
+	// ReturnTypeRapid 预上传时网盘中已存在相同内容的文件
+	ReturnTypeRapid = 2
+
+	// MinUploadBlockSize 普通用户的分片大小, 单文件最大 4GB
+	MinUploadBlockSize = 4 * converter.MB
+	// MiddleUploadBlockSize 会员的分片大小, 单文件最大 10GB
+	MiddleUploadBlockSize = 16 * converter.MB
+	// MaxUploadBlockSize 超级会员的分片大小, 单文件最大 20GB
+	MaxUploadBlockSize = 32 * converter.MB
+)
+
+var (
+	// ErrUploadMD5NotFound 分片上传未返回 md5
+	ErrUploadMD5NotFound = errors.New("unknown response data, md5 not found")
+)
+
+func (r *uploadTmpFileResponse) errInfo() *ErrorInfo {
+	return &ErrorInfo{
+		ErrNo:  r.ErrorCode,
+		ErrMsg: r.ErrorMsg,
+	}
+}
+
+// BlockSize 根据文件大小返回上传的分片大小, 分片数量不超过 1024
+func BlockSize(fileSize int64) int64 {
+	switch {
+	case fileSize <= 1024*MinUploadBlockSize:
+		return MinUploadBlockSize
+	case fileSize <= 1024*MiddleUploadBlockSize:
+		return MiddleUploadBlockSize
+	}
+	return MaxUploadBlockSize
+}
+
+// Precreate 预上传, blockList 为按 BlockSize 分片计算的 md5 列表
+func (c *Client) Precreate(ctx context.Context, targetPath string, size int64, blockList []string, rtype Rtype) (*PrecreateResult, pcserror.Error) {
+	data, err := json.Marshal(blockList)
+	if err != nil {
+		errInfo := NewErrorInfo(OperationPrecreate)
+		errInfo.ErrType = pcserror.ErrTypeInternalError
+		errInfo.Err = err
+		return nil, errInfo
+	}
+
+	resp := precreateResponse{}
+	pcsError := c.request(ctx, OperationPrecreate, http.MethodPost, c.generateURL("rest/2.0/xpan/file", "precreate", nil), map[string]string{
+		"path":       targetPath,
+		"size":       strconv.FormatInt(size, 10),
+		"isdir":      "0",
+		"autoinit":   "1",
+		"rtype":      strconv.Itoa(int(rtype)),
+		"block_list": string(data),
+	}, &resp)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return &resp.PrecreateResult, nil
+}
+
+// UploadTmpFile 上传分片, partseq 从 0 开始, 返回分片的 md5
+func (c *Client) UploadTmpFile(ctx context.Context, targetPath, uploadID string, partseq int, r rio.ReaderLen64) (md5 string, pcsError pcserror.Error) {
+	u := c.generateURL("rest/2.0/pcs/superfile2", "upload", map[string]string{
+		"type":     "tmpfile",
+		"path":     targetPath,
+		"uploadid": uploadID,
+		"partseq":  strconv.Itoa(partseq),
+	})
+	u.Host = c.pcsAddr
+
+	mr := multipartreader.NewMultipartReader()
+	mr.AddFormFile("file", "file", r)
+	mr.CloseMultipart()
+
+	resp := uploadTmpFileResponse{}
+	pcsError = c.request(ctx, OperationUploadTmpFile, http.MethodPost, u, mr, &resp)
+	if pcsError != nil {
+		return "", pcsError
+	}
+	if resp.MD5 == "" {
+		errInfo := NewErrorInfo(OperationUploadTmpFile)
+		errInfo.ErrType = pcserror.ErrTypeInternalError
+		errInfo.Err = ErrUploadMD5NotFound
+		return "", errInfo
+	}
+	return resp.MD5, nil
+}
+
+// Create 合并分片创建文件, blockList 为各分片上传后返回的 md5
+func (c *Client) Create(ctx context.Context, targetPath string, size int64, uploadID string, blockList []string, rtype Rtype) (*File, pcserror.Error) {
+	data, err := json.Marshal(blockList)
+	if err != nil {
+		errInfo := NewErrorInfo(OperationCreate)
+		errInfo.ErrType = pcserror.ErrTypeInternalError
+		errInfo.Err = err
+		return nil, errInfo
+	}
+
+	resp := createResponse{}
+	pcsError := c.request(ctx, OperationCreate, http.MethodPost, c.generateURL("rest/2.0/xpan/file", "create", nil), map[string]string{
+		"path":       targetPath,
+		"size":       strconv.FormatInt(size, 10),
+		"isdir":      "0",
+		"rtype":      strconv.Itoa(int(rtype)),
+		"uploadid":   uploadID,
+		"block_list": string(data),
+	}, &resp)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	if resp.ServerFilename == "" {
+		resp.ServerFilename = resp.Name
+	}
+	return &resp.File, nil
+}
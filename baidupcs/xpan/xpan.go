@@ -0,0 +1,179 @@
+// Package xpan 百度网盘开放平台 (xpan) 接口, 使用 access_token 鉴权
+package xpan
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+type (
+	// Client xpan 接口客户端
+	Client struct {
+		client      *requester.HTTPClient
+		accessToken string
+		addr        string
+		pcsAddr     string // 上传分片使用的 pcs 地址
+	}
+
+	// errnoGetter 获取响应中的 errno
+	errnoGetter interface {
+		errInfo() *ErrorInfo
+	}
+
+	// Response 响应中的公共字段
+	Response struct {
+		Errno     int    `json:"errno"`
+		ErrMsg    string `json:"errmsg"`
+		RequestID any    `json:"request_id"`
+	}
+)
+
+const (
+	// DefaultAddr 默认的接口地址
+	DefaultAddr = "pan.baidu.com"
+	// DefaultPCSAddr 默认的上传分片地址
+	DefaultPCSAddr = "d.pcs.baidu.com"
+	// UserAgent 开放平台要求的 User-Agent
+	UserAgent = "pan.baidu.com"
+
+	// OperationList 获取目录下的文件列表
+	OperationList = "xpan获取文件列表"
+	// OperationListAll 递归获取文件列表
+	OperationListAll = "xpan递归获取文件列表"
+	// OperationSearch 搜索文件
+	OperationSearch = "xpan搜索文件"
+	// OperationFileMetas 查询文件信息
+	OperationFileMetas = "xpan查询文件信息"
+	// OperationCategoryInfo 获取分类文件总个数
+	OperationCategoryInfo = "xpan获取分类文件总个数"
+	// OperationCategoryList 获取分类文件列表
+	OperationCategoryList = "xpan获取分类文件列表"
+	// OperationCopy 复制文件
+	OperationCopy = "xpan复制文件"
+	// OperationMove 移动文件
+	OperationMove = "xpan移动文件"
+	// OperationRename 重命名文件
+	OperationRename = "xpan重命名文件"
+	// OperationDelete 删除文件
+	OperationDelete = "xpan删除文件"
+	// OperationPrecreate 预上传
+	OperationPrecreate = "xpan预上传"
+	// OperationUploadTmpFile 分片上传
+	OperationUploadTmpFile = "xpan分片上传"
+	// OperationCreate 创建文件
+	OperationCreate = "xpan创建文件"
+)
+
+var (
+	xpanVerbose = pcsverbose.New("XPAN")
+)
+
+// NewClient 初始化 Client, client 为 nil 时使用新的 HTTPClient
+func NewClient(accessToken string, client *requester.HTTPClient) *Client {
+	if client == nil {
+		client = requester.NewHTTPClient()
+	}
+	return &Client{
+		client:      client,
+		accessToken: accessToken,
+		addr:        DefaultAddr,
+		pcsAddr:     DefaultPCSAddr,
+	}
+}
+
+// SetAddr 设置接口地址
+func (c *Client) SetAddr(addr string) {
+	c.addr = addr
+}
+
+// SetPCSAddr 设置上传分片使用的 pcs 地址
+func (c *Client) SetPCSAddr(pcsAddr string) {
+	c.pcsAddr = pcsAddr
+}
+
+// AccessToken 返回使用的 access_token
+func (c *Client) AccessToken() string {
+	return c.accessToken
+}
+
+func (r *Response) errInfo() *ErrorInfo {
+	return &ErrorInfo{
+		ErrNo:  r.Errno,
+		ErrMsg: r.ErrMsg,
+	}
+}
+
+// generateURL 生成接口地址, subPath 如 rest/2.0/xpan/file
+func (c *Client) generateURL(subPath, method string, param map[string]string) *url.URL {
+	query := url.Values{}
+	if method != "" {
+		query.Set("method", method)
+	}
+	query.Set("access_token", c.accessToken)
+	for k, v := range param {
+		query.Set(k, v)
+	}
+	return &url.URL{
+		Scheme:   "https",
+		Host:     c.addr,
+		Path:     "/" + subPath,
+		RawQuery: query.Encode(),
+	}
+}
+
+// request 发送请求, 解析 json 到 data, data 需包含 Response.
+// post 为 map[string]string 时以表单提交, 也可以是 io.Reader, 如 multipartreader
+func (c *Client) request(ctx context.Context, op, method string, u *url.URL, post any, data errnoGetter) pcserror.Error {
+	xpanVerbose.Infof("%s URL: %s\n", op, c.maskURL(u))
+
+	header := map[string]string{
+		"User-Agent": UserAgent,
+	}
+	if _, ok := post.(map[string]string); ok {
+		header["Content-Type"] = "application/x-www-form-urlencoded"
+	}
+	resp, err := c.client.FetchContext(ctx, method, u.String(), post, header)
+	if err != nil {
+		errInfo := NewErrorInfo(op)
+		errInfo.SetNetError(err)
+		return errInfo
+	}
+
+	err = jsonhelper.UnmarshalData(bytes.NewReader(resp), data)
+	if err != nil {
+		errInfo := NewErrorInfo(op)
+		errInfo.SetJSONError(err)
+		return errInfo
+	}
+
+	errInfo := data.errInfo()
+	if errInfo.ErrNo != 0 {
+		errInfo.Operation = op
+		errInfo.SetRemoteError()
+		return errInfo
+	}
+	return nil
+}
+
+// get 发送 GET 请求
+func (c *Client) get(ctx context.Context, op string, u *url.URL, data errnoGetter) pcserror.Error {
+	return c.request(ctx, op, http.MethodGet, u, nil, data)
+}
+
+// maskURL 输出日志时隐藏 access_token
+func (c *Client) maskURL(u *url.URL) string {
+	masked := *u
+	query := masked.Query()
+	if query.Has("access_token") {
+		query.Set("access_token", "***")
+	}
+	masked.RawQuery = query.Encode()
+	return masked.String()
+}
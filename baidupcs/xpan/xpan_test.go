@@ -0,0 +1,110 @@
+package xpan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	srv := httptest.NewTLSServer(handler)
+	t.Cleanup(srv.Close)
+	c := NewClient("token", nil)
+	c.SetAddr(strings.TrimPrefix(srv.URL, "https://"))
+	return c
+}
+
+func TestListPagination(t *testing.T) {
+	const total = 2500
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("access_token") != "token" || r.Header.Get("User-Agent") != UserAgent {
+			t.Errorf("bad request: %s", r.URL)
+		}
+		start, _ := strconv.Atoi(q.Get("start"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		list := []map[string]any{}
+		for i := start; i < min(start+limit, total); i++ {
+			list = append(list, map[string]any{"fs_id": i, "server_filename": fmt.Sprint(i), "isdir": 0})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"errno": 0, "list": list})
+	})
+
+	list, err := c.List(context.Background(), "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != total || list[total-1].FsID != total-1 {
+		t.Fatalf("got %d files", len(list))
+	}
+
+	list, err = c.List(context.Background(), "/", &ListOptions{Start: 10, Limit: 1500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1500 || list[0].FsID != 10 || list[0].Filename != "10" {
+		t.Fatalf("got %d files", len(list))
+	}
+}
+
+func TestListAllCursor(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		hasMore := start < 2
+		json.NewEncoder(w).Encode(map[string]any{
+			"errno":    0,
+			"has_more": map[bool]int{true: 1, false: 0}[hasMore],
+			"cursor":   start + 1,
+			"list":     []map[string]any{{"fs_id": start, "path": "/a"}},
+		})
+	})
+
+	list, err := c.ListAll(context.Background(), "/", &ListAllOptions{Recursion: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("got %d files", len(list))
+	}
+}
+
+func TestErrno(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"errno":-6,"request_id":123}`)
+	})
+
+	_, err := c.FileMetas(context.Background(), []uint64{1}, &MetaOptions{Dlink: true})
+	if err == nil || !IsAccessTokenInvalid(err) || err.GetRemoteErrCode() != ErrnoAccessDenied {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if IsNotExist(err) {
+		t.Fatal("not a not-exist error")
+	}
+}
+
+func TestManagePartialFailed(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Query().Get("opera") != "move" || r.PostFormValue("ondup") != string(OndupFail) {
+			t.Errorf("bad request: %s %s", r.Method, r.URL)
+		}
+		var items []*CopyMoveItem
+		json.Unmarshal([]byte(r.PostFormValue("filelist")), &items)
+		if len(items) != 1 || items[0].Dest != "/b" || items[0].Newname != "c" {
+			t.Errorf("bad filelist: %s", r.PostFormValue("filelist"))
+		}
+		fmt.Fprint(w, `{"errno":12,"info":[{"errno":-9,"path":"/a"}]}`)
+	})
+
+	results, err := c.Move(context.Background(), "", NewCopyMoveItem("/a", "/b/c"))
+	if err == nil || err.GetRemoteErrCode() != ErrnoPartialFailed {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if failed := results.Failed(); len(failed) != 1 || failed[0].Path != "/a" {
+		t.Fatalf("unexpected results: %v", results)
+	}
+}
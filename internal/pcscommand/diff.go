@@ -0,0 +1,92 @@
+package pcscommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcschanges"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+// RunDiff 列出网盘在上次运行之后的文件变更, cursor 按账号保存.
+// 指定 cursor 时从该位置开始, reset 时丢弃保存的 cursor
+func RunDiff(cursor string, reset bool) {
+	uid := pcsconfig.Config.ActiveUser().UID
+	if reset {
+		err := pcschanges.SaveCursor(uid, "")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	if cursor == "" {
+		saved, err := pcschanges.LoadCursor(uid)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if saved == "" {
+			fmt.Println("未记录 cursor, 正在获取网盘当前的 cursor...")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var (
+		pcs = GetBaiduPCS()
+		tb  = pcstable.NewTable(os.Stdout)
+		n   = 0
+	)
+	tb.SetHeader([]string{"#", "变更", "文件大小", "修改日期", "路径"})
+	appendList := func(action string, fds baidupcs.FileDirectoryList) {
+		for _, fd := range fds {
+			size, p := converter.ConvertFileSize(fd.Size, 2), fd.Path
+			if fd.Isdir {
+				size, p = "-", p+baidupcs.PathSeparator
+			}
+			if action == "删除" {
+				tb.Append([]string{strconv.Itoa(n), action, "-", "-", p})
+			} else {
+				tb.Append([]string{strconv.Itoa(n), action, size, pcstime.FormatTime(fd.Mtime), p})
+			}
+			n++
+		}
+	}
+
+	for {
+		diff, err := pcschanges.Fetch(ctx, pcs, uid, cursor)
+		if diff == nil {
+			fmt.Printf("获取文件变更失败: %s\n", err)
+			break
+		}
+		if err != nil {
+			fmt.Printf("保存 cursor 失败: %s\n", err)
+		}
+		if diff.Reset {
+			fmt.Println("服务器要求重新列出全部文件, 之前的变更已不可用")
+		}
+		appendList("新增", diff.Added)
+		appendList("修改", diff.Modified)
+		appendList("删除", diff.Deleted)
+
+		cursor = diff.Cursor
+		if !diff.HasMore || cursor == "" {
+			break
+		}
+	}
+
+	if n > 0 {
+		tb.Render()
+		fmt.Println()
+	}
+	fmt.Printf("共 %d 个变更, cursor: %s\n", n, cursor)
+}
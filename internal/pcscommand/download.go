@@ -5,6 +5,7 @@ import (
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
@@ -161,10 +162,10 @@ func RunDownload(paths []string, options *DownloadOptions) {
 		}
 		// 设置下载并发数
 		executor.SetParallel(loadCount)
-		// 设置储存的路径
-		vPath := v.Path
+		// 设置储存的路径, 加密目录中的文件保存为解密后的文件名
+		vPath := pcscrypt.PlainPath(v.Path)
 		if !options.FullPath {
-			vPath = filepath.Join(v.PreBase, filepath.Base(v.Path))
+			vPath = filepath.Join(pcscrypt.PlainName(v.PreBase), filepath.Base(vPath))
 		}
 		if options.SaveTo != "" {
 			unit.SavePath = filepath.Join(options.SaveTo, vPath)
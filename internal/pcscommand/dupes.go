@@ -0,0 +1,68 @@
+package pcscommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdupes"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+)
+
+// RunDupes 查找重复文件并输出报告, remove 为 true 时将多余的副本移入回收站
+func RunDupes(opt pcsdupes.Options, remove bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	au := pcsconfig.Config.ActiveUser()
+	opt.Path = au.PathJoin(opt.Path)
+	if opt.Prefer != "" {
+		opt.Prefer = au.PathJoin(opt.Prefer)
+	}
+
+	if !opt.UseIndex {
+		fmt.Printf("正在列出 %s 中的文件, 文件较多时需要较长时间...\n", opt.Path)
+	}
+	result, err := pcsdupes.Find(ctx, GetBaiduPCS(), au.UID, opt, func(scanned int) {
+		fmt.Printf("\r已扫描 %d 个文件", scanned)
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("查找重复文件失败: %s\n", err)
+		return
+	}
+	if len(result.Groups) == 0 {
+		fmt.Printf("扫描了 %d 个文件, 没有重复的文件\n", result.Scanned)
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "md5", "文件大小", "副本数", "可节省", "操作", "路径"})
+	for i, g := range result.Groups {
+		tb.Append([]string{strconv.Itoa(i), g.MD5, converter.ConvertFileSize(g.Size, 2), strconv.Itoa(len(g.Files)), converter.ConvertFileSize(g.Wasted, 2), "保留", g.Keep})
+		for _, p := range g.Remove {
+			tb.Append([]string{"", "", "", "", "", "删除", p})
+		}
+	}
+	tb.Render()
+	for i, g := range result.Groups {
+		if g.Note != "" {
+			fmt.Printf("[%d] %s\n", i, g.Note)
+		}
+	}
+	fmt.Printf("\n扫描了 %d 个文件, %d 组重复, 可删除 %d 个副本, 节省 %s\n", result.Scanned, len(result.Groups), result.DupFiles, converter.ConvertFileSize(result.Wasted, 2))
+
+	if !remove {
+		fmt.Println("以上为预览, 指定 -delete 将多余的副本移入回收站")
+		return
+	}
+	err = pcsdupes.Apply(ctx, GetBaiduPCS(), result)
+	fmt.Printf("已将 %d 个副本移入回收站\n", result.Removed)
+	if err != nil {
+		fmt.Printf("%d 个副本移入回收站失败: %s\n", len(result.Failed), err)
+	}
+}
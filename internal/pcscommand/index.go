@@ -0,0 +1,116 @@
+package pcscommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsindex"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+// RunIndexBuild 列出整个网盘, 建立当前账号的索引
+func RunIndexBuild() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Println("正在列出网盘中的全部文件, 文件较多时需要较长时间...")
+	info, err := pcsindex.Build(ctx, GetBaiduPCS(), GetActiveUser().UID, func(scanned int) {
+		fmt.Printf("\r已列出 %d 项", scanned)
+	})
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("建立索引失败: %s\n", err)
+		return
+	}
+	printIndexInfo(info)
+}
+
+// RunIndexRefresh 增量更新当前账号的索引
+func RunIndexRefresh() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := pcsindex.Refresh(ctx, GetBaiduPCS(), GetActiveUser().UID)
+	if err != nil {
+		fmt.Printf("更新索引失败: %s\n", err)
+		return
+	}
+	if result.Rebuilt {
+		fmt.Println("服务器要求重新列出全部文件, 已重新建立索引")
+	} else {
+		fmt.Printf("新增: %d, 修改: %d, 删除: %d\n", result.Added, result.Modified, result.Deleted)
+	}
+	printIndexInfo(&result.Info)
+}
+
+// RunIndexInfo 输出当前账号的索引概况
+func RunIndexInfo() {
+	idx, err := pcsindex.Load(GetActiveUser().UID)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	printIndexInfo(&idx.Info)
+}
+
+// RunIndexQuery 在当前账号的索引中查询, 不请求网盘
+func RunIndexQuery(q *pcsindex.Query, refresh bool) {
+	if q.Prefix != "" {
+		q.Prefix = pcsconfig.Config.ActiveUser().PathJoin(q.Prefix)
+	}
+	if refresh {
+		RunIndexRefresh()
+	}
+
+	result, err := pcsindex.Search(GetActiveUser().UID, q)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "文件大小", "修改日期", "md5", "路径"})
+	var total int64
+	for i, e := range result.Entries {
+		size := "-"
+		if !e.IsDir {
+			size = converter.ConvertFileSize(e.Size, 2)
+			total += e.Size
+		}
+		tb.Append([]string{strconv.Itoa(q.Offset + i), size, pcstime.FormatTime(e.Mtime), e.MD5, e.Path})
+	}
+	tb.Render()
+	fmt.Printf("\n共 %d 项, 显示 %d 项, 总大小: %s\n", result.Total, len(result.Entries), converter.ConvertFileSize(total, 2))
+}
+
+// ParseIndexTime 解析查询条件中的时间, 支持 unix 时间戳和东八区的日期
+func ParseIndexTime(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	for _, layout := range []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		t, err := time.ParseInLocation(layout, s, pcstime.CSTLocation)
+		if err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return 0, fmt.Errorf("无法解析时间: %s, 格式为 2006-01-02 或 2006-01-02 15:04:05", s)
+}
+
+// printIndexInfo 输出索引概况
+func printIndexInfo(info *pcsindex.Info) {
+	fmt.Printf("文件: %d, 目录: %d, 总大小: %s\n", info.Files, info.Dirs, converter.ConvertFileSize(info.Size, 2))
+	fmt.Printf("建立时间: %s, 更新时间: %s\n", formatScheduleTime(info.BuiltAt), formatScheduleTime(info.UpdatedAt))
+	fmt.Printf("索引文件位于: %s\n", filepath.Join(pcsconfig.GetConfigDir(), pcsindex.IndexDirName))
+}
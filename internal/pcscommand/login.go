@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	baidulogin "github.com/qjfoidnh/Baidu-Login"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscaptcha"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsliner"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/cookiefile"
 	"image/png"
 	"io/ioutil"
+	"os"
 	"strings"
 )
 
@@ -168,3 +171,17 @@ for_1:
 	}
 	return
 }
+
+// RunLoginByCookieFile 使用浏览器导出的 cookies 文件登录百度帐号,
+// 支持 Netscape cookies.txt, 浏览器扩展导出的 JSON 和 HAR
+func RunLoginByCookieFile(filename string) (baidu *pcsconfig.Baidu, err error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cookies, err := cookiefile.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析 cookies 文件失败: %s", err)
+	}
+	return pcsconfig.Config.SetupUserByCookies(cookies)
+}
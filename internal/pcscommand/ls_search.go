@@ -3,6 +3,7 @@ package pcscommand
 import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
@@ -43,13 +44,13 @@ func RunLs(pcspath string, lsOptions *LsOptions, orderOptions *baidupcs.OrderOpt
 		return
 	}
 
-	fmt.Printf("\n当前目录: %s\n----\n", pcspath)
+	fmt.Printf("\n当前目录: %s\n----\n", pcscrypt.PlainPath(pcspath))
 
 	if lsOptions == nil {
 		lsOptions = &LsOptions{}
 	}
 
-	renderTable(opLs, lsOptions.Total, pcspath, files)
+	renderTable(opLs, lsOptions.Total, pcspath, pcscrypt.DecryptList(files))
 	return
 }
 
@@ -71,7 +72,7 @@ func RunSearch(targetPath, keyword string, opt *SearchOptions) {
 		return
 	}
 
-	renderTable(opSearch, opt.Total, targetPath, files)
+	renderTable(opSearch, opt.Total, targetPath, pcscrypt.DecryptList(files))
 	return
 }
 
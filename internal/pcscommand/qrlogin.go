@@ -66,6 +66,7 @@ func (s QRCodeStatus) String() string {
 type QRCodeInfo struct {
 	Sign     string    `json:"sign"`      // 二维码标识符
 	ImgURL   string    `json:"img_url"`   // 二维码图片URL
+	Content  string    `json:"content"`   // 二维码内容, 用于本地渲染
 	ASCIIQA  string    `json:"ascii_qr"`  // ASCII格式二维码
 	ExpireAt time.Time `json:"expire_at"` // 过期时间
 }
@@ -149,20 +150,17 @@ func (c *QRLoginClient) GetQRCode() (*QRCodeInfo, error) {
 	}
 
 	// 解码图片获取真实二维码内容，用于生成可扫描的ASCII二维码
-	var asciiQR string
 	qrContent, err := decodeQRCodeFromURL(imgURL)
-	if err == nil && qrContent != "" {
-		// 使用真实内容生成ASCII二维码
-		asciiQR = generateASCIIQRCode(qrContent)
-	} else {
-		// 解码失败时，使用URL生成（备用方案，扫描后会跳转）
-		asciiQR = generateASCIIQRCode(imgURL)
+	if err != nil || qrContent == "" {
+		// 解码失败时，按 sign 构造二维码内容
+		qrContent = QRCodeContent(qrResp.Sign)
 	}
 
 	return &QRCodeInfo{
 		Sign:     qrResp.Sign,
 		ImgURL:   imgURL,
-		ASCIIQA:  asciiQR,
+		Content:  qrContent,
+		ASCIIQA:  generateASCIIQRCode(qrContent),
 		ExpireAt: time.Now().Add(DefaultQRCodeTimeout),
 	}, nil
 }
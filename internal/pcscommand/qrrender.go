@@ -0,0 +1,95 @@
+package pcscommand
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"time"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+const (
+	// QRCodeContentURL 扫码登录二维码的内容, 解码百度返回的图片失败时按 sign 构造
+	QRCodeContentURL = "https://wappass.baidu.com/wp/?qrlogin&t=%d&error=0&sign=%s&cmd=login&lp=pc&tpl=netdisk&adapter=3&qrloginfrom=pc"
+	// qrCodeQuietZone 二维码四周留白的模块数
+	qrCodeQuietZone = 2
+)
+
+// QRCodeContent 根据 sign 构造二维码内容
+func QRCodeContent(sign string) string {
+	return fmt.Sprintf(QRCodeContentURL, time.Now().Unix(), sign)
+}
+
+// encodeQRCode 编码二维码, 返回的矩阵每个模块占一个点, 已包含留白
+func encodeQRCode(content string) (*gozxing.BitMatrix, error) {
+	return qrcode.NewQRCodeWriter().Encode(content, gozxing.BarcodeFormat_QR_CODE, 0, 0, map[gozxing.EncodeHintType]interface{}{
+		gozxing.EncodeHintType_MARGIN: qrCodeQuietZone,
+	})
+}
+
+// qrCodeScale 计算每个模块的像素数, 使图片边长不小于 size
+func qrCodeScale(width, size int) int {
+	scale := 1
+	if width > 0 && size > width {
+		scale = (size + width - 1) / width
+	}
+	return scale
+}
+
+// RenderQRCodePNG 将二维码内容渲染为 PNG 图片, size 为图片的最小边长
+func RenderQRCodePNG(w io.Writer, content string, size int) error {
+	matrix, err := encodeQRCode(content)
+	if err != nil {
+		return fmt.Errorf("生成二维码失败: %v", err)
+	}
+
+	width := matrix.GetWidth()
+	scale := qrCodeScale(width, size)
+	img := image.NewPaletted(image.Rect(0, 0, width*scale, width*scale), color.Palette{color.White, color.Black})
+	for y := 0; y < width; y++ {
+		for x := 0; x < width; x++ {
+			if !matrix.Get(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex(x*scale+dx, y*scale+dy, 1)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// RenderQRCodeSVG 将二维码内容渲染为 SVG 图片, size 为图片的最小边长
+func RenderQRCodeSVG(w io.Writer, content string, size int) error {
+	matrix, err := encodeQRCode(content)
+	if err != nil {
+		return fmt.Errorf("生成二维码失败: %v", err)
+	}
+
+	width := matrix.GetWidth()
+	pixels := width * qrCodeScale(width, size)
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, pixels, pixels, width, width)
+	fmt.Fprintf(w, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, width, width)
+	for y := 0; y < width; y++ {
+		// 合并同一行中连续的黑色模块
+		for x := 0; x < width; {
+			if !matrix.Get(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < width && matrix.Get(x, y) {
+				x++
+			}
+			fmt.Fprintf(w, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	_, err = io.WriteString(w, `"/></svg>`)
+	return err
+}
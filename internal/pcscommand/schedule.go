@@ -0,0 +1,158 @@
+package pcscommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsschedule"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+// RunScheduleList 列出定时任务
+func RunScheduleList() {
+	list, err := pcsschedule.Default.List()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(list) == 0 {
+		fmt.Println("没有定时任务")
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"id", "名称", "类型", "cron", "状态", "执行次数", "上次执行", "结果", "下次执行"})
+	for _, s := range list {
+		status := "启用"
+		if s.Disabled {
+			status = "暂停"
+		}
+		tb.Append([]string{s.ID, s.Name, string(s.Kind), s.Cron, status, strconv.Itoa(s.Runs), formatScheduleTime(s.LastRunAt), formatRunResult(s.LastResult), formatScheduleTime(s.NextRunAt)})
+	}
+	tb.Render()
+	fmt.Println("\n定时任务由 server 执行, 修改后 server 会自动重新读取")
+}
+
+// RunScheduleAdd 添加定时任务, 网盘路径相对于当前工作目录
+func RunScheduleAdd(spec pcsschedule.Spec) {
+	au := pcsconfig.Config.ActiveUser()
+	for i, p := range spec.Options.Paths {
+		spec.Options.Paths[i] = au.PathJoin(p)
+	}
+	if spec.Options.RemoteDir != "" {
+		spec.Options.RemoteDir = au.PathJoin(spec.Options.RemoteDir)
+	}
+
+	s, err := pcsschedule.Default.Create(spec)
+	if s == nil {
+		fmt.Printf("添加定时任务失败: %s\n", err)
+		return
+	}
+	if err != nil {
+		fmt.Printf("保存定时任务失败: %s\n", err)
+		return
+	}
+	fmt.Printf("已添加定时任务 %s, 下次执行: %s\n", s.ID, formatScheduleTime(s.NextRunAt))
+}
+
+// RunScheduleDelete 删除定时任务
+func RunScheduleDelete(ids ...string) {
+	for _, id := range ids {
+		err := pcsschedule.Default.Delete(id)
+		if err != nil {
+			fmt.Printf("删除定时任务 %s 失败: %s\n", id, err)
+			continue
+		}
+		fmt.Printf("已删除定时任务 %s\n", id)
+	}
+}
+
+// RunScheduleEnable 启用或暂停定时任务
+func RunScheduleEnable(enable bool, ids ...string) {
+	for _, id := range ids {
+		s, err := pcsschedule.Default.Get(id)
+		if err != nil {
+			fmt.Printf("%s: %s\n", id, err)
+			continue
+		}
+		s.Disabled = !enable
+		s, err = pcsschedule.Default.Update(id, s.Spec)
+		if err != nil {
+			fmt.Printf("修改定时任务 %s 失败: %s\n", id, err)
+			continue
+		}
+		if enable {
+			fmt.Printf("已启用定时任务 %s, 下次执行: %s\n", id, formatScheduleTime(s.NextRunAt))
+		} else {
+			fmt.Printf("已暂停定时任务 %s\n", id)
+		}
+	}
+}
+
+// RunScheduleRun 在当前进程中立即执行一次定时任务
+func RunScheduleRun(id string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := pcsschedule.Default.RunNow(ctx, GetBaiduPCS(), id)
+	if result == nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(formatRunResult(result))
+	if result.Message != "" {
+		fmt.Println(result.Message)
+	}
+	if err != nil {
+		fmt.Printf("保存执行记录失败: %s\n", err)
+	}
+}
+
+// RunScheduleHistory 输出定时任务的执行记录
+func RunScheduleHistory(id string) {
+	s, err := pcsschedule.Default.Get(id)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(s.History) == 0 {
+		fmt.Println("没有执行记录")
+		return
+	}
+
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "开始时间", "耗时", "方式", "结果", "说明"})
+	for i, r := range s.History {
+		trigger := "定时"
+		if r.Manual {
+			trigger = "手动"
+		}
+		tb.Append([]string{strconv.Itoa(i), formatScheduleTime(r.StartAt), fmt.Sprintf("%ds", r.EndAt-r.StartAt), trigger, formatRunResult(r), r.Message})
+	}
+	tb.Render()
+}
+
+// formatScheduleTime 格式化时间戳, 为0时输出 -
+func formatScheduleTime(t int64) string {
+	if t == 0 {
+		return "-"
+	}
+	return pcstime.FormatTime(t)
+}
+
+// formatRunResult 格式化执行结果
+func formatRunResult(r *pcsschedule.RunResult) string {
+	switch {
+	case r == nil:
+		return "-"
+	case r.Success:
+		return "成功"
+	default:
+		return "失败: " + r.Err
+	}
+}
@@ -0,0 +1,69 @@
+package pcscommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcssync"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+)
+
+// RunSync 执行同步, 中断时保存已完成部分的同步状态
+func RunSync(opt pcssync.Options) {
+	opt.RemoteDir = pcsconfig.Config.ActiveUser().PathJoin(opt.RemoteDir)
+	syncer, err := pcssync.NewSyncer(GetBaiduPCS(), opt)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("正在比较 %s 和 %s 的文件...\n", syncer.Options.LocalDir, syncer.Options.RemoteDir)
+	result, err := syncer.Run(ctx)
+	if err != nil && result == nil {
+		fmt.Printf("同步失败: %s\n", err)
+		return
+	}
+
+	printSyncResult(result)
+	if err != nil {
+		fmt.Printf("保存同步状态失败: %s\n", err)
+	}
+}
+
+// printSyncResult 输出同步结果, 跳过的文件只统计数量
+func printSyncResult(result *pcssync.Result) {
+	tb := pcstable.NewTable(os.Stdout)
+	tb.SetHeader([]string{"#", "操作", "文件大小", "路径", "说明"})
+	n := 0
+	for _, c := range result.Changes {
+		if c.Action == pcssync.ActionSkip && c.Err == "" {
+			continue
+		}
+		note := c.Reason
+		if c.Err != "" {
+			note = "失败: " + c.Err
+		}
+		action := string(c.Action)
+		if c.Side != "" {
+			action += " " + string(c.Side)
+		}
+		tb.Append([]string{fmt.Sprint(n), action, converter.ConvertFileSize(c.Size, 2), c.Path, note})
+		n++
+	}
+	if n > 0 {
+		tb.Render()
+		fmt.Println()
+	}
+
+	if result.DryRun {
+		fmt.Printf("试运行, 未修改任何文件. ")
+	}
+	fmt.Printf("新增: %d, 更新: %d, 删除: %d, 跳过: %d, 冲突: %d, 失败: %d\n", result.Added, result.Updated, result.Deleted, result.Skipped, result.Conflicts, result.Failed)
+}
@@ -31,6 +31,7 @@ type (
 		NoSplitFile     bool   // 禁用分片上传
 		Policy          string // 同名文件处理策略
 		NoFilenameCheck bool   // 禁用文件名合法性检查
+		API             string // 上传使用的接口, pcs 或 xpan, 为空时使用配置
 	}
 )
 
@@ -66,6 +67,11 @@ func RunUpload(localPaths []string, savePath string, opt *UploadOptions) {
 		opt.Policy = pcsconfig.Config.UPolicy
 	}
 
+	if opt.API != "" && opt.API != pcsconfig.UploadAPIPCS && opt.API != pcsconfig.UploadAPIXpan {
+		fmt.Printf("未知的上传接口: %s, 可选 %s, %s\n", opt.API, pcsconfig.UploadAPIPCS, pcsconfig.UploadAPIXpan)
+		return
+	}
+
 	err := matchPathByShellPatternOnce(&savePath)
 	if err != nil {
 		fmt.Printf("警告: 上传文件, 获取网盘路径 %s 错误, %s\n", savePath, err)
@@ -143,6 +149,7 @@ func RunUpload(localPaths []string, savePath string, opt *UploadOptions) {
 				NoSplitFile:       opt.NoSplitFile,
 				UploadStatistic:   statistic,
 				Policy:            opt.Policy,
+				API:               opt.API,
 			}, opt.MaxRetry)
 			if LoadCount >= opt.Load {
 				LoadCount = opt.Load
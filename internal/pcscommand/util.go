@@ -6,6 +6,8 @@ import (
 	"math/rand"
 	"path"
 	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
 )
 
 const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -43,7 +45,7 @@ func RunTestShellPattern(pattern string) {
 }
 
 func matchPathByShellPatternOnce(pattern *string) error {
-	paths, err := GetBaiduPCS().MatchPathByShellPattern(GetActiveUser().PathJoin(*pattern))
+	paths, err := GetBaiduPCS().MatchPathByShellPattern(pcscrypt.RemotePath(GetActiveUser().PathJoin(*pattern)))
 	if err != nil {
 		return err
 	}
@@ -62,7 +64,7 @@ func matchPathByShellPatternOnce(pattern *string) error {
 func matchPathByShellPattern(patterns ...string) (pcspaths []string, err error) {
 	acUser, pcs := GetActiveUser(), GetBaiduPCS()
 	for k := range patterns {
-		ps, err := pcs.MatchPathByShellPattern(acUser.PathJoin(patterns[k]))
+		ps, err := pcs.MatchPathByShellPattern(pcscrypt.RemotePath(acUser.PathJoin(patterns[k])))
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,25 @@
+package pcscommand
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcswatch"
+)
+
+// RunWatch 监听本地目录并自动上传, 直到中断
+func RunWatch(opt pcswatch.LocalOptions) {
+	opt.RemoteDir = pcsconfig.Config.ActiveUser().PathJoin(opt.RemoteDir)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Printf("正在监听 %s, 文件将上传到 %s, 按 Ctrl+C 退出\n", opt.LocalDir, opt.RemoteDir)
+	err := pcswatch.Watch(ctx, GetBaiduPCS(), opt)
+	if err != nil {
+		fmt.Printf("监听失败: %s\n", err)
+	}
+}
@@ -0,0 +1,55 @@
+package pcscommand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+// RunXpanLogin 使用设备码为当前帐号授权开放平台, 保存 accesstoken 和 refresh_token
+func RunXpanLogin() error {
+	activeUser := pcsconfig.Config.ActiveUser()
+	if activeUser.UID == 0 {
+		return errors.New("请先登录百度帐号")
+	}
+	oauth, err := pcsconfig.Config.XpanOAuth()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	dc, err := oauth.DeviceCode(ctx)
+	if err != nil {
+		return fmt.Errorf("获取设备码失败: %s", err)
+	}
+	fmt.Printf("请在浏览器中打开 %s, 输入用户码: %s\n", dc.VerificationURL, dc.UserCode)
+	if dc.QrcodeURL != "" {
+		fmt.Printf("或使用百度网盘 App 扫描二维码: %s\n", dc.QrcodeURL)
+	}
+	fmt.Printf("等待授权, %d 秒内有效, 按 Ctrl+C 取消...\n", dc.ExpiresIn)
+
+	tok, err := oauth.WaitToken(ctx, dc)
+	if err != nil {
+		return fmt.Errorf("授权失败: %s", err)
+	}
+	activeUser.SetXpanToken(tok)
+
+	info, err := xpan.NewClient(tok.AccessToken, pcsconfig.Config.HTTPClient()).UserInfo(ctx)
+	if err == nil {
+		fmt.Printf("授权成功, 网盘用户名: %s\n", info.NetdiskName)
+	} else {
+		fmt.Printf("授权成功, 获取网盘用户信息失败: %s\n", err)
+	}
+	if tok.ExpiresAt > 0 {
+		fmt.Printf("accessToken 有效期至 %s, 过期前将自动刷新\n", pcstime.FormatTime(tok.ExpiresAt))
+	}
+	return nil
+}
@@ -41,9 +41,15 @@ type Baidu struct {
 	SBOXTKN string `json:"sboxtkn"`
 	COOKIES string `json:"cookies"`
 
-	AccessToken string `json:"accesstoken"`
+	AccessToken      string `json:"accesstoken"`
+	XpanRefreshToken string `json:"xpan_refresh_token"` // xpan 授权登录获得的 refresh_token
+	XpanTokenExpires int64  `json:"xpan_token_expires"` // accesstoken 的过期时间, unix 时间戳, 0 为未知
 
 	Workdir string `json:"workdir"` // 工作目录
+
+	CredentialStatus    CredentialStatus `json:"credential_status,omitempty"`     // 凭证状态, 由定期检查更新
+	CredentialMessage   string           `json:"credential_message,omitempty"`    // 凭证失效的原因
+	CredentialCheckedAt int64            `json:"credential_checked_at,omitempty"` // 上次检查凭证的时间, unix 时间戳
 }
 
 // BaiduPCS 初始化*baidupcs.BaiduPCS
@@ -60,6 +66,8 @@ func (baidu *Baidu) BaiduPCS() *baidupcs.BaiduPCS {
 	pcs.SetHTTPS(Config.EnableHTTPS)
 	pcs.SetPCSUserAgent(Config.PCSUA)
 	pcs.SetPanUserAgent(Config.PanUA)
+	pcs.SetPCSAddr(Config.PCSAddr)
+	pcs.SetStaticPCSAddr(Config.FixPCSAddr)
 	pcs.SetUID(baidu.UID)
 	pcs.SetaccessToken(baidu.AccessToken)
 	return pcs
@@ -127,11 +135,15 @@ func (bl *BaiduUserList) String() string {
 	builder := &strings.Builder{}
 
 	tb := pcstable.NewTable(builder)
-	tb.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
-	tb.SetHeader([]string{"#", "uid", "用户名", "性别", "age"})
+	tb.SetColumnAlignment([]int{tablewriter.ALIGN_DEFAULT, tablewriter.ALIGN_RIGHT, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER, tablewriter.ALIGN_CENTER})
+	tb.SetHeader([]string{"#", "uid", "用户名", "性别", "age", "凭证"})
 
 	for k, baiduInfo := range *bl {
-		tb.Append([]string{strconv.Itoa(k), strconv.FormatUint(baiduInfo.UID, 10), baiduInfo.Name, baiduInfo.Sex, fmt.Sprint(baiduInfo.Age)})
+		status := string(baiduInfo.Credential().Status)
+		if status == "" {
+			status = "-"
+		}
+		tb.Append([]string{strconv.Itoa(k), strconv.FormatUint(baiduInfo.UID, 10), baiduInfo.Name, baiduInfo.Sex, fmt.Sprint(baiduInfo.Age), status})
 	}
 
 	tb.Render()
@@ -0,0 +1,139 @@
+package pcsconfig
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+// CredentialStatus 帐号凭证的状态
+type CredentialStatus string
+
+const (
+	// CredentialUnknown 尚未检查
+	CredentialUnknown CredentialStatus = ""
+	// CredentialValid 凭证有效
+	CredentialValid CredentialStatus = "valid"
+	// CredentialExpired BDUSS 已失效, 需要重新登录
+	CredentialExpired CredentialStatus = "expired"
+	// CredentialNeedStoken BDUSS 有效, 但缺少 STOKEN, 无法使用分享和转存功能
+	CredentialNeedStoken CredentialStatus = "need_stoken"
+)
+
+var (
+	credentialMu sync.Mutex
+
+	// credentialExpiredErrnos 表示登录状态已失效的错误码, 其他错误无法判断凭证状态
+	credentialExpiredErrnos = map[int]bool{
+		-4:    true, // 登录信息有误
+		-6:    true, // 请重新登录
+		3:     true, // 未登录或帐号无效
+		31045: true, // user not exists
+	}
+)
+
+// CredentialState 帐号凭证的检查结果
+type CredentialState struct {
+	Status    CredentialStatus `json:"status"`
+	Message   string           `json:"message,omitempty"`    // 失效原因
+	CheckedAt int64            `json:"checked_at,omitempty"` // 检查时间, unix 时间戳
+}
+
+// Bad 凭证是否需要用户处理
+func (s CredentialStatus) Bad() bool {
+	return s == CredentialExpired || s == CredentialNeedStoken
+}
+
+// Credential 返回帐号凭证的检查结果
+func (baidu *Baidu) Credential() CredentialState {
+	credentialMu.Lock()
+	defer credentialMu.Unlock()
+	return CredentialState{
+		Status:    baidu.CredentialStatus,
+		Message:   baidu.CredentialMessage,
+		CheckedAt: baidu.CredentialCheckedAt,
+	}
+}
+
+// CheckCredential 通过获取 UK 检查 BDUSS 是否有效, 并检查是否设置了 STOKEN.
+// 只有表示登录失效的错误码才认为凭证失效, 网络错误等无法判断凭证状态时返回错误, 不修改状态
+func (baidu *Baidu) CheckCredential(ctx context.Context) (CredentialStatus, error) {
+	status, message := CredentialValid, ""
+	_, pcsError := baidu.BaiduPCS().UKContext(ctx)
+	switch {
+	case pcsError == nil:
+		if baidu.STOKEN == "" && !strings.Contains(baidu.COOKIES, "STOKEN=") {
+			status, message = CredentialNeedStoken, "未设置 STOKEN, 无法使用分享和转存功能"
+		}
+	case pcsError.GetErrType() == pcserror.ErrTypeRemoteError && credentialExpiredErrnos[pcsError.GetRemoteErrCode()]:
+		status, message = CredentialExpired, pcsError.Error()
+	default:
+		return CredentialUnknown, pcsError
+	}
+
+	credentialMu.Lock()
+	baidu.CredentialStatus = status
+	baidu.CredentialMessage = message
+	baidu.CredentialCheckedAt = time.Now().Unix()
+	credentialMu.Unlock()
+	return status, nil
+}
+
+// CheckCredentials 检查所有帐号的凭证, 返回状态变为失效的帐号, 以及是否有帐号的状态发生变化
+func (c *PCSConfig) CheckCredentials(ctx context.Context) (bad []*Baidu, changed bool, err error) {
+	for _, baidu := range c.Users() {
+		if ctx.Err() != nil {
+			return bad, changed, ctx.Err()
+		}
+		old := baidu.Credential().Status
+		status, cerr := baidu.CheckCredential(ctx)
+		if cerr != nil {
+			err = cerr
+			continue
+		}
+		if status == old {
+			continue
+		}
+		changed = true
+		if status.Bad() {
+			bad = append(bad, baidu)
+		}
+	}
+	return bad, changed, err
+}
+
+// StartCredentialChecker 在后台定期检查所有帐号的凭证, 直到 ctx 取消.
+// 帐号凭证变为失效时调用 onBad, 状态发生变化时保存配置
+func (c *PCSConfig) StartCredentialChecker(ctx context.Context, interval time.Duration, onBad func(baidu *Baidu)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			bad, changed, err := c.CheckCredentials(ctx)
+			if err != nil && ctx.Err() == nil {
+				pcsConfigVerbose.Warnf("check credentials: %s\n", err)
+			}
+			for _, baidu := range bad {
+				state := baidu.Credential()
+				pcsConfigVerbose.Warnf("credential of %s (uid: %d) is %s: %s\n", baidu.Name, baidu.UID, state.Status, state.Message)
+				if onBad != nil {
+					onBad(baidu)
+				}
+			}
+			if changed {
+				if err = c.Save(); err != nil {
+					pcsConfigVerbose.Warnf("save config: %s\n", err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
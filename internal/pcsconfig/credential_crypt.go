@@ -0,0 +1,209 @@
+package pcsconfig
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/json-iterator/go"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
+)
+
+const (
+	// EnvCredentialKey 凭证加密密钥的环境变量
+	EnvCredentialKey = "BAIDUPCS_GO_CREDENTIAL_KEY"
+	// EnvCredentialKeyFile 凭证加密密钥文件路径的环境变量
+	EnvCredentialKeyFile = "BAIDUPCS_GO_CREDENTIAL_KEY_FILE"
+
+	// encryptedCredentialPrefix 加密后的凭证前缀, 之后为 base64 编码的 salt, nonce 和密文
+	encryptedCredentialPrefix = "enc1:"
+	credentialSaltSize        = 16
+)
+
+var (
+	// ErrCredentialKeyNotSet 未提供凭证加密密钥
+	ErrCredentialKeyNotSet = errors.New("未提供凭证加密密钥, 请设置环境变量 " + EnvCredentialKey + " 或 " + EnvCredentialKeyFile)
+	// ErrCredentialKeyWrong 凭证解密失败
+	ErrCredentialKeyWrong = errors.New("凭证解密失败, 密钥不正确或配置文件已损坏")
+
+	// CredentialKeyPrompt 环境变量未提供密钥时调用, 返回用户输入的口令, 为 nil 时不提示
+	CredentialKeyPrompt func() (string, error)
+
+	credentialKeyMu  sync.Mutex
+	credentialSecret []byte
+	credentialSalt   []byte                  // 加密使用的 salt, 沿用配置文件中的 salt, 避免重复生成密钥
+	credentialKeys   = map[string][32]byte{} // salt 对应的密钥
+
+	// encryptCredentials 保存配置时是否加密凭证
+	encryptCredentials atomic.Bool
+)
+
+// credentialFields 需要加密的凭证字段
+func (baidu *Baidu) credentialFields() []*string {
+	return []*string{&baidu.BDUSS, &baidu.PTOKEN, &baidu.STOKEN, &baidu.SBOXTKN, &baidu.COOKIES, &baidu.AccessToken, &baidu.XpanRefreshToken}
+}
+
+// secretFields 配置中需要和帐号凭证一同加密的密码字段
+func (c *PCSConfig) secretFields() []*string {
+	return []*string{&c.CryptPassword, &c.XpanSecretKey}
+}
+
+// encryptSecrets 加密配置中的密码字段, 用于写入配置文件的副本
+func (c *PCSConfig) encryptSecrets() error {
+	for _, field := range c.secretFields() {
+		v, err := encryptCredential(*field)
+		if err != nil {
+			return err
+		}
+		*field = v
+	}
+	return nil
+}
+
+// MarshalJSON 开启凭证加密时, 加密凭证字段
+func (baidu *Baidu) MarshalJSON() ([]byte, error) {
+	type plainBaidu Baidu
+	// 凭证状态可能正在被后台检查修改
+	credentialMu.Lock()
+	encrypted := *baidu
+	credentialMu.Unlock()
+	if !encryptCredentials.Load() {
+		return jsoniter.Marshal((*plainBaidu)(&encrypted))
+	}
+
+	for _, field := range encrypted.credentialFields() {
+		v, err := encryptCredential(*field)
+		if err != nil {
+			return nil, err
+		}
+		*field = v
+	}
+	return jsoniter.Marshal((*plainBaidu)(&encrypted))
+}
+
+// decryptCredentials 解密从配置文件载入的凭证和密码, 未加密的保持不变
+func (c *PCSConfig) decryptCredentials() error {
+	fields := c.secretFields()
+	for _, baidu := range c.BaiduUserList {
+		fields = append(fields, baidu.credentialFields()...)
+	}
+	for _, field := range fields {
+		v, err := decryptCredential(*field)
+		if err != nil {
+			return err
+		}
+		*field = v
+	}
+	return nil
+}
+
+// CheckCredentialKey 检查是否能获取凭证加密密钥, 必要时提示输入口令
+func CheckCredentialKey() error {
+	credentialKeyMu.Lock()
+	defer credentialKeyMu.Unlock()
+	_, err := loadCredentialSecret()
+	return err
+}
+
+// loadCredentialSecret 依次从环境变量, 密钥文件, 口令提示获取密钥, 调用方需持有 credentialKeyMu
+func loadCredentialSecret() ([]byte, error) {
+	if credentialSecret != nil {
+		return credentialSecret, nil
+	}
+
+	var secret string
+	if v, ok := os.LookupEnv(EnvCredentialKey); ok {
+		secret = v
+	} else if p, ok := os.LookupEnv(EnvCredentialKeyFile); ok {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		secret = strings.TrimSpace(string(data))
+	} else if CredentialKeyPrompt != nil {
+		v, err := CredentialKeyPrompt()
+		if err != nil {
+			return nil, err
+		}
+		secret = v
+	}
+	if secret == "" {
+		return nil, ErrCredentialKeyNotSet
+	}
+	credentialSecret = []byte(secret)
+	return credentialSecret, nil
+}
+
+// credentialKey 由密钥和 salt 生成加密使用的密钥, 调用方需持有 credentialKeyMu
+func credentialKey(salt []byte) ([32]byte, error) {
+	if key, ok := credentialKeys[string(salt)]; ok {
+		return key, nil
+	}
+	secret, err := loadCredentialSecret()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	key, err := pcsutil.DeriveKey(secret, salt)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	credentialKeys[string(salt)] = key
+	return key, nil
+}
+
+// encryptCredential 加密凭证, 空字符串不加密
+func encryptCredential(v string) (string, error) {
+	if v == "" || strings.HasPrefix(v, encryptedCredentialPrefix) {
+		return v, nil
+	}
+
+	credentialKeyMu.Lock()
+	defer credentialKeyMu.Unlock()
+	if credentialSalt == nil {
+		salt := make([]byte, credentialSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		credentialSalt = salt
+	}
+	key, err := credentialKey(credentialSalt)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := pcsutil.AESGCMEncrypt(key, []byte(v))
+	if err != nil {
+		return "", err
+	}
+	return encryptedCredentialPrefix + base64.RawStdEncoding.EncodeToString(append(append([]byte(nil), credentialSalt...), ciphertext...)), nil
+}
+
+// decryptCredential 解密凭证, 未加密的凭证原样返回
+func decryptCredential(v string) (string, error) {
+	if !strings.HasPrefix(v, encryptedCredentialPrefix) {
+		return v, nil
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(v, encryptedCredentialPrefix))
+	if err != nil || len(data) < credentialSaltSize {
+		return "", ErrCredentialKeyWrong
+	}
+
+	credentialKeyMu.Lock()
+	defer credentialKeyMu.Unlock()
+	salt := data[:credentialSaltSize]
+	key, err := credentialKey(salt)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := pcsutil.AESGCMDecrypt(key, data[credentialSaltSize:])
+	if err != nil {
+		return "", ErrCredentialKeyWrong
+	}
+	if credentialSalt == nil {
+		credentialSalt = append([]byte(nil), salt...)
+	}
+	return string(plaintext), nil
+}
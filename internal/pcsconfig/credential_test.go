@@ -0,0 +1,130 @@
+package pcsconfig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+// startFakePan 启动代理, 将所有 https 请求转发到模拟的网盘接口.
+// BDUSS 以 expired 开头的帐号返回登录失效
+func startFakePan(t *testing.T) {
+	pan := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("BDUSS")
+		if err == nil && strings.HasPrefix(cookie.Value, "expired") {
+			fmt.Fprint(w, `{"errno":-6}`)
+			return
+		}
+		fmt.Fprint(w, `{"errno":0,"records":[{"uk":1}]}`)
+	}))
+	t.Cleanup(pan.Close)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "connect only", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", pan.Listener.Addr().String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(proxy.Close)
+
+	requester.SetGlobalProxy(proxy.URL)
+	t.Cleanup(func() { requester.SetGlobalProxy("") })
+}
+
+func TestCheckCredentials(t *testing.T) {
+	startFakePan(t)
+	c := NewConfig(filepath.Join(t.TempDir(), "config.json"))
+	c.InitDefaultConfig()
+	c.BaiduUserList = BaiduUserList{
+		{BaiduBase: BaiduBase{UID: 1, Name: "valid"}, BDUSS: "valid", STOKEN: "stoken"},
+		{BaiduBase: BaiduBase{UID: 2, Name: "expired"}, BDUSS: "expired"},
+		{BaiduBase: BaiduBase{UID: 3, Name: "nostoken"}, BDUSS: "nostoken"},
+	}
+
+	bad, changed, err := c.CheckCredentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || len(bad) != 2 {
+		t.Fatalf("changed %v, %d bad accounts, want true and 2", changed, len(bad))
+	}
+	want := map[uint64]CredentialStatus{1: CredentialValid, 2: CredentialExpired, 3: CredentialNeedStoken}
+	for _, baidu := range c.Users() {
+		if status := baidu.Credential().Status; status != want[baidu.UID] {
+			t.Errorf("uid %d: status %s, want %s", baidu.UID, status, want[baidu.UID])
+		}
+	}
+
+	_, changed, err = c.CheckCredentials(context.Background())
+	if err != nil || changed {
+		t.Fatalf("second check: changed %v, err %v", changed, err)
+	}
+}
+
+// TestCheckCredentialsConcurrentLogin 后台检查凭证时登录和退出帐号, 使用 -race 运行
+func TestCheckCredentialsConcurrentLogin(t *testing.T) {
+	startFakePan(t)
+	c := NewConfig(filepath.Join(t.TempDir(), "config.json"))
+	c.InitDefaultConfig()
+	for i := uint64(1); i <= 5; i++ {
+		c.BaiduUserList = append(c.BaiduUserList, &Baidu{BaiduBase: BaiduBase{UID: i, Name: fmt.Sprint("user", i)}, BDUSS: "expired"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			c.CheckCredentials(ctx)
+		}
+	}()
+
+	// 登录新帐号并退出最早的帐号, 帐号数量保持不变
+	uids := []uint64{1, 2, 3, 4, 5}
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for uid := uint64(100); time.Now().Before(deadline); uid++ {
+		c.userMu.Lock()
+		c.BaiduUserList = append(c.BaiduUserList, &Baidu{BaiduBase: BaiduBase{UID: uid, Name: fmt.Sprint("user", uid)}, BDUSS: "valid"})
+		c.userMu.Unlock()
+		if _, err := c.DeleteUser(&BaiduBase{UID: uids[0]}); err != nil {
+			t.Fatal(err)
+		}
+		uids = append(uids[1:], uid)
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+
+	if n := c.NumLogins(); n != len(uids) {
+		t.Errorf("%d accounts, want %d", n, len(uids))
+	}
+}
@@ -0,0 +1,293 @@
+package pcsconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+)
+
+const (
+	// EnvPrefix 配置项环境变量的前缀, 环境变量名为前缀加上大写的配置项名称, 如 max_parallel 对应 BAIDUPCS_GO_MAX_PARALLEL
+	EnvPrefix = "BAIDUPCS_GO_"
+	// EnvConfigReadOnly 只读配置模式的环境变量, 开启后不写入配置文件
+	EnvConfigReadOnly = "BAIDUPCS_GO_CONFIG_READONLY"
+	// EnvBDUSS 启动时登录的百度帐号 BDUSS 的环境变量
+	EnvBDUSS = "BAIDUPCS_GO_BDUSS"
+	// EnvSTOKEN 启动时登录的百度帐号 STOKEN 的环境变量
+	EnvSTOKEN = "BAIDUPCS_GO_STOKEN"
+	// EnvCookies 启动时登录的百度帐号 cookies 的环境变量
+	EnvCookies = "BAIDUPCS_GO_COOKIES"
+)
+
+var (
+	// envKeyAliases 配置项在 config set 中使用的名称, 也可作为环境变量名
+	envKeyAliases = map[string]string{
+		"u_policy": "upload_policy",
+	}
+)
+
+// envOverride 被环境变量覆盖的配置项
+type envOverride struct {
+	index     int           // PCSConfig 中字段的序号
+	fileValue reflect.Value // 配置文件中的值
+	envValue  reflect.Value // 环境变量设置的值
+}
+
+// EnvVar 配置项对应的环境变量
+type EnvVar struct {
+	Key   string // 配置项名称
+	Name  string // 环境变量名
+	Value string // 环境变量的值
+	Set   bool   // 是否设置了环境变量
+}
+
+// EnvName 返回配置项对应的环境变量名
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+// ReadOnly 是否开启了只读配置模式
+func ReadOnly() bool {
+	v, _ := strconv.ParseBool(os.Getenv(EnvConfigReadOnly))
+	return v
+}
+
+// EnvVars 返回所有可由环境变量覆盖的配置项
+func EnvVars() []EnvVar {
+	t := reflect.TypeOf((*PCSConfig)(nil)).Elem()
+	vars := make([]EnvVar, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := envFieldKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		ev := EnvVar{
+			Key:  key,
+			Name: EnvName(key),
+		}
+		if name, value, ok := lookupEnv(key); ok {
+			ev.Name, ev.Value, ev.Set = name, value, true
+		}
+		vars = append(vars, ev)
+	}
+	return vars
+}
+
+// EnvOverridden 返回被环境变量覆盖的配置项名称, 与 Schema 中的名称一致
+func (c *PCSConfig) EnvOverridden() []string {
+	t := reflect.TypeOf(c).Elem()
+	keys := make([]string, 0, len(c.envOverrides))
+	for _, o := range c.envOverrides {
+		key, _ := envFieldKey(t.Field(o.index))
+		if alias, ok := envKeyAliases[key]; ok {
+			key = alias
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// DisplayValue 返回用于显示的环境变量的值, 密码类型的配置项不显示内容, 代理地址不显示用户名和密码
+func (ev EnvVar) DisplayValue() string {
+	key := ev.Key
+	if alias, ok := envKeyAliases[key]; ok {
+		key = alias
+	}
+	if item := SchemaItem(key); item != nil && item.Type == ItemTypePassword {
+		return showPassword(ev.Value)
+	}
+	if key == "proxy" {
+		return showProxy(ev.Value)
+	}
+	return ev.Value
+}
+
+// lookupEnv 查找配置项对应的环境变量, 返回找到的环境变量名和值
+func lookupEnv(key string) (name, value string, ok bool) {
+	name = EnvName(key)
+	value, ok = os.LookupEnv(name)
+	if ok {
+		return
+	}
+	if alias, has := envKeyAliases[key]; has {
+		aliasName := EnvName(alias)
+		if value, ok = os.LookupEnv(aliasName); ok {
+			return aliasName, value, true
+		}
+	}
+	return name, "", false
+}
+
+// envFieldKey 返回字段的配置项名称, 不支持由环境变量设置的字段返回 false
+func envFieldKey(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	switch field.Type.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Uint64:
+	default:
+		return "", false
+	}
+	key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if key == "" || key == "-" {
+		return "", false
+	}
+	return key, true
+}
+
+// applyEnv 使用环境变量覆盖配置文件中的配置项, 覆盖的值不会写回配置文件
+func (c *PCSConfig) applyEnv() error {
+	c.envOverrides = nil
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := envFieldKey(t.Field(i))
+		if !ok {
+			continue
+		}
+		name, raw, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		field := v.Field(i)
+		fileValue := reflect.New(field.Type()).Elem()
+		fileValue.Set(field)
+		err := setEnvField(field, key, raw)
+		if err != nil {
+			return fmt.Errorf("环境变量 %s 的值不合法: %s", name, err)
+		}
+		c.envOverrides = append(c.envOverrides, envOverride{
+			index:     i,
+			fileValue: fileValue,
+		})
+	}
+	if len(c.envOverrides) == 0 {
+		return nil
+	}
+
+	c.fix()
+	// 记录修复后的值, 用于判断运行时是否修改了配置项
+	for i := range c.envOverrides {
+		field := v.Field(c.envOverrides[i].index)
+		c.envOverrides[i].envValue = reflect.New(field.Type()).Elem()
+		c.envOverrides[i].envValue.Set(field)
+	}
+	pcsConfigVerbose.Infof("config overridden by environment: %s\n", strings.Join(c.EnvOverridden(), ", "))
+	return nil
+}
+
+// setEnvField 解析环境变量的值并设置字段, 下载缓存和限速支持带单位的大小
+func setEnvField(field reflect.Value, key, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch key {
+	case "cache_size", "max_download_rate", "max_upload_rate":
+		size, err := converter.ParseFileSizeStr(stripPerSecond(raw))
+		if err != nil {
+			return err
+		}
+		field.SetInt(size)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	}
+	return nil
+}
+
+// fileView 返回写入配置文件的配置, 运行时未修改的被环境变量覆盖的配置项使用配置文件中的原值,
+// 开启凭证加密时加密其中的密码
+func (c *PCSConfig) fileView() (interface{}, error) {
+	encrypt := encryptCredentials.Load()
+	if len(c.envOverrides) == 0 && !encrypt {
+		return c, nil
+	}
+
+	src := reflect.ValueOf(c).Elem()
+	view := reflect.New(src.Type())
+	dst := view.Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if src.Type().Field(i).IsExported() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	for _, o := range c.envOverrides {
+		if src.Field(o.index).Interface() == o.envValue.Interface() {
+			dst.Field(o.index).Set(o.fileValue)
+		}
+	}
+	if encrypt {
+		err := view.Interface().(*PCSConfig).encryptSecrets()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return view.Interface(), nil
+}
+
+// setupEnvUser 使用环境变量中的 BDUSS 登录百度帐号, 已存在相同 BDUSS 的帐号时跳过
+func (c *PCSConfig) setupEnvUser() error {
+	bduss := os.Getenv(EnvBDUSS)
+	if bduss == "" {
+		return nil
+	}
+	for _, baidu := range c.Users() {
+		if baidu != nil && baidu.BDUSS == bduss {
+			return nil
+		}
+	}
+
+	// 只读模式下帐号不会写入配置文件, 重载配置时沿用已登录的帐号, 避免重复请求
+	if c.envUser != nil && c.envUser.BDUSS == bduss {
+		c.userMu.Lock()
+		list := c.BaiduUserList[:0]
+		for _, baidu := range c.BaiduUserList {
+			if baidu != nil && baidu.UID != c.envUser.UID {
+				list = append(list, baidu)
+			}
+		}
+		c.BaiduUserList = append(list, c.envUser)
+		c.userMu.Unlock()
+		if c.BaiduActiveUID == 0 {
+			c.BaiduActiveUID = c.envUser.UID
+		}
+		return nil
+	}
+
+	activeUID := c.BaiduActiveUID
+	baidu, err := c.SetupUserByBDUSS(bduss, "", os.Getenv(EnvSTOKEN), os.Getenv(EnvCookies))
+	if err != nil {
+		return fmt.Errorf("使用环境变量 %s 登录失败: %s", EnvBDUSS, err)
+	}
+	// 配置文件中已有当前帐号时, 不切换帐号
+	if activeUID != 0 && activeUID != baidu.UID && c.CheckBaiduUserExist(&BaiduBase{UID: activeUID}) {
+		c.BaiduActiveUID = activeUID
+		c.activeUser, c.pcs = nil, nil
+	}
+	c.envUser = baidu
+	return nil
+}
@@ -15,4 +15,6 @@ var (
 	ErrConfigFileNoPermission = errors.New("config file permission denied")
 	//ErrConfigContentsParseError 解析Config数据错误
 	ErrConfigContentsParseError = errors.New("config contents parse error")
+	//ErrCryptSaltNotSet 只读配置模式下未设置加密目录的 salt
+	ErrCryptSaltNotSet = errors.New("只读配置模式下需通过环境变量 BAIDUPCS_GO_CRYPT_SALT 设置加密目录的 salt")
 )
@@ -1,20 +1,35 @@
 package pcsconfig
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
 	"regexp"
 	"strings"
 
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/cookiefile"
 )
 
 const (
+	// cryptSaltSize 加密目录 salt 的字节数
+	cryptSaltSize = 16
+
 	opDelete = "delete"
 	opSwitch = "switch"
 	opGet    = "get"
 )
 
 func (c *PCSConfig) manipUser(op string, baiduBase *BaiduBase) (*Baidu, error) {
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
+
 	// empty baiduBase
 	if baiduBase == nil || (baiduBase.UID == 0 && baiduBase.Name == "") {
 		switch op {
@@ -137,13 +152,68 @@ func (c *PCSConfig) SetupUserByBDUSS(bduss, ptoken, stoken, cookies string) (bai
 	b.STOKEN = stoken
 	b.COOKIES = cookies
 
+	c.userMu.Lock()
 	c.BaiduUserList = append(c.BaiduUserList, b)
+	c.userMu.Unlock()
 
 	// 自动切换用户
 	c.setupNewUser(b)
 	return b, nil
 }
 
+// SetupUserByCookies 使用浏览器导出的 cookies 登录百度帐号,
+// 提取 BDUSS, STOKEN, BAIDUID, PTOKEN 及发送到网盘的 cookies, 通过 UK 和 bdstoken 验证有效后添加帐号
+func (c *PCSConfig) SetupUserByCookies(cookies []*http.Cookie) (baidu *Baidu, err error) {
+	var (
+		bduss   = cookiefile.Lookup(cookies, "BDUSS", baidupcs.PanBaiduCom)
+		stoken  = cookiefile.Lookup(cookies, "STOKEN", baidupcs.PanBaiduCom)
+		baiduid = cookiefile.Lookup(cookies, "BAIDUID", baidupcs.PanBaiduCom)
+		ptoken  = cookiefile.Lookup(cookies, "PTOKEN", "passport.baidu.com")
+		cookie  = cookiefile.String(cookiefile.ForHost(cookies, baidupcs.PanBaiduCom))
+	)
+	if bduss == "" {
+		return nil, errors.New("cookies 中未找到 BDUSS")
+	}
+
+	// 验证 cookies 有效
+	pcs := (&Baidu{BDUSS: bduss, STOKEN: stoken, COOKIES: cookie}).BaiduPCS()
+	if _, pcsError := pcs.UK(); pcsError != nil {
+		return nil, fmt.Errorf("BDUSS 无效: %s", pcsError)
+	}
+	if _, pcsError := pcs.BDSToken(); pcsError != nil {
+		return nil, fmt.Errorf("获取 bdstoken 失败, cookies 可能已失效: %s", pcsError)
+	}
+
+	baidu, err = c.SetupUserByBDUSS(bduss, ptoken, stoken, cookie)
+	if err != nil {
+		return nil, err
+	}
+	baidu.BAIDUID = baiduid
+	return baidu, nil
+}
+
+// SetNotifyURL 设置通知地址, 空字符串为关闭
+func (c *PCSConfig) SetNotifyURL(notifyURL string) error {
+	err := checkNotifyURL(notifyURL)
+	if err != nil {
+		return err
+	}
+	c.NotifyURL = notifyURL
+	return nil
+}
+
+// checkNotifyURL 检查通知地址, 为空表示不通知
+func checkNotifyURL(notifyURL string) error {
+	if notifyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(notifyURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("通知地址不合法: %s", notifyURL)
+	}
+	return nil
+}
+
 // SetAppID 设置app_id
 func (c *PCSConfig) SetAppID(appID int) {
 	c.AppID = appID
@@ -206,7 +276,7 @@ func (c *PCSConfig) SetPanUA(panUA string) {
 
 // SetPCSAddr 设置 PCS 服务器地址
 func (c *PCSConfig) SETPCSAddr(pcsaddr string) bool {
-	match, _ := regexp.MatchString("^([cd]\\d?\\.)?pcs\\.baidu\\.com", pcsaddr)
+	match := validPCSAddr(pcsaddr)
 	if match {
 		c.PCSAddr = pcsaddr
 		if c.pcs != nil {
@@ -216,6 +286,12 @@ func (c *PCSConfig) SETPCSAddr(pcsaddr string) bool {
 	return match
 }
 
+// validPCSAddr PCS 服务器地址是否合法
+func validPCSAddr(pcsaddr string) bool {
+	match, _ := regexp.MatchString("^([cd]\\d?\\.)?pcs\\.baidu\\.com", pcsaddr)
+	return match
+}
+
 // SetStaticPCSAddr 设置上传时是否关闭动态PCS域名
 func (c *PCSConfig) SetStaticPCSAddr(static bool) {
 	c.FixPCSAddr = static
@@ -241,6 +317,16 @@ func (c *PCSConfig) SetUploadPolicy(upolicy string) {
 	c.UPolicy = upolicy
 }
 
+// SetUploadAPI 设置上传使用的接口
+func (c *PCSConfig) SetUploadAPI(api string) error {
+	switch api {
+	case UploadAPIPCS, UploadAPIXpan:
+		c.UploadAPI = api
+		return nil
+	}
+	return fmt.Errorf("未知的上传接口: %s, 可选 %s, %s", api, UploadAPIPCS, UploadAPIXpan)
+}
+
 // SetProxy 设置代理
 func (c *PCSConfig) SetProxy(proxy string) {
 	c.Proxy = proxy
@@ -268,3 +354,78 @@ func (c *PCSConfig) SetIgnoreIllegal(ignore bool) {
 func (c *PCSConfig) SetForceLogin(username string) {
 	c.ForceLogin = username
 }
+
+// SetCryptDir 设置网盘加密目录, 为空时不加密
+func (c *PCSConfig) SetCryptDir(dir string) {
+	if dir != "" {
+		dir = path.Clean("/" + dir)
+	}
+	c.CryptDir = dir
+}
+
+// SetCryptPassword 设置加密目录的密码, 没有 salt 时同时生成
+func (c *PCSConfig) SetCryptPassword(password string) {
+	c.CryptPassword = password
+	if password != "" && c.CryptSalt == "" {
+		c.CryptSalt = newCryptSalt()
+	}
+}
+
+// SetCryptSalt 设置加密目录生成密钥的 salt, 为 hex 编码
+func (c *PCSConfig) SetCryptSalt(salt string) error {
+	err := checkCryptSalt(salt)
+	if err != nil {
+		return err
+	}
+	c.CryptSalt = salt
+	return nil
+}
+
+// checkCryptSalt 检查 salt 是否为足够长度的 hex 编码
+func checkCryptSalt(salt string) error {
+	b, err := hex.DecodeString(salt)
+	if err != nil || len(b) < cryptSaltSize {
+		return fmt.Errorf("salt 应为至少 %d 字节的 hex 编码", cryptSaltSize)
+	}
+	return nil
+}
+
+// initCryptSalt 设置了加密目录的密码但没有 salt 时, 生成随机的 salt 并保存
+func (c *PCSConfig) initCryptSalt() error {
+	if c.CryptPassword == "" || c.CryptSalt != "" {
+		return nil
+	}
+	if ReadOnly() {
+		// 每次启动生成不同的 salt 会导致已上传的文件无法解密
+		return ErrCryptSaltNotSet
+	}
+	c.CryptSalt = newCryptSalt()
+	return c.Save()
+}
+
+// newCryptSalt 生成随机的 salt
+func newCryptSalt() string {
+	salt := make([]byte, cryptSaltSize)
+	rand.Read(salt)
+	return hex.EncodeToString(salt)
+}
+
+// SetCryptFilename 设置加密目录中是否同时加密文件名
+func (c *PCSConfig) SetCryptFilename(encrypt bool) {
+	c.CryptFilename = encrypt
+}
+
+// SetEncryptCredentials 设置是否加密保存帐号凭证
+func (c *PCSConfig) SetEncryptCredentials(encrypt bool) {
+	c.EncryptCredentials = encrypt
+}
+
+// SetXpanAppKey 设置开放平台应用的 AppKey
+func (c *PCSConfig) SetXpanAppKey(appKey string) {
+	c.XpanAppKey = appKey
+}
+
+// SetXpanSecretKey 设置开放平台应用的 SecretKey
+func (c *PCSConfig) SetXpanSecretKey(secretKey string) {
+	c.XpanSecretKey = secretKey
+}
@@ -2,6 +2,8 @@
 package pcsconfig
 
 import (
+	"bytes"
+	"encoding/json"
 	"github.com/json-iterator/go"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
@@ -20,6 +22,11 @@ const (
 	EnvConfigDir = "BAIDUPCS_GO_CONFIG_DIR"
 	// ConfigName 配置文件名
 	ConfigName = "pcs_config.json"
+
+	// UploadAPIPCS 使用 PCS 接口上传
+	UploadAPIPCS = "pcs"
+	// UploadAPIXpan 使用开放平台 (xpan) 接口上传, 需要 accesstoken
+	UploadAPIXpan = "xpan"
 )
 
 var (
@@ -60,12 +67,25 @@ type PCSConfig struct {
 	NoCheck        bool   `json:"no_check"`             // 禁用下载md5校验
 	IgnoreIllegal  bool   `json:"ignore_illegal"`       // 禁用上传文件名非法字符检查
 	UPolicy        string `json:"u_policy"`             // 上传重名文件处理策略
+	UploadAPI      string `json:"upload_api"`           // 上传使用的接口, pcs 或 xpan
+	CryptDir       string `json:"crypt_dir"`            // 网盘加密目录, 为空时不加密
+	CryptPassword  string `json:"crypt_password"`       // 加密目录的密码
+	CryptSalt      string `json:"crypt_salt"`           // 加密目录生成密钥的 salt, hex 编码, 设置密码时自动生成
+	CryptFilename  bool   `json:"crypt_filename"`       // 加密目录中同时加密文件名
+	XpanAppKey     string `json:"xpan_app_key"`         // 开放平台应用的 AppKey, 用于 xpan 授权登录
+	XpanSecretKey  string `json:"xpan_secret_key"`      // 开放平台应用的 SecretKey
+	NotifyURL      string `json:"notify_url"`           // 帐号凭证失效等事件的通知地址, 以 json 格式 POST
+
+	EncryptCredentials bool `json:"encrypt_credentials"` // 加密保存帐号凭证和密码, 密钥由环境变量, 密钥文件或启动时输入的口令提供
 
 	configFilePath string
 	configFile     *os.File
 	fileMu         sync.Mutex
+	userMu         sync.RWMutex // 保护 BaiduUserList, 帐号列表会被后台任务读取
 	activeUser     *Baidu
 	pcs            *baidupcs.BaiduPCS
+	envOverrides   []envOverride // 被环境变量覆盖的配置项
+	envUser        *Baidu        // 由环境变量 BDUSS 登录的帐号
 }
 
 // NewConfig 返回 PCSConfig 指针对象
@@ -96,11 +116,25 @@ func (c *PCSConfig) Close() error {
 	return nil
 }
 
-// Save 保存配置信息到配置文件
+// Save 保存配置信息到配置文件, 先写入临时文件再替换, 避免写入中断导致配置文件损坏.
+// 只读配置模式下不写入文件
 func (c *PCSConfig) Save() error {
 	// 检测配置项是否合法, 不合法则自动修复
 	c.fix()
 
+	if ReadOnly() {
+		pcsConfigVerbose.Infof("config is read-only, skip saving %s\n", c.configFilePath)
+		return nil
+	}
+
+	if c.EncryptCredentials {
+		err := CheckCredentialKey()
+		if err != nil {
+			return err
+		}
+	}
+	encryptCredentials.Store(c.EncryptCredentials)
+
 	err := c.lazyOpenConfigFile()
 	if err != nil {
 		return err
@@ -109,28 +143,50 @@ func (c *PCSConfig) Save() error {
 	c.fileMu.Lock()
 	defer c.fileMu.Unlock()
 
-	data, err := jsoniter.MarshalIndent(c, "", " ")
+	// 帐号信息由 Baidu.MarshalJSON 生成, 需重新缩进
+	c.userMu.RLock()
+	view, err := c.fileView()
 	if err != nil {
-		// json数据生成失败
-		panic(err)
+		c.userMu.RUnlock()
+		return err
 	}
-
-	// 减掉多余的部分
-	err = c.configFile.Truncate(int64(len(data)))
+	raw, err := jsoniter.Marshal(view)
+	c.userMu.RUnlock()
 	if err != nil {
 		return err
 	}
-
-	_, err = c.configFile.Seek(0, os.SEEK_SET)
+	buf := &bytes.Buffer{}
+	err = json.Indent(buf, raw, "", " ")
 	if err != nil {
 		return err
 	}
+	data := buf.Bytes()
 
-	_, err = c.configFile.Write(data)
+	tmpPath := c.configFilePath + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if cerr := tmpFile.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
 
+	// 替换前关闭旧的配置文件, 下次读取时重新打开
+	c.configFile.Close()
+	c.configFile = nil
+	err = os.Rename(tmpPath, c.configFilePath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
 	return nil
 }
 
@@ -145,21 +201,38 @@ func (c *PCSConfig) init() error {
 		return err
 	}
 
-	// 载入配置
-	// 如果 activeUser 已初始化, 则跳过
-	if c.activeUser != nil && c.activeUser.UID == c.BaiduActiveUID {
-		return nil
+	// 环境变量优先于配置文件
+	err = c.applyEnv()
+	if err != nil {
+		return err
 	}
-
-	c.activeUser, err = c.GetBaiduUser(&BaiduBase{
-		UID: c.BaiduActiveUID,
-	})
+	err = c.setupEnvUser()
+	if err != nil {
+		return err
+	}
+	err = c.initCryptSalt()
 	if err != nil {
 		return err
 	}
-	c.pcs = c.activeUser.BaiduPCS()
-	c.pcs.SetPCSAddr(c.PCSAddr)
 
+	// 载入配置
+	// 如果 activeUser 已初始化, 则跳过
+	if c.activeUser == nil || c.activeUser.UID != c.BaiduActiveUID {
+		c.activeUser, err = c.GetBaiduUser(&BaiduBase{
+			UID: c.BaiduActiveUID,
+		})
+		if err != nil {
+			return err
+		}
+		c.pcs = c.activeUser.BaiduPCS()
+	}
+
+	c.applyRuntime()
+	return nil
+}
+
+// applyRuntime 将配置应用到全局的网络设置及当前帐号的 BaiduPCS, 重载配置后立即生效
+func (c *PCSConfig) applyRuntime() {
 	// 设置全局User-Agent
 	requester.UserAgent = c.UserAgent
 	// 设置全局代理
@@ -169,7 +242,14 @@ func (c *PCSConfig) init() error {
 	// 设置本地网卡地址
 	requester.SetLocalTCPAddrList(strings.Split(c.LocalAddrs, ",")...)
 
-	return nil
+	if c.pcs != nil {
+		c.pcs.SetAPPID(c.AppID)
+		c.pcs.SetHTTPS(c.EnableHTTPS)
+		c.pcs.SetPCSUserAgent(c.PCSUA)
+		c.pcs.SetPanUserAgent(c.PanUA)
+		c.pcs.SetPCSAddr(c.PCSAddr)
+		c.pcs.SetStaticPCSAddr(c.FixPCSAddr)
+	}
 }
 
 // lazyOpenConfigFile 打开配置文件
@@ -197,6 +277,10 @@ func (c *PCSConfig) lazyOpenConfigFile() (err error) {
 
 // loadConfigFromFile 载入配置
 func (c *PCSConfig) loadConfigFromFile() (err error) {
+	if ReadOnly() {
+		return c.loadReadOnlyConfig()
+	}
+
 	err = c.lazyOpenConfigFile()
 	if err != nil {
 		return err
@@ -221,11 +305,38 @@ func (c *PCSConfig) loadConfigFromFile() (err error) {
 		return err
 	}
 
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
 	err = jsonhelper.UnmarshalData(c.configFile, c)
 	if err != nil {
 		return ErrConfigContentsParseError
 	}
-	return nil
+	return c.decryptCredentials()
+}
+
+// loadReadOnlyConfig 只读配置模式下载入配置, 不创建配置文件及目录, 配置文件不存在时使用默认配置
+func (c *PCSConfig) loadReadOnlyConfig() error {
+	data, err := os.ReadFile(c.configFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if os.IsPermission(err) {
+			return ErrConfigFileNoPermission
+		}
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	c.userMu.Lock()
+	defer c.userMu.Unlock()
+	err = jsonhelper.UnmarshalData(bytes.NewReader(data), c)
+	if err != nil {
+		return ErrConfigContentsParseError
+	}
+	return c.decryptCredentials()
 }
 
 func (c *PCSConfig) InitDefaultConfig() {
@@ -242,6 +353,7 @@ func (c *PCSConfig) InitDefaultConfig() {
 	c.EnableHTTPS = true
 	c.NoCheck = true
 	c.UPolicy = baidupcs.SkipPolicy
+	c.UploadAPI = UploadAPIPCS
 	c.Proxy = ""
 	c.LocalAddrs = ""
 	c.IgnoreIllegal = true
@@ -331,4 +443,7 @@ func (c *PCSConfig) fix() {
 	if c.UPolicy != baidupcs.SkipPolicy && c.UPolicy != baidupcs.OverWritePolicy && c.UPolicy != baidupcs.RsyncPolicy {
 		c.UPolicy = baidupcs.SkipPolicy
 	}
+	if c.UploadAPI != UploadAPIPCS && c.UploadAPI != UploadAPIXpan {
+		c.UploadAPI = UploadAPIPCS
+	}
 }
@@ -0,0 +1,539 @@
+package pcsconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+// ItemType 配置项的类型
+type ItemType string
+
+const (
+	// ItemTypeInt 整数
+	ItemTypeInt ItemType = "int"
+	// ItemTypeSize 文件大小, 支持单位, 如 64KB, 1MB
+	ItemTypeSize ItemType = "size"
+	// ItemTypeRate 传输速率, 支持单位, 后缀 /s 可省略, 如 2MB/s, 0 表示不限制
+	ItemTypeRate ItemType = "rate"
+	// ItemTypeBool 布尔值
+	ItemTypeBool ItemType = "bool"
+	// ItemTypeString 字符串
+	ItemTypeString ItemType = "string"
+	// ItemTypeEnum 只能取 Options 中的值
+	ItemTypeEnum ItemType = "enum"
+	// ItemTypePassword 密码等敏感内容, 读取时不返回明文
+	ItemTypePassword ItemType = "password"
+
+	// passwordMask 密码类型配置项已设置时显示的内容, 设置为该值时不修改
+	passwordMask = "******"
+)
+
+// ConfigItem 配置项的定义
+type ConfigItem struct {
+	Name        string   `json:"name"`              // 配置项名称, 用于 config set 参数, API 及环境变量
+	Type        ItemType `json:"type"`              // 类型
+	Default     string   `json:"default"`           // 默认值
+	Suggest     string   `json:"suggest,omitempty"` // 建议值
+	Options     []string `json:"options,omitempty"` // 可选值, 仅 enum 类型
+	Min         int64    `json:"min,omitempty"`     // 最小值, 仅 int, size 类型
+	Max         int64    `json:"max,omitempty"`     // 最大值, 为 0 时不限制
+	Description string   `json:"description"`       // 描述
+	Restart     bool     `json:"restart"`           // 修改后需重启程序才能生效
+	Env         string   `json:"env"`               // 覆盖该配置项的环境变量
+
+	field func(c *PCSConfig) interface{}        // 返回 PCSConfig 中字段的指针
+	check func(value interface{}) error         // 检查解析后的值, 为空时不检查
+	apply func(c *PCSConfig, value interface{}) // 设置配置项并立即生效, 为空时直接修改字段
+	show  func(value string) string             // 返回用于显示的值, 隐藏其中的敏感内容
+}
+
+var (
+	// ErrConfigItemNotFound 未知的配置项
+	ErrConfigItemNotFound = errors.New("未知的配置项")
+
+	// Schema 所有配置项的定义, 顺序即显示顺序.
+	// 配置项修改后立即生效, 或在之后开始的任务中读取, Restart 为 true 的配置项需重启程序才能生效
+	Schema = []*ConfigItem{
+		{
+			Name:        "appid",
+			Type:        ItemTypeInt,
+			Description: "百度 PCS 应用ID",
+			field:       func(c *PCSConfig) interface{} { return &c.AppID },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetAppID(v.(int))
+			},
+		},
+		{
+			Name:        "cache_size",
+			Type:        ItemTypeSize,
+			Suggest:     "1KB ~ 256KB",
+			Min:         1024,
+			Description: "下载缓存, 如果硬盘占用高或下载速度慢, 请尝试调大此值",
+			field:       func(c *PCSConfig) interface{} { return &c.CacheSize },
+		},
+		{
+			Name:        "max_parallel",
+			Type:        ItemTypeInt,
+			Suggest:     "1 ~ 20",
+			Min:         1,
+			Description: "下载总最大并发量, 非svip不可>1",
+			field:       func(c *PCSConfig) interface{} { return &c.MaxParallel },
+		},
+		{
+			Name:        "max_upload_parallel",
+			Type:        ItemTypeInt,
+			Suggest:     "1 ~ 100",
+			Min:         1,
+			Description: "上传单文件最大并发量",
+			field:       func(c *PCSConfig) interface{} { return &c.MaxUploadParallel },
+		},
+		{
+			Name:        "max_download_load",
+			Type:        ItemTypeInt,
+			Suggest:     "1 ~ 5",
+			Min:         1,
+			Description: "同时进行下载文件的最大数量",
+			field:       func(c *PCSConfig) interface{} { return &c.MaxDownloadLoad },
+		},
+		{
+			Name:        "max_download_rate",
+			Type:        ItemTypeRate,
+			Description: "限制最大下载速度, 0代表不限制, 对之后开始下载的文件生效",
+			field:       func(c *PCSConfig) interface{} { return &c.MaxDownloadRate },
+		},
+		{
+			Name:        "max_upload_rate",
+			Type:        ItemTypeRate,
+			Description: "限制最大上传速度, 0代表不限制, 对之后开始上传的文件生效",
+			field:       func(c *PCSConfig) interface{} { return &c.MaxUploadRate },
+		},
+		{
+			Name:        "max_upload_load",
+			Type:        ItemTypeInt,
+			Suggest:     "1 ~ 4",
+			Min:         1,
+			Description: "同时进行上传文件的最大数量",
+			field:       func(c *PCSConfig) interface{} { return &c.MaxUploadLoad },
+		},
+		{
+			Name:        "savedir",
+			Type:        ItemTypeString,
+			Description: "下载文件的储存目录",
+			field:       func(c *PCSConfig) interface{} { return &c.SaveDir },
+		},
+		{
+			Name:        "enable_https",
+			Type:        ItemTypeBool,
+			Suggest:     "true",
+			Description: "启用 https",
+			field:       func(c *PCSConfig) interface{} { return &c.EnableHTTPS },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetEnableHTTPS(v.(bool))
+			},
+		},
+		{
+			Name:        "force_login_username",
+			Type:        ItemTypeString,
+			Suggest:     "留空",
+			Description: "强制登录指定用户名, 适用于tieba用户信息接口不可用的情况, 如登录正常请留空",
+			field:       func(c *PCSConfig) interface{} { return &c.ForceLogin },
+		},
+		{
+			Name:        "ignore_illegal",
+			Type:        ItemTypeBool,
+			Suggest:     "false",
+			Description: "关闭上传文件的文件名非法字符检查",
+			field:       func(c *PCSConfig) interface{} { return &c.IgnoreIllegal },
+		},
+		{
+			Name:        "no_check",
+			Type:        ItemTypeBool,
+			Description: "关闭下载文件的md5校验",
+			field:       func(c *PCSConfig) interface{} { return &c.NoCheck },
+		},
+		{
+			Name:    "upload_policy",
+			Type:    ItemTypeEnum,
+			Suggest: baidupcs.SkipPolicy,
+			Options: []string{baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy},
+			Description: fmt.Sprintf("上传遇到重名文件时的处理策略, %s(默认，跳过)、%s(覆盖)、%s(仅跳过大小未变化的文件其余覆盖)",
+				baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy),
+			field: func(c *PCSConfig) interface{} { return &c.UPolicy },
+		},
+		{
+			Name:    "upload_api",
+			Type:    ItemTypeEnum,
+			Suggest: UploadAPIPCS,
+			Options: []string{UploadAPIPCS, UploadAPIXpan},
+			Description: fmt.Sprintf("上传使用的接口, %s(默认)、%s(开放平台接口, 需要 accesstoken). 使用 %s 接口无权限时, 已授权 xpan 的帐号自动改用 %s 接口",
+				UploadAPIPCS, UploadAPIXpan, UploadAPIPCS, UploadAPIXpan),
+			field: func(c *PCSConfig) interface{} { return &c.UploadAPI },
+		},
+		{
+			Name:        "encrypt_credentials",
+			Type:        ItemTypeBool,
+			Suggest:     "false",
+			Description: "加密保存帐号凭证, crypt_password 和 xpan_secret_key, 密钥由环境变量 " + EnvCredentialKey + ", " + EnvCredentialKeyFile + " 或启动时输入的口令提供",
+			field:       func(c *PCSConfig) interface{} { return &c.EncryptCredentials },
+			check: func(v interface{}) error {
+				if !v.(bool) {
+					return nil
+				}
+				return CheckCredentialKey()
+			},
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetEncryptCredentials(v.(bool))
+			},
+		},
+		{
+			Name:        "notify_url",
+			Type:        ItemTypeString,
+			Description: "帐号凭证失效时以 json 格式 POST 通知的地址, 仅在 server 模式下定期检查",
+			field:       func(c *PCSConfig) interface{} { return &c.NotifyURL },
+			check: func(v interface{}) error {
+				return checkNotifyURL(v.(string))
+			},
+		},
+		{
+			Name:        "user_agent",
+			Type:        ItemTypeString,
+			Suggest:     requester.DefaultUserAgent,
+			Description: "浏览器标识",
+			Restart:     true, // 当前帐号已创建的 HTTPClient 仍使用旧的浏览器标识
+			field:       func(c *PCSConfig) interface{} { return &c.UserAgent },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetUserAgent(v.(string))
+			},
+		},
+		{
+			Name:        "pcs_ua",
+			Type:        ItemTypeString,
+			Description: "PCS 浏览器标识",
+			field:       func(c *PCSConfig) interface{} { return &c.PCSUA },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetPCSUA(v.(string))
+			},
+		},
+		{
+			Name:        "pcs_addr",
+			Type:        ItemTypeString,
+			Suggest:     "pcs.baidu.com",
+			Description: "PCS 服务器地址",
+			field:       func(c *PCSConfig) interface{} { return &c.PCSAddr },
+			check: func(v interface{}) error {
+				if !validPCSAddr(v.(string)) {
+					return errors.New("pcs服务器地址不合法")
+				}
+				return nil
+			},
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SETPCSAddr(v.(string))
+			},
+		},
+		{
+			Name:        "fix_pcs_addr",
+			Type:        ItemTypeBool,
+			Suggest:     "false",
+			Description: "不使用动态PCS服务器地址, 通常情况保持默认即可",
+			field:       func(c *PCSConfig) interface{} { return &c.FixPCSAddr },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetStaticPCSAddr(v.(bool))
+			},
+		},
+		{
+			Name:        "pan_ua",
+			Type:        ItemTypeString,
+			Suggest:     baidupcs.NetdiskUA,
+			Description: "Pan 浏览器标识",
+			field:       func(c *PCSConfig) interface{} { return &c.PanUA },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetPanUA(v.(string))
+			},
+		},
+		{
+			Name:        "proxy",
+			Type:        ItemTypeString,
+			Description: "设置代理, 支持 http/socks5 代理, 显示时隐藏其中的用户名和密码",
+			field:       func(c *PCSConfig) interface{} { return &c.Proxy },
+			show:        showProxy,
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetProxy(v.(string))
+			},
+		},
+		{
+			Name:        "proxy_hostnames",
+			Type:        ItemTypeString,
+			Description: "设置走代理的域名范围, 多个域名以逗号分隔, 留空表示全部代理. 国外VPS遇上传问题可尝试代理pan.baidu.com回国",
+			field:       func(c *PCSConfig) interface{} { return &c.ProxyHostnames },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetProxyHostnames(v.(string))
+			},
+		},
+		{
+			Name:        "local_addrs",
+			Type:        ItemTypeString,
+			Description: "设置本地网卡地址, 多个地址用逗号隔开",
+			field:       func(c *PCSConfig) interface{} { return &c.LocalAddrs },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetLocalAddrs(v.(string))
+			},
+		},
+		{
+			Name:        "crypt_dir",
+			Type:        ItemTypeString,
+			Description: "网盘加密目录, 上传到其中的文件会加密, 下载时自动解密, 留空表示不加密",
+			field:       func(c *PCSConfig) interface{} { return &c.CryptDir },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetCryptDir(v.(string))
+			},
+		},
+		{
+			Name:        "crypt_password",
+			Type:        ItemTypePassword,
+			Description: "加密目录的密码, 修改后已上传的文件将无法解密",
+			field:       func(c *PCSConfig) interface{} { return &c.CryptPassword },
+			apply: func(c *PCSConfig, v interface{}) {
+				c.SetCryptPassword(v.(string))
+			},
+		},
+		{
+			Name:        "crypt_salt",
+			Type:        ItemTypeString,
+			Description: "加密目录生成密钥的 salt, 设置密码时自动生成, 迁移配置时需与密码一同保留, 修改后已上传的文件将无法解密",
+			field:       func(c *PCSConfig) interface{} { return &c.CryptSalt },
+			check: func(v interface{}) error {
+				return checkCryptSalt(v.(string))
+			},
+		},
+		{
+			Name:        "crypt_filename",
+			Type:        ItemTypeBool,
+			Suggest:     "false",
+			Description: "加密目录中同时加密文件名和目录名, 修改后已上传的文件名将无法解密",
+			field:       func(c *PCSConfig) interface{} { return &c.CryptFilename },
+		},
+		{
+			Name:        "xpan_app_key",
+			Type:        ItemTypeString,
+			Description: "百度网盘开放平台应用的 AppKey, 用于 login -xpan 授权",
+			field:       func(c *PCSConfig) interface{} { return &c.XpanAppKey },
+		},
+		{
+			Name:        "xpan_secret_key",
+			Type:        ItemTypePassword,
+			Description: "百度网盘开放平台应用的 SecretKey",
+			field:       func(c *PCSConfig) interface{} { return &c.XpanSecretKey },
+		},
+	}
+)
+
+func init() {
+	d := DefaultConfig()
+	for _, item := range Schema {
+		item.Default = item.Format(d)
+		item.Env = EnvName(item.Name)
+	}
+}
+
+// SchemaItem 返回名称为 name 的配置项定义
+func SchemaItem(name string) *ConfigItem {
+	for _, item := range Schema {
+		if item.Name == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// DefaultConfig 返回默认配置, 用于获取配置项的默认值
+func DefaultConfig() *PCSConfig {
+	c := &PCSConfig{}
+	c.InitDefaultConfig()
+	return c
+}
+
+// Value 返回配置项的值, 密码类型已设置时返回掩码, 其他敏感内容按 show 隐藏
+func (item *ConfigItem) Value(c *PCSConfig) interface{} {
+	switch v := item.field(c).(type) {
+	case *int:
+		return *v
+	case *int64:
+		return *v
+	case *bool:
+		return *v
+	case *string:
+		if item.Type == ItemTypePassword {
+			return showPassword(*v)
+		}
+		if item.show != nil {
+			return item.show(*v)
+		}
+		return *v
+	}
+	return nil
+}
+
+// Format 返回配置项用于显示的值
+func (item *ConfigItem) Format(c *PCSConfig) string {
+	v := item.Value(c)
+	switch item.Type {
+	case ItemTypeSize:
+		return converter.ConvertFileSize(toInt64(v), 2)
+	case ItemTypeRate:
+		return showMaxRate(toInt64(v))
+	}
+	return fmt.Sprint(v)
+}
+
+// Parse 解析并检查配置项的值
+func (item *ConfigItem) Parse(s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	var n int64
+	switch item.Type {
+	case ItemTypeInt:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s 不是整数", s)
+		}
+		n = int64(i)
+	case ItemTypeSize, ItemTypeRate:
+		size, err := converter.ParseFileSizeStr(stripPerSecond(s))
+		if err != nil {
+			return nil, err
+		}
+		n = size
+	case ItemTypeBool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s 不是布尔值", s)
+		}
+		return b, item.checkValue(b)
+	case ItemTypeEnum:
+		for _, option := range item.Options {
+			if s == option {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%s 不是可选值, 可选 %s", s, strings.Join(item.Options, ", "))
+	default:
+		return s, item.checkValue(s)
+	}
+
+	if n < item.Min || (item.Max > 0 && n > item.Max) {
+		return nil, fmt.Errorf("%s 超出范围", s)
+	}
+	if _, ok := item.field(&PCSConfig{}).(*int); ok {
+		return int(n), nil
+	}
+	return n, nil
+}
+
+// checkValue 检查解析后的值
+func (item *ConfigItem) checkValue(v interface{}) error {
+	if item.check == nil {
+		return nil
+	}
+	return item.check(v)
+}
+
+// masked 设置的值是否为显示时隐藏了敏感内容的值, 是则不修改
+func (item *ConfigItem) masked(c *PCSConfig, s string) bool {
+	if item.Type == ItemTypePassword {
+		return s == passwordMask
+	}
+	if item.show == nil {
+		return false
+	}
+	v, ok := item.field(c).(*string)
+	return ok && s != *v && s == item.show(*v)
+}
+
+// Set 解析并设置配置项, 影响当前连接的配置立即生效, 不保存配置文件.
+// 值为显示时隐藏了敏感内容的值 (如密码的掩码) 时不修改
+func (item *ConfigItem) Set(c *PCSConfig, s string) error {
+	if item.masked(c, s) {
+		return nil
+	}
+	v, err := item.Parse(s)
+	if err != nil {
+		return fmt.Errorf("设置 %s 错误: %s", item.Name, err)
+	}
+	if item.apply != nil {
+		item.apply(c, v)
+		return nil
+	}
+
+	switch field := item.field(c).(type) {
+	case *int:
+		*field = v.(int)
+	case *int64:
+		*field = v.(int64)
+	case *bool:
+		*field = v.(bool)
+	case *string:
+		*field = v.(string)
+	}
+	return nil
+}
+
+// SetItem 设置名称为 name 的配置项
+func (c *PCSConfig) SetItem(name, value string) error {
+	item := SchemaItem(name)
+	if item == nil {
+		return fmt.Errorf("%s: %s", ErrConfigItemNotFound, name)
+	}
+	return item.Set(c, value)
+}
+
+// SetItems 设置多个配置项, 先检查所有的值, 有错误时不修改任何配置项
+func (c *PCSConfig) SetItems(values map[string]string) error {
+	for name, value := range values {
+		item := SchemaItem(name)
+		if item == nil {
+			return fmt.Errorf("%s: %s", ErrConfigItemNotFound, name)
+		}
+		if item.masked(c, value) {
+			continue
+		}
+		if _, err := item.Parse(value); err != nil {
+			return fmt.Errorf("设置 %s 错误: %s", name, err)
+		}
+	}
+
+	// 按 Schema 的顺序设置
+	for _, item := range Schema {
+		value, ok := values[item.Name]
+		if !ok {
+			continue
+		}
+		if err := item.Set(c, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Values 返回所有配置项的值
+func (c *PCSConfig) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(Schema))
+	for _, item := range Schema {
+		values[item.Name] = item.Value(c)
+	}
+	return values
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	}
+	return 0
+}
@@ -26,6 +26,29 @@ func stripPerSecond(sizeStr string) string {
 	return sizeStr[:i]
 }
 
+// showPassword 不显示密码的内容
+func showPassword(password string) string {
+	if password == "" {
+		return ""
+	}
+	return "******"
+}
+
+// showProxy 不显示代理地址中的用户名和密码
+func showProxy(proxy string) string {
+	at := strings.LastIndex(proxy, "@")
+	if at < 0 {
+		return proxy
+	}
+	start := strings.Index(proxy, "://")
+	if start < 0 || start > at {
+		start = 0
+	} else {
+		start += len("://")
+	}
+	return proxy[:start] + "******" + proxy[at:]
+}
+
 func showMaxRate(size int64) string {
 	if size <= 0 {
 		return "不限制"
@@ -0,0 +1,147 @@
+package pcsconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+)
+
+const (
+	// XpanRefreshAhead accesstoken 在过期前多久刷新
+	XpanRefreshAhead = 3 * 24 * time.Hour
+)
+
+var (
+	// ErrXpanAppKeyNotSet 未设置开放平台应用的 AppKey 和 SecretKey
+	ErrXpanAppKeyNotSet = errors.New("未设置 xpan_app_key 和 xpan_secret_key, 请先在百度网盘开放平台创建应用")
+	// ErrXpanNotAuthorized 帐号未设置 accesstoken
+	ErrXpanNotAuthorized = errors.New("当前帐号未设置 accesstoken, 请使用 login -xpan 授权或 setastoken 设置")
+
+	xpanTokenMu sync.Mutex
+)
+
+// XpanOAuth 返回开放平台授权客户端
+func (c *PCSConfig) XpanOAuth() (*xpan.OAuth, error) {
+	if c.XpanAppKey == "" || c.XpanSecretKey == "" {
+		return nil, ErrXpanAppKeyNotSet
+	}
+	return xpan.NewOAuth(c.XpanAppKey, c.XpanSecretKey, c.HTTPClient()), nil
+}
+
+// SetXpanToken 保存 xpan 授权获得的令牌
+func (baidu *Baidu) SetXpanToken(tok *xpan.Token) {
+	xpanTokenMu.Lock()
+	defer xpanTokenMu.Unlock()
+	baidu.setXpanToken(tok)
+}
+
+func (baidu *Baidu) setXpanToken(tok *xpan.Token) {
+	baidu.AccessToken = tok.AccessToken
+	if tok.RefreshToken != "" {
+		baidu.XpanRefreshToken = tok.RefreshToken
+	}
+	baidu.XpanTokenExpires = tok.ExpiresAt
+
+	// 更新当前帐号正在使用的 BaiduPCS
+	if Config.activeUser == baidu && Config.pcs != nil {
+		Config.pcs.SetaccessToken(baidu.AccessToken)
+	}
+}
+
+// XpanToken 返回保存的令牌
+func (baidu *Baidu) XpanToken() *xpan.Token {
+	xpanTokenMu.Lock()
+	defer xpanTokenMu.Unlock()
+	return &xpan.Token{
+		AccessToken:  baidu.AccessToken,
+		RefreshToken: baidu.XpanRefreshToken,
+		ExpiresAt:    baidu.XpanTokenExpires,
+	}
+}
+
+// XpanAccessToken 返回 accesstoken, 即将过期且有 refresh_token 时先刷新并保存配置
+func (baidu *Baidu) XpanAccessToken(ctx context.Context) (string, error) {
+	_, err := baidu.RefreshXpanToken(ctx, false)
+	if err != nil {
+		pcsConfigVerbose.Warnf("refresh xpan token: %s\n", err)
+	}
+
+	xpanTokenMu.Lock()
+	defer xpanTokenMu.Unlock()
+	if baidu.AccessToken == "" {
+		return "", ErrXpanNotAuthorized
+	}
+	if err != nil && baidu.XpanTokenExpires > 0 && time.Now().Unix() >= baidu.XpanTokenExpires {
+		// 已过期且刷新失败
+		return "", err
+	}
+	return baidu.AccessToken, nil
+}
+
+// RefreshXpanToken 刷新 accesstoken, force 为 false 时只在即将过期时刷新, 返回是否刷新
+func (baidu *Baidu) RefreshXpanToken(ctx context.Context, force bool) (bool, error) {
+	xpanTokenMu.Lock()
+	defer xpanTokenMu.Unlock()
+
+	if baidu.XpanRefreshToken == "" {
+		return false, nil
+	}
+	tok := xpan.Token{ExpiresAt: baidu.XpanTokenExpires}
+	if !force && !tok.Expired(XpanRefreshAhead) {
+		return false, nil
+	}
+
+	oauth, err := Config.XpanOAuth()
+	if err != nil {
+		return false, err
+	}
+	newTok, err := oauth.Refresh(ctx, baidu.XpanRefreshToken)
+	if err != nil {
+		return false, err
+	}
+	baidu.setXpanToken(newTok)
+	pcsConfigVerbose.Infof("xpan token refreshed, uid: %d, expires at: %d\n", baidu.UID, baidu.XpanTokenExpires)
+
+	// refresh_token 只能使用一次, 需要立即保存
+	return true, Config.Save()
+}
+
+// RefreshXpanTokens 刷新所有即将过期的 accesstoken, 返回刷新的帐号数量
+func (c *PCSConfig) RefreshXpanTokens(ctx context.Context) (n int, err error) {
+	for _, baidu := range c.Users() {
+		refreshed, rerr := baidu.RefreshXpanToken(ctx, false)
+		if rerr != nil {
+			err = rerr
+			continue
+		}
+		if refreshed {
+			n++
+		}
+	}
+	return n, err
+}
+
+// StartXpanTokenRefresher 在后台定期刷新即将过期的 accesstoken, 直到 ctx 取消
+func (c *PCSConfig) StartXpanTokenRefresher(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := c.RefreshXpanTokens(ctx)
+			if err != nil {
+				pcsConfigVerbose.Warnf("refresh xpan tokens: %s\n", err)
+			} else if n > 0 {
+				pcsConfigVerbose.Infof("refreshed %d xpan tokens\n", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,173 @@
+// Package pcschanges 网盘文件变更, 按账号保存 cursor
+package pcschanges
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+)
+
+const (
+	// CursorFileName 持久化的 cursor 文件名
+	CursorFileName = "pcs_changes.json"
+)
+
+type (
+	// CursorEntry 账号上次读取变更的位置
+	CursorEntry struct {
+		Cursor    string `json:"cursor"`
+		UpdatedAt int64  `json:"updated_at"`
+	}
+
+	// cursorData cursor 文件的内容, key 为 uid
+	cursorData struct {
+		Accounts map[string]*CursorEntry `json:"accounts"`
+	}
+)
+
+var (
+	cursorMu sync.Mutex
+)
+
+// cursorFilePath 返回 cursor 文件路径
+func cursorFilePath() string {
+	return filepath.Join(pcsconfig.GetConfigDir(), CursorFileName)
+}
+
+// loadCursorData 读取 cursor 文件, 调用前需加锁
+func loadCursorData() (*cursorData, error) {
+	data := &cursorData{}
+	f, err := os.Open(cursorFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > 0 {
+		err = jsonhelper.UnmarshalData(f, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// LoadCursor 读取账号保存的 cursor, 不存在时返回空
+func LoadCursor(uid uint64) (string, error) {
+	cursorMu.Lock()
+	defer cursorMu.Unlock()
+
+	data, err := loadCursorData()
+	if err != nil {
+		return "", err
+	}
+	entry := data.Accounts[strconv.FormatUint(uid, 10)]
+	if entry == nil {
+		return "", nil
+	}
+	return entry.Cursor, nil
+}
+
+// SaveCursor 保存账号的 cursor, cursor 为空时删除, 先写入临时文件再替换
+func SaveCursor(uid uint64, cursor string) error {
+	cursorMu.Lock()
+	defer cursorMu.Unlock()
+
+	data, err := loadCursorData()
+	if err != nil {
+		return err
+	}
+	if data.Accounts == nil {
+		data.Accounts = map[string]*CursorEntry{}
+	}
+	key := strconv.FormatUint(uid, 10)
+	if cursor == "" {
+		delete(data.Accounts, key)
+	} else {
+		data.Accounts[key] = &CursorEntry{
+			Cursor:    cursor,
+			UpdatedAt: time.Now().Unix(),
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = jsonhelper.MarshalData(buf, data)
+	if err != nil {
+		return err
+	}
+
+	filename := cursorFilePath()
+	tmp := filename + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// LatestCursor 获取网盘当前的 cursor, 会翻页读取并丢弃从头开始的全部文件列表
+func LatestCursor(ctx context.Context, pcs *baidupcs.BaiduPCS) (string, error) {
+	cursor := ""
+	for {
+		diff, pcsError := pcs.FilesDirectoriesDiffContext(ctx, cursor)
+		if pcsError != nil {
+			return "", pcsError
+		}
+		cursor = diff.Cursor
+		if !diff.HasMore || cursor == "" {
+			return cursor, nil
+		}
+	}
+}
+
+// Fetch 获取账号在 cursor 之后的一页变更, 并保存返回的 cursor.
+// cursor 为空时使用账号保存的 cursor, 都为空时只获取网盘当前的 cursor, 返回的变更为空
+func Fetch(ctx context.Context, pcs *baidupcs.BaiduPCS, uid uint64, cursor string) (*baidupcs.FileDirectoryDiff, error) {
+	var err error
+	if cursor == "" {
+		cursor, err = LoadCursor(uid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var diff *baidupcs.FileDirectoryDiff
+	if cursor == "" {
+		cursor, err = LatestCursor(ctx, pcs)
+		if err != nil {
+			return nil, err
+		}
+		diff = &baidupcs.FileDirectoryDiff{
+			Cursor: cursor,
+		}
+	} else {
+		var pcsError error
+		diff, pcsError = pcs.FilesDirectoriesDiffContext(ctx, cursor)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+	}
+
+	if diff.Cursor != "" {
+		err = SaveCursor(uid, diff.Cursor)
+		if err != nil {
+			return diff, err
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,217 @@
+// Package pcscrypt 网盘加密目录, 上传时加密文件内容和文件名, 下载时解密, 类似 rclone crypt.
+//
+// 文件内容按 64 KiB 分块, 每块使用 XSalsa20-Poly1305 (secretbox) 加密并认证, 与 rclone crypt 相同.
+// 加密后的文件以 32 字节的文件头开始, 包含随机的 nonce, 每块使用的 nonce 为文件 nonce 加上块序号.
+// 文件名使用确定性的加密 (HMAC-SHA256 生成 IV, AES-256-CTR 加密), 相同的文件名加密结果相同, 编码为小写的 base32hex.
+// 密钥由密码和配置中随机生成的 salt 经 scrypt 生成.
+package pcscrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+type (
+	// Cipher 加密和解密文件内容及文件名
+	Cipher struct {
+		data    [32]byte     // 文件内容
+		name    cipher.Block // 文件名
+		nameMAC []byte       // 生成文件名的 IV
+	}
+)
+
+const (
+	// Magic 加密文件头的标识
+	Magic = "BPCSCRY1"
+	// NonceSize 文件头中 nonce 的长度
+	NonceSize = 24
+	// HeaderSize 文件头的长度
+	HeaderSize = len(Magic) + NonceSize
+
+	// BlockSize 每块明文的长度
+	BlockSize = 64 * 1024
+	// BlockOverhead 每块密文比明文多出的认证标签长度
+	BlockOverhead = secretbox.Overhead
+	// EncryptedBlockSize 每块密文的长度, 最后一块可能较短
+	EncryptedBlockSize = BlockSize + BlockOverhead
+
+	// SaltSize 生成的 salt 的长度
+	SaltSize = 16
+
+	// MaxNameLength 加密后文件名的最大长度
+	MaxNameLength = 255
+)
+
+var (
+	// ErrEmptyPassword 未设置密码
+	ErrEmptyPassword = errors.New("crypt password is empty")
+	// ErrEmptySalt 未设置 salt
+	ErrEmptySalt = errors.New("crypt salt is empty")
+	// ErrBadHeader 不是加密文件
+	ErrBadHeader = errors.New("not an encrypted file")
+	// ErrBadSize 密文长度不是有效的加密文件长度
+	ErrBadSize = errors.New("invalid encrypted file size")
+	// ErrAuthFailed 密文块认证失败, 文件已损坏或密码不正确
+	ErrAuthFailed = errors.New("block authentication failed, file corrupted or wrong password")
+	// ErrBadName 不是加密的文件名, 或密码不正确
+	ErrBadName = errors.New("not an encrypted name or wrong password")
+	// ErrNameTooLong 加密后的文件名过长
+	ErrNameTooLong = errors.New("encrypted name too long")
+
+	nameEncoding = base32.NewEncoding("0123456789abcdefghijklmnopqrstuv").WithPadding(base32.NoPadding)
+)
+
+// NewCipher 由密码和 salt 生成 Cipher, 使用 scrypt 生成密钥, 耗时较长, 调用方应复用返回的 Cipher
+func NewCipher(password string, salt []byte) (*Cipher, error) {
+	if password == "" {
+		return nil, ErrEmptyPassword
+	}
+	if len(salt) == 0 {
+		return nil, ErrEmptySalt
+	}
+	key, err := scrypt.Key([]byte(password), salt, 1<<15, 8, 1, 96)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cipher{
+		nameMAC: key[64:],
+	}
+	copy(c.data[:], key[:32])
+	c.name, err = aes.NewCipher(key[32:64])
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewSalt 生成随机的 salt, 每份配置使用不同的 salt
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// EncryptedSize 返回加密后的文件大小
+func EncryptedSize(size int64) int64 {
+	blocks, rest := size/BlockSize, size%BlockSize
+	encrypted := int64(HeaderSize) + blocks*EncryptedBlockSize
+	if rest > 0 {
+		encrypted += rest + BlockOverhead
+	}
+	return encrypted
+}
+
+// DecryptedSize 返回解密后的文件大小, size 不是有效的加密文件大小时返回 ErrBadSize
+func DecryptedSize(size int64) (int64, error) {
+	size -= int64(HeaderSize)
+	if size < 0 {
+		return 0, ErrBadSize
+	}
+	blocks, rest := size/EncryptedBlockSize, size%EncryptedBlockSize
+	if rest == 0 {
+		return blocks * BlockSize, nil
+	}
+	if rest <= BlockOverhead {
+		return 0, ErrBadSize
+	}
+	return blocks*BlockSize + rest - BlockOverhead, nil
+}
+
+// NewNonce 生成随机的 nonce, 每个文件使用不同的 nonce
+func NewNonce() ([]byte, error) {
+	nonce := make([]byte, NonceSize)
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// Header 返回包含 nonce 的文件头
+func Header(nonce []byte) []byte {
+	return append([]byte(Magic), nonce...)
+}
+
+// ParseHeader 解析文件头, 返回 nonce
+func ParseHeader(header []byte) ([]byte, error) {
+	if len(header) < HeaderSize || !bytes.Equal(header[:len(Magic)], []byte(Magic)) {
+		return nil, ErrBadHeader
+	}
+	return header[len(Magic):HeaderSize], nil
+}
+
+// blockNonce 返回第 index 块使用的 nonce, 为文件 nonce 按小端序加上块序号
+func blockNonce(nonce []byte, index int64) *[NonceSize]byte {
+	var n [NonceSize]byte
+	copy(n[:], nonce)
+	carry := uint64(index)
+	for i := 0; i < NonceSize && carry > 0; i++ {
+		sum := uint64(n[i]) + carry&0xff
+		n[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+	return &n
+}
+
+// sealBlock 加密第 index 块明文, 追加到 dst
+func (c *Cipher) sealBlock(dst, plain, nonce []byte, index int64) []byte {
+	return secretbox.Seal(dst, plain, blockNonce(nonce, index), &c.data)
+}
+
+// openBlock 解密并认证第 index 块密文, 追加到 dst
+func (c *Cipher) openBlock(dst, sealed, nonce []byte, index int64) ([]byte, error) {
+	plain, ok := secretbox.Open(dst, sealed, blockNonce(nonce, index), &c.data)
+	if !ok {
+		return nil, ErrAuthFailed
+	}
+	return plain, nil
+}
+
+// EncryptName 加密单个文件名
+func (c *Cipher) EncryptName(name string) (string, error) {
+	mac := hmac.New(sha256.New, c.nameMAC)
+	mac.Write([]byte(name))
+	iv := mac.Sum(nil)[:aes.BlockSize]
+
+	buf := make([]byte, aes.BlockSize+len(name))
+	copy(buf, iv)
+	cipher.NewCTR(c.name, iv).XORKeyStream(buf[aes.BlockSize:], []byte(name))
+	encrypted := nameEncoding.EncodeToString(buf)
+	if len(encrypted) > MaxNameLength {
+		return "", fmt.Errorf("%w: %s", ErrNameTooLong, name)
+	}
+	return encrypted, nil
+}
+
+// DecryptName 解密单个文件名, 同时校验文件名是否由当前密码加密
+func (c *Cipher) DecryptName(encrypted string) (string, error) {
+	buf, err := nameEncoding.DecodeString(strings.ToLower(encrypted))
+	if err != nil || len(buf) <= aes.BlockSize {
+		return "", ErrBadName
+	}
+	iv := buf[:aes.BlockSize]
+	name := make([]byte, len(buf)-aes.BlockSize)
+	cipher.NewCTR(c.name, iv).XORKeyStream(name, buf[aes.BlockSize:])
+
+	mac := hmac.New(sha256.New, c.nameMAC)
+	mac.Write(name)
+	if !hmac.Equal(mac.Sum(nil)[:aes.BlockSize], iv) {
+		return "", ErrBadName
+	}
+	return string(name), nil
+}
@@ -0,0 +1,191 @@
+package pcscrypt
+
+import (
+	"encoding/hex"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+)
+
+type (
+	// Remote 网盘中的加密目录, 目录中的文件内容都会加密, EncryptNames 时文件名和子目录名也会加密
+	Remote struct {
+		*Cipher
+		Dir          string
+		EncryptNames bool
+	}
+)
+
+var (
+	cryptVerbose = pcsverbose.New("CRYPT")
+
+	cacheMu      sync.Mutex
+	cachedKey    string
+	cachedCipher *Cipher
+	cachedErr    error
+)
+
+// Active 根据配置返回加密目录, 未设置 crypt_dir 或 crypt_password 时返回 nil
+func Active() *Remote {
+	cfg := pcsconfig.Config
+	if cfg.CryptDir == "" || cfg.CryptPassword == "" {
+		return nil
+	}
+
+	cacheMu.Lock()
+	if key := cfg.CryptPassword + "\x00" + cfg.CryptSalt; cachedCipher == nil || cachedKey != key {
+		cachedKey = key
+		salt, err := hex.DecodeString(cfg.CryptSalt)
+		if err != nil {
+			cachedCipher, cachedErr = nil, err
+		} else {
+			cachedCipher, cachedErr = NewCipher(cfg.CryptPassword, salt)
+		}
+	}
+	c, err := cachedCipher, cachedErr
+	cacheMu.Unlock()
+	if err != nil {
+		cryptVerbose.Warnf("init cipher: %s\n", err)
+		return nil
+	}
+
+	return &Remote{
+		Cipher:       c,
+		Dir:          path.Clean("/" + cfg.CryptDir),
+		EncryptNames: cfg.CryptFilename,
+	}
+}
+
+// InDir 网盘路径是否位于配置的加密目录中, 未设置密码时也会判断
+func InDir(p string) bool {
+	dir := pcsconfig.Config.CryptDir
+	if dir == "" {
+		return false
+	}
+	r := &Remote{Dir: path.Clean("/" + dir)}
+	return r.Contains(path.Clean("/" + p))
+}
+
+// Contains 网盘路径是否位于加密目录中, 加密目录本身不算
+func (r *Remote) Contains(p string) bool {
+	if r.Dir == baidupcs.PathSeparator {
+		return p != baidupcs.PathSeparator
+	}
+	return strings.HasPrefix(p, r.Dir+baidupcs.PathSeparator)
+}
+
+// mapPath 对加密目录中的每一级文件名执行 fn
+func (r *Remote) mapPath(p string, fn func(name string) (string, error)) (string, error) {
+	p = path.Clean(p)
+	if !r.EncryptNames || !r.Contains(p) {
+		return p, nil
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(p, r.Dir), baidupcs.PathSeparator)
+	if rel == "" {
+		return p, nil
+	}
+	names := strings.Split(rel, baidupcs.PathSeparator)
+	for i, name := range names {
+		var err error
+		names[i], err = fn(name)
+		if err != nil {
+			return "", err
+		}
+	}
+	return path.Join(r.Dir, strings.Join(names, baidupcs.PathSeparator)), nil
+}
+
+// EncryptPath 将明文路径转换为网盘中的路径, 已加密的文件名保持不变, 可以重复调用
+func (r *Remote) EncryptPath(p string) (string, error) {
+	return r.mapPath(p, func(name string) (string, error) {
+		if _, err := r.DecryptName(name); err == nil {
+			return name, nil
+		}
+		return r.EncryptName(name)
+	})
+}
+
+// DecryptPath 将网盘中的路径转换为明文路径, 无法解密的文件名保持不变
+func (r *Remote) DecryptPath(p string) string {
+	p, _ = r.mapPath(p, func(name string) (string, error) {
+		return r.PlainName(name), nil
+	})
+	return p
+}
+
+// PlainName 解密文件名, 无法解密时返回原文件名
+func (r *Remote) PlainName(name string) string {
+	if !r.EncryptNames {
+		return name
+	}
+	plain, err := r.DecryptName(name)
+	if err != nil {
+		return name
+	}
+	return plain
+}
+
+// DecryptFileDirectory 将网盘文件信息中的路径和文件名转换为明文.
+// 加密目录中也可能有未加密的文件, 列出文件时不读取文件头, 大小保持为网盘中的大小
+func (r *Remote) DecryptFileDirectory(fd *baidupcs.FileDirectory) {
+	if !r.Contains(fd.Path) {
+		return
+	}
+	fd.Path = r.DecryptPath(fd.Path)
+	fd.Filename = path.Base(fd.Path)
+}
+
+// RemotePath 按配置将明文路径转换为网盘中的路径, 未启用加密或转换失败时返回原路径
+func RemotePath(p string) string {
+	r := Active()
+	if r == nil {
+		return p
+	}
+	remote, err := r.EncryptPath(p)
+	if err != nil {
+		cryptVerbose.Warnf("%s\n", err)
+		return p
+	}
+	return remote
+}
+
+// PlainPath 按配置将网盘中的路径转换为明文路径
+func PlainPath(p string) string {
+	r := Active()
+	if r == nil {
+		return p
+	}
+	return r.DecryptPath(p)
+}
+
+// PlainName 按配置解密文件名, 相对路径会逐级解密
+func PlainName(name string) string {
+	r := Active()
+	if r == nil {
+		return name
+	}
+	names := strings.Split(name, baidupcs.PathSeparator)
+	for i := range names {
+		names[i] = r.PlainName(names[i])
+	}
+	return strings.Join(names, baidupcs.PathSeparator)
+}
+
+// DecryptList 按配置将文件列表转换为明文, 返回新的列表, 不修改可能被缓存的原列表
+func DecryptList(fdl baidupcs.FileDirectoryList) baidupcs.FileDirectoryList {
+	r := Active()
+	if r == nil {
+		return fdl
+	}
+	list := make(baidupcs.FileDirectoryList, len(fdl))
+	for i, fd := range fdl {
+		plain := *fd
+		r.DecryptFileDirectory(&plain)
+		list[i] = &plain
+	}
+	return list
+}
@@ -0,0 +1,259 @@
+package pcscrypt
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+type (
+	// ReadAtCloser 可从任意位置读取的文件
+	ReadAtCloser interface {
+		io.ReaderAt
+		io.Closer
+	}
+
+	// Encrypter 加密读取明文文件, 读到的内容为文件头和密文, 不产生临时文件
+	Encrypter struct {
+		c         *Cipher
+		f         ReadAtCloser
+		header    []byte
+		nonce     []byte
+		plainSize int64 // 明文大小
+		size      int64 // 加密后的大小
+
+		mu  sync.Mutex
+		pos int64
+
+		blockMu    sync.Mutex
+		blockIndex int64  // 已加密的块序号, 为 -1 时没有
+		block      []byte // 已加密的块
+		plain      []byte // 读取明文块的缓冲
+	}
+
+	// Decrypter 解密写入, 写入位置为密文中的位置, 文件头之前的内容会被忽略.
+	// 密文块接收完整并通过认证后才解密写入, 未接收完整的块保存在内存中
+	Decrypter struct {
+		c     *Cipher
+		w     io.WriterAt
+		nonce []byte
+		size  int64 // 密文大小, 包含文件头
+
+		mu      sync.Mutex
+		pending map[int64]*pendingBlock
+	}
+
+	// pendingBlock 未接收完整的密文块
+	pendingBlock struct {
+		data   []byte
+		filled []uint64 // 已接收的字节, 每位对应一个字节
+		count  int      // 已接收的字节数
+	}
+)
+
+var (
+	// ErrInvalidSeek 无效的 Seek 位置
+	ErrInvalidSeek = errors.New("invalid seek position")
+	// ErrIncompleteBlock 下载结束时仍有未接收完整的密文块
+	ErrIncompleteBlock = errors.New("incomplete encrypted block")
+)
+
+// NewEncrypter 使用 nonce 加密明文文件 f, size 为明文大小.
+// 同一个文件每次上传应使用相同的 nonce, 以保证计算的 md5 和上传的内容一致
+func (c *Cipher) NewEncrypter(f ReadAtCloser, size int64, nonce []byte) *Encrypter {
+	return &Encrypter{
+		c:          c,
+		f:          f,
+		header:     Header(nonce),
+		nonce:      nonce,
+		plainSize:  size,
+		size:       EncryptedSize(size),
+		blockIndex: -1,
+	}
+}
+
+// ReadAt 从密文的 off 位置读取
+func (e *Encrypter) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrInvalidSeek
+	}
+	if off >= e.size {
+		return 0, io.EOF
+	}
+	if off < int64(HeaderSize) {
+		n = copy(p, e.header[off:])
+		off += int64(n)
+	}
+
+	e.blockMu.Lock()
+	defer e.blockMu.Unlock()
+	for n < len(p) && off < e.size {
+		pos := off - int64(HeaderSize) // 在密文块中的位置
+		err = e.sealBlock(pos / EncryptedBlockSize)
+		if err != nil {
+			return n, err
+		}
+		m := copy(p[n:], e.block[pos%EncryptedBlockSize:])
+		n += m
+		off += int64(m)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// sealBlock 读取并加密第 index 块明文, 调用方需持有 blockMu
+func (e *Encrypter) sealBlock(index int64) error {
+	if e.blockIndex == index {
+		return nil
+	}
+	start := index * BlockSize
+	size := min(BlockSize, e.plainSize-start)
+	if e.plain == nil {
+		e.plain = make([]byte, BlockSize)
+	}
+	m, err := e.f.ReadAt(e.plain[:size], start)
+	if int64(m) < size {
+		if err == nil || err == io.EOF {
+			// 文件在读取期间变短
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	e.block = e.c.sealBlock(e.block[:0], e.plain[:size], e.nonce, index)
+	e.blockIndex = index
+	return nil
+}
+
+// Read 顺序读取
+func (e *Encrypter) Read(p []byte) (n int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n, err = e.ReadAt(p, e.pos)
+	e.pos += int64(n)
+	return n, err
+}
+
+// Seek 设置顺序读取的位置
+func (e *Encrypter) Seek(offset int64, whence int) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += e.pos
+	case io.SeekEnd:
+		offset += e.size
+	default:
+		return 0, ErrInvalidSeek
+	}
+	if offset < 0 {
+		return 0, ErrInvalidSeek
+	}
+	e.pos = offset
+	return offset, nil
+}
+
+// Len 返回加密后的大小
+func (e *Encrypter) Len() int64 {
+	return e.size
+}
+
+// Close 关闭明文文件
+func (e *Encrypter) Close() error {
+	return e.f.Close()
+}
+
+// NewDecrypter 使用文件头中的 nonce 解密后写入 w, size 为密文大小
+func (c *Cipher) NewDecrypter(w io.WriterAt, nonce []byte, size int64) *Decrypter {
+	return &Decrypter{
+		c:       c,
+		w:       w,
+		nonce:   nonce,
+		size:    size,
+		pending: map[int64]*pendingBlock{},
+	}
+}
+
+// SetWriter 设置解密后写入的位置, 用于重试时重新打开文件, 未接收完整的块会保留
+func (d *Decrypter) SetWriter(w io.WriterAt) {
+	d.mu.Lock()
+	d.w = w
+	d.mu.Unlock()
+}
+
+// WriteAt 接收密文中 off 位置的内容, 块接收完整后解密并写入明文的对应位置
+func (d *Decrypter) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < int64(HeaderSize) {
+		skip := int(min(int64(HeaderSize)-off, int64(len(p))))
+		p, off, n = p[skip:], off+int64(skip), skip
+	}
+
+	for len(p) > 0 {
+		pos := off - int64(HeaderSize) // 在密文块中的位置
+		index, start := pos/EncryptedBlockSize, int(pos%EncryptedBlockSize)
+		m := min(len(p), d.blockLen(index)-start)
+		if m <= 0 {
+			return n, ErrBadSize
+		}
+		err = d.fill(index, start, p[:m])
+		if err != nil {
+			return n, err
+		}
+		p, off, n = p[m:], off+int64(m), n+m
+	}
+	return n, nil
+}
+
+// Finish 检查所有密文块都已解密写入
+func (d *Decrypter) Finish() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.pending) > 0 {
+		return ErrIncompleteBlock
+	}
+	return nil
+}
+
+// blockLen 返回第 index 块密文的长度
+func (d *Decrypter) blockLen(index int64) int {
+	return int(min(EncryptedBlockSize, d.size-int64(HeaderSize)-index*EncryptedBlockSize))
+}
+
+// fill 将密文写入第 index 块的 start 位置, 块接收完整后解密写入
+func (d *Decrypter) fill(index int64, start int, p []byte) error {
+	d.mu.Lock()
+	b := d.pending[index]
+	if b == nil {
+		size := d.blockLen(index)
+		b = &pendingBlock{
+			data:   make([]byte, size),
+			filled: make([]uint64, (size+63)/64),
+		}
+		d.pending[index] = b
+	}
+	copy(b.data[start:], p)
+	for i := start; i < start+len(p); i++ {
+		if bit := uint64(1) << (i % 64); b.filled[i/64]&bit == 0 {
+			b.filled[i/64] |= bit
+			b.count++
+		}
+	}
+	complete := b.count == len(b.data)
+	if complete {
+		delete(d.pending, index)
+	}
+	w := d.w
+	d.mu.Unlock()
+	if !complete {
+		return nil
+	}
+
+	plain, err := d.c.openBlock(nil, b.data, d.nonce, index)
+	if err != nil {
+		return err
+	}
+	_, err = w.WriteAt(plain, index*BlockSize)
+	return err
+}
@@ -0,0 +1,149 @@
+package pcscrypt
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// memFile 内存中的文件
+type memFile struct {
+	data []byte
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	if end := int(off) + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+	return copy(f.data[off:], p), nil
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+func newTestCipher(t *testing.T) *Cipher {
+	c, err := NewCipher("password", []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSize(t *testing.T) {
+	for _, size := range []int64{0, 1, BlockSize - 1, BlockSize, BlockSize + 1, 3*BlockSize + 100} {
+		got, err := DecryptedSize(EncryptedSize(size))
+		if err != nil || got != size {
+			t.Errorf("DecryptedSize(EncryptedSize(%d)) = %d, %v", size, got, err)
+		}
+	}
+	if _, err := DecryptedSize(int64(HeaderSize) + BlockOverhead); err != ErrBadSize {
+		t.Errorf("block without data should be invalid, got %v", err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := newTestCipher(t)
+	nonce, err := NewNonce()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain := make([]byte, 3*BlockSize+1234)
+	rand.Read(plain)
+	e := c.NewEncrypter(&memFile{data: plain}, int64(len(plain)), nonce)
+	encrypted, err := io.ReadAll(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(encrypted)) != e.Len() {
+		t.Fatalf("encrypted length %d, want %d", len(encrypted), e.Len())
+	}
+
+	// 随机位置读取与顺序读取一致
+	part := make([]byte, 1000)
+	if _, err = e.ReadAt(part, BlockSize+10); err != nil || !bytes.Equal(part, encrypted[BlockSize+10:BlockSize+1010]) {
+		t.Fatalf("ReadAt mismatch: %v", err)
+	}
+
+	// 分段乱序写入, 段的边界不与块对齐
+	out := &memFile{}
+	d := c.NewDecrypter(out, nonce, int64(len(encrypted)))
+	var bounds []int
+	for off := 0; off < len(encrypted); off += 7777 {
+		bounds = append(bounds, off)
+	}
+	for _, i := range rand.Perm(len(bounds)) {
+		end := min(bounds[i]+7777, len(encrypted))
+		if _, err = d.WriteAt(encrypted[bounds[i]:end], int64(bounds[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = d.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.data, plain) {
+		t.Fatal("decrypted data mismatch")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	c := newTestCipher(t)
+	nonce, _ := NewNonce()
+	plain := bytes.Repeat([]byte("a"), BlockSize+10)
+	encrypted, err := io.ReadAll(c.NewEncrypter(&memFile{data: plain}, int64(len(plain)), nonce))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted[HeaderSize+5] ^= 1
+
+	d := c.NewDecrypter(&memFile{}, nonce, int64(len(encrypted)))
+	if _, err = d.WriteAt(encrypted, 0); err != ErrAuthFailed {
+		t.Fatalf("tampered block: got %v, want %v", err, ErrAuthFailed)
+	}
+}
+
+func TestDecryptIncomplete(t *testing.T) {
+	c := newTestCipher(t)
+	nonce, _ := NewNonce()
+	plain := make([]byte, 100)
+	encrypted, _ := io.ReadAll(c.NewEncrypter(&memFile{data: plain}, int64(len(plain)), nonce))
+
+	d := c.NewDecrypter(&memFile{}, nonce, int64(len(encrypted)))
+	d.WriteAt(encrypted[:len(encrypted)-1], 0)
+	if err := d.Finish(); err != ErrIncompleteBlock {
+		t.Fatalf("got %v, want %v", err, ErrIncompleteBlock)
+	}
+}
+
+func TestEncrypterFileShrunk(t *testing.T) {
+	c := newTestCipher(t)
+	nonce, _ := NewNonce()
+	name := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(name, make([]byte, 10), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := c.NewEncrypter(f, 100, nonce)
+	defer e.Close()
+	if _, err = io.ReadAll(e); err != io.ErrUnexpectedEOF {
+		t.Fatalf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,82 @@
+package pcsdownload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+// prepareDecrypt 下载加密目录中的文件时, 读取文件头中的 nonce, 下载时解密写入.
+// 不在加密目录中或文件头不匹配时, 按原样下载
+func (dtu *DownloadTaskUnit) prepareDecrypt(ctx context.Context, downloadURL string, client *requester.HTTPClient) error {
+	r := pcscrypt.Active()
+	if r == nil || !r.Contains(dtu.PcsPath) || dtu.FileInfo.Size < int64(pcscrypt.HeaderSize) {
+		dtu.crypt, dtu.nonce, dtu.decrypter = nil, nil, nil
+		return nil
+	}
+
+	resp, err := client.ReqContext(ctx, http.MethodGet, downloadURL, nil, map[string]string{
+		"Range": "bytes=0-" + strconv.Itoa(pcscrypt.HeaderSize-1),
+	})
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("%s, 读取加密文件头错误: %s", StrDownloadInitError, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("%s, 读取加密文件头错误: %s", StrDownloadInitError, resp.Status)
+	}
+	header := make([]byte, pcscrypt.HeaderSize)
+	_, err = io.ReadFull(resp.Body, header)
+	if err != nil {
+		return fmt.Errorf("%s, 读取加密文件头错误: %s", StrDownloadInitError, err)
+	}
+
+	nonce, err := pcscrypt.ParseHeader(header)
+	if err != nil {
+		// 不是加密上传的文件
+		dtu.verboseInfof("[%s] %s: %s, 不解密\n", dtu.taskInfo.Id(), dtu.PcsPath, err)
+		dtu.crypt, dtu.nonce, dtu.decrypter = nil, nil, nil
+		return nil
+	}
+	if _, err = pcscrypt.DecryptedSize(dtu.FileInfo.Size); err != nil {
+		return fmt.Errorf("%s, %s", StrDownloadInitError, err)
+	}
+
+	if dtu.decrypter == nil || !bytes.Equal(dtu.nonce, nonce) {
+		// 未接收完整的密文块只保存在内存中, 不能从之前的进程保存的断点继续下载
+		os.Remove(dtu.Cfg.InstanceStatePath)
+		dtu.decrypter = nil
+	}
+	dtu.crypt, dtu.nonce = r.Cipher, nonce
+	return nil
+}
+
+// decryptWriter 返回解密后写入 writer 的 Decrypter, 重试时沿用未接收完整的密文块
+func (dtu *DownloadTaskUnit) decryptWriter(writer io.WriterAt) *pcscrypt.Decrypter {
+	if dtu.decrypter == nil {
+		dtu.decrypter = dtu.crypt.NewDecrypter(writer, dtu.nonce, dtu.FileInfo.Size)
+	} else {
+		dtu.decrypter.SetWriter(writer)
+	}
+	return dtu.decrypter
+}
+
+// fileSize 返回下载后本地文件的大小, 解密时为明文的大小
+func (dtu *DownloadTaskUnit) fileSize() int64 {
+	if dtu.crypt != nil {
+		size, err := pcscrypt.DecryptedSize(dtu.FileInfo.Size)
+		if err == nil {
+			return size
+		}
+	}
+	return dtu.FileInfo.Size
+}
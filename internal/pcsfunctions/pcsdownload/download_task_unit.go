@@ -1,12 +1,14 @@
 package pcsdownload
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcstable"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
@@ -20,6 +22,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -53,6 +56,14 @@ type (
 		SavePath string // 保存的路径
 
 		FileInfo *baidupcs.FileDirectory // 文件或目录详情
+
+		crypt     *pcscrypt.Cipher    // 解密文件内容, 不是加密文件时为 nil
+		nonce     []byte              // 加密文件头中的 nonce
+		decrypter *pcscrypt.Decrypter // 解密写入, 重试时保留未接收完整的密文块
+
+		pauseMu sync.Mutex
+		paused  bool                   // 是否已暂停
+		der     *downloader.Downloader // 正在执行的下载器
 	}
 )
 
@@ -94,7 +105,7 @@ func (dtu *DownloadTaskUnit) verboseInfof(format string, a ...interface{}) {
 }
 
 // download 执行下载
-func (dtu *DownloadTaskUnit) download(downloadURL string, client *requester.HTTPClient) (err error) {
+func (dtu *DownloadTaskUnit) download(ctx context.Context, downloadURL string, client *requester.HTTPClient) (err error) {
 	var (
 		writer downloader.Writer
 		file   *os.File
@@ -119,12 +130,22 @@ func (dtu *DownloadTaskUnit) download(downloadURL string, client *requester.HTTP
 			return fmt.Errorf("%s, path %s: not a directory", StrDownloadInitError, dir)
 		}
 
+		err = dtu.prepareDecrypt(ctx, downloadURL, client)
+		if err != nil {
+			return err
+		}
+
 		// 打开文件
 		writer, file, err = downloader.NewDownloaderWriterByFilename(dtu.SavePath, os.O_CREATE|os.O_WRONLY, 0666)
 		if err != nil {
 			return fmt.Errorf("%s, %s", StrDownloadInitError, err)
 		}
 		defer file.Close()
+
+		if dtu.crypt != nil {
+			// 下载的是密文, 解密后写入文件
+			writer = dtu.decryptWriter(writer)
+		}
 	}
 
 	der := downloader.NewDownloader(downloadURL, writer, dtu.Cfg)
@@ -190,9 +211,18 @@ func (dtu *DownloadTaskUnit) download(downloadURL string, client *requester.HTTP
 		if dtu.Cfg.IsTest {
 			fmt.Printf("[%s] 测试下载开始\n\n", dtu.taskInfo.Id())
 		}
+		// 开始前已被暂停
+		if dtu.IsPaused() {
+			der.Pause()
+		}
 	})
 
-	err = der.Execute()
+	dtu.setDownloader(der)
+	err = der.ExecuteContext(ctx)
+	dtu.setDownloader(nil)
+	if err == nil && dtu.crypt != nil && !dtu.Cfg.IsTest {
+		err = dtu.decrypter.Finish()
+	}
 	isComplete = true
 	fmt.Print("\n")
 
@@ -231,6 +261,40 @@ func (dtu *DownloadTaskUnit) download(downloadURL string, client *requester.HTTP
 	return nil
 }
 
+func (dtu *DownloadTaskUnit) setDownloader(der *downloader.Downloader) {
+	dtu.pauseMu.Lock()
+	dtu.der = der
+	dtu.pauseMu.Unlock()
+}
+
+// Pause 暂停下载, 断点信息会立即保存.
+// 任务尚未开始下载时, 将在开始后立即暂停
+func (dtu *DownloadTaskUnit) Pause() {
+	dtu.pauseMu.Lock()
+	defer dtu.pauseMu.Unlock()
+	dtu.paused = true
+	if dtu.der != nil {
+		dtu.der.Pause()
+	}
+}
+
+// Resume 恢复下载
+func (dtu *DownloadTaskUnit) Resume() {
+	dtu.pauseMu.Lock()
+	defer dtu.pauseMu.Unlock()
+	dtu.paused = false
+	if dtu.der != nil {
+		dtu.der.Resume()
+	}
+}
+
+// IsPaused 是否已暂停
+func (dtu *DownloadTaskUnit) IsPaused() bool {
+	dtu.pauseMu.Lock()
+	defer dtu.pauseMu.Unlock()
+	return dtu.paused
+}
+
 // panHTTPClient 获取包含特定User-Agent的HTTPClient
 func (dtu *DownloadTaskUnit) panHTTPClient() *requester.HTTPClient {
 	if client == nil {
@@ -279,7 +343,7 @@ func (dtu *DownloadTaskUnit) handleError(result *taskframework.TaskUnitRunResult
 	}
 }
 
-func (dtu *DownloadTaskUnit) execPanDownload(dlink string, result *taskframework.TaskUnitRunResult, okPtr *bool) {
+func (dtu *DownloadTaskUnit) execPanDownload(ctx context.Context, dlink string, result *taskframework.TaskUnitRunResult, okPtr *bool) {
 	dtu.verboseInfof("[%s] 获取到下载链接: %s\n", dtu.taskInfo.Id(), dlink)
 
 	client := dtu.panHTTPClient()
@@ -287,7 +351,7 @@ func (dtu *DownloadTaskUnit) execPanDownload(dlink string, result *taskframework
 	cookieJar := activePCS.GetClient().Jar
 	newCookieJar, _ := CloneJarWithDomain(cookieJar, dlink)
 	client.SetCookiejar(newCookieJar)
-	err := dtu.download(dlink, client)
+	err := dtu.download(ctx, dlink, client)
 	if err != nil {
 		result.ResultMessage = StrDownloadFailed
 		result.Err = err
@@ -297,7 +361,7 @@ func (dtu *DownloadTaskUnit) execPanDownload(dlink string, result *taskframework
 	*okPtr = true
 }
 
-func (dtu *DownloadTaskUnit) locateDownload(result *taskframework.TaskUnitRunResult) (ok bool) {
+func (dtu *DownloadTaskUnit) locateDownload(ctx context.Context, result *taskframework.TaskUnitRunResult) (ok bool) {
 	rawDlinks, err := GetLocateDownloadLinks(dtu.PCS, dtu.PcsPath)
 	if err != nil {
 		result.ResultMessage = StrDownloadGetDlinkFailed
@@ -318,18 +382,18 @@ func (dtu *DownloadTaskUnit) locateDownload(result *taskframework.TaskUnitRunRes
 	FixHTTPLinkURL(raw_dlink)
 	dlink := raw_dlink.String()
 
-	dtu.execPanDownload(dlink, result, &ok)
+	dtu.execPanDownload(ctx, dlink, result, &ok)
 	return
 }
 
-func (dtu *DownloadTaskUnit) pcsOrStreamingDownload(mode DownloadMode, result *taskframework.TaskUnitRunResult) (ok bool) {
+func (dtu *DownloadTaskUnit) pcsOrStreamingDownload(ctx context.Context, mode DownloadMode, result *taskframework.TaskUnitRunResult) (ok bool) {
 	dfunc := func(downloadURL string, jar http.CookieJar) error {
 		client := pcsconfig.Config.PCSHTTPClient()
 		client.SetCookiejar(jar)
 		client.SetKeepAlive(true)
 		client.SetTimeout(10 * time.Minute)
 
-		return dtu.download(downloadURL, client)
+		return dtu.download(ctx, downloadURL, client)
 	}
 
 	var err error
@@ -355,7 +419,7 @@ func (dtu *DownloadTaskUnit) pcsOrStreamingDownload(mode DownloadMode, result *t
 func (dtu *DownloadTaskUnit) checkFileValid(result *taskframework.TaskUnitRunResult) (ok bool) {
 	fi, err := os.Stat(dtu.SavePath)
 	if err == nil {
-		if fi.Size() != dtu.FileInfo.Size {
+		if fi.Size() != dtu.fileSize() {
 			result.ResultMessage = StrDownloadCheckLengthFailed
 			result.NeedNextdindex = true
 			result.NeedRetry = true
@@ -374,7 +438,11 @@ func (dtu *DownloadTaskUnit) checkFileValid(result *taskframework.TaskUnitRunRes
 	}
 
 	// 就在这里处理校验出错
-	err = CheckFileValid(dtu.SavePath, dtu.FileInfo)
+	if dtu.crypt != nil {
+		err = CheckEncryptedFileValid(dtu.SavePath, dtu.FileInfo, dtu.crypt, dtu.nonce)
+	} else {
+		err = CheckFileValid(dtu.SavePath, dtu.FileInfo)
+	}
 	if err != nil {
 		result.ResultMessage = StrDownloadChecksumFailed
 		result.Err = err
@@ -435,7 +503,7 @@ func (dtu *DownloadTaskUnit) RetryWait() time.Duration {
 	return pcsfunctions.RetryWait(dtu.taskInfo.Retry())
 }
 
-func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
+func (dtu *DownloadTaskUnit) Run(ctx context.Context) (result *taskframework.TaskUnitRunResult) {
 	result = &taskframework.TaskUnitRunResult{}
 	// 获取文件信息
 	var err error
@@ -506,6 +574,13 @@ func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 		return
 	}
 
+	// 开始下载前已取消
+	if err = ctx.Err(); err != nil {
+		result.ResultMessage = StrDownloadFailed
+		result.Err = err
+		return
+	}
+
 	if !dtu.Cfg.IsTest {
 		// 不是测试下载, 输出下载路径
 		fmt.Printf("[%s] 将会下载到路径: %s\n\n", dtu.taskInfo.Id(), dtu.SavePath)
@@ -515,9 +590,9 @@ func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 	// 获取下载链接
 	switch dtu.DownloadMode {
 	case DownloadModeLocate:
-		ok = dtu.locateDownload(result)
+		ok = dtu.locateDownload(ctx, result)
 	case DownloadModePCS, DownloadModeStreaming:
-		ok = dtu.pcsOrStreamingDownload(dtu.DownloadMode, result)
+		ok = dtu.pcsOrStreamingDownload(ctx, dtu.DownloadMode, result)
 	}
 
 	if !ok {
@@ -539,7 +614,7 @@ func (dtu *DownloadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 		}
 	}
 	// 统计下载
-	dtu.DownloadStatistic.AddTotalSize(dtu.FileInfo.Size)
+	dtu.DownloadStatistic.AddTotalSize(dtu.fileSize())
 	// 下载成功
 	result.Succeed = true
 	return
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
 	"golang.org/x/net/publicsuffix"
 	"net/http"
@@ -15,11 +16,24 @@ import (
 
 // CheckFileValid 检测文件有效性
 func CheckFileValid(filePath string, fileInfo *baidupcs.FileDirectory) error {
+	return checkFileValid(filePath, fileInfo, nil)
+}
+
+// CheckEncryptedFileValid 检测解密后的文件有效性, 使用相同的 nonce 重新加密后和网盘文件的 md5 比较
+func CheckEncryptedFileValid(filePath string, fileInfo *baidupcs.FileDirectory, c *pcscrypt.Cipher, nonce []byte) error {
+	return checkFileValid(filePath, fileInfo, func(f *os.File, size int64) (checksum.File, int64, error) {
+		e := c.NewEncrypter(f, size, nonce)
+		return e, e.Len(), nil
+	})
+}
+
+func checkFileValid(filePath string, fileInfo *baidupcs.FileDirectory, transform func(f *os.File, size int64) (checksum.File, int64, error)) error {
 	if len(fileInfo.BlockList) != 1 {
 		return ErrDownloadNotSupportChecksum
 	}
 
 	f := checksum.NewLocalFileChecksum(filePath, int(baidupcs.SliceMD5Size))
+	f.Transform = transform
 	err := f.OpenPath()
 	if err != nil {
 		return err
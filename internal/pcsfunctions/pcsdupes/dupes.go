@@ -0,0 +1,272 @@
+// Package pcsdupes 查找网盘中 md5 和大小都相同的重复文件, 按规则保留一份, 其余移入回收站
+package pcsdupes
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsindex"
+)
+
+type (
+	// Rule 保留哪一份副本的规则
+	Rule string
+
+	// Options 查找选项
+	Options struct {
+		Path     string `json:"path"`                // 网盘目录, 默认为根目录
+		MinSize  int64  `json:"min_size,omitempty"`  // 忽略小于该大小的文件
+		Rule     Rule   `json:"rule"`                // 保留规则, 默认 oldest
+		Prefer   string `json:"prefer,omitempty"`    // 优先保留的目录, 用于 prefer 规则
+		UseIndex bool   `json:"use_index,omitempty"` // 从本地索引读取文件列表, 不请求网盘
+	}
+
+	// File 重复的文件
+	File struct {
+		Path  string `json:"path"`
+		Ctime int64  `json:"ctime"`
+		Mtime int64  `json:"mtime"`
+	}
+
+	// Group md5 和大小都相同的一组文件
+	Group struct {
+		MD5    string   `json:"md5"`
+		Size   int64    `json:"size"`
+		Wasted int64    `json:"wasted"` // 删除多余副本后可节省的空间
+		Keep   string   `json:"keep"`
+		Remove []string `json:"remove"`
+		Files  []*File  `json:"files"`
+		Note   string   `json:"note,omitempty"`
+	}
+
+	// Result 查找结果
+	Result struct {
+		Path     string   `json:"path"`
+		Scanned  int      `json:"scanned"`   // 扫描的文件数量
+		DupFiles int      `json:"dup_files"` // 可删除的副本数量
+		Wasted   int64    `json:"wasted"`    // 可节省的空间
+		Groups   []*Group `json:"groups,omitempty"`
+		Applied  bool     `json:"applied"` // 是否已将多余的副本移入回收站
+		Removed  int      `json:"removed,omitempty"`
+		Failed   []string `json:"failed,omitempty"` // 移入回收站失败的文件
+	}
+
+	// groupKey 分组依据
+	groupKey struct {
+		md5  string
+		size int64
+	}
+)
+
+const (
+	// RuleOldest 保留最早上传的副本
+	RuleOldest Rule = "oldest"
+	// RuleShortest 保留路径最短的副本
+	RuleShortest Rule = "shortest"
+	// RulePrefer 保留位于优先目录中的副本
+	RulePrefer Rule = "prefer"
+
+	// removeBatchSize 每次请求移入回收站的文件数量
+	removeBatchSize = 100
+)
+
+var (
+	// ErrUnknownRule 未知的保留规则
+	ErrUnknownRule = errors.New("unknown keep rule, must be oldest, shortest or prefer")
+	// ErrPreferRequired prefer 规则未指定目录
+	ErrPreferRequired = errors.New("prefer rule requires a preferred folder")
+)
+
+// normalize 检查选项并填充默认值
+func (opt *Options) normalize() error {
+	opt.Path = path.Clean("/" + opt.Path)
+	switch opt.Rule {
+	case "":
+		opt.Rule = RuleOldest
+	case RuleOldest, RuleShortest:
+	case RulePrefer:
+		if opt.Prefer == "" {
+			return ErrPreferRequired
+		}
+	default:
+		return ErrUnknownRule
+	}
+	if opt.Prefer != "" {
+		opt.Prefer = path.Clean("/" + opt.Prefer)
+	}
+	return nil
+}
+
+// inDir 路径是否位于目录中
+func inDir(p, dir string) bool {
+	return dir == baidupcs.PathSeparator || strings.HasPrefix(p, dir+baidupcs.PathSeparator)
+}
+
+// Find 查找目录中的重复文件, 只生成报告, 不删除文件. progress 可以为空, 每扫描 1000 个文件调用一次
+func Find(ctx context.Context, pcs *baidupcs.BaiduPCS, uid uint64, opt Options, progress func(scanned int)) (*Result, error) {
+	err := opt.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		result = &Result{Path: opt.Path}
+		groups = map[groupKey][]*File{}
+	)
+	add := func(p, md5 string, size, ctime, mtime int64) {
+		result.Scanned++
+		if progress != nil && result.Scanned%1000 == 0 {
+			progress(result.Scanned)
+		}
+		// 空文件和没有 md5 的文件无法判断是否重复
+		md5 = strings.ToLower(baidupcs.DecryptMD5(md5))
+		if size <= 0 || size < opt.MinSize || len(md5) != 32 {
+			return
+		}
+		key := groupKey{md5: md5, size: size}
+		groups[key] = append(groups[key], &File{Path: p, Ctime: ctime, Mtime: mtime})
+	}
+
+	if opt.UseIndex {
+		idx, err := pcsindex.Load(uid)
+		if err != nil {
+			return nil, err
+		}
+		qr, err := idx.Search(&pcsindex.Query{Prefix: opt.Path, Type: pcsindex.TypeFile})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range qr.Entries {
+			add(e.Path, e.MD5, e.Size, e.Ctime, e.Mtime)
+		}
+	} else {
+		var walkErr error
+		pcs.FilesDirectoriesRecurseListContext(ctx, opt.Path, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+			if pcsError != nil {
+				walkErr = pcsError
+				return false
+			}
+			if !fd.Isdir {
+				add(fd.Path, fd.MD5, fd.Size, fd.Ctime, fd.Mtime)
+			}
+			return true
+		})
+		if walkErr == nil {
+			walkErr = ctx.Err()
+		}
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	}
+	if progress != nil {
+		progress(result.Scanned)
+	}
+
+	for key, files := range groups {
+		if len(files) < 2 {
+			continue
+		}
+		g := newGroup(key, files, &opt)
+		result.Groups = append(result.Groups, g)
+		result.DupFiles += len(g.Remove)
+		result.Wasted += g.Wasted
+	}
+	// 浪费空间多的排在前面
+	sort.Slice(result.Groups, func(i, j int) bool {
+		a, b := result.Groups[i], result.Groups[j]
+		if a.Wasted != b.Wasted {
+			return a.Wasted > b.Wasted
+		}
+		return a.Keep < b.Keep
+	})
+	return result, nil
+}
+
+// newGroup 按规则选出保留的副本
+func newGroup(key groupKey, files []*File, opt *Options) *Group {
+	older := func(a, b *File) bool {
+		at, bt := a.Ctime, b.Ctime
+		if at == 0 || bt == 0 {
+			at, bt = a.Mtime, b.Mtime
+		}
+		if at != bt {
+			return at < bt
+		}
+		return a.Path < b.Path
+	}
+	switch opt.Rule {
+	case RuleShortest:
+		sort.Slice(files, func(i, j int) bool {
+			a, b := files[i], files[j]
+			if len(a.Path) != len(b.Path) {
+				return len(a.Path) < len(b.Path)
+			}
+			return older(a, b)
+		})
+	default:
+		sort.Slice(files, func(i, j int) bool {
+			return older(files[i], files[j])
+		})
+	}
+
+	g := &Group{
+		MD5:    key.md5,
+		Size:   key.size,
+		Wasted: int64(len(files)-1) * key.size,
+		Files:  files,
+	}
+	keep := 0
+	if opt.Rule == RulePrefer {
+		keep = -1
+		for i, f := range files {
+			if inDir(f.Path, opt.Prefer) {
+				keep = i
+				break
+			}
+		}
+		if keep < 0 {
+			keep = 0
+			g.Note = "没有位于优先目录中的副本, 保留最早上传的副本"
+		}
+	}
+	g.Keep = files[keep].Path
+	g.Remove = make([]string, 0, len(files)-1)
+	for i, f := range files {
+		if i != keep {
+			g.Remove = append(g.Remove, f.Path)
+		}
+	}
+	return g
+}
+
+// Apply 按报告将多余的副本分批移入回收站, 已移除的数量和失败的文件记录在 result 中
+func Apply(ctx context.Context, pcs *baidupcs.BaiduPCS, result *Result) error {
+	var paths []string
+	for _, g := range result.Groups {
+		paths = append(paths, g.Remove...)
+	}
+
+	var lastErr error
+	for len(paths) > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := min(removeBatchSize, len(paths))
+		batch := paths[:n]
+		paths = paths[n:]
+		pcsError := pcs.RemoveContext(ctx, batch...)
+		if pcsError != nil {
+			result.Failed = append(result.Failed, batch...)
+			lastErr = pcsError
+			continue
+		}
+		result.Removed += len(batch)
+	}
+	result.Applied = true
+	return lastErr
+}
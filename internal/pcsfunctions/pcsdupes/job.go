@@ -0,0 +1,203 @@
+package pcsdupes
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// JobStatus 查找任务状态
+	JobStatus string
+
+	// Job 后台执行的查找任务, 先生成报告, 确认后再通过 Apply 删除多余的副本
+	Job struct {
+		ID         string    `json:"id"`
+		Options    Options   `json:"options"`
+		Status     JobStatus `json:"status"`
+		Scanned    int       `json:"scanned"`
+		Result     *Result   `json:"result,omitempty"`
+		Err        string    `json:"error,omitempty"`
+		CreatedAt  int64     `json:"created_at"`
+		FinishedAt int64     `json:"finished_at,omitempty"`
+
+		pcs    *baidupcs.BaiduPCS
+		cancel context.CancelFunc
+	}
+
+	// JobManager 查找任务管理器
+	JobManager struct {
+		mu     sync.RWMutex
+		lastID int64
+		jobs   map[string]*Job
+	}
+)
+
+const (
+	// JobScanning 正在扫描
+	JobScanning JobStatus = "scanning"
+	// JobReported 已生成报告, 等待确认
+	JobReported JobStatus = "reported"
+	// JobApplying 正在移入回收站
+	JobApplying JobStatus = "applying"
+	// JobApplied 已移入回收站
+	JobApplied JobStatus = "applied"
+	// JobFailed 已失败
+	JobFailed JobStatus = "failed"
+	// JobCanceled 已取消
+	JobCanceled JobStatus = "canceled"
+)
+
+var (
+	// DefaultJobs 默认的查找任务管理器
+	DefaultJobs = NewJobManager()
+
+	// ErrJobNotFound 查找任务不存在
+	ErrJobNotFound = errors.New("dupes job not found")
+	// ErrJobNotReported 查找任务尚未生成报告或已经执行过
+	ErrJobNotReported = errors.New("dupes job has no pending report")
+)
+
+// NewJobManager 初始化JobManager
+func NewJobManager() *JobManager {
+	return &JobManager{
+		jobs: map[string]*Job{},
+	}
+}
+
+// Start 在后台开始查找重复文件, 只生成报告
+func (m *JobManager) Start(pcs *baidupcs.BaiduPCS, uid uint64, opt Options) (*Job, error) {
+	err := opt.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	n := time.Now().UnixNano() / int64(time.Millisecond)
+	if n <= m.lastID {
+		n = m.lastID + 1
+	}
+	m.lastID = n
+	job := &Job{
+		ID:        strconv.FormatInt(n, 36),
+		Options:   opt,
+		Status:    JobScanning,
+		CreatedAt: time.Now().Unix(),
+		pcs:       pcs,
+		cancel:    cancel,
+	}
+	m.jobs[job.ID] = job
+	snapshot := *job
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := Find(ctx, pcs, uid, opt, func(scanned int) {
+			m.mu.Lock()
+			job.Scanned = scanned
+			m.mu.Unlock()
+		})
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		job.Result = result
+		m.finish(ctx, job, err, JobReported)
+	}()
+	return &snapshot, nil
+}
+
+// Apply 在后台将报告中多余的副本移入回收站
+func (m *JobManager) Apply(id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, ErrJobNotFound
+	}
+	if job.Status != JobReported {
+		m.mu.Unlock()
+		return nil, ErrJobNotReported
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job.Status, job.FinishedAt, job.cancel = JobApplying, 0, cancel
+	snapshot := *job
+	// 在副本上记录删除结果, 完成后再替换, 避免和快照竞争
+	result := *job.Result
+	m.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		err := Apply(ctx, job.pcs, &result)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		job.Result = &result
+		m.finish(ctx, job, err, JobApplied)
+	}()
+	return &snapshot, nil
+}
+
+// finish 记录任务结束的状态, 调用前需加锁
+func (m *JobManager) finish(ctx context.Context, job *Job, err error, done JobStatus) {
+	job.FinishedAt = time.Now().Unix()
+	switch {
+	case ctx.Err() != nil:
+		job.Status = JobCanceled
+	case err != nil:
+		job.Status, job.Err = JobFailed, err.Error()
+	default:
+		job.Status = done
+	}
+}
+
+// Get 获取查找任务的快照
+func (m *JobManager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// List 按创建时间列出所有查找任务的快照, 不包含分组
+func (m *JobManager) List() []*Job {
+	m.mu.RLock()
+	list := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		snapshot := *job
+		if snapshot.Result != nil {
+			result := *snapshot.Result
+			result.Groups = nil
+			result.Failed = nil
+			snapshot.Result = &result
+		}
+		list = append(list, &snapshot)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Cancel 取消正在扫描或移入回收站的任务
+func (m *JobManager) Cancel(id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	job.cancel()
+	return nil
+}
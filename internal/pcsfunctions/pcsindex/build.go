@@ -0,0 +1,264 @@
+package pcsindex
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcschanges"
+)
+
+type (
+	// BuildStatus 后台建立索引的状态
+	BuildStatus struct {
+		UID        uint64 `json:"uid"`
+		Running    bool   `json:"running"`
+		Scanned    int    `json:"scanned"` // 已列出的文件和目录数量
+		StartedAt  int64  `json:"started_at"`
+		FinishedAt int64  `json:"finished_at,omitempty"`
+		Err        string `json:"error,omitempty"`
+	}
+
+	// RefreshResult 增量更新的结果
+	RefreshResult struct {
+		Added    int  `json:"added"`
+		Modified int  `json:"modified"`
+		Deleted  int  `json:"deleted"`
+		Rebuilt  bool `json:"rebuilt"` // 服务器要求重新全量列出, 已重新建立索引
+		Info     Info `json:"info"`
+	}
+)
+
+var (
+	// ErrIndexBusy 账号的索引正在建立或更新
+	ErrIndexBusy = errors.New("index is being built or refreshed")
+
+	busyMu sync.Mutex
+	busy   = map[uint64]bool{}
+	builds = map[uint64]*BuildStatus{}
+)
+
+// acquire 标记账号的索引正在更新
+func acquire(uid uint64) error {
+	busyMu.Lock()
+	defer busyMu.Unlock()
+	if busy[uid] {
+		return ErrIndexBusy
+	}
+	busy[uid] = true
+	return nil
+}
+
+// release 取消标记
+func release(uid uint64) {
+	busyMu.Lock()
+	delete(busy, uid)
+	busyMu.Unlock()
+}
+
+// Build 列出整个网盘建立账号的索引, 覆盖已有的索引. progress 可以为空, 每列出 1000 项调用一次
+func Build(ctx context.Context, pcs *baidupcs.BaiduPCS, uid uint64, progress func(scanned int)) (*Info, error) {
+	err := acquire(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer release(uid)
+	return build(ctx, pcs, uid, progress)
+}
+
+// build 建立索引, 调用前需标记
+func build(ctx context.Context, pcs *baidupcs.BaiduPCS, uid uint64, progress func(scanned int)) (*Info, error) {
+	// 先获取 cursor, 列出期间的变更在下次更新时处理
+	cursor, err := pcschanges.LatestCursor(ctx, pcs)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := newIndex(uid)
+	err = walk(ctx, pcs, baidupcs.PathSeparator, idx, progress)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	idx.Cursor = cursor
+	idx.BuiltAt = now
+	idx.UpdatedAt = now
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	err = save(idx)
+	if err != nil {
+		return nil, err
+	}
+	info := idx.Info
+	return &info, nil
+}
+
+// walk 递归列出目录, 加入索引
+func walk(ctx context.Context, pcs *baidupcs.BaiduPCS, dir string, idx *Index, progress func(scanned int)) error {
+	var (
+		walkErr error
+		scanned int
+	)
+	pcs.FilesDirectoriesRecurseListContext(ctx, dir, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if pcsError != nil {
+			walkErr = pcsError
+			return false
+		}
+		if depth == 0 && fd.Path == baidupcs.PathSeparator {
+			return true
+		}
+		idx.put(newEntry(fd))
+		scanned++
+		if progress != nil && scanned%1000 == 0 {
+			progress(scanned)
+		}
+		return true
+	})
+	if walkErr == nil {
+		walkErr = ctx.Err()
+	}
+	if walkErr == nil && progress != nil {
+		progress(scanned)
+	}
+	return walkErr
+}
+
+// StartBuild 在后台建立账号的索引, 通过 GetBuild 查询进度
+func StartBuild(pcs *baidupcs.BaiduPCS, uid uint64) (*BuildStatus, error) {
+	err := acquire(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &BuildStatus{
+		UID:       uid,
+		Running:   true,
+		StartedAt: time.Now().Unix(),
+	}
+	busyMu.Lock()
+	builds[uid] = status
+	s := *status
+	busyMu.Unlock()
+
+	go func() {
+		defer release(uid)
+		_, err := build(context.Background(), pcs, uid, func(scanned int) {
+			busyMu.Lock()
+			status.Scanned = scanned
+			busyMu.Unlock()
+		})
+
+		busyMu.Lock()
+		status.Running = false
+		status.FinishedAt = time.Now().Unix()
+		if err != nil {
+			status.Err = err.Error()
+		}
+		busyMu.Unlock()
+	}()
+	return &s, nil
+}
+
+// GetBuild 获取账号最近一次后台建立索引的状态, 没有时返回 nil
+func GetBuild(uid uint64) *BuildStatus {
+	busyMu.Lock()
+	defer busyMu.Unlock()
+	status := builds[uid]
+	if status == nil {
+		return nil
+	}
+	s := *status
+	return &s
+}
+
+// Refresh 读取上次更新之后的网盘变更, 增量更新账号的索引
+func Refresh(ctx context.Context, pcs *baidupcs.BaiduPCS, uid uint64) (*RefreshResult, error) {
+	err := acquire(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer release(uid)
+
+	old, err := Load(uid)
+	if err != nil {
+		return nil, err
+	}
+	if old.Cursor == "" {
+		return nil, ErrIndexNotBuilt
+	}
+
+	// 查询可能正在使用已读取的索引, 在副本上修改
+	idx := &Index{
+		Info:    old.Info,
+		entries: maps.Clone(old.entries),
+	}
+	var (
+		result = &RefreshResult{}
+		cursor = idx.Cursor
+		dirs   []string
+	)
+	for {
+		diff, pcsError := pcs.FilesDirectoriesDiffContext(ctx, cursor)
+		if pcsError != nil {
+			return nil, pcsError
+		}
+		if diff.Reset {
+			info, err := build(ctx, pcs, uid, nil)
+			if err != nil {
+				return nil, err
+			}
+			return &RefreshResult{Rebuilt: true, Info: *info}, nil
+		}
+		// 同一路径可能先删除再新建, 先处理删除
+		for _, fd := range diff.Deleted {
+			result.Deleted += idx.remove(fd.Path)
+		}
+		for _, fd := range diff.Added {
+			idx.put(newEntry(fd))
+			result.Added++
+			if fd.Isdir {
+				// 移动或复制进来的目录不会列出其中的文件
+				dirs = append(dirs, fd.Path)
+			}
+		}
+		for _, fd := range diff.Modified {
+			idx.put(newEntry(fd))
+			result.Modified++
+		}
+		cursor = diff.Cursor
+		if !diff.HasMore || cursor == "" {
+			break
+		}
+	}
+
+	for _, dir := range dirs {
+		err = walk(ctx, pcs, dir, idx, nil)
+		if err != nil {
+			if pcsError, ok := err.(pcserror.Error); ok && pcsError.GetRemoteErrCode() == 31066 {
+				// 已被删除
+				continue
+			}
+			return nil, err
+		}
+	}
+
+	if cursor != "" {
+		idx.Cursor = cursor
+	}
+	idx.UpdatedAt = time.Now().Unix()
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	err = save(idx)
+	if err != nil {
+		return nil, err
+	}
+	result.Info = idx.Info
+	return result, nil
+}
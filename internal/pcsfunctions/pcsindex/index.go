@@ -0,0 +1,249 @@
+// Package pcsindex 网盘文件的本地索引, 按账号保存在配置目录中, 通过网盘的增量变更检测保持更新
+package pcsindex
+
+import (
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+)
+
+type (
+	// Entry 索引中的文件或目录
+	Entry struct {
+		Path  string `json:"path"`
+		FsID  int64  `json:"fs_id,omitempty"`
+		IsDir bool   `json:"is_dir,omitempty"`
+		Size  int64  `json:"size,omitempty"`
+		Ctime int64  `json:"ctime,omitempty"`
+		Mtime int64  `json:"mtime,omitempty"`
+		MD5   string `json:"md5,omitempty"`
+	}
+
+	// Info 索引的概况
+	Info struct {
+		UID       uint64 `json:"uid"`
+		Cursor    string `json:"cursor,omitempty"`     // 增量更新的起点
+		BuiltAt   int64  `json:"built_at,omitempty"`   // 全量建立的时间
+		UpdatedAt int64  `json:"updated_at,omitempty"` // 最近一次更新的时间
+		Files     int    `json:"files"`
+		Dirs      int    `json:"dirs"`
+		Size      int64  `json:"size"` // 文件总大小
+	}
+
+	// Index 账号的文件索引
+	Index struct {
+		Info
+		entries map[string]*Entry
+	}
+
+	// indexData 索引文件的内容
+	indexData struct {
+		Info
+		Entries []*Entry `json:"entries"`
+	}
+
+	// cachedIndex 已读取的索引
+	cachedIndex struct {
+		index   *Index
+		modTime time.Time
+	}
+)
+
+const (
+	// IndexDirName 索引目录, 位于配置目录中, 每个账号一个文件
+	IndexDirName = "index"
+)
+
+var (
+	// ErrIndexNotBuilt 索引尚未建立
+	ErrIndexNotBuilt = errors.New("index not built, run index build first")
+
+	cacheMu sync.Mutex
+	cache   = map[uint64]*cachedIndex{}
+)
+
+// newIndex 初始化Index
+func newIndex(uid uint64) *Index {
+	return &Index{
+		Info: Info{
+			UID: uid,
+		},
+		entries: map[string]*Entry{},
+	}
+}
+
+// newEntry 由网盘文件信息生成索引项
+func newEntry(fd *baidupcs.FileDirectory) *Entry {
+	e := &Entry{
+		Path:  fd.Path,
+		FsID:  fd.FsID,
+		IsDir: fd.Isdir,
+		Ctime: fd.Ctime,
+		Mtime: fd.Mtime,
+	}
+	if !fd.Isdir {
+		e.Size = fd.Size
+		e.MD5 = baidupcs.DecryptMD5(fd.MD5)
+	}
+	return e
+}
+
+// put 加入或更新索引项
+func (idx *Index) put(e *Entry) {
+	idx.entries[e.Path] = e
+}
+
+// remove 删除索引项, 目录会连同其中的文件一起删除, 返回删除的数量
+func (idx *Index) remove(p string) (n int) {
+	if _, ok := idx.entries[p]; ok {
+		delete(idx.entries, p)
+		n++
+	}
+	prefix := strings.TrimSuffix(p, baidupcs.PathSeparator) + baidupcs.PathSeparator
+	for k := range idx.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(idx.entries, k)
+			n++
+		}
+	}
+	return n
+}
+
+// count 重新统计文件数量和大小
+func (idx *Index) count() {
+	idx.Files, idx.Dirs, idx.Size = 0, 0, 0
+	for _, e := range idx.entries {
+		if e.IsDir {
+			idx.Dirs++
+			continue
+		}
+		idx.Files++
+		idx.Size += e.Size
+	}
+}
+
+// indexFilePath 返回账号的索引文件路径
+func indexFilePath(uid uint64) string {
+	return filepath.Join(pcsconfig.GetConfigDir(), IndexDirName, strconv.FormatUint(uid, 10)+".json.gz")
+}
+
+// Load 读取账号的索引, 文件未修改时使用已读取的索引. 不要修改返回的索引
+func Load(uid uint64) (*Index, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return load(uid)
+}
+
+// load 读取账号的索引, 调用前需加锁
+func load(uid uint64) (*Index, error) {
+	filename := indexFilePath(uid)
+	fi, err := os.Stat(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			delete(cache, uid)
+			return nil, ErrIndexNotBuilt
+		}
+		return nil, err
+	}
+	if c := cache[uid]; c != nil && c.modTime.Equal(fi.ModTime()) {
+		return c.index, nil
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// 索引可能很大, 使用 gzip 压缩
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	data := &indexData{}
+	err = jsonhelper.UnmarshalData(zr, data)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{
+		Info:    data.Info,
+		entries: make(map[string]*Entry, len(data.Entries)),
+	}
+	for _, e := range data.Entries {
+		idx.put(e)
+	}
+	idx.count()
+	cache[uid] = &cachedIndex{index: idx, modTime: fi.ModTime()}
+	return idx, nil
+}
+
+// save 保存索引, 先写入临时文件再替换, 调用前需加锁
+func save(idx *Index) error {
+	idx.count()
+	data := &indexData{
+		Info:    idx.Info,
+		Entries: make([]*Entry, 0, len(idx.entries)),
+	}
+	for _, e := range idx.entries {
+		data.Entries = append(data.Entries, e)
+	}
+	sort.Slice(data.Entries, func(i, j int) bool {
+		return data.Entries[i].Path < data.Entries[j].Path
+	})
+
+	filename := indexFilePath(idx.UID)
+	err := os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	err = jsonhelper.MarshalData(zw, data)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	err = os.Rename(tmp, filename)
+	if err != nil {
+		return err
+	}
+
+	if fi, err := os.Stat(filename); err == nil {
+		cache[idx.UID] = &cachedIndex{index: idx, modTime: fi.ModTime()}
+	}
+	return nil
+}
+
+// Remove 删除账号的索引
+func Remove(uid uint64) error {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	delete(cache, uid)
+	err := os.Remove(indexFilePath(uid))
+	if os.IsNotExist(err) {
+		return ErrIndexNotBuilt
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package pcsindex
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Query 索引查询条件, 为零值的条件不限制
+	Query struct {
+		Name        string   `json:"name,omitempty"`         // 文件名通配符, 例如 *.mp4, 不区分大小写
+		Regex       string   `json:"regex,omitempty"`        // 匹配完整路径的正则表达式
+		Exts        []string `json:"exts,omitempty"`         // 扩展名, 例如 mp4, 不区分大小写
+		MinSize     int64    `json:"min_size,omitempty"`     // 最小文件大小
+		MaxSize     int64    `json:"max_size,omitempty"`     // 最大文件大小
+		MtimeAfter  int64    `json:"mtime_after,omitempty"`  // 修改时间不早于
+		MtimeBefore int64    `json:"mtime_before,omitempty"` // 修改时间早于
+		MD5         string   `json:"md5,omitempty"`
+		Prefix      string   `json:"prefix,omitempty"` // 只查询该目录下的文件
+		Type        string   `json:"type,omitempty"`   // file 或 dir, 默认都查询
+		Sort        string   `json:"sort,omitempty"`   // 排序: path, name, size 或 mtime, 默认 path
+		Desc        bool     `json:"desc,omitempty"`   // 倒序
+		Offset      int      `json:"offset,omitempty"`
+		Limit       int      `json:"limit,omitempty"` // 为0时不限制
+	}
+
+	// QueryResult 查询结果
+	QueryResult struct {
+		Total   int      `json:"total"` // 满足条件的数量
+		Entries []*Entry `json:"entries"`
+	}
+
+	// matcher 编译后的查询条件
+	matcher struct {
+		q      *Query
+		name   string
+		re     *regexp.Regexp
+		exts   map[string]bool
+		prefix string
+		md5    string
+	}
+)
+
+const (
+	// TypeFile 只查询文件
+	TypeFile = "file"
+	// TypeDir 只查询目录
+	TypeDir = "dir"
+)
+
+var (
+	// ErrUnknownType 未知的查询类型
+	ErrUnknownType = errors.New("unknown entry type, must be file or dir")
+	// ErrUnknownSort 未知的排序方式
+	ErrUnknownSort = errors.New("unknown sort, must be path, name, size or mtime")
+)
+
+// compile 检查并编译查询条件
+func (q *Query) compile() (*matcher, error) {
+	m := &matcher{
+		q:    q,
+		name: strings.ToLower(q.Name),
+		md5:  strings.ToLower(q.MD5),
+	}
+	if m.name != "" {
+		if _, err := path.Match(m.name, ""); err != nil {
+			return nil, fmt.Errorf("invalid name pattern %q: %s", q.Name, err)
+		}
+	}
+	if q.Regex != "" {
+		re, err := regexp.Compile(q.Regex)
+		if err != nil {
+			return nil, err
+		}
+		m.re = re
+	}
+	if len(q.Exts) > 0 {
+		m.exts = map[string]bool{}
+		for _, ext := range q.Exts {
+			m.exts[strings.ToLower(strings.TrimPrefix(ext, "."))] = true
+		}
+	}
+	if q.Prefix != "" {
+		m.prefix = path.Clean("/" + q.Prefix)
+	}
+	switch q.Type {
+	case "", TypeFile, TypeDir:
+	default:
+		return nil, ErrUnknownType
+	}
+	switch q.Sort {
+	case "", "path", "name", "size", "mtime":
+	default:
+		return nil, ErrUnknownSort
+	}
+	return m, nil
+}
+
+// match 索引项是否满足条件
+func (m *matcher) match(e *Entry) bool {
+	q := m.q
+	switch {
+	case q.Type == TypeFile && e.IsDir, q.Type == TypeDir && !e.IsDir:
+		return false
+	case m.prefix != "" && m.prefix != baidupcs.PathSeparator && !strings.HasPrefix(e.Path, m.prefix+baidupcs.PathSeparator):
+		return false
+	case q.MinSize > 0 && e.Size < q.MinSize, q.MaxSize > 0 && e.Size > q.MaxSize:
+		return false
+	case q.MtimeAfter > 0 && e.Mtime < q.MtimeAfter, q.MtimeBefore > 0 && e.Mtime >= q.MtimeBefore:
+		return false
+	case m.md5 != "" && e.MD5 != m.md5:
+		return false
+	}
+
+	name := path.Base(e.Path)
+	if m.exts != nil {
+		if e.IsDir {
+			return false
+		}
+		if !m.exts[strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))] {
+			return false
+		}
+	}
+	if m.name != "" {
+		if ok, _ := path.Match(m.name, strings.ToLower(name)); !ok {
+			return false
+		}
+	}
+	if m.re != nil && !m.re.MatchString(e.Path) {
+		return false
+	}
+	return true
+}
+
+// Search 在索引中查询
+func (idx *Index) Search(q *Query) (*QueryResult, error) {
+	m, err := q.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*Entry
+	for _, e := range idx.entries {
+		if m.match(e) {
+			list = append(list, e)
+		}
+	}
+
+	var less func(a, b *Entry) bool
+	switch q.Sort {
+	case "name":
+		less = func(a, b *Entry) bool {
+			return path.Base(a.Path) < path.Base(b.Path)
+		}
+	case "size":
+		less = func(a, b *Entry) bool {
+			return a.Size < b.Size
+		}
+	case "mtime":
+		less = func(a, b *Entry) bool {
+			return a.Mtime < b.Mtime
+		}
+	default:
+		less = func(a, b *Entry) bool {
+			return a.Path < b.Path
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if q.Desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Path < b.Path
+	})
+
+	result := &QueryResult{
+		Total: len(list),
+	}
+	if q.Offset > 0 {
+		list = list[min(q.Offset, len(list)):]
+	}
+	if q.Limit > 0 && len(list) > q.Limit {
+		list = list[:q.Limit]
+	}
+	result.Entries = list
+	return result, nil
+}
+
+// Search 在账号的索引中查询
+func Search(uid uint64, q *Query) (*QueryResult, error) {
+	idx, err := Load(uid)
+	if err != nil {
+		return nil, err
+	}
+	return idx.Search(q)
+}
@@ -0,0 +1,93 @@
+// Package pcsnotify 向配置的通知地址发送事件通知
+package pcsnotify
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+type (
+	// EventType 通知类型
+	EventType string
+
+	// Notification 通知内容
+	Notification struct {
+		Event   EventType                  `json:"event"`
+		UID     uint64                     `json:"uid,omitempty"`
+		Name    string                     `json:"name,omitempty"`
+		Status  pcsconfig.CredentialStatus `json:"status,omitempty"`
+		Message string                     `json:"message,omitempty"`
+		Time    int64                      `json:"time"`
+	}
+)
+
+const (
+	// EventCredentialInvalid 帐号凭证失效
+	EventCredentialInvalid EventType = "credential_invalid"
+
+	// sendRetry 发送失败时的重试次数
+	sendRetry = 3
+)
+
+var (
+	notifyVerbose = pcsverbose.New("NOTIFY")
+)
+
+// CredentialInvalid 帐号凭证失效的通知
+func CredentialInvalid(baidu *pcsconfig.Baidu) *Notification {
+	state := baidu.Credential()
+	return &Notification{
+		Event:   EventCredentialInvalid,
+		UID:     baidu.UID,
+		Name:    baidu.Name,
+		Status:  state.Status,
+		Message: state.Message,
+	}
+}
+
+// Send 在后台以 json 格式 POST 通知到配置的 notify_url, 未配置时忽略
+func Send(n *Notification) {
+	u := pcsconfig.Config.NotifyURL
+	if u == "" {
+		return
+	}
+	n.Time = time.Now().Unix()
+	go send(pcsconfig.Config.HTTPClient(), u, n)
+}
+
+// send 发送通知, 非 2xx 响应时重试
+func send(client *requester.HTTPClient, u string, n *Notification) {
+	buf := &bytes.Buffer{}
+	err := jsonhelper.MarshalData(buf, n)
+	if err != nil {
+		return
+	}
+	body := buf.Bytes()
+
+	client.SetTimeout(10 * time.Second)
+	header := map[string]string{
+		"Content-Type": "application/json",
+	}
+	for i := 0; i < sendRetry; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(1<<i) * time.Second)
+		}
+		resp, err := client.ReqContext(context.Background(), http.MethodPost, u, body, header)
+		if err != nil {
+			notifyVerbose.Warnf("notify %s: %s\n", u, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return
+		}
+		notifyVerbose.Warnf("notify %s: %s\n", u, resp.Status)
+	}
+}
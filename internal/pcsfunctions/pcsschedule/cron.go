@@ -0,0 +1,216 @@
+package pcsschedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Cron 解析后的 cron 表达式
+	Cron struct {
+		minute, hour, dom, month, dow uint64
+		domStar, dowStar              bool
+		every                         time.Duration // @every
+	}
+
+	// cronField 表达式中单个字段的取值范围
+	cronField struct {
+		name     string
+		min, max int
+		names    []string // 从 min 开始的名称, 例如 jan, sun
+	}
+)
+
+var (
+	fieldMinute = cronField{name: "minute", min: 0, max: 59}
+	fieldHour   = cronField{name: "hour", min: 0, max: 23}
+	fieldDom    = cronField{name: "day of month", min: 1, max: 31}
+	fieldMonth  = cronField{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	fieldDow    = cronField{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+
+	// cronDescriptors 预定义的表达式
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+
+	// ErrCronEmpty cron 表达式为空
+	ErrCronEmpty = errors.New("empty cron expression")
+)
+
+const (
+	// MinEvery @every 的最小间隔
+	MinEvery = time.Minute
+
+	// cronSearchYears 查找下次执行时间的最大年数, 例如 2月30日永远不会执行
+	cronSearchYears = 5
+)
+
+// ParseCron 解析 cron 表达式.
+// 支持标准的 5 个字段 "分 时 日 月 周", 字段中可使用 *, 列表 1,2, 范围 1-5, 步长 */10 和月份, 星期的英文缩写;
+// 另外支持 @yearly, @monthly, @weekly, @daily, @hourly 和 @every 1h30m
+func ParseCron(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, ErrCronEmpty
+	}
+
+	if strings.HasPrefix(expr, "@every") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", expr, err)
+		}
+		if d < MinEvery {
+			return nil, fmt.Errorf("invalid cron expression %q: interval must be at least %s", expr, MinEvery)
+		}
+		return &Cron{every: d}, nil
+	}
+	if s, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = s
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var (
+		c   = &Cron{}
+		err error
+	)
+	for i, p := range []struct {
+		field cronField
+		bits  *uint64
+		star  *bool
+	}{
+		{fieldMinute, &c.minute, nil},
+		{fieldHour, &c.hour, nil},
+		{fieldDom, &c.dom, &c.domStar},
+		{fieldMonth, &c.month, nil},
+		{fieldDow, &c.dow, &c.dowStar},
+	} {
+		*p.bits, err = p.field.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %s", expr, err)
+		}
+		if p.star != nil {
+			*p.star = fields[i] == "*" || fields[i] == "?"
+		}
+	}
+	// 星期日可以写作 0 或 7
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parse 解析单个字段, 返回取值的位图
+func (f cronField) parse(s string) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		var (
+			rng  = part
+			step = 1
+		)
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			rng = part[:i]
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %s", f.name, part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*" || rng == "?":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			i := strings.IndexByte(rng, '-')
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field: %s", f.name, part)
+			}
+		default:
+			if lo, err = f.value(rng); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if step > 1 {
+				// 5/10 表示从 5 开始每 10 个
+				hi = f.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value 解析字段中的数字或英文缩写
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value in %s field: %s", f.name, s)
+	}
+	return v, nil
+}
+
+// Next 返回 t 之后的下次执行时间, 精确到分钟. 找不到时返回零值
+func (c *Cron) Next(t time.Time) time.Time {
+	if c.every > 0 {
+		return t.Truncate(time.Second).Add(c.every)
+	}
+
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(cronSearchYears, 0, 0)
+	for t.Before(end) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatch 日期是否匹配. 与标准 cron 相同, 日和周都有限制时满足其一即可
+func (c *Cron) dayMatch(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
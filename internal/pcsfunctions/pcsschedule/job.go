@@ -0,0 +1,316 @@
+package pcsschedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+)
+
+type (
+	// Kind 定时任务类型
+	Kind string
+
+	// JobOptions 定时任务选项, 不同类型的任务使用不同的字段
+	JobOptions struct {
+		LocalPaths []string `json:"local_paths,omitempty"` // upload: 服务器本地的文件或目录
+		RemoteDir  string   `json:"remote_dir,omitempty"`  // upload: 网盘保存目录
+		Policy     string   `json:"policy,omitempty"`      // upload: 重名文件策略, 默认 rsync
+		Parallel   int      `json:"parallel,omitempty"`    // upload: 同时上传的文件数量
+
+		Paths      []string `json:"paths,omitempty"`       // export: 网盘文件或目录
+		SavePath   string   `json:"save_path,omitempty"`   // export: 导出文件路径, 每次执行覆盖, 默认在配置目录的 exports 中按时间命名
+		Recursive  bool     `json:"recursive,omitempty"`   // export: 包含子目录
+		LinkFormat bool     `json:"link_format,omitempty"` // export: 以秒传链接格式导出
+
+		Threshold float64 `json:"threshold,omitempty"` // quota: 使用率达到该百分比时视为失败
+	}
+)
+
+const (
+	// KindUpload 上传服务器本地的文件或目录
+	KindUpload Kind = "upload"
+	// KindExport 导出网盘文件的秒传信息
+	KindExport Kind = "export"
+	// KindOfflineDlQuery 查询离线下载任务
+	KindOfflineDlQuery Kind = "offlinedl_query"
+	// KindOfflineDlClear 清空离线下载任务记录
+	KindOfflineDlClear Kind = "offlinedl_clear"
+	// KindRecycleClear 清空回收站
+	KindRecycleClear Kind = "recycle_clear"
+	// KindQuota 检查网盘空间使用率
+	KindQuota Kind = "quota"
+
+	// ExportDirName 默认导出目录, 位于配置目录中
+	ExportDirName = "exports"
+)
+
+var (
+	// ErrUnknownKind 未知的任务类型
+	ErrUnknownKind = errors.New("unknown schedule kind")
+)
+
+// normalize 按任务类型检查选项并填充默认值
+func (opt *JobOptions) normalize(kind Kind) error {
+	switch kind {
+	case KindUpload:
+		if len(opt.LocalPaths) == 0 || opt.RemoteDir == "" {
+			return errors.New("upload schedule requires local_paths and remote_dir")
+		}
+		opt.RemoteDir = path.Clean("/" + opt.RemoteDir)
+		switch opt.Policy {
+		case "":
+			opt.Policy = baidupcs.RsyncPolicy
+		case baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy:
+		default:
+			return fmt.Errorf("unknown upload policy: %s", opt.Policy)
+		}
+	case KindExport:
+		if len(opt.Paths) == 0 {
+			return errors.New("export schedule requires paths")
+		}
+		for i := range opt.Paths {
+			opt.Paths[i] = path.Clean("/" + opt.Paths[i])
+		}
+	case KindQuota:
+		if opt.Threshold < 0 || opt.Threshold > 100 {
+			return errors.New("quota threshold must be between 0 and 100")
+		}
+	case KindOfflineDlQuery, KindOfflineDlClear, KindRecycleClear:
+	default:
+		return ErrUnknownKind
+	}
+	return nil
+}
+
+// execute 执行一次任务, 返回结果说明和附加数据
+func execute(ctx context.Context, pcs *baidupcs.BaiduPCS, kind Kind, opt JobOptions) (message string, data interface{}, err error) {
+	switch kind {
+	case KindUpload:
+		return runUpload(ctx, pcs, opt)
+	case KindExport:
+		return runExport(ctx, pcs, opt)
+	case KindOfflineDlQuery:
+		return runOfflineDlQuery(ctx, pcs)
+	case KindOfflineDlClear:
+		total, pcsError := pcs.CloudDlClearTaskContext(ctx)
+		if pcsError != nil {
+			return "", nil, pcsError
+		}
+		return fmt.Sprintf("清除 %d 条离线下载记录", total), map[string]interface{}{"cleared": total}, nil
+	case KindRecycleClear:
+		n, pcsError := pcs.RecycleClearContext(ctx)
+		if pcsError != nil {
+			return "", nil, pcsError
+		}
+		return fmt.Sprintf("清空回收站, 数量: %d", n), map[string]interface{}{"cleared": n}, nil
+	case KindQuota:
+		return runQuota(ctx, pcs, opt)
+	}
+	return "", nil, ErrUnknownKind
+}
+
+// runUpload 上传本地文件, 目录中的文件保持相对路径
+func runUpload(ctx context.Context, pcs *baidupcs.BaiduPCS, opt JobOptions) (string, interface{}, error) {
+	t := pcstransfer.Default.New(pcstransfer.KindUpload, pcstransfer.Options{
+		Parallel:  opt.Parallel,
+		Policy:    opt.Policy,
+		NoPersist: true,
+	})
+	var walkErrs []string
+	for _, localPath := range opt.LocalPaths {
+		files, err := pcsutil.WalkDir(localPath, "")
+		if err != nil {
+			walkErrs = append(walkErrs, fmt.Sprintf("%s: %s", localPath, err))
+			continue
+		}
+		for _, file := range files {
+			rel, _ := filepath.Rel(filepath.Dir(localPath), file)
+			_, err = t.AppendUpload(file, path.Join(opt.RemoteDir, filepath.ToSlash(rel)))
+			if err != nil {
+				pcstransfer.Default.Remove(t.ID)
+				return "", nil, err
+			}
+		}
+	}
+	if t.Len() == 0 {
+		pcstransfer.Default.Remove(t.ID)
+		if len(walkErrs) > 0 {
+			return "", nil, errors.New(strings.Join(walkErrs, "; "))
+		}
+		return "没有需要上传的文件", map[string]interface{}{"total": 0}, nil
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		t.Cancel("")
+	})
+	t.Execute()
+	stop()
+
+	info := t.Info(true)
+	var failed []string
+	for _, item := range info.Items {
+		if item.Status != pcstransfer.StatusSucceeded {
+			failed = append(failed, fmt.Sprintf("%s: %s", item.Source, item.Message))
+		}
+	}
+	failed = append(failed, walkErrs...)
+	data := map[string]interface{}{
+		"transfer_id": t.ID,
+		"total":       info.Total,
+		"succeeded":   info.Counts[pcstransfer.StatusSucceeded],
+		"failed":      failed,
+	}
+	msg := fmt.Sprintf("上传 %d 个文件, 成功 %d 个", info.Total, info.Counts[pcstransfer.StatusSucceeded])
+	if ctx.Err() != nil {
+		return msg, data, ctx.Err()
+	}
+	if len(failed) > 0 {
+		return msg, data, fmt.Errorf("%d 个文件上传失败", len(failed))
+	}
+	return msg, data, nil
+}
+
+// runExport 导出秒传信息, 格式与 export 命令相同
+func runExport(ctx context.Context, pcs *baidupcs.BaiduPCS, opt JobOptions) (string, interface{}, error) {
+	savePath := opt.SavePath
+	if savePath == "" {
+		dir := filepath.Join(pcsconfig.GetConfigDir(), ExportDirName)
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			return "", nil, err
+		}
+		savePath = filepath.Join(dir, "BaiduPCS-Go_export_"+pcstime.BeijingTimeOption("")+".txt")
+	}
+	f, err := os.OpenFile(savePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	var (
+		exported int
+		failed   []string
+		writeErr error
+	)
+	exportFile := func(fd *baidupcs.FileDirectory) {
+		rinfo, pcsError := pcs.ExportByFileInfoContext(ctx, fd)
+		if pcsError != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", fd.Path, pcsError))
+			return
+		}
+		line := fmt.Sprintf("BaiduPCS-Go rapidupload -length=%d -md5=%s -slicemd5=%s -crc32=%s \"%s\"\n", rinfo.ContentLength, rinfo.ContentMD5, rinfo.SliceMD5, rinfo.ContentCrc32, fd.Path)
+		if opt.LinkFormat {
+			line = fmt.Sprintf("%s#%s#%d#%s\n", rinfo.ContentMD5, rinfo.SliceMD5, rinfo.ContentLength, path.Base(fd.Path))
+		}
+		if _, writeErr = f.WriteString(line); writeErr == nil {
+			exported++
+		}
+	}
+
+	for _, p := range opt.Paths {
+		if opt.Recursive {
+			pcs.FilesDirectoriesRecurseListContext(ctx, p, baidupcs.DefaultOrderOptions, func(_ int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+				if pcsError != nil {
+					failed = append(failed, fmt.Sprintf("%s: %s", p, pcsError))
+					return true
+				}
+				if !fd.Isdir {
+					exportFile(fd)
+				}
+				return writeErr == nil && ctx.Err() == nil
+			})
+		} else {
+			fd, pcsError := pcs.FilesDirectoriesMetaContext(ctx, p)
+			if pcsError != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", p, pcsError))
+				continue
+			}
+			if !fd.Isdir {
+				exportFile(fd)
+				continue
+			}
+			fds, pcsError := pcs.FilesDirectoriesListContext(ctx, p, baidupcs.DefaultOrderOptions)
+			if pcsError != nil {
+				failed = append(failed, fmt.Sprintf("%s: %s", p, pcsError))
+				continue
+			}
+			for _, fd := range fds {
+				if !fd.Isdir && writeErr == nil && ctx.Err() == nil {
+					exportFile(fd)
+				}
+			}
+		}
+		if writeErr != nil {
+			return "", nil, writeErr
+		}
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+	}
+
+	data := map[string]interface{}{
+		"save_path": savePath,
+		"exported":  exported,
+		"failed":    failed,
+	}
+	msg := fmt.Sprintf("导出 %d 个文件到 %s", exported, savePath)
+	if len(failed) > 0 {
+		return msg, data, fmt.Errorf("%d 个文件或目录导出失败", len(failed))
+	}
+	return msg, data, nil
+}
+
+// runOfflineDlQuery 查询离线下载任务, 按状态统计数量
+func runOfflineDlQuery(ctx context.Context, pcs *baidupcs.BaiduPCS) (string, interface{}, error) {
+	tasks, pcsError := pcs.CloudDlListTaskContext(ctx)
+	if pcsError != nil {
+		return "", nil, pcsError
+	}
+	var (
+		counts  = map[string]int{}
+		running int
+		failed  int
+	)
+	for _, task := range tasks {
+		counts[task.StatusText]++
+		switch task.Status {
+		case 0, 7:
+		case 1:
+			running++
+		default:
+			failed++
+		}
+	}
+	msg := fmt.Sprintf("离线下载任务 %d 个, 进行中 %d 个, 失败 %d 个", len(tasks), running, failed)
+	return msg, map[string]interface{}{"total": len(tasks), "running": running, "failed": failed, "counts": counts}, nil
+}
+
+// runQuota 检查网盘空间使用率
+func runQuota(ctx context.Context, pcs *baidupcs.BaiduPCS, opt JobOptions) (string, interface{}, error) {
+	quota, used, pcsError := pcs.QuotaInfoContext(ctx)
+	if pcsError != nil {
+		return "", nil, pcsError
+	}
+	var ratio float64
+	if quota > 0 {
+		ratio = 100 * float64(used) / float64(quota)
+	}
+	msg := fmt.Sprintf("总空间: %s, 已用空间: %s, 比率: %.2f%%", converter.ConvertFileSize(quota), converter.ConvertFileSize(used), ratio)
+	data := map[string]interface{}{"quota": quota, "used": used, "ratio": ratio}
+	if opt.Threshold > 0 && ratio >= opt.Threshold {
+		return msg, data, fmt.Errorf("空间使用率 %.2f%% 达到阈值 %.2f%%", ratio, opt.Threshold)
+	}
+	return msg, data, nil
+}
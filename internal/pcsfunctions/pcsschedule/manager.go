@@ -0,0 +1,554 @@
+// Package pcsschedule 定时任务, 在 server 中按 cron 表达式执行上传, 导出, 离线下载查询等任务
+package pcsschedule
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+)
+
+type (
+	// Spec 定时任务的设置
+	Spec struct {
+		Name     string     `json:"name,omitempty"`
+		Cron     string     `json:"cron"`
+		Kind     Kind       `json:"kind"`
+		Options  JobOptions `json:"options"`
+		Disabled bool       `json:"disabled,omitempty"`
+	}
+
+	// RunResult 一次执行的结果
+	RunResult struct {
+		StartAt int64       `json:"start_at"`
+		EndAt   int64       `json:"end_at"`
+		Manual  bool        `json:"manual,omitempty"` // 手动执行
+		Success bool        `json:"success"`
+		Message string      `json:"message,omitempty"`
+		Err     string      `json:"error,omitempty"`
+		Data    interface{} `json:"data,omitempty"`
+	}
+
+	// Schedule 定时任务及其执行记录
+	Schedule struct {
+		ID string `json:"id"`
+		Spec
+		CreatedAt  int64        `json:"created_at"`
+		UpdatedAt  int64        `json:"updated_at,omitempty"`
+		Running    bool         `json:"running"`
+		Runs       int          `json:"runs"`
+		LastRunAt  int64        `json:"last_run_at,omitempty"`
+		NextRunAt  int64        `json:"next_run_at,omitempty"`
+		LastResult *RunResult   `json:"last_result,omitempty"`
+		History    []*RunResult `json:"history,omitempty"` // 最近的执行记录, 新的在前
+	}
+
+	// entry 管理器中的定时任务
+	entry struct {
+		info   Schedule
+		cron   *Cron
+		cancel context.CancelFunc // 执行中的任务
+	}
+
+	// Manager 定时任务管理器. 任务保存在配置目录中,
+	// 文件被其他进程 (例如 schedule 命令) 修改后会重新读取
+	Manager struct {
+		mu      sync.Mutex
+		lastID  int64
+		entries map[string]*entry
+		loaded  bool
+		modTime time.Time
+		getPCS  func() *baidupcs.BaiduPCS
+		wake    chan struct{}
+	}
+
+	// scheduleData 定时任务文件的内容
+	scheduleData struct {
+		Schedules []*Schedule `json:"schedules,omitempty"`
+	}
+)
+
+const (
+	// ScheduleFileName 持久化的定时任务文件名
+	ScheduleFileName = "pcs_schedules.json"
+
+	// HistorySize 每个任务保存的执行记录数量
+	HistorySize = 20
+
+	// reloadInterval 检查定时任务文件是否被修改的间隔
+	reloadInterval = 30 * time.Second
+	// missedRunGrace 读取定时任务时, 超过该时间未执行的视为错过, 不再补做
+	missedRunGrace = time.Minute
+)
+
+var (
+	// Default 默认的定时任务管理器
+	Default = NewManager()
+
+	// ErrScheduleNotFound 定时任务不存在
+	ErrScheduleNotFound = errors.New("schedule not found")
+	// ErrScheduleRunning 定时任务正在执行
+	ErrScheduleRunning = errors.New("schedule is running")
+	// ErrNotLoggedIn 没有可用的百度帐号
+	ErrNotLoggedIn = errors.New("no active baidu account")
+
+	scheduleVerbose = pcsverbose.New("SCHEDULE")
+)
+
+// NewManager 初始化Manager
+func NewManager() *Manager {
+	return &Manager{
+		entries: map[string]*entry{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// normalize 检查设置并解析 cron 表达式
+func (spec *Spec) normalize() (*Cron, error) {
+	c, err := ParseCron(spec.Cron)
+	if err != nil {
+		return nil, err
+	}
+	return c, spec.Options.normalize(spec.Kind)
+}
+
+// Start 读取保存的定时任务并在后台按时执行, getPCS 返回执行时使用的帐号.
+// 停止期间错过的执行不会补做, 返回任务数量
+func (m *Manager) Start(getPCS func() *baidupcs.BaiduPCS) (int, error) {
+	m.mu.Lock()
+	err := m.load()
+	n := len(m.entries)
+	started := m.getPCS != nil
+	m.getPCS = getPCS
+	m.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	if !started {
+		go m.loop()
+	}
+	return n, nil
+}
+
+// loop 执行到期的任务, 并定期检查定时任务文件
+func (m *Manager) loop() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-m.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+
+		m.mu.Lock()
+		err := m.load()
+		if err != nil {
+			scheduleVerbose.Warnf("reload schedules: %s\n", err)
+		}
+		now := time.Now()
+		wait := reloadInterval
+		for _, e := range m.entries {
+			if e.info.Disabled || e.info.NextRunAt == 0 {
+				continue
+			}
+			next := time.Unix(e.info.NextRunAt, 0)
+			if !next.After(now) {
+				if e.info.Running {
+					scheduleVerbose.Warnf("schedule %s is still running, skip\n", e.info.ID)
+				} else {
+					m.run(e, m.getPCS(), false)
+				}
+				e.info.NextRunAt = nextRunAt(e.cron, now)
+				if e.info.NextRunAt == 0 {
+					continue
+				}
+				next = time.Unix(e.info.NextRunAt, 0)
+			}
+			if d := next.Sub(now); d < wait {
+				wait = d
+			}
+		}
+		m.mu.Unlock()
+		timer.Reset(wait)
+	}
+}
+
+// notify 唤醒 loop 重新计算等待时间
+func (m *Manager) notify() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// nextRunAt 返回 now 之后的下次执行时间, 按东八区时间计算, 与显示的时间一致. 不会执行时返回0
+func nextRunAt(c *Cron, now time.Time) int64 {
+	next := c.Next(now.In(pcstime.CSTLocation))
+	if next.IsZero() {
+		return 0
+	}
+	return next.Unix()
+}
+
+// nextID 生成定时任务 id, 调用前需加锁
+func (m *Manager) nextID() string {
+	n := time.Now().UnixNano() / int64(time.Millisecond)
+	if n <= m.lastID {
+		n = m.lastID + 1
+	}
+	m.lastID = n
+	return strconv.FormatInt(n, 36)
+}
+
+// Create 添加定时任务. 返回的任务不为 nil 而 err 不为 nil 时, 任务已添加但保存失败
+func (m *Manager) Create(spec Spec) (*Schedule, error) {
+	c, err := spec.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err = m.load()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	e := &entry{
+		info: Schedule{
+			ID:        m.nextID(),
+			Spec:      spec,
+			CreatedAt: now.Unix(),
+			NextRunAt: nextRunAt(c, now),
+		},
+		cron: c,
+	}
+	m.entries[e.info.ID] = e
+	m.notify()
+	return e.status(), m.save()
+}
+
+// Update 修改定时任务的设置, 保留执行记录
+func (m *Manager) Update(id string, spec Spec) (*Schedule, error) {
+	c, err := spec.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err = m.load()
+	if err != nil {
+		return nil, err
+	}
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	now := time.Now()
+	e.info.Spec = spec
+	e.info.UpdatedAt = now.Unix()
+	e.info.NextRunAt = nextRunAt(c, now)
+	e.cron = c
+	m.notify()
+	return e.status(), m.save()
+}
+
+// Delete 删除定时任务, 正在执行的任务会被取消
+func (m *Manager) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.load()
+	if err != nil {
+		return err
+	}
+	e, ok := m.entries[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+	if e.cancel != nil {
+		e.cancel()
+	}
+	delete(m.entries, id)
+	return m.save()
+}
+
+// Get 获取定时任务
+func (m *Manager) Get(id string) (*Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	return e.status(), nil
+}
+
+// List 按创建顺序列出定时任务
+func (m *Manager) List() ([]*Schedule, error) {
+	m.mu.Lock()
+	err := m.load()
+	list := make([]*Schedule, 0, len(m.entries))
+	for _, e := range m.entries {
+		list = append(list, e.status())
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// Trigger 在后台立即执行一次定时任务, 不影响下次执行时间
+func (m *Manager) Trigger(pcs *baidupcs.BaiduPCS, id string) (*Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	e, ok := m.entries[id]
+	if !ok {
+		return nil, ErrScheduleNotFound
+	}
+	if e.info.Running {
+		return nil, ErrScheduleRunning
+	}
+	m.run(e, pcs, true)
+	return e.status(), nil
+}
+
+// RunNow 在当前进程中立即执行一次定时任务, 等待执行结束并保存结果
+func (m *Manager) RunNow(ctx context.Context, pcs *baidupcs.BaiduPCS, id string) (*RunResult, error) {
+	info, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if info.Running {
+		return nil, ErrScheduleRunning
+	}
+
+	result := runJob(ctx, pcs, info.Kind, info.Options, true)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err = m.load()
+	if err != nil {
+		return result, err
+	}
+	if e, ok := m.entries[id]; ok {
+		e.record(result)
+		err = m.save()
+	}
+	return result, err
+}
+
+// run 在后台执行任务, 结束后保存结果, 调用前需加锁
+func (m *Manager) run(e *entry, pcs *baidupcs.BaiduPCS, manual bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.info.Running = true
+	e.cancel = cancel
+	id, kind, opt := e.info.ID, e.info.Kind, e.info.Options
+
+	go func() {
+		defer cancel()
+		result := runJob(ctx, pcs, kind, opt, manual)
+		if !result.Success {
+			scheduleVerbose.Warnf("schedule %s: %s\n", id, result.Err)
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		e.info.Running = false
+		e.cancel = nil
+		if m.entries[id] != e {
+			// 执行期间被删除
+			return
+		}
+		err := m.load()
+		if err != nil {
+			scheduleVerbose.Warnf("reload schedules: %s\n", err)
+		}
+		e.record(result)
+		err = m.save()
+		if err != nil {
+			scheduleVerbose.Warnf("save schedules: %s\n", err)
+		}
+	}()
+}
+
+// runJob 执行任务并记录结果
+func runJob(ctx context.Context, pcs *baidupcs.BaiduPCS, kind Kind, opt JobOptions, manual bool) *RunResult {
+	result := &RunResult{
+		StartAt: time.Now().Unix(),
+		Manual:  manual,
+	}
+	var err error
+	if pcs == nil {
+		err = ErrNotLoggedIn
+	} else {
+		result.Message, result.Data, err = execute(ctx, pcs, kind, opt)
+	}
+	result.EndAt = time.Now().Unix()
+	result.Success = err == nil
+	if err != nil {
+		result.Err = err.Error()
+	}
+	return result
+}
+
+// record 记录执行结果
+func (e *entry) record(result *RunResult) {
+	e.info.Runs++
+	e.info.LastRunAt = result.StartAt
+	e.info.LastResult = result
+	e.info.History = append([]*RunResult{result}, e.info.History...)
+	if len(e.info.History) > HistorySize {
+		e.info.History = e.info.History[:HistorySize]
+	}
+}
+
+// status 返回任务的快照, 调用前需加锁
+func (e *entry) status() *Schedule {
+	info := e.info
+	if info.Disabled {
+		info.NextRunAt = 0
+	}
+	info.Options.LocalPaths = append([]string(nil), info.Options.LocalPaths...)
+	info.Options.Paths = append([]string(nil), info.Options.Paths...)
+	info.History = append([]*RunResult(nil), info.History...)
+	return &info
+}
+
+// scheduleFilePath 返回定时任务文件路径
+func scheduleFilePath() string {
+	return filepath.Join(pcsconfig.GetConfigDir(), ScheduleFileName)
+}
+
+// load 定时任务文件被修改时重新读取, 调用前需加锁.
+// 已有的任务在原处更新, 保留执行状态
+func (m *Manager) load() error {
+	filename := scheduleFilePath()
+	fi, err := os.Stat(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			m.loaded = true
+			return nil
+		}
+		return err
+	}
+	if m.loaded && fi.ModTime().Equal(m.modTime) {
+		return nil
+	}
+
+	data := &scheduleData{}
+	if fi.Size() > 0 {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		err = jsonhelper.UnmarshalData(f, data)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	m.loaded = true
+	m.modTime = fi.ModTime()
+
+	var (
+		now  = time.Now()
+		seen = map[string]bool{}
+	)
+	for _, info := range data.Schedules {
+		c, err := info.Spec.normalize()
+		if err != nil {
+			scheduleVerbose.Warnf("load schedule %s: %s\n", info.ID, err)
+			continue
+		}
+		seen[info.ID] = true
+		if now.Sub(time.Unix(info.NextRunAt, 0)) > missedRunGrace {
+			// 停止期间错过的执行
+			info.NextRunAt = nextRunAt(c, now)
+		}
+
+		e, ok := m.entries[info.ID]
+		if !ok {
+			info.Running = false
+			m.entries[info.ID] = &entry{info: *info, cron: c}
+			continue
+		}
+		info.Running = e.info.Running
+		e.info = *info
+		e.cron = c
+	}
+	for id, e := range m.entries {
+		if seen[id] {
+			continue
+		}
+		if e.cancel != nil {
+			e.cancel()
+		}
+		delete(m.entries, id)
+	}
+	return nil
+}
+
+// save 保存定时任务, 先写入临时文件再替换, 调用前需加锁
+func (m *Manager) save() error {
+	data := &scheduleData{}
+	for _, e := range m.entries {
+		info := e.info
+		info.Running = false
+		data.Schedules = append(data.Schedules, &info)
+	}
+	sort.Slice(data.Schedules, func(i, j int) bool {
+		return data.Schedules[i].ID < data.Schedules[j].ID
+	})
+
+	buf := &bytes.Buffer{}
+	err := jsonhelper.MarshalData(buf, data)
+	if err != nil {
+		return err
+	}
+
+	filename := scheduleFilePath()
+	tmp := filename + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, filename)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(filename); err == nil {
+		m.modTime = fi.ModTime()
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package pcsstorage
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+)
+
+type (
+	// Baidu 百度网盘后端
+	Baidu struct {
+		PCS *baidupcs.BaiduPCS // 为空时使用当前登录的帐号
+	}
+)
+
+// NewBaidu 初始化百度网盘后端, pcs 为空时始终使用当前登录的帐号
+func NewBaidu(pcs *baidupcs.BaiduPCS) *Baidu {
+	return &Baidu{
+		PCS: pcs,
+	}
+}
+
+func (b *Baidu) pcs() *baidupcs.BaiduPCS {
+	if b.PCS != nil {
+		return b.PCS
+	}
+	return pcsconfig.Config.ActiveUserBaiduPCS()
+}
+
+// Name 后端名称
+func (b *Baidu) Name() string {
+	return NameBaidu
+}
+
+// List 列出目录下的文件和目录
+func (b *Baidu) List(ctx context.Context, dir string, options *baidupcs.OrderOptions) (baidupcs.FileDirectoryList, error) {
+	list, pcsError := b.pcs().FilesDirectoriesListContext(ctx, dir, options)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return list, nil
+}
+
+// Meta 获取单个文件/目录的元信息
+func (b *Baidu) Meta(ctx context.Context, p string) (*baidupcs.FileDirectory, error) {
+	fd, pcsError := b.pcs().FilesDirectoriesMetaContext(ctx, p)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return fd, nil
+}
+
+// Search 按文件名关键字搜索
+func (b *Baidu) Search(ctx context.Context, dir, keyword string, recurse bool) (baidupcs.FileDirectoryList, error) {
+	list, pcsError := b.pcs().SearchContext(ctx, dir, keyword, recurse)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return list, nil
+}
+
+// Match 通配符匹配文件路径
+func (b *Baidu) Match(ctx context.Context, pattern string) ([]string, error) {
+	paths, pcsError := b.pcs().MatchPathByShellPatternContext(ctx, pattern)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return paths, nil
+}
+
+// Mkdir 创建目录
+func (b *Baidu) Mkdir(ctx context.Context, p string) error {
+	if pcsError := b.pcs().MkdirContext(ctx, p); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// Remove 删除文件/目录
+func (b *Baidu) Remove(ctx context.Context, paths ...string) error {
+	if pcsError := b.pcs().RemoveContext(ctx, paths...); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// Rename 重命名文件/目录
+func (b *Baidu) Rename(ctx context.Context, from, to string) error {
+	if pcsError := b.pcs().RenameContext(ctx, from, to); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// Copy 复制文件/目录
+func (b *Baidu) Copy(ctx context.Context, cpmvJSON ...*baidupcs.CpMvJSON) error {
+	if pcsError := b.pcs().CopyContext(ctx, cpmvJSON...); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// Move 移动文件/目录
+func (b *Baidu) Move(ctx context.Context, cpmvJSON ...*baidupcs.CpMvJSON) error {
+	if pcsError := b.pcs().MoveContext(ctx, cpmvJSON...); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// Upload 上传服务器本地文件, 复用 pcsupload.UploadTaskUnit, 不持久化
+func (b *Baidu) Upload(ctx context.Context, localPath, savePath, policy string) error {
+	t := pcstransfer.Default.New(pcstransfer.KindUpload, pcstransfer.Options{
+		Policy:    policy,
+		NoPersist: true,
+	})
+	_, err := t.AppendUpload(localPath, savePath)
+	if err != nil {
+		pcstransfer.Default.Remove(t.ID)
+		return err
+	}
+	return executeTransfer(ctx, t, "上传失败")
+}
+
+// Download 下载文件到服务器本地, 复用 pcsdownload.DownloadTaskUnit, 不持久化
+func (b *Baidu) Download(ctx context.Context, p, localPath string, overwrite bool) error {
+	t := pcstransfer.Default.New(pcstransfer.KindDownload, pcstransfer.Options{
+		Overwrite: overwrite,
+		NoPersist: true,
+	})
+	t.AppendDownload(p, localPath, nil)
+	return executeTransfer(ctx, t, "下载失败")
+}
+
+// executeTransfer 执行传输任务直到结束, ctx 取消时取消任务, 失败时返回第一个错误信息
+func executeTransfer(ctx context.Context, t *pcstransfer.Transfer, failed string) error {
+	stop := context.AfterFunc(ctx, func() {
+		t.Cancel("")
+	})
+	defer stop()
+	t.Execute()
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if t.Failed() > 0 || t.Skipped() > 0 {
+		for _, item := range t.Info(true).Items {
+			if item.Message != "" {
+				return errors.New(item.Message)
+			}
+		}
+		return errors.New(failed)
+	}
+	return nil
+}
+
+// DownloadURL 获取文件的下载链接
+func (b *Baidu) DownloadURL(ctx context.Context, p string) ([]string, error) {
+	info, pcsError := b.pcs().LocateDownloadContext(ctx, p)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+
+	urls := make([]string, 0, len(info.URLs)+1)
+	if u := info.SingleURL(true); u != nil {
+		urls = append(urls, u.String())
+	}
+	for _, u := range info.URLs {
+		urls = append(urls, u.URL)
+	}
+	if len(urls) == 0 {
+		return nil, baidupcs.ErrLocateDownloadURLNotFound
+	}
+	return urls, nil
+}
+
+// ShareSet 创建分享
+func (b *Baidu) ShareSet(ctx context.Context, paths []string, option *baidupcs.ShareOption) (*baidupcs.Shared, error) {
+	shared, pcsError := b.pcs().ShareSetContext(ctx, paths, option)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return shared, nil
+}
+
+// ShareList 列出分享, 同时补充私密分享的提取码
+func (b *Baidu) ShareList(ctx context.Context, page int) (baidupcs.ShareRecordInfoList, error) {
+	pcs := b.pcs()
+	records, pcsError := pcs.ShareListContext(ctx, page)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+
+	for _, record := range records {
+		if record.Public == 0 && record.ExpireType != -1 {
+			info, pcsError := pcs.ShareSURLInfoContext(ctx, record.ShareID)
+			if pcsError == nil {
+				record.Passwd = strings.TrimSpace(info.Pwd)
+			}
+		}
+	}
+	return records, nil
+}
+
+// ShareCancel 取消分享
+func (b *Baidu) ShareCancel(ctx context.Context, shareIDs []int64) error {
+	if pcsError := b.pcs().ShareCancelContext(ctx, shareIDs); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// RecycleList 列出回收站
+func (b *Baidu) RecycleList(ctx context.Context, page int) (baidupcs.RecycleFDInfoList, error) {
+	list, pcsError := b.pcs().RecycleListContext(ctx, page)
+	if pcsError != nil {
+		return nil, pcsError
+	}
+	return list, nil
+}
+
+// RecycleRestore 恢复回收站中的文件/目录
+func (b *Baidu) RecycleRestore(ctx context.Context, fsIDs ...int64) error {
+	if _, pcsError := b.pcs().RecycleRestoreContext(ctx, fsIDs...); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// RecycleDelete 彻底删除回收站中的文件/目录
+func (b *Baidu) RecycleDelete(ctx context.Context, fsIDs ...int64) error {
+	if pcsError := b.pcs().RecycleDeleteContext(ctx, fsIDs...); pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// RecycleClear 清空回收站
+func (b *Baidu) RecycleClear(ctx context.Context) (int, error) {
+	n, pcsError := b.pcs().RecycleClearContext(ctx)
+	if pcsError != nil {
+		return 0, pcsError
+	}
+	return n, nil
+}
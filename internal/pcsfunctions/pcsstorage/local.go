@@ -0,0 +1,557 @@
+package pcsstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+const (
+	// LocalMetaDirName 本地目录后端保存回收站和分享记录的目录, 不会出现在文件列表中
+	LocalMetaDirName = ".BaiduPCS-Go"
+)
+
+type (
+	// Local 本地目录后端, 以目录 Root 作为网盘根目录
+	Local struct {
+		Root string
+
+		mu sync.Mutex // 保护元数据文件
+	}
+)
+
+var (
+	// ErrLocalReservedPath 路径为本地目录后端的保留路径
+	ErrLocalReservedPath = errors.New("reserved path")
+	// ErrLocalOutsideRoot 路径解析符号链接后超出根目录
+	ErrLocalOutsideRoot = errors.New("path outside root")
+)
+
+// NewLocal 初始化本地目录后端, root 必须为已存在的目录
+func NewLocal(root string) (*Local, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s: 不是目录", root)
+	}
+	return &Local{
+		Root: root,
+	}, nil
+}
+
+// Name 后端名称
+func (l *Local) Name() string {
+	return NameLocal
+}
+
+// clean 规范化网盘路径
+func (l *Local) clean(p string) string {
+	return path.Clean("/" + p)
+}
+
+// isReserved 是否为保留路径
+func (l *Local) isReserved(p string) bool {
+	p = l.clean(p)
+	return p == "/"+LocalMetaDirName || strings.HasPrefix(p, "/"+LocalMetaDirName+"/")
+}
+
+// realPath 网盘路径对应的本地路径, 规范化并解析符号链接后不会超出根目录
+func (l *Local) realPath(p string) (string, error) {
+	if l.isReserved(p) {
+		return "", &os.PathError{Op: "access", Path: p, Err: ErrLocalReservedPath}
+	}
+	real := filepath.Join(l.Root, filepath.FromSlash(l.clean(p)))
+	err := l.checkInRoot(real)
+	if err != nil {
+		return "", &os.PathError{Op: "access", Path: p, Err: err}
+	}
+	return real, nil
+}
+
+// checkInRoot 检查本地路径解析符号链接后是否位于根目录中, 路径可以不存在
+func (l *Local) checkInRoot(name string) error {
+	root, err := filepath.EvalSymlinks(l.Root)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolvePath(name)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ErrLocalOutsideRoot
+	}
+	return nil
+}
+
+// resolvePath 解析路径中的符号链接, 不存在的部分原样保留.
+// 指向不存在的路径的符号链接无法确定位置, 视为超出根目录
+func resolvePath(name string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(name)
+	if err == nil || !os.IsNotExist(err) {
+		return resolved, err
+	}
+	if _, err = os.Lstat(name); err == nil {
+		return "", ErrLocalOutsideRoot
+	}
+	dir := filepath.Dir(name)
+	if dir == name {
+		return name, nil
+	}
+	parent, err := resolvePath(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(name)), nil
+}
+
+// fsID 根据路径生成文件id
+func (l *Local) fsID(p string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(l.clean(p)))
+	return int64(h.Sum64() >> 1)
+}
+
+// fileDirectory 转换文件信息
+func (l *Local) fileDirectory(p string, info os.FileInfo) *baidupcs.FileDirectory {
+	p = l.clean(p)
+	fd := &baidupcs.FileDirectory{
+		FsID:     l.fsID(p),
+		Path:     p,
+		Filename: path.Base(p),
+		Ctime:    info.ModTime().Unix(),
+		Mtime:    info.ModTime().Unix(),
+		Isdir:    info.IsDir(),
+	}
+	if !fd.Isdir {
+		fd.Size = info.Size()
+	}
+	return fd
+}
+
+// List 列出目录下的文件和目录, 目录排在前面
+func (l *Local) List(ctx context.Context, dir string, options *baidupcs.OrderOptions) (baidupcs.FileDirectoryList, error) {
+	real, err := l.realPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(real)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make(baidupcs.FileDirectoryList, 0, len(entries))
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		p := path.Join(l.clean(dir), entry.Name())
+		if l.isReserved(p) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fd := l.fileDirectory(p, info)
+		if fd.Isdir {
+			fd.Ifhassubdir = hasSubDir(filepath.Join(real, entry.Name()))
+		}
+		list = append(list, fd)
+	}
+
+	if options == nil {
+		options = baidupcs.DefaultOrderOptions
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Isdir != list[j].Isdir {
+			return list[i].Isdir
+		}
+		var less bool
+		switch options.By {
+		case baidupcs.OrderByTime:
+			less = list[i].Mtime < list[j].Mtime
+		case baidupcs.OrderBySize:
+			less = list[i].Size < list[j].Size
+		default:
+			less = list[i].Filename < list[j].Filename
+		}
+		if options.Order == baidupcs.OrderDesc {
+			return !less
+		}
+		return less
+	})
+	return list, nil
+}
+
+// hasSubDir 目录是否含有子目录
+func hasSubDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta 获取单个文件/目录的元信息, 文件会计算 md5
+func (l *Local) Meta(ctx context.Context, p string) (*baidupcs.FileDirectory, error) {
+	real, err := l.realPath(p)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return nil, err
+	}
+
+	fd := l.fileDirectory(p, info)
+	if fd.Isdir {
+		fd.Ifhassubdir = hasSubDir(real)
+		return fd, nil
+	}
+	fd.MD5, err = fileMD5(ctx, real)
+	if err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
+
+// fileMD5 计算文件的 md5
+func fileMD5(ctx context.Context, filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	_, err = io.Copy(h, &ctxReader{ctx: ctx, r: f})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Search 按文件名关键字搜索, 不区分大小写
+func (l *Local) Search(ctx context.Context, dir, keyword string, recurse bool) (baidupcs.FileDirectoryList, error) {
+	real, err := l.realPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	keyword = strings.ToLower(keyword)
+
+	list := baidupcs.FileDirectoryList{}
+	err = filepath.Walk(real, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if name == real {
+			return nil
+		}
+		rel, _ := filepath.Rel(l.Root, name)
+		p := l.clean(filepath.ToSlash(rel))
+		if l.isReserved(p) {
+			return filepath.SkipDir
+		}
+		if strings.Contains(strings.ToLower(info.Name()), keyword) {
+			list = append(list, l.fileDirectory(p, info))
+		}
+		if info.IsDir() && !recurse {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Match 通配符匹配文件路径, 规则同 BaiduPCS.MatchPathByShellPattern
+func (l *Local) Match(ctx context.Context, pattern string) ([]string, error) {
+	if !path.IsAbs(pattern) {
+		return nil, baidupcs.ErrMatchPathByShellPatternNotAbsPath
+	}
+	segments := strings.Split(path.Clean(pattern), "/")[1:]
+
+	paths := []string{"/"}
+	for _, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		next := make([]string, 0, len(paths))
+		for _, p := range paths {
+			if !strings.ContainsAny(seg, baidupcs.ShellPatternCharacters) {
+				next = append(next, path.Join(p, seg))
+				continue
+			}
+			fds, err := l.List(ctx, p, nil)
+			if err != nil {
+				return nil, err
+			}
+			for _, fd := range fds {
+				if matched, _ := path.Match(seg, fd.Filename); matched {
+					next = append(next, fd.Path)
+				}
+			}
+		}
+		paths = next
+	}
+	return paths, nil
+}
+
+// Mkdir 创建目录, 父目录不存在时一并创建
+func (l *Local) Mkdir(ctx context.Context, p string) error {
+	real, err := l.realPath(p)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(real, 0755)
+}
+
+// Remove 删除文件/目录, 删除的文件移入回收站
+func (l *Local) Remove(ctx context.Context, paths ...string) error {
+	for _, p := range paths {
+		if l.clean(p) == "/" {
+			return &os.PathError{Op: "remove", Path: p, Err: ErrLocalReservedPath}
+		}
+		err := l.recycle(p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rename 重命名文件/目录
+func (l *Local) Rename(ctx context.Context, from, to string) error {
+	return l.Move(ctx, &baidupcs.CpMvJSON{From: from, To: to})
+}
+
+// Copy 复制文件/目录, 目标已存在时返回错误
+func (l *Local) Copy(ctx context.Context, cpmvJSON ...*baidupcs.CpMvJSON) error {
+	for _, cpmv := range cpmvJSON {
+		from, to, err := l.cpmvPaths(cpmv)
+		if err != nil {
+			return err
+		}
+		err = l.copyAll(ctx, from, to)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Move 移动文件/目录, 目标已存在时返回错误
+func (l *Local) Move(ctx context.Context, cpmvJSON ...*baidupcs.CpMvJSON) error {
+	for _, cpmv := range cpmvJSON {
+		from, to, err := l.cpmvPaths(cpmv)
+		if err != nil {
+			return err
+		}
+		err = os.Rename(from, to)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cpmvPaths 检查并转换复制/移动的源路径和目标路径
+func (l *Local) cpmvPaths(cpmv *baidupcs.CpMvJSON) (from, to string, err error) {
+	from, err = l.realPath(cpmv.From)
+	if err != nil {
+		return
+	}
+	to, err = l.realPath(cpmv.To)
+	if err != nil {
+		return
+	}
+	if l.clean(cpmv.From) == "/" {
+		return "", "", &os.PathError{Op: "move", Path: cpmv.From, Err: ErrLocalReservedPath}
+	}
+	if _, err = os.Stat(from); err != nil {
+		return
+	}
+	if _, err = os.Lstat(to); err == nil {
+		return "", "", &os.PathError{Op: "move", Path: cpmv.To, Err: os.ErrExist}
+	}
+	err = os.MkdirAll(filepath.Dir(to), 0755)
+	return
+}
+
+// Upload 复制服务器本地文件, policy 同上传的重名文件策略
+func (l *Local) Upload(ctx context.Context, localPath, savePath, policy string) error {
+	to, err := l.realPath(savePath)
+	if err != nil {
+		return err
+	}
+	src, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	if src.IsDir() {
+		return &os.PathError{Op: "upload", Path: localPath, Err: errors.New("is a directory")}
+	}
+
+	if dst, err := os.Stat(to); err == nil {
+		switch policy {
+		case baidupcs.SkipPolicy:
+			return nil
+		case baidupcs.RsyncPolicy:
+			if dst.Size() == src.Size() {
+				return nil
+			}
+		}
+		if dst.IsDir() {
+			return &os.PathError{Op: "upload", Path: savePath, Err: os.ErrExist}
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(to), 0755)
+	if err != nil {
+		return err
+	}
+	return copyFile(ctx, localPath, to)
+}
+
+// Download 复制文件到服务器本地, overwrite 为 false 时本地文件已存在返回错误
+func (l *Local) Download(ctx context.Context, p, localPath string, overwrite bool) error {
+	from, err := l.realPath(p)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return &os.PathError{Op: "download", Path: p, Err: errors.New("is a directory")}
+	}
+	if _, err = os.Lstat(localPath); err == nil && !overwrite {
+		return &os.PathError{Op: "download", Path: localPath, Err: os.ErrExist}
+	}
+
+	err = os.MkdirAll(filepath.Dir(localPath), 0755)
+	if err != nil {
+		return err
+	}
+	return copyFile(ctx, from, localPath)
+}
+
+// DownloadURL 返回服务器本地文件的 file:// 链接
+func (l *Local) DownloadURL(ctx context.Context, p string) ([]string, error) {
+	real, err := l.realPath(p)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &os.PathError{Op: "locate", Path: p, Err: errors.New("is a directory")}
+	}
+	u := &url.URL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(real),
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		// windows 盘符
+		u.Path = "/" + u.Path
+	}
+	return []string{u.String()}, nil
+}
+
+// copyAll 复制文件或整个目录, 目录中的符号链接解析后不能超出根目录
+func (l *Local) copyAll(ctx context.Context, from, to string) error {
+	return filepath.Walk(from, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if err = l.checkInRoot(name); err != nil {
+				return &os.PathError{Op: "copy", Path: name, Err: err}
+			}
+		}
+		rel, _ := filepath.Rel(from, name)
+		target := filepath.Join(to, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		return copyFile(ctx, name, target)
+	})
+}
+
+// copyFile 复制单个文件, 保留修改时间
+func copyFile(ctx context.Context, from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm()|0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, &ctxReader{ctx: ctx, r: src})
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Chtimes(to, info.ModTime(), info.ModTime())
+}
+
+// ctxReader ctx 取消时中断读取
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr *ctxReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
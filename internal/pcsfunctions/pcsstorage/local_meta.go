@@ -0,0 +1,394 @@
+package pcsstorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+)
+
+const (
+	// LocalRecycleDays 本地回收站保留天数, 同百度网盘
+	LocalRecycleDays = 10
+	// localPageSize 回收站和分享列表每页的数量
+	localPageSize = 100
+	// localMetaFileName 元数据文件名
+	localMetaFileName = "local.json"
+	// localRecycleDirName 回收站目录名
+	localRecycleDirName = "recycle"
+)
+
+type (
+	// localRecycleItem 本地回收站中的文件/目录
+	localRecycleItem struct {
+		baidupcs.RecycleFDInfo
+		DeletedAt int64 `json:"deleted_at"`
+	}
+
+	// localShare 本地分享记录
+	localShare struct {
+		ShareID   int64    `json:"share_id"`
+		Paths     []string `json:"paths"`
+		FsIDs     []int64  `json:"fs_ids"`
+		Pwd       string   `json:"pwd"`
+		ShortURL  string   `json:"short_url"`
+		CreatedAt int64    `json:"created_at"`
+		Period    int      `json:"period"` // 有效期天数, 0 为永久
+	}
+
+	// localMeta 本地目录后端的元数据
+	localMeta struct {
+		LastID  int64               `json:"last_id"`
+		Recycle []*localRecycleItem `json:"recycle"`
+		Shares  []*localShare       `json:"shares"`
+	}
+)
+
+var (
+	// ErrLocalRecycleNotFound 回收站中不存在该文件
+	ErrLocalRecycleNotFound = errors.New("recycle item not found")
+	// ErrLocalShareNotFound 分享不存在
+	ErrLocalShareNotFound = errors.New("share not found")
+)
+
+func (l *Local) metaDir() string {
+	return filepath.Join(l.Root, LocalMetaDirName)
+}
+
+func (l *Local) recycleDir() string {
+	return filepath.Join(l.metaDir(), localRecycleDirName)
+}
+
+// loadMeta 读取元数据, 调用前需加锁
+func (l *Local) loadMeta() (*localMeta, error) {
+	meta := &localMeta{}
+	f, err := os.Open(filepath.Join(l.metaDir(), localMetaFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	err = jsonhelper.UnmarshalData(f, meta)
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// saveMeta 保存元数据, 先写入临时文件再替换, 调用前需加锁
+func (l *Local) saveMeta(meta *localMeta) error {
+	buf := &bytes.Buffer{}
+	err := jsonhelper.MarshalData(buf, meta)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(l.metaDir(), 0700)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(l.metaDir(), localMetaFileName)
+	tmp := filename + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// updateMeta 加锁读取元数据, fn 返回 nil 时保存
+func (l *Local) updateMeta(fn func(meta *localMeta) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	meta, err := l.loadMeta()
+	if err != nil {
+		return err
+	}
+	err = fn(meta)
+	if err != nil {
+		return err
+	}
+	return l.saveMeta(meta)
+}
+
+// nextID 生成元数据中不重复的id
+func (meta *localMeta) nextID() int64 {
+	meta.LastID++
+	return meta.LastID
+}
+
+// expireRecycle 清理超过保留天数的回收站文件
+func (l *Local) expireRecycle(meta *localMeta) {
+	deadline := time.Now().AddDate(0, 0, -LocalRecycleDays).Unix()
+	kept := meta.Recycle[:0]
+	for _, item := range meta.Recycle {
+		if item.DeletedAt < deadline {
+			os.RemoveAll(filepath.Join(l.recycleDir(), strconv.FormatInt(item.FsID, 10)))
+			continue
+		}
+		kept = append(kept, item)
+	}
+	meta.Recycle = kept
+}
+
+// recycle 将文件/目录移入回收站
+func (l *Local) recycle(p string) error {
+	real, err := l.realPath(p)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return err
+	}
+
+	return l.updateMeta(func(meta *localMeta) error {
+		err := os.MkdirAll(l.recycleDir(), 0700)
+		if err != nil {
+			return err
+		}
+
+		item := &localRecycleItem{
+			RecycleFDInfo: baidupcs.RecycleFDInfo{
+				FsID:     meta.nextID(),
+				Path:     l.clean(p),
+				Filename: path.Base(l.clean(p)),
+				Ctime:    info.ModTime().Unix(),
+				Mtime:    info.ModTime().Unix(),
+			},
+			DeletedAt: time.Now().Unix(),
+		}
+		if info.IsDir() {
+			item.Isdir = 1
+		} else {
+			item.Size = info.Size()
+		}
+
+		err = os.Rename(real, filepath.Join(l.recycleDir(), strconv.FormatInt(item.FsID, 10)))
+		if err != nil {
+			return err
+		}
+		meta.Recycle = append(meta.Recycle, item)
+		return nil
+	})
+}
+
+// RecycleList 列出回收站, 最近删除的排在前面
+func (l *Local) RecycleList(ctx context.Context, page int) (baidupcs.RecycleFDInfoList, error) {
+	var list baidupcs.RecycleFDInfoList
+	err := l.updateMeta(func(meta *localMeta) error {
+		l.expireRecycle(meta)
+		now := time.Now().Unix()
+		for i := len(meta.Recycle) - 1; i >= 0; i-- {
+			item := meta.Recycle[i]
+			info := item.RecycleFDInfo
+			info.LeftTime = LocalRecycleDays - int((now-item.DeletedAt)/86400)
+			list = append(list, &info)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paginate(list, page), nil
+}
+
+// RecycleRestore 恢复回收站中的文件/目录到原路径, 原路径已存在时返回错误
+func (l *Local) RecycleRestore(ctx context.Context, fsIDs ...int64) error {
+	return l.updateMeta(func(meta *localMeta) error {
+		for _, fsID := range fsIDs {
+			i := meta.findRecycle(fsID)
+			if i < 0 {
+				return ErrLocalRecycleNotFound
+			}
+			item := meta.Recycle[i]
+			real, err := l.realPath(item.Path)
+			if err != nil {
+				return err
+			}
+			if _, err = os.Lstat(real); err == nil {
+				return &os.PathError{Op: "restore", Path: item.Path, Err: os.ErrExist}
+			}
+			err = os.MkdirAll(filepath.Dir(real), 0755)
+			if err != nil {
+				return err
+			}
+			err = os.Rename(filepath.Join(l.recycleDir(), strconv.FormatInt(fsID, 10)), real)
+			if err != nil {
+				return err
+			}
+			meta.Recycle = append(meta.Recycle[:i], meta.Recycle[i+1:]...)
+		}
+		return nil
+	})
+}
+
+// RecycleDelete 彻底删除回收站中的文件/目录
+func (l *Local) RecycleDelete(ctx context.Context, fsIDs ...int64) error {
+	return l.updateMeta(func(meta *localMeta) error {
+		for _, fsID := range fsIDs {
+			i := meta.findRecycle(fsID)
+			if i < 0 {
+				return ErrLocalRecycleNotFound
+			}
+			err := os.RemoveAll(filepath.Join(l.recycleDir(), strconv.FormatInt(fsID, 10)))
+			if err != nil {
+				return err
+			}
+			meta.Recycle = append(meta.Recycle[:i], meta.Recycle[i+1:]...)
+		}
+		return nil
+	})
+}
+
+// RecycleClear 清空回收站
+func (l *Local) RecycleClear(ctx context.Context) (n int, err error) {
+	err = l.updateMeta(func(meta *localMeta) error {
+		n = len(meta.Recycle)
+		meta.Recycle = nil
+		return os.RemoveAll(l.recycleDir())
+	})
+	return
+}
+
+func (meta *localMeta) findRecycle(fsID int64) int {
+	for i, item := range meta.Recycle {
+		if item.FsID == fsID {
+			return i
+		}
+	}
+	return -1
+}
+
+// ShareSet 创建分享, 仅记录分享信息, 链接为 local://s/<短链接>
+func (l *Local) ShareSet(ctx context.Context, paths []string, option *baidupcs.ShareOption) (*baidupcs.Shared, error) {
+	if option == nil {
+		option = &baidupcs.ShareOption{}
+	}
+	pwd := option.Password
+	if len(pwd) != 4 {
+		pwd = baidupcs.CreatePasswd()
+	}
+
+	share := &localShare{
+		Pwd:       pwd,
+		CreatedAt: time.Now().Unix(),
+		Period:    option.Period,
+	}
+	for _, p := range paths {
+		real, err := l.realPath(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = os.Stat(real); err != nil {
+			return nil, err
+		}
+		share.Paths = append(share.Paths, l.clean(p))
+		share.FsIDs = append(share.FsIDs, l.fsID(p))
+	}
+
+	err := l.updateMeta(func(meta *localMeta) error {
+		share.ShareID = meta.nextID()
+		share.ShortURL = "1" + strconv.FormatInt(share.ShareID, 36)
+		meta.Shares = append(meta.Shares, share)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link := share.link()
+	if option.IsCombined {
+		link += "?pwd=" + pwd
+	}
+	return &baidupcs.Shared{
+		Link:    link,
+		Pwd:     pwd,
+		ShareID: share.ShareID,
+	}, nil
+}
+
+func (share *localShare) link() string {
+	return "local://s/" + share.ShortURL
+}
+
+// ShareList 列出分享, 最近创建的排在前面
+func (l *Local) ShareList(ctx context.Context, page int) (baidupcs.ShareRecordInfoList, error) {
+	l.mu.Lock()
+	meta, err := l.loadMeta()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	var records baidupcs.ShareRecordInfoList
+	for i := len(meta.Shares) - 1; i >= 0; i-- {
+		share := meta.Shares[i]
+		record := &baidupcs.ShareRecordInfo{
+			ShareID:   share.ShareID,
+			FsIds:     share.FsIDs,
+			Passwd:    share.Pwd,
+			Shortlink: share.link(),
+		}
+		if len(share.Paths) > 0 {
+			record.TypicalPath = share.Paths[0]
+		}
+		if share.Period > 0 {
+			left := share.CreatedAt + int64(share.Period)*86400 - now
+			if left <= 0 {
+				record.ExpireType = -1
+			} else {
+				record.ExpireTime = left
+			}
+		}
+		records = append(records, record)
+	}
+	return paginate(records, page), nil
+}
+
+// ShareCancel 取消分享
+func (l *Local) ShareCancel(ctx context.Context, shareIDs []int64) error {
+	return l.updateMeta(func(meta *localMeta) error {
+		for _, id := range shareIDs {
+			found := false
+			for i, share := range meta.Shares {
+				if share.ShareID == id {
+					meta.Shares = append(meta.Shares[:i], meta.Shares[i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return ErrLocalShareNotFound
+			}
+		}
+		return nil
+	})
+}
+
+// paginate 返回第 page 页, page 从 1 开始
+func paginate[T any](list []T, page int) []T {
+	if page < 1 {
+		page = 1
+	}
+	start := (page - 1) * localPageSize
+	if start >= len(list) {
+		return nil
+	}
+	end := start + localPageSize
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end]
+}
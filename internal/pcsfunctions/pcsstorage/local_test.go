@@ -0,0 +1,94 @@
+package pcsstorage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+// newSymlinkLocal 创建本地目录后端, 根目录中的 link 指向根目录外的目录, dangling 指向根目录外不存在的文件
+func newSymlinkLocal(t *testing.T) (l *Local, outside string) {
+	root, outside := t.TempDir(), t.TempDir()
+	err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(root, "file"), []byte("file"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skip("symlink not supported:", err)
+	}
+	err = os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(filepath.Join(root, "file"), filepath.Join(root, "inside"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = NewLocal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, outside
+}
+
+func TestLocalSymlinkEscape(t *testing.T) {
+	l, outside := newSymlinkLocal(t)
+	ctx := context.Background()
+	upload := filepath.Join(t.TempDir(), "upload")
+	if err := os.WriteFile(upload, []byte("upload"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checks := map[string]error{}
+	_, checks["list"] = l.List(ctx, "/link", nil)
+	_, checks["meta"] = l.Meta(ctx, "/link/secret")
+	_, checks["download url"] = l.DownloadURL(ctx, "/link/secret")
+	checks["download"] = l.Download(ctx, "/link/secret", filepath.Join(t.TempDir(), "secret"), true)
+	checks["mkdir"] = l.Mkdir(ctx, "/link/dir")
+	checks["upload"] = l.Upload(ctx, upload, "/link/upload", baidupcs.OverWritePolicy)
+	checks["upload dangling"] = l.Upload(ctx, upload, "/dangling", baidupcs.OverWritePolicy)
+	checks["remove"] = l.Remove(ctx, "/link/secret")
+	checks["move"] = l.Move(ctx, &baidupcs.CpMvJSON{From: "/file", To: "/link/file"})
+	checks["copy"] = l.Copy(ctx, &baidupcs.CpMvJSON{From: "/link", To: "/copy"})
+	for op, err := range checks {
+		if !errors.Is(err, ErrLocalOutsideRoot) {
+			t.Errorf("%s: want ErrLocalOutsideRoot, got %v", op, err)
+		}
+	}
+
+	entries, err := os.ReadDir(outside)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "secret" {
+		t.Errorf("directory outside root was modified: %v", entries)
+	}
+}
+
+func TestLocalSymlinkInside(t *testing.T) {
+	l, _ := newSymlinkLocal(t)
+	ctx := context.Background()
+
+	fd, err := l.Meta(ctx, "/inside")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fd.Size != int64(len("file")) {
+		t.Errorf("unexpected size %d", fd.Size)
+	}
+	if err = l.Mkdir(ctx, "/new/dir"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.List(ctx, "/new", nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,89 @@
+// Package pcsstorage 存储后端, 以统一的接口操作百度网盘或本地目录
+package pcsstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// Storage 存储后端, 路径均为以 / 开头的绝对路径
+	Storage interface {
+		// Name 后端名称
+		Name() string
+
+		// List 列出目录下的文件和目录
+		List(ctx context.Context, dir string, options *baidupcs.OrderOptions) (baidupcs.FileDirectoryList, error)
+		// Meta 获取单个文件/目录的元信息
+		Meta(ctx context.Context, p string) (*baidupcs.FileDirectory, error)
+		// Search 按文件名关键字搜索
+		Search(ctx context.Context, dir, keyword string, recurse bool) (baidupcs.FileDirectoryList, error)
+		// Match 通配符匹配文件路径, pattern 为绝对路径
+		Match(ctx context.Context, pattern string) ([]string, error)
+
+		// Mkdir 创建目录
+		Mkdir(ctx context.Context, p string) error
+		// Remove 删除文件/目录, 删除的文件进入回收站
+		Remove(ctx context.Context, paths ...string) error
+		// Rename 重命名文件/目录
+		Rename(ctx context.Context, from, to string) error
+		// Copy 复制文件/目录
+		Copy(ctx context.Context, cpmvJSON ...*baidupcs.CpMvJSON) error
+		// Move 移动文件/目录
+		Move(ctx context.Context, cpmvJSON ...*baidupcs.CpMvJSON) error
+
+		// Upload 上传服务器本地文件, 阻塞直到上传结束
+		Upload(ctx context.Context, localPath, savePath, policy string) error
+		// Download 下载文件到服务器本地, 阻塞直到下载结束
+		Download(ctx context.Context, p, localPath string, overwrite bool) error
+		// DownloadURL 获取文件的下载链接, 第一条为首选链接
+		DownloadURL(ctx context.Context, p string) ([]string, error)
+
+		// ShareSet 创建分享
+		ShareSet(ctx context.Context, paths []string, option *baidupcs.ShareOption) (*baidupcs.Shared, error)
+		// ShareList 列出分享
+		ShareList(ctx context.Context, page int) (baidupcs.ShareRecordInfoList, error)
+		// ShareCancel 取消分享
+		ShareCancel(ctx context.Context, shareIDs []int64) error
+
+		// RecycleList 列出回收站
+		RecycleList(ctx context.Context, page int) (baidupcs.RecycleFDInfoList, error)
+		// RecycleRestore 恢复回收站中的文件/目录
+		RecycleRestore(ctx context.Context, fsIDs ...int64) error
+		// RecycleDelete 彻底删除回收站中的文件/目录
+		RecycleDelete(ctx context.Context, fsIDs ...int64) error
+		// RecycleClear 清空回收站, 返回删除的数量
+		RecycleClear(ctx context.Context) (int, error)
+	}
+)
+
+const (
+	// NameBaidu 百度网盘后端
+	NameBaidu = "baidu"
+	// NameLocal 本地目录后端
+	NameLocal = "local"
+)
+
+var (
+	// ErrUnknownBackend 未知的存储后端
+	ErrUnknownBackend = errors.New("unknown storage backend")
+)
+
+// Parse 解析存储后端描述, 支持 baidu 和 local:<目录>
+func Parse(spec string) (Storage, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	switch strings.ToLower(name) {
+	case "", NameBaidu:
+		return NewBaidu(nil), nil
+	case NameLocal:
+		if arg == "" {
+			return nil, fmt.Errorf("%s: 未指定本地目录", spec)
+		}
+		return NewLocal(arg)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownBackend, spec)
+}
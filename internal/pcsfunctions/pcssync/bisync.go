@@ -0,0 +1,423 @@
+package pcssync
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+)
+
+const (
+	// ConflictSuffix 冲突副本的文件名后缀, 后接冲突发生的时间
+	ConflictSuffix = ".conflict-"
+
+	// batchMetaSize 批量获取元信息时每次请求的文件数
+	batchMetaSize = 100
+)
+
+var (
+	// errDiffReset 服务器要求重新全量列出
+	errDiffReset = errors.New("diff cursor reset")
+)
+
+// bisync 双向同步: 根据上次同步的状态判断哪一侧发生了修改, 将修改传播到另一侧.
+// 两边都修改过的文件保留两份, 本地文件重命名为冲突副本后上传, 再下载网盘的版本
+func (s *Syncer) bisync(ctx context.Context) ([]*Change, error) {
+	remotes, cursor, err := s.remoteSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := s.walkLocal()
+	if err == ErrLocalDirNotExist && len(s.state.Files) == 0 {
+		// 首次同步时本地目录可以不存在, 已同步过的目录不存在时可能未挂载, 不能当作全部删除
+		locals, err = map[string]*localFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]struct{}{}
+	for rel := range locals {
+		paths[rel] = struct{}{}
+	}
+	for rel := range remotes {
+		paths[rel] = struct{}{}
+	}
+	for rel := range s.state.Files {
+		paths[rel] = struct{}{}
+	}
+
+	var (
+		changes       []*Change
+		uploads       []*Change
+		downloads     []*Change
+		conflicts     []*Change
+		localDeletes  []*Change
+		remoteDeletes []*Change
+	)
+	for rel := range paths {
+		c := s.compareBoth(rel, locals[rel], remotes[rel])
+		if c == nil {
+			continue
+		}
+		changes = append(changes, c)
+		switch {
+		case c.Action == ActionConflict:
+			conflicts = append(conflicts, c)
+		case c.Action == ActionAdd || c.Action == ActionUpdate:
+			if c.Side == SideRemote {
+				uploads = append(uploads, c)
+			} else {
+				downloads = append(downloads, c)
+			}
+		case c.Action == ActionDelete:
+			if c.Side == SideRemote {
+				remoteDeletes = append(remoteDeletes, c)
+			} else {
+				localDeletes = append(localDeletes, c)
+			}
+		}
+	}
+
+	if s.Options.DryRun {
+		sortChanges(changes)
+		return changes, nil
+	}
+	err = s.checkDeletes(len(localDeletes), len(locals), len(remotes) == 0)
+	if err == nil {
+		err = s.checkDeletes(len(remoteDeletes), len(remotes), len(locals) == 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 冲突: 本地文件改名后作为新文件上传, 原路径下载网盘的版本
+	for _, c := range conflicts {
+		rel := s.conflictName(c.Path, locals, remotes)
+		err := os.Rename(s.localPath(c.Path), s.localPath(rel))
+		if err != nil {
+			c.Err = err.Error()
+			continue
+		}
+		lf := locals[c.Path]
+		locals[rel] = &localFile{path: s.localPath(rel), size: lf.size, mtime: lf.mtime, md5: lf.md5}
+		delete(locals, c.Path)
+		c.Reason = "两边均已修改, 本地文件已重命名为 " + rel
+
+		up := &Change{Action: ActionAdd, Path: rel, Size: lf.size, Side: SideRemote, Reason: "冲突副本"}
+		changes = append(changes, up)
+		uploads = append(uploads, up)
+		downloads = append(downloads, &Change{Action: ActionUpdate, Path: c.Path, Size: remotes[c.Path].Size, Side: SideLocal, Reason: "冲突, 使用网盘的版本"})
+	}
+	sortChanges(changes)
+
+	if len(uploads) > 0 {
+		s.pushUploads(ctx, uploads, locals)
+		s.recordUploads(ctx, uploads, locals)
+	}
+	if len(downloads) > 0 {
+		s.pullDownloads(ctx, downloads, remotes)
+	}
+	for _, c := range localDeletes {
+		err := os.Remove(locals[c.Path].path)
+		if err != nil && !os.IsNotExist(err) {
+			c.Err = err.Error()
+			continue
+		}
+		delete(s.state.Files, c.Path)
+	}
+	if len(remoteDeletes) > 0 {
+		paths := make([]string, 0, len(remoteDeletes))
+		for _, c := range remoteDeletes {
+			paths = append(paths, s.remotePath(c.Path))
+		}
+		pcsError := s.PCS.RemoveContext(ctx, paths...)
+		for _, c := range remoteDeletes {
+			if pcsError != nil {
+				c.Err = pcsError.Error()
+				continue
+			}
+			delete(s.state.Files, c.Path)
+		}
+	}
+
+	// 全部成功时才记录新的 cursor, 否则下次从旧的 cursor 重新检测
+	failed := ctx.Err() != nil
+	for _, c := range changes {
+		if c.Err != "" {
+			failed = true
+			break
+		}
+	}
+	for _, c := range downloads {
+		if c.Err != "" {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		s.state.Cursor = cursor
+	}
+	return changes, nil
+}
+
+// compareBoth 比较本地文件, 网盘文件和上次同步的状态, 决定需要执行的操作.
+// lf, fd 为空表示对应的一侧不存在, 两侧都不存在时清除状态并返回 nil
+func (s *Syncer) compareBoth(rel string, lf *localFile, fd *baidupcs.FileDirectory) *Change {
+	st := s.state.Files[rel]
+	switch {
+	case lf == nil && fd == nil:
+		if !s.Options.DryRun {
+			delete(s.state.Files, rel)
+		}
+		return nil
+
+	case fd == nil:
+		if st == nil {
+			return &Change{Action: ActionAdd, Path: rel, Size: lf.size, Side: SideRemote}
+		}
+		if s.localChanged(lf, st) {
+			return &Change{Action: ActionAdd, Path: rel, Size: lf.size, Side: SideRemote, Reason: "网盘已删除, 本地已修改"}
+		}
+		return &Change{Action: ActionDelete, Path: rel, Size: lf.size, Side: SideLocal, Reason: "网盘已删除"}
+
+	case lf == nil:
+		if st == nil {
+			return &Change{Action: ActionAdd, Path: rel, Size: fd.Size, Side: SideLocal}
+		}
+		if remoteChanged(fd, st) {
+			return &Change{Action: ActionAdd, Path: rel, Size: fd.Size, Side: SideLocal, Reason: "本地已删除, 网盘已修改"}
+		}
+		return &Change{Action: ActionDelete, Path: rel, Size: fd.Size, Side: SideRemote, Reason: "本地已删除"}
+	}
+
+	c := &Change{Path: rel, Size: lf.size}
+	if st != nil {
+		lc, rc := s.localChanged(lf, st), remoteChanged(fd, st)
+		switch {
+		case !lc && !rc:
+			c.Action = ActionSkip
+			s.recordSame(rel, lf, fd)
+			return c
+		case lc && !rc:
+			c.Action, c.Side, c.Reason = ActionUpdate, SideRemote, "本地已修改"
+			return c
+		case !lc && rc:
+			c.Action, c.Side, c.Size, c.Reason = ActionUpdate, SideLocal, fd.Size, "网盘已修改"
+			return c
+		}
+	}
+
+	// 两边均为新增或均已修改, 内容相同时不算冲突
+	if lf.size == fd.Size {
+		remoteMD5 := baidupcs.DecryptMD5(fd.MD5)
+		sum, err := lf.sumMD5()
+		if err != nil {
+			c.Action, c.Err = ActionSkip, err.Error()
+			return c
+		}
+		if remoteMD5 != "" && strings.EqualFold(sum, remoteMD5) {
+			c.Action, c.Reason = ActionSkip, "md5相同"
+			s.recordSame(rel, lf, fd)
+			return c
+		}
+	}
+	c.Action, c.Reason = ActionConflict, "两边均已修改"
+	return c
+}
+
+// localChanged 本地文件在上次同步后是否被修改, 只有修改时间变化时比较 md5
+func (s *Syncer) localChanged(lf *localFile, st *StateEntry) bool {
+	if lf.size != st.Size {
+		return true
+	}
+	if lf.mtime == st.LocalMtime {
+		return false
+	}
+	if st.MD5 == "" {
+		return true
+	}
+	sum, err := lf.sumMD5()
+	if err != nil {
+		return true
+	}
+	return !strings.EqualFold(sum, st.MD5)
+}
+
+// remoteChanged 网盘文件在上次同步后是否被修改, 只有修改时间变化时比较 md5
+func remoteChanged(fd *baidupcs.FileDirectory, st *StateEntry) bool {
+	if fd.Size != st.Size {
+		return true
+	}
+	if fd.Mtime == st.RemoteMtime {
+		return false
+	}
+	remoteMD5 := baidupcs.DecryptMD5(fd.MD5)
+	return remoteMD5 == "" || st.MD5 == "" || !strings.EqualFold(remoteMD5, st.MD5)
+}
+
+// conflictName 生成冲突副本的相对路径, 例如 a/b.conflict-20060102-150405.txt, 与已有文件重名时追加序号
+func (s *Syncer) conflictName(rel string, locals map[string]*localFile, remotes map[string]*baidupcs.FileDirectory) string {
+	var (
+		ext  = path.Ext(rel)
+		base = strings.TrimSuffix(rel, ext) + ConflictSuffix + time.Now().Format("20060102-150405")
+		name = base + ext
+	)
+	for i := 1; ; i++ {
+		_, localExists := locals[name]
+		_, remoteExists := remotes[name]
+		if !localExists && !remoteExists {
+			return name
+		}
+		name = base + "-" + strconv.Itoa(i) + ext
+	}
+}
+
+// recordUploads 获取上传成功的文件在网盘的元信息, 记录同步状态
+func (s *Syncer) recordUploads(ctx context.Context, uploads []*Change, locals map[string]*localFile) {
+	var done []*Change
+	for _, c := range uploads {
+		if c.Err == "" {
+			done = append(done, c)
+		}
+	}
+
+	for len(done) > 0 {
+		n := min(len(done), batchMetaSize)
+		batch := done[:n]
+		done = done[n:]
+
+		paths := make([]string, 0, len(batch))
+		for _, c := range batch {
+			paths = append(paths, s.remotePath(c.Path))
+		}
+		fds, pcsError := s.PCS.FilesDirectoriesBatchMetaContext(ctx, paths...)
+		if pcsError != nil {
+			syncVerbose.Warnf("get uploaded files meta: %s\n", pcsError)
+			continue
+		}
+		byPath := make(map[string]*baidupcs.FileDirectory, len(fds))
+		for _, fd := range fds {
+			byPath[fd.Path] = fd
+		}
+		for _, c := range batch {
+			fd := byPath[s.remotePath(c.Path)]
+			if fd == nil {
+				continue
+			}
+			lf := locals[c.Path]
+			s.state.Files[c.Path] = &StateEntry{
+				Size:        lf.size,
+				LocalMtime:  lf.mtime,
+				RemoteMtime: fd.Mtime,
+				MD5:         baidupcs.DecryptMD5(fd.MD5),
+			}
+		}
+	}
+}
+
+// remoteSnapshot 获取网盘目录下的文件和检测时的 cursor.
+// 有上次同步的 cursor 时, 在上次同步的状态上应用网盘的增量变更, 否则列出整个目录
+func (s *Syncer) remoteSnapshot(ctx context.Context) (map[string]*baidupcs.FileDirectory, string, error) {
+	if s.state.Cursor != "" {
+		remotes, cursor, err := s.remoteIncremental(ctx)
+		if err == nil {
+			return remotes, cursor, nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+		syncVerbose.Warnf("incremental detection failed, fallback to full listing: %s\n", err)
+	}
+
+	// 先获取 cursor 再列出目录, 列出期间的修改会在下次同步时检测到
+	cursor, _, err := s.remoteDiff(ctx, "", nil)
+	if err != nil {
+		syncVerbose.Warnf("get diff cursor: %s\n", err)
+		cursor = ""
+	}
+	remotes, err := s.walkRemote(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return remotes, cursor, nil
+}
+
+// remoteIncremental 在上次同步的状态上应用 cursor 之后的变更
+func (s *Syncer) remoteIncremental(ctx context.Context) (map[string]*baidupcs.FileDirectory, string, error) {
+	remotes := make(map[string]*baidupcs.FileDirectory, len(s.state.Files))
+	for rel, st := range s.state.Files {
+		remotes[rel] = &baidupcs.FileDirectory{
+			Path:     s.remotePath(rel),
+			Filename: path.Base(rel),
+			Size:     st.Size,
+			Mtime:    st.RemoteMtime,
+			MD5:      st.MD5,
+		}
+	}
+
+	var (
+		prefix = strings.TrimSuffix(s.Options.RemoteDir, baidupcs.PathSeparator) + baidupcs.PathSeparator
+		dirs   []string
+	)
+	cursor, reset, err := s.remoteDiff(ctx, s.state.Cursor, func(fd *baidupcs.FileDirectory, deleted bool) {
+		if fd.Path == s.Options.RemoteDir && deleted {
+			clear(remotes)
+			return
+		}
+		if !strings.HasPrefix(fd.Path, prefix) {
+			return
+		}
+		rel := strings.TrimPrefix(fd.Path, prefix)
+		switch {
+		case deleted && fd.Isdir:
+			for k := range remotes {
+				if strings.HasPrefix(k, rel+"/") {
+					delete(remotes, k)
+				}
+			}
+		case deleted:
+			delete(remotes, rel)
+		case fd.Isdir:
+			// 移动或复制进来的目录不会列出其中的文件
+			dirs = append(dirs, fd.Path)
+		default:
+			remotes[rel] = fd
+		}
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if reset {
+		return nil, "", errDiffReset
+	}
+
+	for _, dir := range dirs {
+		var walkErr error
+		s.PCS.FilesDirectoriesRecurseListContext(ctx, dir, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+			if pcsError != nil {
+				if depth != 0 || pcsError.GetRemoteErrCode() != 31066 {
+					walkErr = pcsError
+				}
+				return false
+			}
+			if !fd.Isdir && strings.HasPrefix(fd.Path, prefix) {
+				remotes[strings.TrimPrefix(fd.Path, prefix)] = fd
+			}
+			return true
+		})
+		if walkErr == nil {
+			walkErr = ctx.Err()
+		}
+		if walkErr != nil {
+			return nil, "", walkErr
+		}
+	}
+	return remotes, cursor, nil
+}
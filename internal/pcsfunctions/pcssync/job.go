@@ -0,0 +1,190 @@
+package pcssync
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+type (
+	// JobStatus 同步任务状态
+	JobStatus string
+
+	// Job 后台执行的同步任务
+	Job struct {
+		ID         string    `json:"id"`
+		Options    Options   `json:"options"`
+		Status     JobStatus `json:"status"`
+		Result     *Result   `json:"result,omitempty"`
+		Err        string    `json:"error,omitempty"`
+		Runs       int       `json:"runs"`
+		CreatedAt  int64     `json:"created_at"`
+		LastRunAt  int64     `json:"last_run_at,omitempty"`
+		NextRunAt  int64     `json:"next_run_at,omitempty"`
+		FinishedAt int64     `json:"finished_at,omitempty"`
+
+		cancel context.CancelFunc
+	}
+
+	// JobManager 同步任务管理器
+	JobManager struct {
+		mu     sync.RWMutex
+		lastID int64
+		jobs   map[string]*Job
+	}
+)
+
+const (
+	// JobRunning 执行中
+	JobRunning JobStatus = "running"
+	// JobWaiting 周期任务等待下次执行
+	JobWaiting JobStatus = "waiting"
+	// JobSucceeded 已完成
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed 已失败
+	JobFailed JobStatus = "failed"
+	// JobCanceled 已取消
+	JobCanceled JobStatus = "canceled"
+)
+
+var (
+	// DefaultJobs 默认的同步任务管理器
+	DefaultJobs = NewJobManager()
+
+	// ErrJobNotFound 同步任务不存在
+	ErrJobNotFound = errors.New("sync job not found")
+)
+
+// NewJobManager 初始化JobManager
+func NewJobManager() *JobManager {
+	return &JobManager{
+		jobs: map[string]*Job{},
+	}
+}
+
+// Start 在后台开始同步任务, opt.Interval 大于0时周期执行直到取消, 单次失败不会中止周期任务
+func (m *JobManager) Start(pcs *baidupcs.BaiduPCS, opt Options) (*Job, error) {
+	syncer, err := NewSyncer(pcs, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	n := time.Now().UnixNano() / int64(time.Millisecond)
+	if n <= m.lastID {
+		n = m.lastID + 1
+	}
+	m.lastID = n
+	job := &Job{
+		ID:        strconv.FormatInt(n, 36),
+		Options:   syncer.Options,
+		Status:    JobRunning,
+		CreatedAt: time.Now().Unix(),
+		cancel:    cancel,
+	}
+	m.jobs[job.ID] = job
+	m.mu.Unlock()
+
+	go m.run(ctx, cancel, job, syncer)
+	return job, nil
+}
+
+// run 执行同步任务
+func (m *JobManager) run(ctx context.Context, cancel context.CancelFunc, job *Job, syncer *Syncer) {
+	defer cancel()
+	interval := time.Duration(syncer.Options.Interval) * time.Second
+	for {
+		result, err := syncer.Run(ctx)
+
+		m.mu.Lock()
+		now := time.Now()
+		job.Result = result
+		job.Runs++
+		job.LastRunAt = now.Unix()
+		job.Err = ""
+		if err != nil {
+			job.Err = err.Error()
+		}
+		switch {
+		case ctx.Err() != nil:
+			job.Status, job.FinishedAt = JobCanceled, now.Unix()
+		case interval <= 0 && err != nil:
+			job.Status, job.FinishedAt = JobFailed, now.Unix()
+		case interval <= 0:
+			job.Status, job.FinishedAt = JobSucceeded, now.Unix()
+		default:
+			job.Status, job.NextRunAt = JobWaiting, now.Add(interval).Unix()
+		}
+		status := job.Status
+		m.mu.Unlock()
+		if status != JobWaiting {
+			return
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			m.mu.Lock()
+			job.Status, job.NextRunAt, job.FinishedAt = JobCanceled, 0, time.Now().Unix()
+			m.mu.Unlock()
+			return
+		case <-timer.C:
+		}
+
+		m.mu.Lock()
+		job.Status, job.NextRunAt = JobRunning, 0
+		m.mu.Unlock()
+	}
+}
+
+// Get 获取同步任务的快照
+func (m *JobManager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// List 按创建时间列出所有同步任务的快照, 不包含文件列表
+func (m *JobManager) List() []*Job {
+	m.mu.RLock()
+	list := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		snapshot := *job
+		if snapshot.Result != nil {
+			result := *snapshot.Result
+			result.Changes = nil
+			snapshot.Result = &result
+		}
+		list = append(list, &snapshot)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Cancel 取消同步任务
+func (m *JobManager) Cancel(id string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	job.cancel()
+	return nil
+}
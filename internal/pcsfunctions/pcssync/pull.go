@@ -0,0 +1,166 @@
+package pcssync
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+)
+
+// pull 网盘同步到本地: 下载新增和修改的文件, 可选删除本地多余的文件
+func (s *Syncer) pull(ctx context.Context) ([]*Change, error) {
+	remotes, err := s.walkRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := s.walkLocal()
+	if err == ErrLocalDirNotExist {
+		// 首次同步时本地目录可以不存在, 下载时创建
+		locals, err = map[string]*localFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		changes   []*Change
+		downloads []*Change
+		deletes   []*Change
+	)
+	for rel, fd := range remotes {
+		c := s.comparePull(rel, fd, locals[rel])
+		changes = append(changes, c)
+		if c.Action == ActionAdd || c.Action == ActionUpdate {
+			downloads = append(downloads, c)
+		}
+	}
+	if s.Options.Delete {
+		for rel, lf := range locals {
+			if _, ok := remotes[rel]; ok {
+				continue
+			}
+			c := &Change{Action: ActionDelete, Path: rel, Size: lf.size, Reason: "网盘不存在"}
+			changes = append(changes, c)
+			deletes = append(deletes, c)
+		}
+	}
+	sortChanges(changes)
+
+	if s.Options.DryRun {
+		return changes, nil
+	}
+	// 网盘目录不存在或为空时 remotes 为空, 不能据此删除本地的全部文件
+	err = s.checkDeletes(len(deletes), len(locals), len(remotes) == 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(downloads) > 0 {
+		s.pullDownloads(ctx, downloads, remotes)
+	}
+	for _, c := range deletes {
+		err := os.Remove(locals[c.Path].path)
+		if err != nil && !os.IsNotExist(err) {
+			c.Err = err.Error()
+			continue
+		}
+		delete(s.state.Files, c.Path)
+	}
+	return changes, nil
+}
+
+// comparePull 比较网盘文件和本地文件, lf 为空表示本地不存在.
+// 大小相同且上次同步后未修改的文件直接跳过, 否则比较 md5
+func (s *Syncer) comparePull(rel string, fd *baidupcs.FileDirectory, lf *localFile) *Change {
+	c := &Change{Path: rel, Size: fd.Size}
+	if lf == nil {
+		c.Action = ActionAdd
+		return c
+	}
+	if fd.Size != lf.size {
+		c.Action, c.Reason = ActionUpdate, "大小不同"
+		return c
+	}
+
+	st := s.state.Files[rel]
+	if st != nil && st.Size == lf.size && st.LocalMtime == lf.mtime && st.RemoteMtime == fd.Mtime {
+		c.Action = ActionSkip
+		return c
+	}
+
+	remoteMD5 := baidupcs.DecryptMD5(fd.MD5)
+	if remoteMD5 == "" {
+		// 网盘未记录 md5, 按大小判断
+		c.Action = ActionSkip
+		s.recordSame(rel, lf, fd)
+		return c
+	}
+	sum, err := lf.sumMD5()
+	if err != nil {
+		c.Action, c.Err = ActionSkip, err.Error()
+		return c
+	}
+	if strings.EqualFold(sum, remoteMD5) {
+		c.Action, c.Reason = ActionSkip, "md5相同"
+		s.recordSame(rel, lf, fd)
+		return c
+	}
+	c.Action, c.Reason = ActionUpdate, "md5不同"
+	return c
+}
+
+// pullDownloads 下载文件, 复用 pcsdownload.DownloadTaskUnit, 本地文件的修改时间与网盘一致
+func (s *Syncer) pullDownloads(ctx context.Context, downloads []*Change, remotes map[string]*baidupcs.FileDirectory) {
+	parallel := s.Options.Parallel
+	if parallel <= 0 {
+		parallel = pcsconfig.Config.MaxParallel
+	}
+	t := pcstransfer.Default.New(pcstransfer.KindDownload, pcstransfer.Options{
+		Parallel:  parallel,
+		Overwrite: true,
+		KeepMTime: true,
+		NoPersist: true,
+	})
+
+	bySource := map[string]*Change{}
+	for _, c := range downloads {
+		remotePath := s.remotePath(c.Path)
+		t.AppendDownload(remotePath, s.localPath(c.Path), remotes[c.Path])
+		bySource[remotePath] = c
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		t.Cancel("")
+	})
+	defer stop()
+	t.Execute()
+
+	for _, item := range t.Info(true).Items {
+		c := bySource[item.Source]
+		if c == nil {
+			continue
+		}
+		if item.Status != pcstransfer.StatusSucceeded {
+			c.Err = item.Message
+			if c.Err == "" {
+				c.Err = string(item.Status)
+			}
+			continue
+		}
+
+		fd := remotes[c.Path]
+		info, err := os.Stat(item.Target)
+		if err != nil {
+			continue
+		}
+		s.state.Files[c.Path] = &StateEntry{
+			Size:        fd.Size,
+			LocalMtime:  info.ModTime().Unix(),
+			RemoteMtime: fd.Mtime,
+			MD5:         baidupcs.DecryptMD5(fd.MD5),
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package pcssync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+)
+
+// push 本地同步到网盘: 上传新增和修改的文件, 可选删除网盘中多余的文件 (进入回收站)
+func (s *Syncer) push(ctx context.Context) ([]*Change, error) {
+	locals, err := s.walkLocal()
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := s.walkRemote(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		changes []*Change
+		uploads []*Change
+		deletes []*Change
+	)
+	for rel, lf := range locals {
+		c := s.comparePush(rel, lf, remotes[rel])
+		changes = append(changes, c)
+		if c.Action == ActionAdd || c.Action == ActionUpdate {
+			uploads = append(uploads, c)
+		}
+	}
+	if s.Options.Delete {
+		for rel, fd := range remotes {
+			if _, ok := locals[rel]; ok {
+				continue
+			}
+			c := &Change{Action: ActionDelete, Path: rel, Size: fd.Size, Reason: "本地不存在"}
+			changes = append(changes, c)
+			deletes = append(deletes, c)
+		}
+	}
+	sortChanges(changes)
+
+	if s.Options.DryRun {
+		return changes, nil
+	}
+	err = s.checkDeletes(len(deletes), len(remotes), len(locals) == 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uploads) > 0 {
+		s.pushUploads(ctx, uploads, locals)
+	}
+	if len(deletes) > 0 {
+		paths := make([]string, 0, len(deletes))
+		for _, c := range deletes {
+			paths = append(paths, s.remotePath(c.Path))
+		}
+		pcsError := s.PCS.RemoveContext(ctx, paths...)
+		for _, c := range deletes {
+			if pcsError != nil {
+				c.Err = pcsError.Error()
+				continue
+			}
+			delete(s.state.Files, c.Path)
+		}
+	}
+
+	// 记录上传后网盘文件的修改时间
+	if len(uploads) > 0 {
+		remotes, err = s.walkRemote(ctx)
+		if err != nil {
+			syncVerbose.Warnf("refresh remote files: %s\n", err)
+			return changes, nil
+		}
+		for _, c := range uploads {
+			if c.Err != "" || remotes[c.Path] == nil {
+				continue
+			}
+			lf := locals[c.Path]
+			s.state.Files[c.Path] = &StateEntry{
+				Size:        lf.size,
+				LocalMtime:  lf.mtime,
+				RemoteMtime: remotes[c.Path].Mtime,
+				MD5:         lf.md5,
+			}
+		}
+	}
+	return changes, nil
+}
+
+// comparePush 比较本地文件和网盘文件, fd 为空表示网盘不存在
+func (s *Syncer) comparePush(rel string, lf *localFile, fd *baidupcs.FileDirectory) *Change {
+	c := &Change{Path: rel, Size: lf.size}
+	if fd == nil {
+		c.Action = ActionAdd
+		return c
+	}
+	if fd.Size != lf.size {
+		c.Action, c.Reason = ActionUpdate, "大小不同"
+		return c
+	}
+
+	st := s.state.Files[rel]
+	if st != nil && st.Size == lf.size && st.LocalMtime == lf.mtime && st.RemoteMtime == fd.Mtime {
+		// 上次同步后两边均未修改
+		c.Action = ActionSkip
+		return c
+	}
+
+	if s.Options.CheckMD5 {
+		sum, err := lf.sumMD5()
+		if err != nil {
+			c.Action, c.Err = ActionSkip, err.Error()
+			return c
+		}
+		if strings.EqualFold(sum, baidupcs.DecryptMD5(fd.MD5)) {
+			c.Action, c.Reason = ActionSkip, "md5相同"
+			s.recordSame(rel, lf, fd)
+			return c
+		}
+		c.Action, c.Reason = ActionUpdate, "md5不同"
+		return c
+	}
+
+	if st != nil || lf.mtime > fd.Mtime {
+		c.Action, c.Reason = ActionUpdate, "修改时间不同"
+		return c
+	}
+	c.Action = ActionSkip
+	s.recordSame(rel, lf, fd)
+	return c
+}
+
+// recordSame 记录两边内容相同的文件
+func (s *Syncer) recordSame(rel string, lf *localFile, fd *baidupcs.FileDirectory) {
+	if s.Options.DryRun {
+		return
+	}
+	md5 := lf.md5
+	if md5 == "" {
+		md5 = baidupcs.DecryptMD5(fd.MD5)
+	}
+	s.state.Files[rel] = &StateEntry{
+		Size:        lf.size,
+		LocalMtime:  lf.mtime,
+		RemoteMtime: fd.Mtime,
+		MD5:         md5,
+	}
+}
+
+// pushUploads 上传文件, 复用 pcsupload.UploadTaskUnit, 可通过 /api/transfers 查看进度
+func (s *Syncer) pushUploads(ctx context.Context, uploads []*Change, locals map[string]*localFile) {
+	parallel := s.Options.Parallel
+	if parallel <= 0 {
+		parallel = pcsconfig.Config.MaxUploadLoad
+	}
+	t := pcstransfer.Default.New(pcstransfer.KindUpload, pcstransfer.Options{
+		Parallel:  parallel,
+		Policy:    baidupcs.OverWritePolicy,
+		NoPersist: true,
+	})
+
+	bySource := map[string]*Change{}
+	for _, c := range uploads {
+		lf := locals[c.Path]
+		_, err := t.AppendUpload(lf.path, s.remotePath(c.Path))
+		if err != nil {
+			c.Err = err.Error()
+			continue
+		}
+		bySource[lf.path] = c
+	}
+	if len(bySource) == 0 {
+		pcstransfer.Default.Remove(t.ID)
+		return
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		t.Cancel("")
+	})
+	defer stop()
+	t.Execute()
+
+	for _, item := range t.Info(true).Items {
+		c := bySource[item.Source]
+		if c == nil || item.Status == pcstransfer.StatusSucceeded {
+			continue
+		}
+		c.Err = item.Message
+		if c.Err == "" {
+			c.Err = string(item.Status)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package pcssync
+
+import (
+	"context"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+)
+
+// remoteDiff 从 cursor 开始读取整个网盘的变更, 逐条交给 fn 处理, fn 可以为空.
+// 返回新的 cursor, reset 为 true 表示服务器要求重新全量列出
+func (s *Syncer) remoteDiff(ctx context.Context, cursor string, fn func(fd *baidupcs.FileDirectory, deleted bool)) (next string, reset bool, err error) {
+	for {
+		diff, pcsError := s.PCS.FilesDirectoriesDiffContext(ctx, cursor)
+		if pcsError != nil {
+			return "", false, pcsError
+		}
+		if diff.Reset {
+			return diff.Cursor, true, nil
+		}
+		if fn != nil {
+			// 同一路径可能先删除再新建, 先处理删除
+			for _, fd := range diff.Deleted {
+				fn(fd, true)
+			}
+			for _, fd := range diff.Added {
+				fn(fd, false)
+			}
+			for _, fd := range diff.Modified {
+				fn(fd, false)
+			}
+		}
+		cursor = diff.Cursor
+		if !diff.HasMore || cursor == "" {
+			return cursor, false, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package pcssync
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+)
+
+const (
+	// StateFileName 持久化的同步状态文件名
+	StateFileName = "pcs_sync.json"
+)
+
+type (
+	// StateEntry 单个文件上次同步完成时的状态, 此时本地和网盘的文件内容相同
+	StateEntry struct {
+		Size        int64  `json:"size"`
+		LocalMtime  int64  `json:"local_mtime"`
+		RemoteMtime int64  `json:"remote_mtime"`
+		MD5         string `json:"md5,omitempty"`
+	}
+
+	// PairState 一对同步目录的状态, key 为相对路径
+	PairState struct {
+		LocalDir  string                 `json:"local_dir"`
+		RemoteDir string                 `json:"remote_dir"`
+		SyncedAt  int64                  `json:"synced_at"`
+		Cursor    string                 `json:"cursor,omitempty"` // 双向同步检测网盘变更的 cursor
+		Files     map[string]*StateEntry `json:"files"`
+	}
+
+	// stateData 同步状态文件的内容
+	stateData struct {
+		Pairs map[string]*PairState `json:"pairs"`
+	}
+)
+
+var (
+	stateMu sync.Mutex
+)
+
+// stateFilePath 返回同步状态文件路径
+func stateFilePath() string {
+	return filepath.Join(pcsconfig.GetConfigDir(), StateFileName)
+}
+
+// pairKey 同步目录对的 key
+func pairKey(localDir, remoteDir string) string {
+	return localDir + "|" + remoteDir
+}
+
+// loadStateData 读取同步状态文件, 调用前需加锁
+func loadStateData() (*stateData, error) {
+	data := &stateData{}
+	f, err := os.Open(stateFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > 0 {
+		err = jsonhelper.UnmarshalData(f, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// LoadState 读取同步目录对的状态, 不存在时返回空状态
+func LoadState(localDir, remoteDir string) (*PairState, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	data, err := loadStateData()
+	if err != nil {
+		return nil, err
+	}
+	ps := data.Pairs[pairKey(localDir, remoteDir)]
+	if ps == nil {
+		ps = &PairState{
+			LocalDir:  localDir,
+			RemoteDir: remoteDir,
+		}
+	}
+	if ps.Files == nil {
+		ps.Files = map[string]*StateEntry{}
+	}
+	return ps, nil
+}
+
+// Save 保存同步目录对的状态, 先写入临时文件再替换
+func (ps *PairState) Save() error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	data, err := loadStateData()
+	if err != nil {
+		return err
+	}
+	if data.Pairs == nil {
+		data.Pairs = map[string]*PairState{}
+	}
+	ps.SyncedAt = time.Now().Unix()
+	data.Pairs[pairKey(ps.LocalDir, ps.RemoteDir)] = ps
+
+	buf := &bytes.Buffer{}
+	err = jsonhelper.MarshalData(buf, data)
+	if err != nil {
+		return err
+	}
+
+	filename := stateFilePath()
+	tmp := filename + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,315 @@
+// Package pcssync 本地目录与网盘目录的单向和双向同步
+package pcssync
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+)
+
+type (
+	// Direction 同步方向
+	Direction string
+
+	// Action 对单个文件执行的操作
+	Action string
+
+	// Side 被修改的一侧
+	Side string
+
+	// Options 同步选项
+	Options struct {
+		Direction Direction `json:"direction"`
+		LocalDir  string    `json:"local_dir"`
+		RemoteDir string    `json:"remote_dir"`
+		CheckMD5  bool      `json:"check_md5,omitempty"` // push: 大小相同时比较 md5, pull 总是比较
+		Delete    bool      `json:"delete,omitempty"`    // 删除目标中多余的文件
+		DryRun    bool      `json:"dry_run,omitempty"`   // 只列出需要执行的操作
+		Parallel  int       `json:"parallel,omitempty"`  // 同时传输的文件数量
+		Interval  int       `json:"interval,omitempty"`  // 后台任务的执行间隔 (秒), 为0时只执行一次
+		Force     bool      `json:"force,omitempty"`     // 允许源目录为空或删除比例超过 MaxDeletePercent 时的删除
+	}
+
+	// Change 单个文件的同步操作
+	Change struct {
+		Action Action `json:"action"`
+		Path   string `json:"path"` // 相对于同步目录的路径
+		Size   int64  `json:"size"`
+		Side   Side   `json:"side,omitempty"` // 双向同步时被修改的一侧
+		Reason string `json:"reason,omitempty"`
+		Err    string `json:"error,omitempty"`
+	}
+
+	// Result 同步结果
+	Result struct {
+		Direction Direction `json:"direction"`
+		DryRun    bool      `json:"dry_run"`
+		Added     int       `json:"added"`
+		Updated   int       `json:"updated"`
+		Deleted   int       `json:"deleted"`
+		Skipped   int       `json:"skipped"`
+		Conflicts int       `json:"conflicts"`
+		Failed    int       `json:"failed"`
+		Changes   []*Change `json:"changes"`
+	}
+
+	// localFile 本地文件信息
+	localFile struct {
+		path  string
+		size  int64
+		mtime int64
+		md5   string
+	}
+
+	// Syncer 执行一次同步
+	Syncer struct {
+		PCS     *baidupcs.BaiduPCS
+		Options Options
+
+		state *PairState
+	}
+)
+
+const (
+	// DirectionPush 本地同步到网盘
+	DirectionPush Direction = "push"
+	// DirectionPull 网盘同步到本地
+	DirectionPull Direction = "pull"
+	// DirectionBisync 双向同步
+	DirectionBisync Direction = "bisync"
+)
+
+const (
+	// MaxDeletePercent 未设置 Force 时, 一次同步最多删除目标中文件的百分比
+	MaxDeletePercent = 50
+
+	// minDeleteCheck 删除的文件数不超过该值时不检查删除比例
+	minDeleteCheck = 10
+)
+
+const (
+	// ActionAdd 新增
+	ActionAdd Action = "add"
+	// ActionUpdate 更新
+	ActionUpdate Action = "update"
+	// ActionDelete 删除
+	ActionDelete Action = "delete"
+	// ActionSkip 跳过
+	ActionSkip Action = "skip"
+	// ActionConflict 两边均已修改
+	ActionConflict Action = "conflict"
+)
+
+const (
+	// SideLocal 本地
+	SideLocal Side = "local"
+	// SideRemote 网盘
+	SideRemote Side = "remote"
+)
+
+var (
+	// ErrUnknownDirection 未知的同步方向
+	ErrUnknownDirection = errors.New("unknown sync direction")
+	// ErrLocalDirNotExist 本地目录不存在
+	ErrLocalDirNotExist = errors.New("local directory does not exist")
+	// ErrTooManyDeletes 未设置 Force 时拒绝可能由目录丢失导致的大量删除
+	ErrTooManyDeletes = errors.New("too many deletions, use force to allow")
+
+	syncVerbose = pcsverbose.New("SYNC")
+)
+
+// NewSyncer 初始化Syncer, 本地目录转换为绝对路径
+func NewSyncer(pcs *baidupcs.BaiduPCS, opt Options) (*Syncer, error) {
+	localDir, err := filepath.Abs(opt.LocalDir)
+	if err != nil {
+		return nil, err
+	}
+	opt.LocalDir = localDir
+	opt.RemoteDir = path.Clean(opt.RemoteDir)
+	return &Syncer{
+		PCS:     pcs,
+		Options: opt,
+	}, nil
+}
+
+// Run 执行同步, DryRun 时不修改任何文件和同步状态
+func (s *Syncer) Run(ctx context.Context) (*Result, error) {
+	state, err := LoadState(s.Options.LocalDir, s.Options.RemoteDir)
+	if err != nil {
+		return nil, err
+	}
+	s.state = state
+
+	var changes []*Change
+	switch s.Options.Direction {
+	case DirectionPush, "":
+		changes, err = s.push(ctx)
+	case DirectionPull:
+		changes, err = s.pull(ctx)
+	case DirectionBisync:
+		changes, err = s.bisync(ctx)
+	default:
+		return nil, ErrUnknownDirection
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Direction: s.Options.Direction,
+		DryRun:    s.Options.DryRun,
+		Changes:   changes,
+	}
+	for _, c := range changes {
+		if c.Err != "" {
+			result.Failed++
+			continue
+		}
+		switch c.Action {
+		case ActionAdd:
+			result.Added++
+		case ActionUpdate:
+			result.Updated++
+		case ActionDelete:
+			result.Deleted++
+		case ActionSkip:
+			result.Skipped++
+		case ActionConflict:
+			result.Conflicts++
+		}
+	}
+
+	if !s.Options.DryRun {
+		err = s.state.Save()
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// localPath 相对路径对应的本地路径
+func (s *Syncer) localPath(rel string) string {
+	return filepath.Join(s.Options.LocalDir, filepath.FromSlash(rel))
+}
+
+// remotePath 相对路径对应的网盘路径
+func (s *Syncer) remotePath(rel string) string {
+	return path.Join(s.Options.RemoteDir, rel)
+}
+
+// walkLocal 列出本地目录下的文件, key 为相对路径, 目录不存在时返回 ErrLocalDirNotExist
+func (s *Syncer) walkLocal() (map[string]*localFile, error) {
+	info, err := os.Stat(s.Options.LocalDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrLocalDirNotExist
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", s.Options.LocalDir)
+	}
+
+	files := map[string]*localFile{}
+
+	names, err := pcsutil.WalkDir(s.Options.LocalDir, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(s.Options.LocalDir, name)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		files[rel] = &localFile{
+			path:  name,
+			size:  info.Size(),
+			mtime: info.ModTime().Unix(),
+		}
+	}
+	return files, nil
+}
+
+// walkRemote 列出网盘目录下的文件, key 为相对路径, 目录不存在时返回空
+func (s *Syncer) walkRemote(ctx context.Context) (map[string]*baidupcs.FileDirectory, error) {
+	var (
+		files   = map[string]*baidupcs.FileDirectory{}
+		walkErr error
+		prefix  = strings.TrimSuffix(s.Options.RemoteDir, baidupcs.PathSeparator) + baidupcs.PathSeparator
+	)
+	s.PCS.FilesDirectoriesRecurseListContext(ctx, s.Options.RemoteDir, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+		if pcsError != nil {
+			if depth == 0 && pcsError.GetRemoteErrCode() == 31066 {
+				// 网盘目录不存在
+				return false
+			}
+			walkErr = pcsError
+			return false
+		}
+		if fd.Isdir || !strings.HasPrefix(fd.Path, prefix) {
+			return true
+		}
+		files[strings.TrimPrefix(fd.Path, prefix)] = fd
+		return true
+	})
+	if walkErr == nil {
+		walkErr = ctx.Err()
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	return files, nil
+}
+
+// checkDeletes 检查一侧的删除操作, 未设置 Force 时拒绝源目录为空,
+// 或删除的文件数超过目标中文件的 MaxDeletePercent 的删除, 避免目录丢失或未挂载时删除另一侧的全部文件
+func (s *Syncer) checkDeletes(deletes, total int, sourceEmpty bool) error {
+	if deletes == 0 || s.Options.Force {
+		return nil
+	}
+	if sourceEmpty {
+		return fmt.Errorf("%w: source is empty, %d files would be deleted", ErrTooManyDeletes, deletes)
+	}
+	if deletes > minDeleteCheck && deletes*100 > total*MaxDeletePercent {
+		return fmt.Errorf("%w: %d of %d files would be deleted", ErrTooManyDeletes, deletes, total)
+	}
+	return nil
+}
+
+// sumMD5 计算本地文件的 md5, 结果会被缓存
+func (lf *localFile) sumMD5() (string, error) {
+	if lf.md5 != "" {
+		return lf.md5, nil
+	}
+	lfc, err := checksum.GetFileSum(lf.path, checksum.CHECKSUM_MD5)
+	if err != nil {
+		return "", err
+	}
+	lf.md5 = hex.EncodeToString(lfc.MD5)
+	return lf.md5, nil
+}
+
+// sortChanges 按路径排序
+func sortChanges(changes []*Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+}
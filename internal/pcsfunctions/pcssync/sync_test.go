@@ -0,0 +1,42 @@
+package pcssync
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestWalkLocalNotExist(t *testing.T) {
+	s, err := NewSyncer(nil, Options{LocalDir: filepath.Join(t.TempDir(), "missing"), RemoteDir: "/sync"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.walkLocal(); err != ErrLocalDirNotExist {
+		t.Fatalf("walkLocal on missing dir: got %v, want %v", err, ErrLocalDirNotExist)
+	}
+}
+
+func TestCheckDeletes(t *testing.T) {
+	tests := []struct {
+		deletes, total int
+		sourceEmpty    bool
+		force          bool
+		refuse         bool
+	}{
+		{0, 0, true, false, false},
+		{3, 3, true, false, true},
+		{3, 3, true, true, false},
+		{5, 8, false, false, false},
+		{11, 20, false, false, true},
+		{10, 20, false, false, false},
+		{11, 100, false, false, false},
+		{11, 20, false, true, false},
+	}
+	for _, tt := range tests {
+		s := &Syncer{Options: Options{Force: tt.force}}
+		err := s.checkDeletes(tt.deletes, tt.total, tt.sourceEmpty)
+		if errors.Is(err, ErrTooManyDeletes) != tt.refuse {
+			t.Errorf("checkDeletes(%d, %d, %v) force=%v: got %v, refuse %v", tt.deletes, tt.total, tt.sourceEmpty, tt.force, err, tt.refuse)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package pcstransfer
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+)
+
+type (
+	// Manager 传输任务管理器, 未结束的传输任务会持久化到配置目录.
+	// 已结束的传输任务保留 FinishedTTL, 最多保留 MaxFinished 个
+	Manager struct {
+		FinishedTTL time.Duration // 已结束的传输任务的保留时间, 为 0 时不按时间移除
+		MaxFinished int           // 最多保留的已结束的传输任务数量, 为 0 时不限制
+
+		mu        sync.RWMutex
+		saveMu    sync.Mutex
+		lastID    int64
+		dirty     int32
+		transfers map[string]*Transfer
+	}
+)
+
+const (
+	// DefaultFinishedTTL 已结束的传输任务默认的保留时间
+	DefaultFinishedTTL = 24 * time.Hour
+	// DefaultMaxFinished 默认最多保留的已结束的传输任务数量
+	DefaultMaxFinished = 100
+)
+
+var (
+	// Default 默认的传输任务管理器
+	Default = NewManager()
+
+	transferVerbose = pcsverbose.New("TRANSFER")
+)
+
+// NewManager 初始化Manager
+func NewManager() *Manager {
+	return &Manager{
+		FinishedTTL: DefaultFinishedTTL,
+		MaxFinished: DefaultMaxFinished,
+		transfers:   map[string]*Transfer{},
+	}
+}
+
+// nextID 生成不重复的传输任务id, 调用前需加锁
+func (m *Manager) nextID() string {
+	n := time.Now().UnixNano() / int64(time.Millisecond)
+	if n <= m.lastID {
+		n = m.lastID + 1
+	}
+	m.lastID = n
+	return formatID(n)
+}
+
+// New 新建传输任务, 加入文件后调用 Start 开始执行
+func (m *Manager) New(kind Kind, opt Options) *Transfer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := newTransfer(m, m.nextID(), kind, opt)
+	m.transfers[t.ID] = t
+	return t
+}
+
+// Get 获取传输任务
+func (m *Manager) Get(id string) (*Transfer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t, ok := m.transfers[id]
+	if !ok {
+		return nil, ErrTransferNotFound
+	}
+	return t, nil
+}
+
+// Remove 移除传输任务
+func (m *Manager) Remove(id string) {
+	m.mu.Lock()
+	delete(m.transfers, id)
+	m.mu.Unlock()
+	m.save()
+}
+
+// List 按创建时间列出所有传输任务
+func (m *Manager) List() []*Transfer {
+	m.evictFinished(time.Now())
+
+	m.mu.RLock()
+	list := make([]*Transfer, 0, len(m.transfers))
+	for _, t := range m.transfers {
+		list = append(list, t)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// evictFinished 移除结束超过 FinishedTTL 的传输任务,
+// 已结束的传输任务超过 MaxFinished 个时, 移除最早结束的
+func (m *Manager) evictFinished(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	finished := make([]*Transfer, 0)
+	for id, t := range m.transfers {
+		finishedAt, ok := t.FinishedAt()
+		if !ok {
+			continue
+		}
+		if m.FinishedTTL > 0 && now.Sub(finishedAt) > m.FinishedTTL {
+			delete(m.transfers, id)
+			continue
+		}
+		finished = append(finished, t)
+	}
+	if m.MaxFinished <= 0 || len(finished) <= m.MaxFinished {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		a, _ := finished[i].FinishedAt()
+		b, _ := finished[j].FinishedAt()
+		return a.Before(b)
+	})
+	for _, t := range finished[:len(finished)-m.MaxFinished] {
+		delete(m.transfers, t.ID)
+	}
+}
+
+// Restore 从传输队列文件重建未结束的传输任务, 并开始执行.
+// 返回重建的传输任务数量
+func (m *Manager) Restore() (n int, err error) {
+	data, err := loadQueue()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, rec := range data.Transfers {
+		m.mu.Lock()
+		if _, ok := m.transfers[rec.ID]; ok {
+			m.mu.Unlock()
+			continue
+		}
+		t := newTransfer(m, rec.ID, rec.Kind, rec.Options)
+		t.CreatedAt = time.Unix(rec.CreatedAt, 0)
+		t.paused = rec.Paused
+		m.transfers[t.ID] = t
+		m.mu.Unlock()
+
+		for _, ir := range rec.Items {
+			err = t.appendRecord(ir)
+			if err != nil {
+				transferVerbose.Warnf("restore transfer %s: %s\n", rec.ID, err)
+			}
+		}
+		if t.executor.Count() == 0 {
+			// 没有需要执行的文件
+			m.mu.Lock()
+			delete(m.transfers, t.ID)
+			m.mu.Unlock()
+			continue
+		}
+
+		go t.Execute()
+		n++
+	}
+
+	m.save()
+	return n, nil
+}
+
+// markDirty 标记需要保存, 短时间内的多次修改只保存一次
+func (m *Manager) markDirty() {
+	if !atomic.CompareAndSwapInt32(&m.dirty, 0, 1) {
+		return
+	}
+	time.AfterFunc(500*time.Millisecond, m.save)
+}
+
+// save 保存未结束的传输任务到传输队列文件
+func (m *Manager) save() {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	atomic.StoreInt32(&m.dirty, 0)
+
+	records := make([]*Record, 0)
+	for _, t := range m.List() {
+		if t.Options.NoPersist || t.Finished() {
+			continue
+		}
+		records = append(records, t.record())
+	}
+
+	err := saveQueue(records)
+	if err != nil {
+		transferVerbose.Warnf("save transfer queue: %s\n", err)
+	}
+}
@@ -0,0 +1,66 @@
+package pcstransfer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+)
+
+// finish 将传输任务标记为在 at 结束
+func finish(t *Transfer, at time.Time) {
+	t.mu.Lock()
+	t.finished = true
+	t.finishedAt = at
+	t.mu.Unlock()
+}
+
+func TestManagerEvictFinished(t *testing.T) {
+	t.Setenv(pcsconfig.EnvConfigDir, t.TempDir())
+
+	m := NewManager()
+	m.FinishedTTL = time.Hour
+	m.MaxFinished = 2
+
+	now := time.Now()
+	running := m.New(KindDownload, Options{})
+	expired := m.New(KindDownload, Options{})
+	finish(expired, now.Add(-2*time.Hour))
+	oldest := m.New(KindDownload, Options{})
+	finish(oldest, now.Add(-3*time.Minute))
+	older := m.New(KindUpload, Options{})
+	finish(older, now.Add(-2*time.Minute))
+
+	// 没有文件的传输任务执行后立即结束
+	latest := m.New(KindUpload, Options{})
+	latest.Execute()
+	if _, ok := latest.FinishedAt(); !ok {
+		t.Fatal("transfer without items should be finished after Execute")
+	}
+
+	list := m.List()
+	got := map[string]bool{}
+	for _, tr := range list {
+		got[tr.ID] = true
+	}
+	if len(list) != 3 || !got[running.ID] || !got[older.ID] || !got[latest.ID] {
+		t.Fatalf("unexpected transfers after eviction: %v", got)
+	}
+	for _, tr := range []*Transfer{expired, oldest} {
+		if _, err := m.Get(tr.ID); err != ErrTransferNotFound {
+			t.Errorf("transfer %s should be evicted, got %v", tr.ID, err)
+		}
+	}
+}
+
+func TestManagerKeepFinished(t *testing.T) {
+	m := NewManager()
+	m.FinishedTTL = 0
+	m.MaxFinished = 0
+
+	tr := m.New(KindDownload, Options{})
+	finish(tr, time.Now().Add(-365*24*time.Hour))
+	if len(m.List()) != 1 {
+		t.Fatal("finished transfer should be kept when eviction is disabled")
+	}
+}
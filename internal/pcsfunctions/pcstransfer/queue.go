@@ -0,0 +1,130 @@
+package pcstransfer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+)
+
+const (
+	// QueueFileName 持久化的传输队列文件名
+	QueueFileName = "pcs_transfers.json"
+)
+
+type (
+	// ItemRecord 持久化的文件记录
+	ItemRecord struct {
+		Source  string `json:"source"`
+		Target  string `json:"target"`
+		Status  Status `json:"status"`
+		Paused  bool   `json:"paused,omitempty"`
+		Retry   int    `json:"retry,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+
+	// Record 持久化的传输任务记录
+	Record struct {
+		ID        string        `json:"id"`
+		Kind      Kind          `json:"kind"`
+		CreatedAt int64         `json:"created_at"`
+		Paused    bool          `json:"paused,omitempty"`
+		Options   Options       `json:"options"`
+		Items     []*ItemRecord `json:"items"`
+	}
+
+	// queueData 传输队列文件的内容
+	queueData struct {
+		Transfers []*Record `json:"transfers"`
+		Timestamp int64     `json:"timestamp"`
+	}
+)
+
+// queueFilePath 返回传输队列文件路径
+func queueFilePath() string {
+	return filepath.Join(pcsconfig.GetConfigDir(), QueueFileName)
+}
+
+// record 生成传输任务的持久化记录
+func (t *Transfer) record() *Record {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rec := &Record{
+		ID:        t.ID,
+		Kind:      t.Kind,
+		CreatedAt: t.CreatedAt.Unix(),
+		Paused:    t.paused,
+		Options:   t.Options,
+		Items:     make([]*ItemRecord, 0, len(t.items)),
+	}
+	for _, item := range t.items {
+		ir := &ItemRecord{
+			Source:  item.Source,
+			Target:  item.Target,
+			Status:  item.status,
+			Retry:   item.retry,
+			Message: item.message,
+		}
+		if ir.Status == StatusRunning {
+			// 重启后重新排队
+			ir.Status = StatusPending
+		}
+		if item.unit != nil && item.status != StatusSucceeded {
+			ir.Paused = item.unit.IsPaused()
+		}
+		rec.Items = append(rec.Items, ir)
+	}
+	return rec
+}
+
+// loadQueue 读取传输队列文件
+func loadQueue() (*queueData, error) {
+	data := &queueData{}
+	f, err := os.Open(queueFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return data, nil
+	}
+
+	err = jsonhelper.UnmarshalData(f, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// saveQueue 保存传输队列文件, 先写入临时文件再替换, 避免写入中途崩溃损坏文件
+func saveQueue(records []*Record) error {
+	data := &queueData{
+		Transfers: records,
+		Timestamp: time.Now().Unix(),
+	}
+	buf := &bytes.Buffer{}
+	err := jsonhelper.MarshalData(buf, data)
+	if err != nil {
+		return err
+	}
+
+	filename := queueFilePath()
+	tmp := filename + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
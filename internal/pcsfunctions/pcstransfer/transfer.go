@@ -0,0 +1,477 @@
+// Package pcstransfer 传输任务管理, 记录后台执行的上传下载批次, 支持暂停和恢复
+package pcstransfer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsupload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+type (
+	// Kind 传输类型
+	Kind string
+
+	// Status 传输状态
+	Status string
+
+	// Pausable 可暂停的任务单元
+	Pausable interface {
+		taskframework.TaskUnit
+		Pause()
+		Resume()
+		IsPaused() bool
+	}
+
+	// SkipReporter 可返回跳过原因的任务单元, 任务单元没有返回执行结果时表示跳过
+	SkipReporter interface {
+		SkipReason() string
+	}
+
+	// Options 传输选项, 重建任务单元时使用
+	Options struct {
+		Parallel  int    `json:"parallel,omitempty"`   // 同时执行的文件数量
+		Overwrite bool   `json:"overwrite,omitempty"`  // 下载: 覆盖已存在的文件
+		KeepMTime bool   `json:"keep_mtime,omitempty"` // 下载: 修改时间与网盘一致
+		Policy    string `json:"policy,omitempty"`     // 上传: 重名文件策略
+		NoPersist bool   `json:"-"`                    // 不持久化, 例如临时文件的上传
+	}
+
+	// Item 批次中的单个文件
+	Item struct {
+		ID     string // 文件id
+		Source string // 源路径
+		Target string // 目标路径
+
+		unit    Pausable
+		taskID  string // 任务执行器中的任务id
+		status  Status
+		retry   int
+		message string
+	}
+
+	// ItemInfo 单个文件的状态信息
+	ItemInfo struct {
+		ID      string `json:"id"`
+		Source  string `json:"source"`
+		Target  string `json:"target"`
+		Status  Status `json:"status"`
+		Retry   int    `json:"retry"`
+		Message string `json:"message,omitempty"`
+	}
+
+	// Transfer 一批传输任务
+	Transfer struct {
+		ID        string
+		Kind      Kind
+		CreatedAt time.Time
+		Options   Options
+
+		manager    *Manager
+		mu         sync.RWMutex
+		executor   *taskframework.TaskExecutor
+		items      []*Item
+		itemMap    map[string]*Item
+		lastItem   int
+		paused     bool
+		finished   bool
+		finishedAt time.Time
+
+		pcs               *baidupcs.BaiduPCS
+		downloadStatistic *pcsdownload.DownloadStatistic
+		uploadStatistic   *pcsupload.UploadStatistic
+		uploadingDatabase *pcsupload.UploadingDatabase
+	}
+
+	// TransferInfo 批次的状态信息
+	TransferInfo struct {
+		ID        string         `json:"id"`
+		Kind      Kind           `json:"kind"`
+		Status    Status         `json:"status"`
+		CreatedAt int64          `json:"created_at"`
+		Total     int            `json:"total"`
+		Counts    map[Status]int `json:"counts"`
+		Items     []*ItemInfo    `json:"items,omitempty"`
+	}
+
+	// trackedUnit 包装任务单元, 记录执行状态
+	trackedUnit struct {
+		Pausable
+		t    *Transfer
+		item *Item
+	}
+)
+
+const (
+	// KindDownload 下载
+	KindDownload Kind = "download"
+	// KindUpload 上传
+	KindUpload Kind = "upload"
+)
+
+const (
+	// StatusPending 等待中
+	StatusPending Status = "pending"
+	// StatusRunning 执行中
+	StatusRunning Status = "running"
+	// StatusPaused 已暂停
+	StatusPaused Status = "paused"
+	// StatusSucceeded 已成功
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed 已失败
+	StatusFailed Status = "failed"
+	// StatusCanceled 已取消
+	StatusCanceled Status = "canceled"
+	// StatusSkipped 已跳过, 文件没有传输, 如本地文件不可读
+	StatusSkipped Status = "skipped"
+	// StatusFinished 批次已结束
+	StatusFinished Status = "finished"
+)
+
+var (
+	// ErrTransferNotFound 传输任务不存在
+	ErrTransferNotFound = errors.New("transfer not found")
+	// ErrItemNotFound 文件不存在
+	ErrItemNotFound = errors.New("transfer item not found")
+	// ErrTransferFinished 传输任务已结束
+	ErrTransferFinished = errors.New("transfer already finished")
+)
+
+// newTransfer 初始化Transfer
+func newTransfer(m *Manager, id string, kind Kind, opt Options) *Transfer {
+	return &Transfer{
+		ID:        id,
+		Kind:      kind,
+		CreatedAt: time.Now(),
+		Options:   opt,
+		manager:   m,
+		executor: &taskframework.TaskExecutor{
+			IsFailedDeque: true,
+		},
+		itemMap:           map[string]*Item{},
+		downloadStatistic: &pcsdownload.DownloadStatistic{},
+		uploadStatistic:   &pcsupload.UploadStatistic{},
+	}
+}
+
+// append 加入任务单元, unit 为空时只记录已完成的文件
+func (t *Transfer) append(unit Pausable, maxRetry int, source, target string) *Item {
+	t.mu.Lock()
+	t.lastItem++
+	item := &Item{
+		ID:     strconv.Itoa(t.lastItem),
+		Source: source,
+		Target: target,
+		unit:   unit,
+		status: StatusPending,
+	}
+	t.items = append(t.items, item)
+	t.itemMap[item.ID] = item
+	if unit == nil {
+		item.status = StatusSucceeded
+		t.mu.Unlock()
+		return item
+	}
+	if t.paused {
+		unit.Pause()
+	}
+	t.mu.Unlock()
+
+	info := t.executor.Append(&trackedUnit{
+		Pausable: unit,
+		t:        t,
+		item:     item,
+	}, maxRetry)
+	t.mu.Lock()
+	item.taskID = info.Id()
+	t.mu.Unlock()
+	return item
+}
+
+// Len 返回文件数量
+func (t *Transfer) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.items)
+}
+
+// Start 在后台执行全部任务
+func (t *Transfer) Start() {
+	t.manager.save()
+	go t.Execute()
+}
+
+// Execute 执行全部任务, 阻塞直到结束
+func (t *Transfer) Execute() {
+	if t.Options.Parallel > 0 {
+		t.executor.SetParallel(t.Options.Parallel)
+	}
+	switch t.Kind {
+	case KindDownload:
+		t.downloadStatistic.StartTimer()
+	case KindUpload:
+		t.uploadStatistic.StartTimer()
+	}
+
+	t.executor.Execute()
+
+	t.mu.Lock()
+	t.finished = true
+	t.finishedAt = time.Now()
+	if t.uploadingDatabase != nil {
+		t.uploadingDatabase.Close()
+	}
+	t.mu.Unlock()
+	t.manager.save()
+
+	transferVerbose.Infof("transfer %s: %s finished, %d files, %d failed, %d skipped\n", t.ID, t.Kind, t.Len(), t.Failed(), t.Skipped())
+}
+
+// Finished 是否已结束
+func (t *Transfer) Finished() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.finished
+}
+
+// FinishedAt 返回结束的时间, 未结束时返回 false
+func (t *Transfer) FinishedAt() (time.Time, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.finishedAt, t.finished
+}
+
+// Failed 返回失败的文件数量
+func (t *Transfer) Failed() int {
+	return t.count(StatusFailed)
+}
+
+// Skipped 返回跳过的文件数量
+func (t *Transfer) Skipped() int {
+	return t.count(StatusSkipped)
+}
+
+// count 返回状态为 status 的文件数量
+func (t *Transfer) count(status Status) (n int) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, item := range t.items {
+		if item.status == status {
+			n++
+		}
+	}
+	return
+}
+
+// Pause 暂停, itemID 为空时暂停整个批次
+func (t *Transfer) Pause(itemID string) error {
+	return t.control(itemID, true)
+}
+
+// Resume 恢复, itemID 为空时恢复整个批次
+func (t *Transfer) Resume(itemID string) error {
+	return t.control(itemID, false)
+}
+
+func (t *Transfer) control(itemID string, pause bool) error {
+	t.mu.Lock()
+	if t.finished {
+		t.mu.Unlock()
+		return ErrTransferFinished
+	}
+
+	var items []*Item
+	if itemID == "" {
+		t.paused = pause
+		items = t.items
+	} else {
+		item, ok := t.itemMap[itemID]
+		if !ok {
+			t.mu.Unlock()
+			return ErrItemNotFound
+		}
+		items = []*Item{item}
+	}
+
+	for _, item := range items {
+		switch item.status {
+		case StatusSucceeded, StatusFailed, StatusCanceled:
+			continue
+		}
+		if pause {
+			item.unit.Pause()
+		} else {
+			item.unit.Resume()
+		}
+	}
+	t.mu.Unlock()
+	t.manager.markDirty()
+	return nil
+}
+
+// Cancel 取消, itemID 为空时取消整个批次.
+// 已取消的文件不会再执行, 下载的断点信息会保留
+func (t *Transfer) Cancel(itemID string) error {
+	t.mu.RLock()
+	if t.finished {
+		t.mu.RUnlock()
+		return ErrTransferFinished
+	}
+	if itemID == "" {
+		t.mu.RUnlock()
+		t.executor.Stop()
+		return nil
+	}
+	item, ok := t.itemMap[itemID]
+	if !ok || item.taskID == "" {
+		t.mu.RUnlock()
+		return ErrItemNotFound
+	}
+	taskID := item.taskID
+	t.mu.RUnlock()
+
+	err := t.executor.Cancel(taskID)
+	if err == taskframework.ErrTaskNotFound {
+		return ErrItemNotFound
+	}
+	return err
+}
+
+// MoveToFront 将等待中的文件移到队列最前面
+func (t *Transfer) MoveToFront(itemID string) error {
+	t.mu.RLock()
+	item, ok := t.itemMap[itemID]
+	if !ok || item.taskID == "" {
+		t.mu.RUnlock()
+		return ErrItemNotFound
+	}
+	taskID := item.taskID
+	t.mu.RUnlock()
+
+	err := t.executor.MoveToFront(taskID)
+	if err == taskframework.ErrTaskNotFound {
+		return ErrItemNotFound
+	}
+	return err
+}
+
+// SetParallel 修改同时执行的文件数量, 执行中修改立即生效
+func (t *Transfer) SetParallel(parallel int) {
+	t.mu.Lock()
+	t.Options.Parallel = parallel
+	t.mu.Unlock()
+	t.executor.SetParallel(parallel)
+	t.manager.markDirty()
+}
+
+// itemStatus 计算文件的当前状态, 调用前需加锁
+func (t *Transfer) itemStatus(item *Item) Status {
+	switch item.status {
+	case StatusPending, StatusRunning:
+		if item.unit.IsPaused() {
+			return StatusPaused
+		}
+	}
+	return item.status
+}
+
+// Info 返回批次的状态信息, withItems 是否包含各个文件的信息
+func (t *Transfer) Info(withItems bool) *TransferInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	info := &TransferInfo{
+		ID:        t.ID,
+		Kind:      t.Kind,
+		CreatedAt: t.CreatedAt.Unix(),
+		Total:     len(t.items),
+		Counts:    map[Status]int{},
+	}
+	for _, item := range t.items {
+		status := t.itemStatus(item)
+		info.Counts[status]++
+		if withItems {
+			info.Items = append(info.Items, &ItemInfo{
+				ID:      item.ID,
+				Source:  item.Source,
+				Target:  item.Target,
+				Status:  status,
+				Retry:   item.retry,
+				Message: item.message,
+			})
+		}
+	}
+
+	switch {
+	case t.finished:
+		info.Status = StatusFinished
+	case t.paused:
+		info.Status = StatusPaused
+	default:
+		info.Status = StatusRunning
+	}
+	return info
+}
+
+func (t *Transfer) setItemStatus(item *Item, status Status, result *taskframework.TaskUnitRunResult) {
+	t.mu.Lock()
+	item.status = status
+	if result != nil {
+		item.message = result.ResultMessage
+		if result.Err != nil {
+			item.message += ", " + result.Err.Error()
+		}
+	}
+	t.mu.Unlock()
+	t.manager.markDirty()
+}
+
+func (tu *trackedUnit) Run(ctx context.Context) *taskframework.TaskUnitRunResult {
+	tu.t.setItemStatus(tu.item, StatusRunning, nil)
+	return tu.Pausable.Run(ctx)
+}
+
+func (tu *trackedUnit) OnRetry(lastRunResult *taskframework.TaskUnitRunResult) {
+	tu.t.mu.Lock()
+	tu.item.retry++
+	tu.t.mu.Unlock()
+	tu.t.setItemStatus(tu.item, StatusPending, lastRunResult)
+	tu.Pausable.OnRetry(lastRunResult)
+}
+
+func (tu *trackedUnit) OnSuccess(lastRunResult *taskframework.TaskUnitRunResult) {
+	tu.t.setItemStatus(tu.item, StatusSucceeded, lastRunResult)
+	tu.Pausable.OnSuccess(lastRunResult)
+}
+
+func (tu *trackedUnit) OnFailed(lastRunResult *taskframework.TaskUnitRunResult) {
+	status := StatusFailed
+	if errors.Is(lastRunResult.Err, context.Canceled) {
+		status = StatusCanceled
+	}
+	tu.t.setItemStatus(tu.item, status, lastRunResult)
+	tu.Pausable.OnFailed(lastRunResult)
+}
+
+func (tu *trackedUnit) OnComplete(lastRunResult *taskframework.TaskUnitRunResult) {
+	if lastRunResult == nil {
+		// 没有返回结果, 文件没有传输, 视为跳过
+		result := &taskframework.TaskUnitRunResult{}
+		if sr, ok := tu.Pausable.(SkipReporter); ok {
+			result.ResultMessage = sr.SkipReason()
+		}
+		tu.t.setItemStatus(tu.item, StatusSkipped, result)
+	}
+	tu.Pausable.OnComplete(lastRunResult)
+}
+
+// formatID 生成传输任务id
+func formatID(n int64) string {
+	return strconv.FormatInt(n, 36)
+}
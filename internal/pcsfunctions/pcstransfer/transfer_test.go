@@ -0,0 +1,53 @@
+package pcstransfer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+// fakeUnit 返回固定执行结果的任务单元
+type fakeUnit struct {
+	result *taskframework.TaskUnitRunResult
+	reason string
+}
+
+func (u *fakeUnit) SetTaskInfo(info *taskframework.TaskInfo) {}
+func (u *fakeUnit) Run(ctx context.Context) *taskframework.TaskUnitRunResult {
+	return u.result
+}
+func (u *fakeUnit) OnRetry(lastRunResult *taskframework.TaskUnitRunResult)    {}
+func (u *fakeUnit) OnSuccess(lastRunResult *taskframework.TaskUnitRunResult)  {}
+func (u *fakeUnit) OnFailed(lastRunResult *taskframework.TaskUnitRunResult)   {}
+func (u *fakeUnit) OnComplete(lastRunResult *taskframework.TaskUnitRunResult) {}
+func (u *fakeUnit) RetryWait() time.Duration                                  { return 0 }
+func (u *fakeUnit) Pause()                                                    {}
+func (u *fakeUnit) Resume()                                                   {}
+func (u *fakeUnit) IsPaused() bool                                            { return false }
+func (u *fakeUnit) SkipReason() string                                        { return u.reason }
+
+func TestTransferSkipped(t *testing.T) {
+	t.Setenv(pcsconfig.EnvConfigDir, t.TempDir())
+
+	m := NewManager()
+	tr := m.New(KindUpload, Options{NoPersist: true})
+	tr.append(&fakeUnit{result: &taskframework.TaskUnitRunResult{Succeed: true}}, 0, "uploaded", "/uploaded")
+	tr.append(&fakeUnit{reason: "文件不可读"}, 0, "unreadable", "/unreadable")
+	tr.Execute()
+
+	if tr.Failed() != 0 || tr.Skipped() != 1 {
+		t.Fatalf("failed %d, skipped %d, want 0 and 1", tr.Failed(), tr.Skipped())
+	}
+	info := tr.Info(true)
+	if info.Counts[StatusSucceeded] != 1 || info.Counts[StatusSkipped] != 1 {
+		t.Fatalf("unexpected counts: %v", info.Counts)
+	}
+	for _, item := range info.Items {
+		if item.Source == "unreadable" && (item.Status != StatusSkipped || item.Message != "文件不可读") {
+			t.Errorf("skipped item: status %s, message %q", item.Status, item.Message)
+		}
+	}
+}
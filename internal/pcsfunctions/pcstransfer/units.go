@@ -0,0 +1,110 @@
+package pcstransfer
+
+import (
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsupload"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/downloader"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/transfer"
+)
+
+const (
+	// DefaultMaxRetry 默认的最大重试次数
+	DefaultMaxRetry = 3
+)
+
+func (t *Transfer) baiduPCS() *baidupcs.BaiduPCS {
+	if t.pcs == nil {
+		t.pcs = pcsconfig.Config.ActiveUserBaiduPCS()
+	}
+	return t.pcs
+}
+
+// AppendDownload 加入下载任务, fileInfo 为空时, 下载前获取文件信息.
+// 保存路径存在断点信息时, 下载会从断点继续
+func (t *Transfer) AppendDownload(pcsPath, savePath string, fileInfo *baidupcs.FileDirectory) *Item {
+	unit := &pcsdownload.DownloadTaskUnit{
+		Cfg: &downloader.Config{
+			Mode:      transfer.RangeGenMode_BlockSize,
+			CacheSize: pcsconfig.Config.CacheSize,
+			BlockSize: baidupcs.InitRangeSize,
+			MaxRate:   pcsconfig.Config.MaxDownloadRate,
+			TryHTTP:   !pcsconfig.Config.EnableHTTPS,
+		},
+		PCS:                t.baiduPCS(),
+		ParentTaskExecutor: t.executor,
+		DownloadStatistic:  t.downloadStatistic,
+		IsOverwrite:        t.Options.Overwrite,
+		ModifyMTime:        t.Options.KeepMTime,
+		NoCheck:            pcsconfig.Config.NoCheck,
+		DownloadMode:       pcsdownload.DownloadModePCS,
+		PcsPath:            pcsPath,
+		FileInfo:           fileInfo,
+		SavePath:           savePath,
+	}
+	return t.append(unit, DefaultMaxRetry, pcsPath, savePath)
+}
+
+// AppendUpload 加入上传任务
+func (t *Transfer) AppendUpload(localPath, savePath string) (*Item, error) {
+	t.mu.Lock()
+	if t.uploadingDatabase == nil {
+		ud, err := pcsupload.NewUploadingDatabase()
+		if err != nil {
+			t.mu.Unlock()
+			return nil, err
+		}
+		t.uploadingDatabase = ud
+	}
+	t.mu.Unlock()
+
+	policy := t.Options.Policy
+	if policy == "" {
+		policy = pcsconfig.Config.UPolicy
+	}
+	unit := &pcsupload.UploadTaskUnit{
+		LocalFileChecksum: checksum.NewLocalFileChecksum(localPath, int(baidupcs.SliceMD5Size)),
+		SavePath:          savePath,
+		PCS:               t.baiduPCS(),
+		UploadingDatabase: t.uploadingDatabase,
+		Parallel:          pcsconfig.Config.MaxUploadParallel,
+		UploadStatistic:   t.uploadStatistic,
+		Policy:            policy,
+	}
+	return t.append(unit, DefaultMaxRetry, localPath, savePath), nil
+}
+
+// appendRecord 根据持久化的记录重建任务单元
+func (t *Transfer) appendRecord(rec *ItemRecord) error {
+	var (
+		item *Item
+		err  error
+	)
+	switch {
+	case rec.Status == StatusSucceeded, rec.Status == StatusCanceled, rec.Status == StatusSkipped:
+		item = t.append(nil, 0, rec.Source, rec.Target)
+	case t.Kind == KindDownload:
+		item = t.AppendDownload(rec.Source, rec.Target, nil)
+	case t.Kind == KindUpload:
+		item, err = t.AppendUpload(rec.Source, rec.Target)
+		if err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	t.mu.Lock()
+	if item.unit == nil {
+		item.status = rec.Status
+	}
+	item.retry = rec.Retry
+	item.message = rec.Message
+	if rec.Paused && item.unit != nil {
+		item.unit.Pause()
+	}
+	t.mu.Unlock()
+	return nil
+}
@@ -2,21 +2,26 @@ package pcsupload
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcscrypt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/converter"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester/rio"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester/uploader"
+	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,13 +42,23 @@ type (
 		NoRapidUpload     bool   // 禁用秒传
 		NoSplitFile       bool   // 禁用分片上传
 		Policy            string // 上传重名文件策略
+		API               string // 上传使用的接口, 为空时使用配置
 
 		UploadStatistic *UploadStatistic
 
 		taskInfo *taskframework.TaskInfo
+		panPath  string // 网盘中的保存路径, 位于加密目录时文件名可能已加密
+		nonce    []byte // 加密文件内容使用的 nonce, 重试时保持不变
 		panDir   string
 		panFile  string
 		state    *uploader.InstanceState
+		xc       *xpan.Client // xpan 接口客户端, 为空时使用当前帐号创建
+
+		skipReason string // 跳过上传的原因
+
+		pauseMu sync.Mutex
+		paused  bool                    // 是否已暂停
+		muer    *uploader.MultiUploader // 正在执行的上传器
 	}
 )
 
@@ -59,6 +74,8 @@ const (
 )
 
 const (
+	emptyFileMD5 = "d41d8cd98f00b204e9800998ecf8427e"
+
 	StrUploadFailed    = "上传文件失败"
 	DefaultPrintFormat = "\r[%s] ↑ %s/%s %s/s in %s ............"
 	DefaultContentSize = 4 * converter.KB
@@ -68,11 +85,11 @@ func (utu *UploadTaskUnit) SetTaskInfo(taskInfo *taskframework.TaskInfo) {
 	utu.taskInfo = taskInfo
 }
 
-// prepareFile 解析文件阶段
-func (utu *UploadTaskUnit) prepareFile() {
+// preparePath 解析文件保存路径, 初始化断点信息, 在选择上传接口前执行
+func (utu *UploadTaskUnit) preparePath() {
 	// 解析文件保存路径
 	var (
-		panDir, panFile = path.Split(utu.SavePath)
+		panDir, panFile = path.Split(utu.panPath)
 	)
 	utu.panDir = path.Clean(panDir)
 	utu.panFile = panFile
@@ -86,7 +103,10 @@ func (utu *UploadTaskUnit) prepareFile() {
 	//	return
 	//}
 	utu.state = &uploader.InstanceState{}
+}
 
+// prepareFile 解析文件阶段
+func (utu *UploadTaskUnit) prepareFile() {
 	if utu.LocalFileChecksum.Length >= baidupcs.RecommendedUploadSize {
 		fmt.Printf("[%s] 文件超过32GB, 上传有可能失败, 建议分割文件...\n", utu.taskInfo.Id())
 	}
@@ -95,6 +115,7 @@ func (utu *UploadTaskUnit) prepareFile() {
 		freeSpace, err := utu.PCS.SpaceLeftInfo()
 		if err == nil && freeSpace < utu.LocalFileChecksum.Length {
 			fmt.Printf("[%s] 目标文件大小超过剩余空间, 跳过...\n", utu.taskInfo.Id())
+			utu.skipReason = "目标文件大小超过剩余空间"
 			utu.Step = JustGoon
 			return
 		}
@@ -102,7 +123,7 @@ func (utu *UploadTaskUnit) prepareFile() {
 
 	if utu.NoRapidUpload {
 		//fmt.Printf("[%s] 注意: 跳过秒传将无法使用断点续传...\n", utu.taskInfo.Id())
-		pcsError, jsonData := utu.PCS.FakeRapidUpload(utu.SavePath, utu.Policy, utu.LocalFileChecksum.Length)
+		pcsError, jsonData := utu.PCS.FakeRapidUpload(utu.panPath, utu.Policy, utu.LocalFileChecksum.Length)
 		if pcsError != nil {
 			errcode := pcsError.GetRemoteErrCode()
 			if errcode != 114514 && errcode != 1919810 {
@@ -111,6 +132,7 @@ func (utu *UploadTaskUnit) prepareFile() {
 				return
 			} else {
 				fmt.Printf("[%s] 目标文件已存在, 跳过...\n", utu.taskInfo.Id())
+				utu.skipReason = "目标文件已存在"
 				utu.Step = JustGoon
 				return
 			}
@@ -210,7 +232,7 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 
 	fmt.Printf("[%s] 开始计算文件分块md5, 请稍候...\n", utu.taskInfo.Id())
 	if utu.LocalFileChecksum.LocalFileMeta.BlocksList == nil || len(utu.LocalFileChecksum.LocalFileMeta.BlocksList) == 0 {
-		err = utu.LocalFileChecksum.CalculateChunkedSum(blockSize)
+		err := utu.LocalFileChecksum.CalculateChunkedSum(blockSize)
 		if err != nil {
 			// 不重试
 			result.ResultMessage = "计算文件分块md5出错"
@@ -219,7 +241,7 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 		}
 	}
 
-	pcsError, jsonData := utu.PCS.RapidUpload(utu.SavePath, utu.Policy, utu.state.Uploadid, hex.EncodeToString(utu.LocalFileChecksum.MD5),
+	pcsError, jsonData := utu.PCS.RapidUpload(utu.panPath, utu.Policy, utu.state.Uploadid, hex.EncodeToString(utu.LocalFileChecksum.MD5),
 		hex.EncodeToString(utu.LocalFileChecksum.SliceMD5), b64Content, fmt.Sprint(utu.LocalFileChecksum.CRC32),
 		offset, dataLength, utu.LocalFileChecksum.Length, currentTime, utu.LocalFileChecksum.BlocksList)
 	if pcsError == nil {
@@ -231,6 +253,11 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 			return
 		}
 	} else {
+		// PCS 接口无权限, 改用 xpan 接口上传
+		if utu.fallbackXpan(pcsError) {
+			isContinue = true
+			return
+		}
 		// 判断配额是否已满
 		switch pcsError.GetErrType() {
 		// 远程服务器错误
@@ -276,27 +303,33 @@ func (utu *UploadTaskUnit) rapidUpload() (isContinue bool, result *taskframework
 	return
 }
 
-// upload 上传文件
-func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
+// upload 使用 multiUpload 分片上传文件
+func (utu *UploadTaskUnit) upload(ctx context.Context, multiUpload uploader.MultiUpload, blockSize int64) (result *taskframework.TaskUnitRunResult) {
 	utu.Step = StepUploadUpload
 
-	blockSize := getBlockSize(utu.LocalFileChecksum.Length)
-
-	muer := uploader.NewMultiUploader(NewPCSUpload(utu.PCS, utu.SavePath), rio.NewFileReaderAtLen64(utu.LocalFileChecksum.GetFile()), &uploader.MultiUploaderConfig{
+	muer := uploader.NewMultiUploader(multiUpload, rio.NewReaderAtLen64(utu.LocalFileChecksum.GetReader(), utu.LocalFileChecksum.Length), &uploader.MultiUploaderConfig{
 		Parallel:  utu.Parallel,
 		BlockSize: blockSize,
 		MaxRate:   pcsconfig.Config.MaxUploadRate,
 		Policy:    utu.Policy,
-	}, utu.SavePath)
+	}, utu.panPath)
 
 	// 设置断点续传
 	if utu.state != nil {
 		muer.SetInstanceState(utu.state)
 	}
+	// 暂停时保存断点信息
+	muer.OnPause(func() {
+		if utu.state != nil && utu.state.Uploadid != "" {
+			utu.UploadingDatabase.UpdateUploading(&utu.LocalFileChecksum.LocalFileMeta, muer.InstanceState())
+			utu.UploadingDatabase.Save()
+		}
+		fmt.Printf("[%s] 上传已暂停: %s\n", utu.taskInfo.Id(), utu.SavePath)
+	})
 	muer.OnUploadStatusEvent(func(status uploader.Status, updateChan <-chan struct{}) {
 		select {
 		case <-updateChan:
-			if utu.state.Uploadid != "" {
+			if utu.state != nil && utu.state.Uploadid != "" {
 				utu.UploadingDatabase.UpdateUploading(&utu.LocalFileChecksum.LocalFileMeta, muer.InstanceState())
 				utu.UploadingDatabase.Save()
 			}
@@ -335,6 +368,12 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 		// 默认需要重试
 		result.NeedRetry = true
 
+		// PCS 接口无权限, 改用 xpan 接口重试
+		if utu.fallbackXpan(pcsError) {
+			result.ResultMessage = "PCS 上传接口无权限, 改用 xpan 接口重新上传"
+			return
+		}
+
 		switch pcsError.GetErrType() {
 		case pcserror.ErrTypeRemoteError:
 			// 远程百度服务器的错误
@@ -365,7 +404,7 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 
 				result.ResultMessage = StrUploadFailed
 				result.Err = errors.New("上传状态过期, 重新上传")
-			case 31061:
+			case 31061, xpan.ErrnoFileExists:
 				// 已存在重名文件, 不重试
 				result.ResultMessage = StrUploadFailed
 				result.Err = pcsError
@@ -397,11 +436,180 @@ func (utu *UploadTaskUnit) upload() (result *taskframework.TaskUnitRunResult) {
 		}
 		return
 	})
+	utu.setMultiUploader(muer)
+	if utu.IsPaused() {
+		// 开始前已被暂停
+		muer.Pause()
+	}
+	// 取消时中断上传
+	stop := context.AfterFunc(ctx, muer.Cancel)
 	muer.Execute()
+	stop()
+	utu.setMultiUploader(nil)
 
 	return
 }
 
+// xpanUpload 使用开放平台 (xpan) 接口上传文件, 预上传时服务器已有相同内容的文件即秒传成功
+func (utu *UploadTaskUnit) xpanUpload(ctx context.Context) (result *taskframework.TaskUnitRunResult) {
+	utu.Step = StepUploadUpload
+	result = &taskframework.TaskUnitRunResult{}
+
+	xc := utu.xc
+	if xc == nil {
+		accessToken, err := pcsconfig.Config.ActiveUser().XpanAccessToken(ctx)
+		if err != nil {
+			result.ResultMessage = "xpan 接口上传需要 accesstoken"
+			result.Err = err
+			return
+		}
+		client := pcsconfig.Config.HTTPClient()
+		client.SetTimeout(200 * time.Second)
+		xc = xpan.NewClient(accessToken, client)
+	}
+
+	// 检测同名文件, skip 策略下由服务器拒绝列表之后出现的同名文件
+	rtype := xpan.RtypeOverwrite
+	if utu.Policy == baidupcs.SkipPolicy {
+		rtype = xpan.RtypeFail
+	}
+	list, pcsError := xc.List(ctx, utu.panDir, nil)
+	if pcsError != nil && !xpan.IsNotExist(pcsError) {
+		result.ResultMessage = "获取文件列表错误"
+		result.NeedRetry = !xpan.IsAccessTokenInvalid(pcsError)
+		result.Err = pcsError
+		return
+	}
+	for _, f := range list {
+		if f.ServerFilename != utu.panFile {
+			continue
+		}
+		if f.Isdir != 0 {
+			result.ResultMessage = StrUploadFailed
+			result.Err = errors.New("保存路径不可以覆盖目录")
+			return
+		}
+		switch {
+		case utu.Policy == baidupcs.SkipPolicy:
+			result.Extra = baidupcs.SkipPolicy
+			result.ResultMessage = fmt.Sprintf("%s 目标已存在, 跳过", utu.SavePath)
+			return
+		case utu.Policy == baidupcs.RsyncPolicy && f.Size == utu.LocalFileChecksum.Length:
+			result.Extra = baidupcs.RsyncPolicy
+			result.ResultMessage = fmt.Sprintf("%s 目标大小未发生改变, 跳过", utu.SavePath)
+			return
+		}
+	}
+
+	// 分片 md5, 分片大小与 PCS 接口不同时重新计算
+	blockSize := xpan.BlockSize(utu.LocalFileChecksum.Length)
+	blockCount := int((utu.LocalFileChecksum.Length + blockSize - 1) / blockSize)
+	if len(utu.LocalFileChecksum.BlocksList) != blockCount {
+		fmt.Printf("[%s] 开始计算文件分块md5, 请稍候...\n", utu.taskInfo.Id())
+		err := utu.LocalFileChecksum.CalculateChunkedSum(blockSize)
+		if err != nil {
+			result.ResultMessage = "计算文件分块md5出错"
+			result.Err = err
+			return
+		}
+	}
+	blockList := utu.LocalFileChecksum.BlocksList
+	if blockCount == 0 {
+		blockList = []string{emptyFileMD5} // 空文件
+	}
+
+	fmt.Printf("[%s] 使用 xpan 接口上传文件...\n\n", utu.taskInfo.Id())
+	xu := uploader.NewXpanUpload(xc, utu.panPath, utu.LocalFileChecksum.Length, blockList, rtype)
+	return utu.upload(ctx, xu, blockSize)
+}
+
+// fallbackXpan 使用 PCS 接口上传遇到权限错误, 且帐号已授权 xpan 时, 改用 xpan 接口
+func (utu *UploadTaskUnit) fallbackXpan(pcsError pcserror.Error) bool {
+	if utu.API == pcsconfig.UploadAPIXpan || !isPermissionError(pcsError) {
+		return false
+	}
+	if pcsconfig.Config.ActiveUser().AccessToken == "" {
+		return false
+	}
+	utu.API = pcsconfig.UploadAPIXpan
+	fmt.Printf("[%s] PCS 上传接口无权限, 改用 xpan 接口上传\n", utu.taskInfo.Id())
+	return true
+}
+
+func (utu *UploadTaskUnit) setMultiUploader(muer *uploader.MultiUploader) {
+	utu.pauseMu.Lock()
+	utu.muer = muer
+	utu.pauseMu.Unlock()
+}
+
+// Pause 暂停上传, 断点信息会立即保存.
+// 任务尚未开始上传时, 将在开始后立即暂停
+func (utu *UploadTaskUnit) Pause() {
+	utu.pauseMu.Lock()
+	defer utu.pauseMu.Unlock()
+	utu.paused = true
+	if utu.muer != nil {
+		utu.muer.Pause()
+	}
+}
+
+// Resume 恢复上传
+func (utu *UploadTaskUnit) Resume() {
+	utu.pauseMu.Lock()
+	defer utu.pauseMu.Unlock()
+	utu.paused = false
+	if utu.muer != nil {
+		utu.muer.Resume()
+	}
+}
+
+// IsPaused 是否已暂停
+func (utu *UploadTaskUnit) IsPaused() bool {
+	utu.pauseMu.Lock()
+	defer utu.pauseMu.Unlock()
+	return utu.paused
+}
+
+// SkipReason 返回跳过上传的原因, 没有返回执行结果时使用
+func (utu *UploadTaskUnit) SkipReason() string {
+	return utu.skipReason
+}
+
+// prepareCrypt 解析网盘中的保存路径, 保存到加密目录时设置加密读取文件内容
+func (utu *UploadTaskUnit) prepareCrypt() (err error) {
+	utu.panPath = utu.SavePath
+	r := pcscrypt.Active()
+	if r == nil || !r.Contains(path.Clean(utu.SavePath)) {
+		utu.LocalFileChecksum.Transform = nil
+		return nil
+	}
+
+	utu.panPath, err = r.EncryptPath(utu.SavePath)
+	if err != nil {
+		return err
+	}
+	if utu.nonce == nil {
+		utu.nonce, err = pcscrypt.NewNonce()
+		if err != nil {
+			return err
+		}
+	}
+	nonce := utu.nonce
+	utu.LocalFileChecksum.Transform = func(f *os.File, size int64) (checksum.File, int64, error) {
+		e := r.NewEncrypter(f, size, nonce)
+		return e, e.Len(), nil
+	}
+	return nil
+}
+
+// canceledResult 上传已取消的结果
+func (utu *UploadTaskUnit) canceledResult(ctx context.Context) *taskframework.TaskUnitRunResult {
+	return &taskframework.TaskUnitRunResult{
+		ResultMessage: "上传已取消",
+		Err:           ctx.Err(),
+	}
+}
+
 func (utu *UploadTaskUnit) OnRetry(lastRunResult *taskframework.TaskUnitRunResult) {
 	// 输出错误信息
 	if lastRunResult.Err == nil {
@@ -432,21 +640,38 @@ func (utu *UploadTaskUnit) RetryWait() time.Duration {
 	return pcsfunctions.RetryWait(utu.taskInfo.Retry())
 }
 
-func (utu *UploadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
+func (utu *UploadTaskUnit) Run(ctx context.Context) (result *taskframework.TaskUnitRunResult) {
 	fmt.Printf("[%s] 准备上传: %s\n", utu.taskInfo.Id(), utu.LocalFileChecksum.Path)
 
 	if utu.LocalFileChecksum.Length > baidupcs.MaxUploadSize {
 		fmt.Printf("[%s] 文件大小超过128G, 无法上传, 跳过...\n", utu.taskInfo.Id())
+		utu.skipReason = "文件大小超过128G, 无法上传"
+		return
+	}
+
+	err := utu.prepareCrypt()
+	if err != nil {
+		fmt.Printf("[%s] 加密文件名失败, 错误信息: %s, 跳过...\n", utu.taskInfo.Id(), err)
+		utu.skipReason = "加密文件名失败: " + err.Error()
 		return
 	}
 
-	err := utu.LocalFileChecksum.OpenPath()
+	err = utu.LocalFileChecksum.OpenPath()
 	if err != nil {
 		fmt.Printf("[%s] 文件不可读, 错误信息: %s, 跳过...\n", utu.taskInfo.Id(), err)
+		utu.skipReason = "文件不可读: " + err.Error()
 		return
 	}
 	defer utu.LocalFileChecksum.Close() // 关闭文件
 
+	if utu.API == "" {
+		utu.API = pcsconfig.Config.UploadAPI
+	}
+	utu.preparePath()
+	if utu.API == pcsconfig.UploadAPIXpan {
+		return utu.xpanUpload(ctx)
+	}
+
 	// 准备文件
 	utu.prepareFile()
 
@@ -461,6 +686,9 @@ func (utu *UploadTaskUnit) Run() (result *taskframework.TaskUnitRunResult) {
 
 stepUploadRapidUpload:
 	// 秒传
+	if ctx.Err() != nil {
+		return utu.canceledResult(ctx)
+	}
 	{
 		isContinue, rapidUploadResult := utu.rapidUpload()
 		if !isContinue {
@@ -471,7 +699,14 @@ stepUploadRapidUpload:
 
 stepUploadUpload:
 	// 正常上传流程
-	uploadResult := utu.upload()
+	if ctx.Err() != nil {
+		return utu.canceledResult(ctx)
+	}
+	if utu.API == pcsconfig.UploadAPIXpan {
+		// 秒传时 PCS 接口无权限, 已改用 xpan 接口
+		return utu.xpanUpload(ctx)
+	}
+	uploadResult := utu.upload(ctx, NewPCSUpload(utu.PCS, utu.panPath), getBlockSize(utu.LocalFileChecksum.Length))
 
 	return uploadResult
 }
@@ -0,0 +1,176 @@
+package pcsupload
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+)
+
+// fakeXpan 模拟 xpan 接口, 网盘 /dir 中已有 exist.txt
+type fakeXpan struct {
+	mu      sync.Mutex
+	methods []string // 收到的请求
+	rtype   string   // 预上传的 rtype
+}
+
+func (f *fakeXpan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Query().Get("method")
+	if r.URL.Path == "/rest/2.0/pcs/superfile2" {
+		method = "superfile2"
+	}
+	f.mu.Lock()
+	f.methods = append(f.methods, method)
+	f.mu.Unlock()
+
+	switch method {
+	case "list":
+		fmt.Fprint(w, `{"errno":0,"list":[{"server_filename":"exist.txt","path":"/dir/exist.txt","isdir":0,"size":100}]}`)
+	case "precreate":
+		f.mu.Lock()
+		f.rtype = r.PostFormValue("rtype")
+		f.mu.Unlock()
+		fmt.Fprint(w, `{"errno":0,"uploadid":"u1","return_type":1,"block_list":[0]}`)
+	case "superfile2":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h := md5.New()
+		io.Copy(h, file)
+		json.NewEncoder(w).Encode(map[string]string{"md5": hex.EncodeToString(h.Sum(nil))})
+	case "create":
+		fmt.Fprint(w, `{"errno":0,"fs_id":1,"path":"/dir/new.txt","size":5}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeXpan) called(method string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, m := range f.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// newXpanUnit 创建使用 fakeXpan 上传的任务单元
+func newXpanUnit(t *testing.T, f *fakeXpan, savePath, policy string) *UploadTaskUnit {
+	t.Setenv(pcsconfig.EnvConfigDir, t.TempDir())
+	srv := httptest.NewTLSServer(f)
+	t.Cleanup(srv.Close)
+
+	xc := xpan.NewClient("token", nil)
+	xc.SetAddr(strings.TrimPrefix(srv.URL, "https://"))
+	xc.SetPCSAddr(strings.TrimPrefix(srv.URL, "https://"))
+
+	localPath := filepath.Join(t.TempDir(), "local.txt")
+	if err := os.WriteFile(localPath, []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewUploadingDatabase()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	utu := &UploadTaskUnit{
+		LocalFileChecksum: checksum.NewLocalFileChecksum(localPath, int(baidupcs.SliceMD5Size)),
+		SavePath:          savePath,
+		PrintFormat:       DefaultPrintFormat,
+		UploadingDatabase: db,
+		Parallel:          1,
+		Policy:            policy,
+		API:               pcsconfig.UploadAPIXpan,
+		UploadStatistic:   &UploadStatistic{},
+		xc:                xc,
+	}
+	utu.SetTaskInfo(&taskframework.TaskInfo{})
+	return utu
+}
+
+func TestXpanUploadSkipExisting(t *testing.T) {
+	f := &fakeXpan{}
+	utu := newXpanUnit(t, f, "/dir/exist.txt", baidupcs.SkipPolicy)
+
+	result := utu.Run(context.Background())
+	if result == nil || result.Extra != baidupcs.SkipPolicy || result.Err != nil {
+		t.Fatalf("want skipped result, got %+v", result)
+	}
+	if f.called("precreate") || f.called("create") {
+		t.Errorf("existing file was overwritten, requests: %v", f.methods)
+	}
+}
+
+func TestXpanUploadSkipRtype(t *testing.T) {
+	f := &fakeXpan{}
+	utu := newXpanUnit(t, f, "/dir/new.txt", baidupcs.SkipPolicy)
+
+	result := utu.Run(context.Background())
+	if result == nil || !result.Succeed {
+		t.Fatalf("upload failed: %+v", result)
+	}
+	if f.rtype != fmt.Sprint(int(xpan.RtypeFail)) {
+		t.Errorf("skip policy precreate rtype %s, want %d", f.rtype, xpan.RtypeFail)
+	}
+}
+
+func TestXpanUploadPause(t *testing.T) {
+	f := &fakeXpan{}
+	utu := newXpanUnit(t, f, "/dir/new.txt", baidupcs.OverWritePolicy)
+
+	// 开始前暂停, 开始上传后立即触发暂停事件
+	utu.Pause()
+	done := make(chan *taskframework.TaskUnitRunResult)
+	go func() {
+		done <- utu.Run(context.Background())
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		utu.pauseMu.Lock()
+		started := utu.muer != nil
+		utu.pauseMu.Unlock()
+		if started {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("upload did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if f.called("create") {
+		t.Fatal("paused upload finished")
+	}
+	utu.Resume()
+
+	select {
+	case result := <-done:
+		if result == nil || !result.Succeed {
+			t.Fatalf("upload failed after resume: %+v", result)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("upload did not finish after resume")
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"crypto/md5"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
 	"io"
 	"strconv"
+	"strings"
 )
 
 func getBlockSize(fileSize int64) int64 {
@@ -36,3 +38,20 @@ func creaetDataOffset(contentMD5 string, uk, dataTime, fileSize, subSize int64)
 	offset = rawOffset % (fileSize - subSize + 1)
 	return
 }
+
+// isPermissionError PCS 上传接口是否因帐号无权限被拒绝
+func isPermissionError(pcsError pcserror.Error) bool {
+	if pcsError == nil {
+		return false
+	}
+	switch pcsError.GetErrType() {
+	case pcserror.ErrTypeRemoteError:
+		switch pcsError.GetRemoteErrCode() {
+		case -6, 3, 31045, 31064:
+			return true
+		}
+	case pcserror.ErrTypeNetError:
+		return pcsError.GetError() != nil && strings.Contains(pcsError.GetError().Error(), "403")
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Package pcswatch 监听网盘目录和本地目录的文件变化
+package pcswatch
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester"
+)
+
+type (
+	// EventType 文件变化类型
+	EventType string
+
+	// Event 文件变化事件
+	Event struct {
+		ID      int64     `json:"id"`
+		WatchID string    `json:"watch_id"`
+		Type    EventType `json:"type"`
+		Path    string    `json:"path"`
+		IsDir   bool      `json:"is_dir,omitempty"`
+		Size    int64     `json:"size"`
+		MD5     string    `json:"md5,omitempty"`
+		Mtime   int64     `json:"mtime,omitempty"`
+		Time    int64     `json:"time"` // 事件发出的时间
+	}
+
+	// Hub 保存最近的事件, 分发给订阅者和回调地址
+	Hub struct {
+		mu     sync.Mutex
+		lastID int64
+		events []*Event
+		subs   map[chan *Event]struct{}
+		client *requester.HTTPClient
+	}
+)
+
+const (
+	// EventCreated 新增
+	EventCreated EventType = "created"
+	// EventModified 修改
+	EventModified EventType = "modified"
+	// EventDeleted 删除
+	EventDeleted EventType = "deleted"
+
+	// HubBufferSize 保存的最近事件数量, 订阅时可以从中补发
+	HubBufferSize = 1000
+
+	// callbackRetry 回调失败时的重试次数
+	callbackRetry = 3
+)
+
+var (
+	// DefaultHub 默认的事件中心
+	DefaultHub = NewHub()
+
+	watchVerbose = pcsverbose.New("WATCH")
+)
+
+// NewHub 初始化Hub
+func NewHub() *Hub {
+	client := requester.NewHTTPClient()
+	client.SetTimeout(10 * time.Second)
+	return &Hub{
+		subs:   map[chan *Event]struct{}{},
+		client: client,
+	}
+}
+
+// Publish 发出事件, 分配事件 id 后发送给订阅者, 并在后台 POST 到 callbacks
+func (h *Hub) Publish(e *Event, callbacks []string) {
+	h.mu.Lock()
+	h.lastID++
+	e.ID = h.lastID
+	e.Time = time.Now().Unix()
+	h.events = append(h.events, e)
+	if len(h.events) > HubBufferSize {
+		h.events = h.events[len(h.events)-HubBufferSize:]
+	}
+	for ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			// 订阅者处理过慢, 丢弃事件, 可通过 id 不连续发现
+		}
+	}
+	h.mu.Unlock()
+
+	for _, u := range callbacks {
+		go h.callback(u, e)
+	}
+}
+
+// Subscribe 订阅事件, 返回 afterID 之后仍在缓存中的事件和新事件的 channel, 调用 cancel 取消订阅
+func (h *Hub) Subscribe(afterID int64) (backlog []*Event, ch <-chan *Event, cancel func()) {
+	c := make(chan *Event, 64)
+	h.mu.Lock()
+	for _, e := range h.events {
+		if e.ID > afterID {
+			backlog = append(backlog, e)
+		}
+	}
+	h.subs[c] = struct{}{}
+	h.mu.Unlock()
+
+	return backlog, c, func() {
+		h.mu.Lock()
+		delete(h.subs, c)
+		h.mu.Unlock()
+	}
+}
+
+// callback 以 json 格式 POST 事件, 非 2xx 响应时重试
+func (h *Hub) callback(u string, e *Event) {
+	buf := &bytes.Buffer{}
+	err := jsonhelper.MarshalData(buf, e)
+	if err != nil {
+		return
+	}
+	body := buf.Bytes()
+
+	header := map[string]string{
+		"Content-Type": "application/json",
+	}
+	for i := 0; i < callbackRetry; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(1<<i) * time.Second)
+		}
+		resp, err := h.client.ReqContext(context.Background(), http.MethodPost, u, body, header)
+		if err != nil {
+			watchVerbose.Warnf("callback %s: %s\n", u, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return
+		}
+		watchVerbose.Warnf("callback %s: %s\n", u, resp.Status)
+	}
+}
@@ -0,0 +1,506 @@
+package pcswatch
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcstransfer"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/checksum"
+)
+
+type (
+	// AfterUpload 上传并校验成功后对本地文件的处理方式
+	AfterUpload string
+
+	// LocalOptions 本地目录监听选项
+	LocalOptions struct {
+		LocalDir  string      `json:"local_dir"`
+		RemoteDir string      `json:"remote_dir"`
+		Include   []string    `json:"include,omitempty"`  // 只上传匹配的文件, 匹配相对路径或文件名
+		Exclude   []string    `json:"exclude,omitempty"`  // 不上传匹配的文件, 优先于 Include
+		Policy    string      `json:"policy,omitempty"`   // 重名文件策略, 默认使用配置中的策略
+		Settle    int         `json:"settle,omitempty"`   // 文件大小在该时间内不变才上传 (秒)
+		Parallel  int         `json:"parallel,omitempty"` // 同时上传的文件数量
+		After     AfterUpload `json:"after,omitempty"`    // 上传后删除或移动本地文件
+		MoveTo    string      `json:"move_to,omitempty"`  // After 为 move 时移动到的本地目录
+	}
+
+	// LocalWatch 本地目录监听的状态
+	LocalWatch struct {
+		ID        string       `json:"id"`
+		Options   LocalOptions `json:"options"`
+		CreatedAt int64        `json:"created_at"`
+		Err       string       `json:"error,omitempty"` // 最近一次的错误
+		Uploaded  int64        `json:"uploaded"`        // 已上传的文件数
+		Failed    int64        `json:"failed"`          // 上传失败的文件数
+		Pending   int          `json:"pending"`         // 等待写入完成的文件数
+		Uploading int          `json:"uploading"`       // 正在上传的文件数
+	}
+
+	// candidate 等待写入完成的文件
+	candidate struct {
+		path        string
+		size        int64
+		mtime       time.Time
+		stableSince time.Time
+	}
+
+	// uploadedFile 已上传的文件, 用于忽略未改变内容的事件
+	uploadedFile struct {
+		size  int64
+		mtime time.Time
+	}
+
+	// localWatcher 监听本地目录并自动上传
+	localWatcher struct {
+		mu   sync.Mutex
+		info LocalWatch
+
+		pcs    *baidupcs.BaiduPCS
+		cancel context.CancelFunc
+		print  bool // 输出上传结果
+
+		fw         *fsnotify.Watcher
+		candidates map[string]*candidate
+		uploaded   map[string]*uploadedFile
+	}
+)
+
+const (
+	// AfterUploadKeep 保留本地文件
+	AfterUploadKeep AfterUpload = ""
+	// AfterUploadDelete 删除本地文件
+	AfterUploadDelete AfterUpload = "delete"
+	// AfterUploadMove 移动本地文件到 MoveTo
+	AfterUploadMove AfterUpload = "move"
+
+	// DefaultSettle 默认的等待写入完成时间 (秒)
+	DefaultSettle = 5
+)
+
+var (
+	// ErrNoMoveTo 未指定移动到的目录
+	ErrNoMoveTo = errors.New("move_to is required when after is move")
+	// ErrUnknownAfterUpload 未知的上传后处理方式
+	ErrUnknownAfterUpload = errors.New("unknown after upload action")
+)
+
+// normalize 检查选项并填充默认值, 本地目录转换为绝对路径
+func (opt *LocalOptions) normalize() (err error) {
+	opt.LocalDir, err = filepath.Abs(opt.LocalDir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(opt.LocalDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", opt.LocalDir)
+	}
+	opt.RemoteDir = path.Clean("/" + opt.RemoteDir)
+
+	for _, patterns := range [][]string{opt.Include, opt.Exclude} {
+		for _, pattern := range patterns {
+			if _, err = path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %s: %s", pattern, err)
+			}
+		}
+	}
+	switch opt.Policy {
+	case "", baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy:
+	default:
+		return fmt.Errorf("unknown policy: %s", opt.Policy)
+	}
+	switch opt.After {
+	case AfterUploadKeep, AfterUploadDelete:
+	case AfterUploadMove:
+		if opt.MoveTo == "" {
+			return ErrNoMoveTo
+		}
+		opt.MoveTo, err = filepath.Abs(opt.MoveTo)
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrUnknownAfterUpload
+	}
+	if opt.Settle <= 0 {
+		opt.Settle = DefaultSettle
+	}
+	return nil
+}
+
+// newLocalWatcher 初始化localWatcher, 监听本地目录及其子目录
+func newLocalWatcher(pcs *baidupcs.BaiduPCS, info LocalWatch) (*localWatcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &localWatcher{
+		info:       info,
+		pcs:        pcs,
+		fw:         fw,
+		candidates: map[string]*candidate{},
+		uploaded:   map[string]*uploadedFile{},
+	}, nil
+}
+
+// run 监听文件变化直到 ctx 取消. 开始时扫描整个目录, 恢复停止期间错过的文件
+func (w *localWatcher) run(ctx context.Context) {
+	defer w.fw.Close()
+
+	w.addDir(w.info.Options.LocalDir)
+
+	var (
+		tick      = time.NewTicker(time.Second)
+		done      = make(chan struct{})
+		uploading bool
+		queue     []*candidate
+	)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if uploading {
+				<-done
+			}
+			return
+
+		case ev, ok := <-w.fw.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(ev)
+
+		case err, ok := <-w.fw.Errors:
+			if !ok {
+				return
+			}
+			w.setErr(err)
+
+		case <-done:
+			uploading = false
+
+		case now := <-tick.C:
+			queue = append(queue, w.settled(now)...)
+		}
+
+		if !uploading && len(queue) > 0 {
+			uploading = true
+			batch := queue
+			queue = nil
+			go func() {
+				w.upload(ctx, batch)
+				done <- struct{}{}
+			}()
+		}
+
+		w.mu.Lock()
+		w.info.Pending = len(w.candidates) + len(queue)
+		w.mu.Unlock()
+	}
+}
+
+// addDir 监听目录及其子目录, 目录中已有的文件加入候选
+func (w *localWatcher) addDir(dir string) {
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if w.skipDir(p) {
+				return filepath.SkipDir
+			}
+			if err := w.fw.Add(p); err != nil {
+				w.setErr(err)
+			}
+			return nil
+		}
+		w.addCandidate(p)
+		return nil
+	})
+}
+
+// skipDir 不监听 MoveTo 目录
+func (w *localWatcher) skipDir(dir string) bool {
+	opt := w.info.Options
+	return opt.After == AfterUploadMove && dir == opt.MoveTo
+}
+
+// handleEvent 处理文件事件, 新建的目录需要单独监听
+func (w *localWatcher) handleEvent(ev fsnotify.Event) {
+	switch {
+	case ev.Has(fsnotify.Create):
+		info, err := os.Stat(ev.Name)
+		if err != nil {
+			return
+		}
+		if info.IsDir() {
+			w.addDir(ev.Name)
+			return
+		}
+		w.addCandidate(ev.Name)
+	case ev.Has(fsnotify.Write):
+		w.addCandidate(ev.Name)
+	case ev.Has(fsnotify.Remove), ev.Has(fsnotify.Rename):
+		delete(w.candidates, ev.Name)
+		w.setUploaded(ev.Name, nil)
+	}
+}
+
+// addCandidate 加入等待写入完成的文件, 已在等待的文件重新计时由 settled 处理
+func (w *localWatcher) addCandidate(p string) {
+	if _, ok := w.candidates[p]; ok {
+		return
+	}
+	rel, err := filepath.Rel(w.info.Options.LocalDir, p)
+	if err != nil || !w.match(filepath.ToSlash(rel)) {
+		return
+	}
+	info, err := os.Stat(p)
+	if err != nil || !info.Mode().IsRegular() {
+		return
+	}
+	w.mu.Lock()
+	up := w.uploaded[p]
+	w.mu.Unlock()
+	if up != nil && up.size == info.Size() && up.mtime.Equal(info.ModTime()) {
+		return
+	}
+	w.candidates[p] = &candidate{
+		path:        p,
+		size:        info.Size(),
+		mtime:       info.ModTime(),
+		stableSince: time.Now(),
+	}
+}
+
+// match 按 Include 和 Exclude 过滤, 匹配相对路径或文件名
+func (w *localWatcher) match(rel string) bool {
+	var (
+		opt   = w.info.Options
+		base  = path.Base(rel)
+		match = func(patterns []string) bool {
+			for _, pattern := range patterns {
+				if ok, _ := path.Match(pattern, rel); ok {
+					return true
+				}
+				if ok, _ := path.Match(pattern, base); ok {
+					return true
+				}
+			}
+			return false
+		}
+	)
+	if match(opt.Exclude) {
+		return false
+	}
+	return len(opt.Include) == 0 || match(opt.Include)
+}
+
+// settled 返回大小和修改时间在 Settle 内没有变化的文件
+func (w *localWatcher) settled(now time.Time) (ready []*candidate) {
+	settle := time.Duration(w.info.Options.Settle) * time.Second
+	for p, c := range w.candidates {
+		info, err := os.Stat(p)
+		if err != nil {
+			delete(w.candidates, p)
+			continue
+		}
+		if info.Size() != c.size || !info.ModTime().Equal(c.mtime) {
+			c.size, c.mtime, c.stableSince = info.Size(), info.ModTime(), now
+			continue
+		}
+		if now.Sub(c.stableSince) < settle {
+			continue
+		}
+		delete(w.candidates, p)
+		ready = append(ready, c)
+	}
+	return ready
+}
+
+// upload 上传一批文件, 复用 pcsupload.UploadTaskUnit, 可通过 /api/transfers 查看进度
+func (w *localWatcher) upload(ctx context.Context, batch []*candidate) {
+	opt := w.info.Options
+	t := pcstransfer.Default.New(pcstransfer.KindUpload, pcstransfer.Options{
+		Parallel:  opt.Parallel,
+		Policy:    opt.Policy,
+		NoPersist: true,
+	})
+
+	bySource := map[string]*candidate{}
+	for _, c := range batch {
+		rel, err := filepath.Rel(opt.LocalDir, c.path)
+		if err != nil {
+			continue
+		}
+		_, err = t.AppendUpload(c.path, path.Join(opt.RemoteDir, filepath.ToSlash(rel)))
+		if err != nil {
+			w.setErr(err)
+			continue
+		}
+		bySource[c.path] = c
+	}
+	if len(bySource) == 0 {
+		pcstransfer.Default.Remove(t.ID)
+		return
+	}
+
+	w.mu.Lock()
+	w.info.Uploading = len(bySource)
+	w.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, func() {
+		t.Cancel("")
+	})
+	t.Execute()
+	stop()
+
+	for _, item := range t.Info(true).Items {
+		c := bySource[item.Source]
+		if c == nil {
+			continue
+		}
+		if item.Status != pcstransfer.StatusSucceeded {
+			w.mu.Lock()
+			w.info.Failed++
+			w.mu.Unlock()
+			if item.Message != "" {
+				w.setErr(fmt.Errorf("%s: %s", item.Source, item.Message))
+			}
+			continue
+		}
+
+		w.mu.Lock()
+		w.info.Uploaded++
+		w.mu.Unlock()
+		if ctx.Err() != nil {
+			continue
+		}
+		err := w.afterUpload(ctx, c, item.Target)
+		if err != nil {
+			w.setErr(fmt.Errorf("%s: %s", item.Source, err))
+			continue
+		}
+		if w.print {
+			fmt.Printf("[watch %s] 上传完成: %s -> %s\n", w.info.ID, item.Source, item.Target)
+		}
+	}
+
+	w.mu.Lock()
+	w.info.Uploading = 0
+	w.mu.Unlock()
+}
+
+// afterUpload 校验网盘文件的大小和 md5 后删除或移动本地文件, 上传后本地文件又被修改时保留
+func (w *localWatcher) afterUpload(ctx context.Context, c *candidate, target string) error {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != c.size || !info.ModTime().Equal(c.mtime) {
+		// 上传期间被修改, 由新的事件重新上传
+		return nil
+	}
+	w.setUploaded(c.path, &uploadedFile{size: c.size, mtime: c.mtime})
+
+	opt := w.info.Options
+	if opt.After == AfterUploadKeep {
+		return nil
+	}
+
+	fd, pcsError := w.pcs.FilesDirectoriesMetaContext(ctx, target)
+	if pcsError != nil {
+		return pcsError
+	}
+	if fd.Size != c.size {
+		return fmt.Errorf("网盘文件大小 %d 与本地不一致, 保留本地文件", fd.Size)
+	}
+	if remoteMD5 := baidupcs.DecryptMD5(fd.MD5); remoteMD5 != "" {
+		sum, err := checksum.GetFileSum(c.path, checksum.CHECKSUM_MD5)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(hex.EncodeToString(sum.MD5), remoteMD5) {
+			return errors.New("网盘文件 md5 与本地不一致, 保留本地文件")
+		}
+	}
+
+	w.setUploaded(c.path, nil)
+	if opt.After == AfterUploadDelete {
+		return os.Remove(c.path)
+	}
+	rel, err := filepath.Rel(opt.LocalDir, c.path)
+	if err != nil {
+		return err
+	}
+	dst := filepath.Join(opt.MoveTo, rel)
+	err = os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+	return os.Rename(c.path, dst)
+}
+
+// setUploaded 记录已上传的文件, up 为空时删除记录
+func (w *localWatcher) setUploaded(p string, up *uploadedFile) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if up == nil {
+		delete(w.uploaded, p)
+		return
+	}
+	w.uploaded[p] = up
+}
+
+// setErr 记录最近一次的错误
+func (w *localWatcher) setErr(err error) {
+	watchVerbose.Warnf("watch %s: %s\n", w.info.ID, err)
+	if w.print {
+		fmt.Printf("[watch %s] %s\n", w.info.ID, err)
+	}
+	w.mu.Lock()
+	w.info.Err = err.Error()
+	w.mu.Unlock()
+}
+
+// status 返回状态的快照
+func (w *localWatcher) status() *LocalWatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	info := w.info
+	return &info
+}
+
+// Watch 在前台监听本地目录并自动上传, 直到 ctx 取消
+func Watch(ctx context.Context, pcs *baidupcs.BaiduPCS, opt LocalOptions) error {
+	err := opt.normalize()
+	if err != nil {
+		return err
+	}
+	w, err := newLocalWatcher(pcs, LocalWatch{
+		ID:        "cli",
+		Options:   opt,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	w.print = true
+	w.run(ctx)
+	return nil
+}
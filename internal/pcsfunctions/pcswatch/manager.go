@@ -0,0 +1,330 @@
+package pcswatch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/jsonhelper"
+)
+
+const (
+	// WatchFileName 持久化的监听配置文件名
+	WatchFileName = "pcs_watch.json"
+)
+
+type (
+	// Manager 管理监听任务, 配置保存在配置目录中, 重启后可恢复
+	Manager struct {
+		mu     sync.Mutex
+		lastID int64
+		remote map[string]*remoteWatcher
+		local  map[string]*localWatcher
+		Hub    *Hub
+	}
+
+	// watchData 监听配置文件的内容
+	watchData struct {
+		Remote []*RemoteWatch `json:"remote,omitempty"`
+		Local  []*LocalWatch  `json:"local,omitempty"`
+	}
+)
+
+var (
+	// Default 默认的监听任务管理器
+	Default = NewManager(DefaultHub)
+
+	// ErrWatchNotFound 监听任务不存在
+	ErrWatchNotFound = errors.New("watch not found")
+)
+
+// NewManager 初始化Manager
+func NewManager(hub *Hub) *Manager {
+	return &Manager{
+		remote: map[string]*remoteWatcher{},
+		local:  map[string]*localWatcher{},
+		Hub:    hub,
+	}
+}
+
+// checkCallbacks 检查回调地址, 只支持 http 和 https
+func checkCallbacks(callbacks []string) error {
+	for _, u := range callbacks {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return err
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("invalid callback url: %s", u)
+		}
+	}
+	return nil
+}
+
+// nextID 生成监听任务 id, 调用前需加锁
+func (m *Manager) nextID() string {
+	n := time.Now().UnixNano() / int64(time.Millisecond)
+	if n <= m.lastID {
+		n = m.lastID + 1
+	}
+	m.lastID = n
+	return strconv.FormatInt(n, 36)
+}
+
+// StartRemote 开始监听网盘目录
+func (m *Manager) StartRemote(pcs *baidupcs.BaiduPCS, opt RemoteOptions) (*RemoteWatch, error) {
+	err := opt.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	w := m.startRemote(pcs, RemoteWatch{
+		ID:        m.nextID(),
+		Options:   opt,
+		CreatedAt: time.Now().Unix(),
+	})
+	err = m.save()
+	m.mu.Unlock()
+	return w.status(), err
+}
+
+// startRemote 在后台运行监听任务, 调用前需加锁
+func (m *Manager) startRemote(pcs *baidupcs.BaiduPCS, info RemoteWatch) *remoteWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &remoteWatcher{
+		info:    info,
+		pcs:     pcs,
+		hub:     m.Hub,
+		cancel:  cancel,
+		pending: map[string]*pendingEvent{},
+	}
+	m.remote[info.ID] = w
+	go w.run(ctx)
+	return w
+}
+
+// ListRemote 按创建顺序列出网盘目录监听任务
+func (m *Manager) ListRemote() []*RemoteWatch {
+	m.mu.Lock()
+	list := make([]*RemoteWatch, 0, len(m.remote))
+	for _, w := range m.remote {
+		list = append(list, w.status())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// GetRemote 获取网盘目录监听任务的状态
+func (m *Manager) GetRemote(id string) (*RemoteWatch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.remote[id]
+	if !ok {
+		return nil, ErrWatchNotFound
+	}
+	return w.status(), nil
+}
+
+// StopRemote 停止并删除网盘目录监听任务, 未稳定的变化会被丢弃
+func (m *Manager) StopRemote(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.remote[id]
+	if !ok {
+		return ErrWatchNotFound
+	}
+	w.cancel()
+	delete(m.remote, id)
+	return m.save()
+}
+
+// StartLocal 开始监听本地目录并自动上传
+func (m *Manager) StartLocal(pcs *baidupcs.BaiduPCS, opt LocalOptions) (*LocalWatch, error) {
+	err := opt.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, err := m.startLocal(pcs, LocalWatch{
+		ID:        m.nextID(),
+		Options:   opt,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.status(), m.save()
+}
+
+// startLocal 在后台运行本地目录监听任务, 调用前需加锁
+func (m *Manager) startLocal(pcs *baidupcs.BaiduPCS, info LocalWatch) (*localWatcher, error) {
+	w, err := newLocalWatcher(pcs, info)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	m.local[info.ID] = w
+	go w.run(ctx)
+	return w, nil
+}
+
+// ListLocal 按创建顺序列出本地目录监听任务
+func (m *Manager) ListLocal() []*LocalWatch {
+	m.mu.Lock()
+	list := make([]*LocalWatch, 0, len(m.local))
+	for _, w := range m.local {
+		list = append(list, w.status())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// StopLocal 停止并删除本地目录监听任务, 正在上传的文件会被取消
+func (m *Manager) StopLocal(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w, ok := m.local[id]
+	if !ok {
+		return ErrWatchNotFound
+	}
+	w.cancel()
+	delete(m.local, id)
+	return m.save()
+}
+
+// Restore 恢复保存的监听任务, 返回恢复的数量.
+// 网盘目录从恢复时的状态开始检测, 停止期间的变化不会发出事件; 本地目录会重新扫描, 按重名文件策略上传
+func (m *Manager) Restore(pcs *baidupcs.BaiduPCS) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := loadWatchData()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, info := range data.Remote {
+		if _, ok := m.remote[info.ID]; ok {
+			continue
+		}
+		if info.Options.normalize() != nil {
+			continue
+		}
+		m.startRemote(pcs, RemoteWatch{
+			ID:        info.ID,
+			Options:   info.Options,
+			CreatedAt: info.CreatedAt,
+		})
+		n++
+	}
+	for _, info := range data.Local {
+		if _, ok := m.local[info.ID]; ok {
+			continue
+		}
+		if err := info.Options.normalize(); err != nil {
+			watchVerbose.Warnf("restore watch %s: %s\n", info.ID, err)
+			continue
+		}
+		_, err = m.startLocal(pcs, LocalWatch{
+			ID:        info.ID,
+			Options:   info.Options,
+			CreatedAt: info.CreatedAt,
+		})
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// watchFilePath 返回监听配置文件路径
+func watchFilePath() string {
+	return filepath.Join(pcsconfig.GetConfigDir(), WatchFileName)
+}
+
+// loadWatchData 读取监听配置文件
+func loadWatchData() (*watchData, error) {
+	data := &watchData{}
+	f, err := os.Open(watchFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return data, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > 0 {
+		err = jsonhelper.UnmarshalData(f, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// save 保存监听配置, 先写入临时文件再替换, 调用前需加锁
+func (m *Manager) save() error {
+	data := &watchData{}
+	for _, w := range m.remote {
+		data.Remote = append(data.Remote, &RemoteWatch{
+			ID:        w.info.ID,
+			Options:   w.info.Options,
+			CreatedAt: w.info.CreatedAt,
+		})
+	}
+	for _, w := range m.local {
+		data.Local = append(data.Local, &LocalWatch{
+			ID:        w.info.ID,
+			Options:   w.info.Options,
+			CreatedAt: w.info.CreatedAt,
+		})
+	}
+	sort.Slice(data.Remote, func(i, j int) bool {
+		return data.Remote[i].ID < data.Remote[j].ID
+	})
+	sort.Slice(data.Local, func(i, j int) bool {
+		return data.Local[i].ID < data.Local[j].ID
+	})
+
+	buf := &bytes.Buffer{}
+	err := jsonhelper.MarshalData(buf, data)
+	if err != nil {
+		return err
+	}
+
+	filename := watchFilePath()
+	tmp := filename + ".tmp"
+	err = os.WriteFile(tmp, buf.Bytes(), 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
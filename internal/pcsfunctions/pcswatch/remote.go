@@ -0,0 +1,344 @@
+package pcswatch
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcschanges"
+)
+
+type (
+	// RemoteMode 网盘目录的检测方式
+	RemoteMode string
+
+	// RemoteOptions 网盘目录监听选项
+	RemoteOptions struct {
+		Dirs      []string   `json:"dirs"`
+		Callbacks []string   `json:"callbacks,omitempty"` // 事件 POST 到的地址
+		Mode      RemoteMode `json:"mode,omitempty"`      // 检测方式, 默认 diff
+		Recurse   bool       `json:"recurse,omitempty"`   // 包含子目录中的文件
+		Interval  int        `json:"interval,omitempty"`  // 检测间隔 (秒)
+		Debounce  int        `json:"debounce,omitempty"`  // 文件在该时间内没有再变化才发出事件 (秒), 默认与检测间隔相同
+	}
+
+	// RemoteWatch 网盘目录监听的状态
+	RemoteWatch struct {
+		ID          string        `json:"id"`
+		Options     RemoteOptions `json:"options"`
+		CreatedAt   int64         `json:"created_at"`
+		LastCheckAt int64         `json:"last_check_at,omitempty"`
+		Err         string        `json:"error,omitempty"` // 最近一次检测的错误
+		Events      int64         `json:"events"`          // 已发出的事件数
+		Pending     int           `json:"pending"`         // 等待稳定的文件数
+	}
+
+	// pendingEvent 等待稳定的文件变化
+	pendingEvent struct {
+		typ      EventType
+		fd       *baidupcs.FileDirectory
+		changeAt time.Time // 最后一次发现变化的时间
+	}
+
+	// remoteWatcher 监听网盘目录
+	remoteWatcher struct {
+		mu   sync.Mutex
+		info RemoteWatch
+
+		pcs    *baidupcs.BaiduPCS
+		hub    *Hub
+		cancel context.CancelFunc
+
+		cursor   string                             // diff 模式
+		snapshot map[string]*baidupcs.FileDirectory // list 模式
+		pending  map[string]*pendingEvent
+	}
+)
+
+const (
+	// RemoteModeDiff 使用网盘的增量变更检测, 每次检测只需一次请求
+	RemoteModeDiff RemoteMode = "diff"
+	// RemoteModeList 定期列出目录, 比较前后两次的文件列表
+	RemoteModeList RemoteMode = "list"
+
+	// DefaultRemoteInterval 默认的检测间隔 (秒)
+	DefaultRemoteInterval = 30
+	// MinRemoteInterval 最小的检测间隔 (秒)
+	MinRemoteInterval = 5
+)
+
+var (
+	// ErrNoWatchDirs 未指定监听的目录
+	ErrNoWatchDirs = errors.New("no directories to watch")
+	// ErrUnknownRemoteMode 未知的检测方式
+	ErrUnknownRemoteMode = errors.New("unknown remote watch mode")
+)
+
+// normalize 检查选项并填充默认值
+func (opt *RemoteOptions) normalize() error {
+	if len(opt.Dirs) == 0 {
+		return ErrNoWatchDirs
+	}
+	for i := range opt.Dirs {
+		opt.Dirs[i] = path.Clean("/" + opt.Dirs[i])
+	}
+	switch opt.Mode {
+	case "":
+		opt.Mode = RemoteModeDiff
+	case RemoteModeDiff, RemoteModeList:
+	default:
+		return ErrUnknownRemoteMode
+	}
+	if opt.Interval <= 0 {
+		opt.Interval = DefaultRemoteInterval
+	}
+	if opt.Interval < MinRemoteInterval {
+		opt.Interval = MinRemoteInterval
+	}
+	if opt.Debounce <= 0 {
+		opt.Debounce = opt.Interval
+	}
+	return checkCallbacks(opt.Callbacks)
+}
+
+// run 定期检测直到 ctx 取消, 第一次检测只记录当前状态
+func (w *remoteWatcher) run(ctx context.Context) {
+	var (
+		opt      = w.info.Options
+		interval = time.Duration(opt.Interval) * time.Second
+		timer    = time.NewTimer(0)
+		inited   bool
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		var err error
+		if !inited {
+			err = w.init(ctx)
+			inited = err == nil
+		} else {
+			err = w.check(ctx)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		w.flush(time.Now())
+
+		w.mu.Lock()
+		w.info.LastCheckAt = time.Now().Unix()
+		w.info.Err = ""
+		if err != nil {
+			w.info.Err = err.Error()
+		}
+		w.info.Pending = len(w.pending)
+		w.mu.Unlock()
+
+		timer.Reset(interval)
+	}
+}
+
+// init 记录检测的起点
+func (w *remoteWatcher) init(ctx context.Context) (err error) {
+	if w.info.Options.Mode == RemoteModeList {
+		w.snapshot, err = w.list(ctx)
+		return err
+	}
+	w.cursor, err = pcschanges.LatestCursor(ctx, w.pcs)
+	return err
+}
+
+// check 检测一次变化, 结果加入 pending
+func (w *remoteWatcher) check(ctx context.Context) error {
+	now := time.Now()
+	if w.info.Options.Mode == RemoteModeList {
+		snapshot, err := w.list(ctx)
+		if err != nil {
+			return err
+		}
+		for p, fd := range snapshot {
+			old := w.snapshot[p]
+			switch {
+			case old == nil:
+				w.observe(EventCreated, fd, now)
+			case old.Size != fd.Size || old.Mtime != fd.Mtime || old.MD5 != fd.MD5:
+				w.observe(EventModified, fd, now)
+			}
+		}
+		for p, fd := range w.snapshot {
+			if _, ok := snapshot[p]; !ok {
+				w.observe(EventDeleted, fd, now)
+			}
+		}
+		w.snapshot = snapshot
+		return nil
+	}
+
+	cursor := w.cursor
+	for {
+		diff, pcsError := w.pcs.FilesDirectoriesDiffContext(ctx, cursor)
+		if pcsError != nil {
+			return pcsError
+		}
+		if diff.Reset {
+			// 无法得知 cursor 之后的变更, 从当前状态重新开始
+			watchVerbose.Warnf("watch %s: diff cursor reset, some changes may be missed\n", w.info.ID)
+			latest, err := pcschanges.LatestCursor(ctx, w.pcs)
+			if err != nil {
+				return err
+			}
+			w.cursor = latest
+			return nil
+		}
+		for _, fd := range diff.Deleted {
+			if w.match(fd.Path) {
+				w.observe(EventDeleted, fd, now)
+			}
+		}
+		for _, fd := range diff.Added {
+			if !fd.Isdir && w.match(fd.Path) {
+				w.observe(EventCreated, fd, now)
+			}
+		}
+		for _, fd := range diff.Modified {
+			if !fd.Isdir && w.match(fd.Path) {
+				w.observe(EventModified, fd, now)
+			}
+		}
+		cursor = diff.Cursor
+		if !diff.HasMore || cursor == "" {
+			break
+		}
+	}
+	if cursor != "" {
+		w.cursor = cursor
+	}
+	return nil
+}
+
+// list 列出监听目录下的文件, 目录不存在时忽略
+func (w *remoteWatcher) list(ctx context.Context) (map[string]*baidupcs.FileDirectory, error) {
+	files := map[string]*baidupcs.FileDirectory{}
+	for _, dir := range w.info.Options.Dirs {
+		if !w.info.Options.Recurse {
+			fds, pcsError := w.pcs.FilesDirectoriesListContext(ctx, dir, baidupcs.DefaultOrderOptions)
+			if pcsError != nil {
+				if pcsError.GetRemoteErrCode() == 31066 {
+					continue
+				}
+				return nil, pcsError
+			}
+			for _, fd := range fds {
+				if !fd.Isdir {
+					files[fd.Path] = fd
+				}
+			}
+			continue
+		}
+
+		var walkErr error
+		w.pcs.FilesDirectoriesRecurseListContext(ctx, dir, baidupcs.DefaultOrderOptions, func(depth int, _ string, fd *baidupcs.FileDirectory, pcsError pcserror.Error) bool {
+			if pcsError != nil {
+				if depth != 0 || pcsError.GetRemoteErrCode() != 31066 {
+					walkErr = pcsError
+				}
+				return false
+			}
+			if !fd.Isdir {
+				files[fd.Path] = fd
+			}
+			return true
+		})
+		if walkErr == nil {
+			walkErr = ctx.Err()
+		}
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	}
+	return files, nil
+}
+
+// match 网盘路径是否在监听的目录中
+func (w *remoteWatcher) match(p string) bool {
+	for _, dir := range w.info.Options.Dirs {
+		if w.info.Options.Recurse {
+			if dir == baidupcs.PathSeparator || strings.HasPrefix(p, dir+baidupcs.PathSeparator) {
+				return true
+			}
+			continue
+		}
+		if path.Dir(p) == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// observe 合并同一文件在稳定前的多次变化, 大小或修改时间变化时重新计时
+func (w *remoteWatcher) observe(typ EventType, fd *baidupcs.FileDirectory, now time.Time) {
+	pe := w.pending[fd.Path]
+	if pe == nil {
+		w.pending[fd.Path] = &pendingEvent{typ: typ, fd: fd, changeAt: now}
+		return
+	}
+
+	switch {
+	case pe.typ == EventCreated && typ == EventDeleted:
+		// 新增后又被删除, 不发出事件
+		delete(w.pending, fd.Path)
+		return
+	case pe.typ == EventCreated:
+		// 新增后继续写入, 仍为新增
+	case pe.typ == EventDeleted && typ == EventCreated:
+		pe.typ = EventModified
+	default:
+		pe.typ = typ
+	}
+	if pe.fd.Size != fd.Size || pe.fd.Mtime != fd.Mtime || typ == EventDeleted {
+		pe.changeAt = now
+	}
+	pe.fd = fd
+}
+
+// flush 发出已稳定的变化
+func (w *remoteWatcher) flush(now time.Time) {
+	debounce := time.Duration(w.info.Options.Debounce) * time.Second
+	for p, pe := range w.pending {
+		if now.Sub(pe.changeAt) < debounce {
+			continue
+		}
+		delete(w.pending, p)
+		w.hub.Publish(&Event{
+			WatchID: w.info.ID,
+			Type:    pe.typ,
+			Path:    pe.fd.Path,
+			IsDir:   pe.fd.Isdir,
+			Size:    pe.fd.Size,
+			MD5:     baidupcs.DecryptMD5(pe.fd.MD5),
+			Mtime:   pe.fd.Mtime,
+		}, w.info.Options.Callbacks)
+
+		w.mu.Lock()
+		w.info.Events++
+		w.mu.Unlock()
+	}
+}
+
+// status 返回状态的快照
+func (w *remoteWatcher) status() *RemoteWatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	info := w.info
+	return &info
+}
@@ -18,9 +18,16 @@ import (
 	"github.com/peterh/liner"
 	"github.com/qjfoidnh/BaiduPCS-Go/api"
 	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcscommand"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsconfig"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdownload"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsdupes"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsindex"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsschedule"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcsstorage"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcssync"
+	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsfunctions/pcswatch"
 	_ "github.com/qjfoidnh/BaiduPCS-Go/internal/pcsinit"
 	"github.com/qjfoidnh/BaiduPCS-Go/internal/pcsupdate"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsliner"
@@ -34,6 +41,7 @@ import (
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/pcstime"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
 	"github.com/urfave/cli"
+	"golang.org/x/term"
 )
 
 const (
@@ -81,10 +89,21 @@ var (
 func init() {
 	pcsutil.ChWorkDir()
 
+	// 凭证已加密且未通过环境变量提供密钥时, 提示输入口令
+	pcsconfig.CredentialKeyPrompt = func() (string, error) {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", pcsconfig.ErrCredentialKeyNotSet
+		}
+		fmt.Fprint(os.Stderr, "请输入凭证加密口令 (输入无回显) > ")
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return string(passphrase), err
+	}
+
 	err := pcsconfig.Config.Init()
 	switch err {
 	case nil:
-	case pcsconfig.ErrConfigFileNoPermission, pcsconfig.ErrConfigContentsParseError:
+	case pcsconfig.ErrConfigFileNoPermission, pcsconfig.ErrConfigContentsParseError, pcsconfig.ErrCredentialKeyNotSet, pcsconfig.ErrCredentialKeyWrong:
 		fmt.Fprintf(os.Stderr, "FATAL ERROR: config file error: %s\n", err)
 		os.Exit(1)
 	default:
@@ -356,7 +375,11 @@ func main() {
 			Usage: "显示程序环境变量",
 			Description: `
 	BAIDUPCS_GO_CONFIG_DIR: 配置文件路径,
-	BAIDUPCS_GO_VERBOSE: 是否启用调试.
+	BAIDUPCS_GO_VERBOSE: 是否启用调试,
+	BAIDUPCS_GO_CONFIG_READONLY: 只读配置模式, 设为 1 时不写入配置文件, 适用于配置文件挂载为只读的情况,
+	BAIDUPCS_GO_BDUSS, BAIDUPCS_GO_STOKEN, BAIDUPCS_GO_COOKIES: 启动时登录的百度帐号, 已登录相同 BDUSS 的帐号时跳过,
+	BAIDUPCS_GO_<配置项>: 覆盖配置文件中的配置项, 配置项名称转为大写, 如 BAIDUPCS_GO_MAX_PARALLEL, BAIDUPCS_GO_SAVEDIR, BAIDUPCS_GO_PROXY.
+	环境变量优先于配置文件, 覆盖的值不会写入配置文件.
 `,
 			Category: "其他",
 			Action: func(c *cli.Context) error {
@@ -375,6 +398,19 @@ func main() {
 					fmt.Printf(envStr, pcsconfig.EnvConfigDir, pcsconfig.GetConfigDir())
 				}
 
+				fmt.Printf(envStr, pcsconfig.EnvConfigReadOnly, strconv.FormatBool(pcsconfig.ReadOnly()))
+				for _, name := range []string{pcsconfig.EnvBDUSS, pcsconfig.EnvSTOKEN, pcsconfig.EnvCookies} {
+					if _, ok = os.LookupEnv(name); ok {
+						fmt.Printf(envStr, name, "******")
+					}
+				}
+
+				// 配置项, 只显示已设置的, 不显示密码的内容
+				for _, ev := range pcsconfig.EnvVars() {
+					if ev.Set {
+						fmt.Printf(envStr, ev.Name, ev.DisplayValue())
+					}
+				}
 				return nil
 			},
 		},
@@ -409,6 +445,16 @@ func main() {
 	BaiduPCS-Go server
 	BaiduPCS-Go server -p 5299
 	BaiduPCS-Go server -p 5299 -auth -user admin -pass 123456
+	BaiduPCS-Go server --backend local:/data/files
+
+	存储后端:
+	baidu         当前登录的百度帐号, 默认
+	local:<目录>  以服务器本地目录作为网盘根目录, 回收站和分享记录保存在该目录的 .BaiduPCS-Go 子目录
+
+	使用 local 后端时, 文件管理, 上传下载, 分享和回收站接口与 baidu 后端一致, 下载到服务器本地时同步复制.
+	以下接口依赖百度网盘的服务端功能或直接使用百度帐号, 只支持 baidu 后端, 其他后端返回 501:
+	/api/changes, /api/sync, /api/watch, /api/index, /api/dupes, /api/schedules, /api/transfer, /api/cloud, /api/xpan
+	帐号管理接口 /api/auth, /api/account 始终操作百度帐号
 `,
 			Category: "其他",
 			Action: func(c *cli.Context) error {
@@ -417,7 +463,13 @@ func main() {
 				user := c.String("user")
 				pass := c.String("pass")
 
+				st, err := pcsstorage.Parse(c.String("backend"))
+				if err != nil {
+					return err
+				}
+
 				srv := api.NewServer(port, user, pass, auth)
+				srv.SetStorage(st)
 				return srv.Start()
 			},
 			Flags: []cli.Flag{
@@ -440,6 +492,11 @@ func main() {
 					Usage: "Basic Auth 密码",
 					Value: "123456",
 				},
+				cli.StringFlag{
+					Name:  "backend",
+					Usage: "存储后端, baidu 或 local:<目录>",
+					Value: pcsstorage.NameBaidu,
+				},
 			},
 		},
 		{
@@ -451,6 +508,12 @@ func main() {
 		BaiduPCS-Go login -username=liuhua
 		BaiduPCS-Go login -bduss=123456789 -stoken=atahsrweoog
 		BaiduPCS-Go login -cookies="BDUSS=xxxxx; BAIDUID=yyyyyy; STOKEN=zzzzz; ...."
+		BaiduPCS-Go login -cookie-file=cookies.txt
+		BaiduPCS-Go login -xpan
+
+	开放平台授权登录:
+		-xpan 使用设备码为当前帐号授权百度网盘开放平台, 获取 accessToken 和 refresh_token, 过期前自动刷新.
+		需要先登录百度帐号, 并通过 config set 设置 xpan_app_key 和 xpan_secret_key.
 
 	常规登录:
 		按提示一步一步来即可.
@@ -460,11 +523,33 @@ func main() {
 		
 	百度Cookies获取办法:
 	以Chrome为例，登录到自己的百度网盘主页，F12，然后切换到Network标签，刷新页面，Network标签下会刷出一大堆东西
-	找到第一条，点击，看到右侧出现的详情，往下翻到Cookies: xxxx; xxxxx; xxx...这样的字段，从冒号后（没有空格）一直复制到字段末尾`,
+	找到第一条，点击，看到右侧出现的详情，往下翻到Cookies: xxxx; xxxxx; xxx...这样的字段，从冒号后（没有空格）一直复制到字段末尾
+
+	百度Cookies文件:
+	-cookie-file 支持 Netscape 格式的 cookies.txt, EditThisCookie/Cookie-Editor 等浏览器扩展导出的 JSON,
+	以及开发者工具 Network 标签导出的 HAR 文件. 登录前会验证 Cookies 是否有效`,
 			Category: "百度帐号",
 			Before:   reloadFn,
 			After:    saveFunc,
 			Action: func(c *cli.Context) error {
+				if c.Bool("xpan") {
+					err := pcscommand.RunXpanLogin()
+					if err != nil {
+						fmt.Println(err)
+					}
+					return nil
+				}
+
+				if c.IsSet("cookie-file") {
+					baidu, err := pcscommand.RunLoginByCookieFile(c.String("cookie-file"))
+					if err != nil {
+						fmt.Println(err)
+						return nil
+					}
+					fmt.Println("百度帐号登录成功:", baidu.Name)
+					return nil
+				}
+
 				var bduss, ptoken, stoken, cookies string
 				if c.IsSet("cookies") {
 					cookies = c.String("cookies")
@@ -518,6 +603,14 @@ func main() {
 					Name:  "cookies",
 					Usage: "使用百度 Cookies 来登录百度账号",
 				},
+				cli.StringFlag{
+					Name:  "cookie-file",
+					Usage: "使用浏览器导出的 Cookies 文件来登录百度账号, 支持 cookies.txt, 扩展导出的 JSON 和 HAR",
+				},
+				cli.BoolFlag{
+					Name:  "xpan",
+					Usage: "为当前帐号授权百度网盘开放平台, 获取可自动刷新的 accessToken",
+				},
 			},
 		},
 		{
@@ -684,6 +777,7 @@ func main() {
 	若不使用秒传链接转存, 可不设定; accessToken申请及获取教程:
 	https://github.com/qjfoidnh/BaiduPCS-Go/wiki/accessToken%E8%8E%B7%E5%8F%96%E6%95%99%E7%A8%8B
 	注意accessToken的有效期为一个月, 过期后请按教程指导更新token
+	使用 login -xpan 授权获取的 accessToken 会在过期前自动刷新
 
 	示例:
 	BaiduPCS-Go setastoken 156.182v9052tgf1006c89891bsfb2401974.YmKOAwBD9yGaG2s4p5NNkX4CXeIbJxx4hAxotfS.PyuHEs
@@ -704,7 +798,9 @@ func main() {
 					cli.ShowCommandHelp(c, c.Command.Name)
 					return nil
 				}
-				activeUser.AccessToken = c.Args().Get(0)
+				// 手动设置的 accessToken 没有 refresh_token, 无法自动刷新
+				activeUser.XpanRefreshToken = ""
+				activeUser.SetXpanToken(&xpan.Token{AccessToken: c.Args().Get(0)})
 				pcsconfig.Config.ActiveUserBaiduPCS().SetaccessToken(c.Args().Get(0))
 				fmt.Printf("当前用户名: %s 成功设置accessToken: %s\n", activeUser.Name, activeUser.AccessToken)
 				return nil
@@ -909,6 +1005,270 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:      "diff",
+			Usage:     "列出网盘的文件变更",
+			UsageText: app.Name + " diff [-cursor=<cursor>] [-reset]",
+			Description: `
+	列出整个网盘在上次运行之后新增, 修改和删除的文件, cursor 按账号保存在配置目录的 pcs_changes.json 中.
+	首次运行只记录网盘当前的 cursor.
+
+	示例:
+
+	列出上次运行之后的变更
+	BaiduPCS-Go diff
+
+	从指定的 cursor 开始列出变更
+	BaiduPCS-Go diff -cursor=<cursor>
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				pcscommand.RunDiff(c.String("cursor"), c.Bool("reset"))
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "cursor",
+					Usage: "从指定的 cursor 开始, 默认使用上次保存的 cursor",
+				},
+				cli.BoolFlag{
+					Name:  "reset",
+					Usage: "丢弃保存的 cursor, 重新从网盘当前状态开始",
+				},
+			},
+		},
+		{
+			Name:  "index",
+			Usage: "网盘文件的本地索引",
+			Description: `
+	在本地建立整个网盘的文件索引, 按账号保存在配置目录的 index 目录中.
+	查询时不请求网盘, 可以按文件名, 扩展名, 大小, 修改时间, md5 和目录过滤.
+	建立索引后, 通过网盘的增量变更检测更新索引, 不需要重新列出全部文件.
+
+	示例:
+
+	1. 建立索引
+	BaiduPCS-Go index build
+
+	2. 增量更新索引
+	BaiduPCS-Go index refresh
+
+	3. 查询大于 1GB 的 mp4 和 mkv 文件, 按大小倒序
+	BaiduPCS-Go index query -ext mp4 -ext mkv -minsize 1GB -sort size -desc
+
+	4. 查询 /我的照片 中 2024 年修改的 jpg 文件
+	BaiduPCS-Go index query -prefix /我的照片 -after 2024-01-01 -before 2025-01-01 "*.jpg"
+
+	5. 查询指定 md5 的文件
+	BaiduPCS-Go index query -md5 0123456789abcdef0123456789abcdef
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				pcscommand.RunIndexInfo()
+				return nil
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:      "build",
+					Usage:     "列出整个网盘, 建立索引",
+					UsageText: app.Name + " index build",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunIndexBuild()
+						return nil
+					},
+				},
+				{
+					Name:      "refresh",
+					Usage:     "增量更新索引",
+					UsageText: app.Name + " index refresh",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunIndexRefresh()
+						return nil
+					},
+				},
+				{
+					Name:      "info",
+					Usage:     "查看索引概况",
+					UsageText: app.Name + " index info",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunIndexInfo()
+						return nil
+					},
+				},
+				{
+					Name:      "query",
+					Aliases:   []string{"q"},
+					Usage:     "在索引中查询",
+					UsageText: app.Name + " index query [arguments...] [文件名通配符]",
+					Action: func(c *cli.Context) error {
+						q := &pcsindex.Query{
+							Name:   c.Args().First(),
+							Regex:  c.String("regex"),
+							Exts:   c.StringSlice("ext"),
+							MD5:    c.String("md5"),
+							Prefix: c.String("prefix"),
+							Type:   c.String("type"),
+							Sort:   c.String("sort"),
+							Desc:   c.Bool("desc"),
+							Offset: c.Int("offset"),
+							Limit:  c.Int("limit"),
+						}
+						var err error
+						if s := c.String("minsize"); s != "" {
+							if q.MinSize, err = converter.ParseFileSizeStr(s); err != nil {
+								fmt.Println(err)
+								return nil
+							}
+						}
+						if s := c.String("maxsize"); s != "" {
+							if q.MaxSize, err = converter.ParseFileSizeStr(s); err != nil {
+								fmt.Println(err)
+								return nil
+							}
+						}
+						if q.MtimeAfter, err = pcscommand.ParseIndexTime(c.String("after")); err != nil {
+							fmt.Println(err)
+							return nil
+						}
+						if q.MtimeBefore, err = pcscommand.ParseIndexTime(c.String("before")); err != nil {
+							fmt.Println(err)
+							return nil
+						}
+						pcscommand.RunIndexQuery(q, c.Bool("refresh"))
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "regex",
+							Usage: "匹配完整路径的正则表达式",
+						},
+						cli.StringSliceFlag{
+							Name:  "ext",
+							Usage: "扩展名, 可以指定多次",
+						},
+						cli.StringFlag{
+							Name:  "minsize",
+							Usage: "最小文件大小, 例如 100MB",
+						},
+						cli.StringFlag{
+							Name:  "maxsize",
+							Usage: "最大文件大小, 例如 1GB",
+						},
+						cli.StringFlag{
+							Name:  "after",
+							Usage: "修改时间不早于, 例如 2024-01-01 或 unix 时间戳",
+						},
+						cli.StringFlag{
+							Name:  "before",
+							Usage: "修改时间早于, 例如 2025-01-01 或 unix 时间戳",
+						},
+						cli.StringFlag{
+							Name:  "md5",
+							Usage: "文件的 md5",
+						},
+						cli.StringFlag{
+							Name:  "prefix",
+							Usage: "只查询该目录下的文件",
+						},
+						cli.StringFlag{
+							Name:  "type",
+							Usage: "file 只查询文件, dir 只查询目录",
+						},
+						cli.StringFlag{
+							Name:  "sort",
+							Usage: "排序: path, name, size 或 mtime",
+							Value: "path",
+						},
+						cli.BoolFlag{
+							Name:  "desc",
+							Usage: "倒序",
+						},
+						cli.IntFlag{
+							Name:  "offset",
+							Usage: "跳过的数量",
+						},
+						cli.IntFlag{
+							Name:  "limit",
+							Usage: "显示的数量, 为0时全部显示",
+							Value: 100,
+						},
+						cli.BoolFlag{
+							Name:  "refresh",
+							Usage: "查询前先增量更新索引",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:      "dupes",
+			Usage:     "查找重复文件",
+			UsageText: app.Name + " dupes [arguments...] [目录]",
+			Description: `
+	按 md5 和大小查找目录中的重复文件, 默认为当前工作目录.
+	默认只输出报告, 每组按规则保留一份, 指定 -delete 后将其余的副本移入回收站.
+
+	保留规则:
+	oldest: 保留最早上传的副本 (默认)
+	shortest: 保留路径最短的副本
+	prefer: 保留位于 -prefer 目录中的副本, 没有时保留最早上传的副本
+
+	示例:
+
+	1. 查找整个网盘中大于 10MB 的重复文件
+	BaiduPCS-Go dupes -minsize 10MB /
+
+	2. 使用本地索引查找, 不请求网盘
+	BaiduPCS-Go dupes -index /
+
+	3. 优先保留 /我的资源 中的副本, 其余移入回收站
+	BaiduPCS-Go dupes -keep prefer -prefer /我的资源 -delete /
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				opt := pcsdupes.Options{
+					Path:     c.Args().First(),
+					Rule:     pcsdupes.Rule(c.String("keep")),
+					Prefer:   c.String("prefer"),
+					UseIndex: c.Bool("index"),
+				}
+				if s := c.String("minsize"); s != "" {
+					var err error
+					if opt.MinSize, err = converter.ParseFileSizeStr(s); err != nil {
+						fmt.Println(err)
+						return nil
+					}
+				}
+				pcscommand.RunDupes(opt, c.Bool("delete"))
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "minsize",
+					Usage: "忽略小于该大小的文件, 例如 10MB",
+				},
+				cli.StringFlag{
+					Name:  "keep",
+					Usage: "保留规则: oldest, shortest 或 prefer",
+					Value: "oldest",
+				},
+				cli.StringFlag{
+					Name:  "prefer",
+					Usage: "优先保留的目录, 用于 prefer 规则",
+				},
+				cli.BoolFlag{
+					Name:  "index",
+					Usage: "从本地索引读取文件列表, 不请求网盘",
+				},
+				cli.BoolFlag{
+					Name:  "delete",
+					Usage: "输出报告后将多余的副本移入回收站",
+				},
+			},
+		},
 		{
 			Name:      "tree",
 			Aliases:   []string{"t"},
@@ -1275,6 +1635,11 @@ func main() {
 
 	4. 使用相对路径
 	BaiduPCS-Go upload 1.mp4 /视频
+
+	5. 使用开放平台 (xpan) 接口上传, 需要先使用 login -xpan 授权或 setastoken 设置 accesstoken
+	BaiduPCS-Go upload -api xpan 1.mp4 /视频
+
+	使用 PCS 接口上传遇到权限错误时, 已授权 xpan 的帐号会自动改用 xpan 接口上传.
 `,
 			Category: "百度网盘",
 			Before:   reloadFn,
@@ -1291,6 +1656,7 @@ func main() {
 					Load:          c.Int("l"),
 					NoRapidUpload: c.Bool("norapid"),
 					Policy:        c.String("policy"),
+					API:           c.String("api"),
 				})
 				return nil
 			},
@@ -1316,6 +1682,434 @@ func main() {
 					Name:  "policy",
 					Usage: fmt.Sprintf("对同名文件的处理策略 (default: %s), %s, %s", baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy),
 				},
+				cli.StringFlag{
+					Name:  "api",
+					Usage: fmt.Sprintf("上传使用的接口, %s, %s, 默认使用配置 upload_api", pcsconfig.UploadAPIPCS, pcsconfig.UploadAPIXpan),
+				},
+			},
+		},
+		{
+			Name:      "watch",
+			Usage:     "监听本地目录并自动上传",
+			UsageText: app.Name + " watch [arguments...] <本地目录> <网盘目录>",
+			Description: `
+	监听本地目录及其子目录, 新增或修改的文件在大小不再变化后上传到网盘.
+	启动时会扫描整个目录, 按重名文件策略上传未监听期间新增的文件.
+	-include 和 -exclude 匹配相对路径或文件名, 可以指定多次.
+
+	示例:
+
+	1. 监听 /data/inbox, 上传到网盘 /收件箱
+	BaiduPCS-Go watch /data/inbox /收件箱
+
+	2. 只上传 jpg 文件, 忽略 .part 文件, 上传校验后删除本地文件
+	BaiduPCS-Go watch -include "*.jpg" -exclude "*.part" -after delete /data/inbox /收件箱
+
+	3. 上传校验后移动到 /data/done
+	BaiduPCS-Go watch -after move -moveto /data/done /data/inbox /收件箱
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 {
+					cli.ShowCommandHelp(c, c.Command.Name)
+					return nil
+				}
+				pcscommand.RunWatch(pcswatch.LocalOptions{
+					LocalDir:  c.Args().Get(0),
+					RemoteDir: c.Args().Get(1),
+					Include:   c.StringSlice("include"),
+					Exclude:   c.StringSlice("exclude"),
+					Policy:    c.String("policy"),
+					Settle:    c.Int("settle"),
+					Parallel:  c.Int("l"),
+					After:     pcswatch.AfterUpload(c.String("after")),
+					MoveTo:    c.String("moveto"),
+				})
+				return nil
+			},
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "include",
+					Usage: "只上传匹配的文件",
+				},
+				cli.StringSliceFlag{
+					Name:  "exclude",
+					Usage: "不上传匹配的文件",
+				},
+				cli.StringFlag{
+					Name:  "policy",
+					Usage: fmt.Sprintf("对同名文件的处理策略 (默认使用配置), %s, %s, %s", baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy),
+				},
+				cli.IntFlag{
+					Name:  "settle",
+					Usage: "文件大小在该时间内不变才上传 (秒)",
+					Value: pcswatch.DefaultSettle,
+				},
+				cli.IntFlag{
+					Name:  "l",
+					Usage: "指定同时上传的最大文件数",
+				},
+				cli.StringFlag{
+					Name:  "after",
+					Usage: "上传并校验后: delete 删除本地文件, move 移动到 -moveto 目录",
+				},
+				cli.StringFlag{
+					Name:  "moveto",
+					Usage: "-after move 时移动到的本地目录",
+				},
+			},
+		},
+		{
+			Name:  "sync",
+			Usage: "同步本地目录和网盘目录",
+			Description: `
+	同步本地目录和网盘目录, 同步状态保存在配置目录的 pcs_sync.json 中.
+	push: 默认比较文件大小和修改时间, 使用 -md5 时, 大小相同的文件会比较 md5.
+	pull: 比较文件大小和 md5, 下载的文件保留网盘中的修改时间.
+	bisync: 双向同步, 根据上次同步的状态判断哪一侧发生了修改, 通过网盘的增量变更检测修改.
+	  两边都修改过的文件保留两份, 本地文件重命名为 "文件名.conflict-时间" 后上传.
+	一侧目录为空或不存在, 或删除超过半数 (且多于10个) 的文件时拒绝执行, 确认需要删除时使用 -force.
+
+	示例:
+
+	1. 试运行, 列出需要上传和删除的文件, 不修改任何文件
+	BaiduPCS-Go sync push -dryrun -delete /data/photos /我的照片
+
+	2. 将本地的 /data/photos 同步到网盘 /我的照片, 网盘中多余的文件移入回收站
+	BaiduPCS-Go sync push -delete /data/photos /我的照片
+
+	3. 将网盘 /我的照片 同步到本地的 /data/photos, 删除本地多余的文件
+	BaiduPCS-Go sync pull -delete /我的照片 /data/photos
+
+	4. 双向同步本地的 /data/team 和网盘 /团队共享
+	BaiduPCS-Go sync bisync /data/team /团队共享
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				cli.ShowCommandHelp(c, c.Command.Name)
+				return nil
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:        "push",
+					Usage:       "将本地目录同步到网盘",
+					UsageText:   app.Name + " sync push [arguments...] <本地目录> <网盘目录>",
+					Description: `上传本地新增和修改的文件, 使用 -delete 时将网盘中多余的文件移入回收站`,
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 2 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunSync(pcssync.Options{
+							Direction: pcssync.DirectionPush,
+							LocalDir:  c.Args().Get(0),
+							RemoteDir: c.Args().Get(1),
+							CheckMD5:  c.Bool("md5"),
+							Delete:    c.Bool("delete"),
+							DryRun:    c.Bool("dryrun"),
+							Parallel:  c.Int("l"),
+							Force:     c.Bool("force"),
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "md5",
+							Usage: "大小相同时比较文件的 md5",
+						},
+						cli.BoolFlag{
+							Name:  "delete",
+							Usage: "将网盘中多余的文件移入回收站",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "允许源目录为空或删除超过半数文件时的删除",
+						},
+						cli.BoolFlag{
+							Name:  "dryrun",
+							Usage: "试运行, 只列出需要执行的操作",
+						},
+						cli.IntFlag{
+							Name:  "l",
+							Usage: "指定同时上传的最大文件数",
+						},
+					},
+				},
+				{
+					Name:        "pull",
+					Usage:       "将网盘目录同步到本地",
+					UsageText:   app.Name + " sync pull [arguments...] <网盘目录> <本地目录>",
+					Description: `下载网盘新增和修改的文件, 使用 -delete 时删除本地多余的文件`,
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 2 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunSync(pcssync.Options{
+							Direction: pcssync.DirectionPull,
+							LocalDir:  c.Args().Get(1),
+							RemoteDir: c.Args().Get(0),
+							Delete:    c.Bool("delete"),
+							DryRun:    c.Bool("dryrun"),
+							Parallel:  c.Int("l"),
+							Force:     c.Bool("force"),
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "delete",
+							Usage: "删除本地多余的文件",
+						},
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "允许源目录为空或删除超过半数文件时的删除",
+						},
+						cli.BoolFlag{
+							Name:  "dryrun",
+							Usage: "试运行, 只列出需要执行的操作",
+						},
+						cli.IntFlag{
+							Name:  "l",
+							Usage: "指定同时下载的最大文件数",
+						},
+					},
+				},
+				{
+					Name:        "bisync",
+					Usage:       "双向同步本地目录和网盘目录",
+					UsageText:   app.Name + " sync bisync [arguments...] <本地目录> <网盘目录>",
+					Description: `将一侧的新增, 修改和删除同步到另一侧, 网盘中删除的文件进入回收站. 两边都修改过的文件保留两份`,
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 2 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunSync(pcssync.Options{
+							Direction: pcssync.DirectionBisync,
+							LocalDir:  c.Args().Get(0),
+							RemoteDir: c.Args().Get(1),
+							DryRun:    c.Bool("dryrun"),
+							Parallel:  c.Int("l"),
+							Force:     c.Bool("force"),
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.BoolFlag{
+							Name:  "force",
+							Usage: "允许源目录为空或删除超过半数文件时的删除",
+						},
+						cli.BoolFlag{
+							Name:  "dryrun",
+							Usage: "试运行, 只列出需要执行的操作",
+						},
+						cli.IntFlag{
+							Name:  "l",
+							Usage: "指定同时上传或下载的最大文件数",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "schedule",
+			Usage: "管理 server 中执行的定时任务",
+			Description: `
+	定时任务由 server 按 cron 表达式执行, 保存在配置目录的 pcs_schedules.json 中,
+	使用本命令修改后, 运行中的 server 会自动重新读取. 每个任务保留最近 20 次执行记录.
+	cron 表达式为 "分 时 日 月 周", 按东八区时间执行, 也可以使用 @daily, @weekly, @hourly 或 @every 2h.
+
+	任务类型:
+	  upload           上传服务器本地的文件或目录, 默认使用 rsync 策略
+	  export           导出网盘文件的秒传信息到服务器本地文件
+	  offlinedl_query  查询离线下载任务
+	  offlinedl_clear  清空离线下载任务记录
+	  recycle_clear    清空回收站
+	  quota            检查网盘空间使用率, 达到 -threshold 时记为失败
+
+	示例:
+
+	1. 每天凌晨 3 点将 /data/backup 上传到网盘 /备份
+	BaiduPCS-Go schedule add -cron "0 3 * * *" -kind upload -local /data/backup -remote /备份
+
+	2. 每周日清空回收站
+	BaiduPCS-Go schedule add -cron "@weekly" -kind recycle_clear
+
+	3. 每小时检查空间使用率是否达到 90%
+	BaiduPCS-Go schedule add -cron "@hourly" -kind quota -threshold 90
+
+	4. 查看任务的执行记录
+	BaiduPCS-Go schedule history <id>
+`,
+			Category: "百度网盘",
+			Before:   reloadFn,
+			Action: func(c *cli.Context) error {
+				pcscommand.RunScheduleList()
+				return nil
+			},
+			Subcommands: []cli.Command{
+				{
+					Name:      "list",
+					Aliases:   []string{"ls"},
+					Usage:     "列出定时任务",
+					UsageText: app.Name + " schedule list",
+					Action: func(c *cli.Context) error {
+						pcscommand.RunScheduleList()
+						return nil
+					},
+				},
+				{
+					Name:      "add",
+					Usage:     "添加定时任务",
+					UsageText: app.Name + " schedule add -cron <cron 表达式> -kind <任务类型> [arguments...]",
+					Action: func(c *cli.Context) error {
+						if c.String("cron") == "" || c.String("kind") == "" {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunScheduleAdd(pcsschedule.Spec{
+							Name:     c.String("name"),
+							Cron:     c.String("cron"),
+							Kind:     pcsschedule.Kind(c.String("kind")),
+							Disabled: c.Bool("disabled"),
+							Options: pcsschedule.JobOptions{
+								LocalPaths: c.StringSlice("local"),
+								RemoteDir:  c.String("remote"),
+								Policy:     c.String("policy"),
+								Parallel:   c.Int("l"),
+								Paths:      c.StringSlice("path"),
+								SavePath:   c.String("save"),
+								Recursive:  c.Bool("r"),
+								LinkFormat: c.Bool("link"),
+								Threshold:  c.Float64("threshold"),
+							},
+						})
+						return nil
+					},
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "cron",
+							Usage: "cron 表达式",
+						},
+						cli.StringFlag{
+							Name:  "kind",
+							Usage: "任务类型: upload, export, offlinedl_query, offlinedl_clear, recycle_clear, quota",
+						},
+						cli.StringFlag{
+							Name:  "name",
+							Usage: "任务名称",
+						},
+						cli.BoolFlag{
+							Name:  "disabled",
+							Usage: "添加后暂停执行",
+						},
+						cli.StringSliceFlag{
+							Name:  "local",
+							Usage: "upload: 服务器本地的文件或目录, 可以指定多次",
+						},
+						cli.StringFlag{
+							Name:  "remote",
+							Usage: "upload: 网盘保存目录",
+						},
+						cli.StringFlag{
+							Name:  "policy",
+							Usage: fmt.Sprintf("upload: 对同名文件的处理策略, %s, %s, %s (默认)", baidupcs.SkipPolicy, baidupcs.OverWritePolicy, baidupcs.RsyncPolicy),
+						},
+						cli.IntFlag{
+							Name:  "l",
+							Usage: "upload: 指定同时上传的最大文件数",
+						},
+						cli.StringSliceFlag{
+							Name:  "path",
+							Usage: "export: 网盘文件或目录, 可以指定多次",
+						},
+						cli.StringFlag{
+							Name:  "save",
+							Usage: "export: 导出文件路径, 每次执行覆盖, 默认在配置目录的 exports 中按时间命名",
+						},
+						cli.BoolFlag{
+							Name:  "r",
+							Usage: "export: 包含子目录",
+						},
+						cli.BoolFlag{
+							Name:  "link",
+							Usage: "export: 以秒传链接格式导出",
+						},
+						cli.Float64Flag{
+							Name:  "threshold",
+							Usage: "quota: 使用率达到该百分比时记为失败",
+						},
+					},
+				},
+				{
+					Name:      "rm",
+					Usage:     "删除定时任务",
+					UsageText: app.Name + " schedule rm <id 1> <id 2> ...",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunScheduleDelete(c.Args()...)
+						return nil
+					},
+				},
+				{
+					Name:      "enable",
+					Usage:     "启用定时任务",
+					UsageText: app.Name + " schedule enable <id 1> <id 2> ...",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunScheduleEnable(true, c.Args()...)
+						return nil
+					},
+				},
+				{
+					Name:      "disable",
+					Usage:     "暂停定时任务",
+					UsageText: app.Name + " schedule disable <id 1> <id 2> ...",
+					Action: func(c *cli.Context) error {
+						if c.NArg() < 1 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunScheduleEnable(false, c.Args()...)
+						return nil
+					},
+				},
+				{
+					Name:        "run",
+					Usage:       "立即执行一次定时任务",
+					UsageText:   app.Name + " schedule run <id>",
+					Description: `在当前进程中执行, 结果记录在执行记录中`,
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunScheduleRun(c.Args().Get(0))
+						return nil
+					},
+				},
+				{
+					Name:      "history",
+					Usage:     "查看定时任务的执行记录",
+					UsageText: app.Name + " schedule history <id>",
+					Action: func(c *cli.Context) error {
+						if c.NArg() != 1 {
+							cli.ShowCommandHelp(c, c.Command.Name)
+							return nil
+						}
+						pcscommand.RunScheduleHistory(c.Args().Get(0))
+						return nil
+					},
+				},
 			},
 		},
 		{
@@ -1845,204 +2639,65 @@ func main() {
 					Description: `
 	注意:
 		可通过设置环境变量 BAIDUPCS_GO_CONFIG_DIR, 指定配置文件存放的目录.
+		配置项均可由环境变量 BAIDUPCS_GO_<配置项> 覆盖, 如 BAIDUPCS_GO_MAX_PARALLEL, 详见 env 命令.
 
 		谨慎修改 appid, user_agent, pcs_ua, pan_ua 的值, 否则访问网盘服务器时, 可能会出现错误
 		cache_size 的值支持可选设置单位了, 单位不区分大小写, b 和 B 均表示字节的意思, 如 64KB, 1MB, 32kb, 65536b, 65536
 		max_download_rate, max_upload_rate 的值支持可选设置单位了, 单位为每秒的传输速率, 后缀'/s' 可省略, 如 2MB/s, 2MB, 2m, 2mb 均为一个意思
+		设置 crypt_dir 和 crypt_password 后, 上传到加密目录的文件内容会分块加密并认证, 下载时自动解密, ls 显示解密后的文件名, 大小为网盘中加密后的大小.
+		设置密码时自动生成随机的 crypt_salt, 迁移配置时需与密码一同保留.
+		crypt_filename 同时加密文件名和目录名, 在其他命令中可以使用明文路径, 但不支持通配符. 修改密码, crypt_salt 或 crypt_filename 后已上传的文件将无法解密
+		xpan_app_key 和 xpan_secret_key 为百度网盘开放平台应用的密钥, 设置后可使用 login -xpan 授权获取 accesstoken
+		开启 encrypt_credentials 后, 配置文件中的 BDUSS, STOKEN, Cookies, accesstoken 等凭证及 crypt_password, xpan_secret_key 加密保存, 密钥依次从环境变量
+		BAIDUPCS_GO_CREDENTIAL_KEY, BAIDUPCS_GO_CREDENTIAL_KEY_FILE 指定的密钥文件读取, 均未设置时启动程序需输入口令
 
 	例子:
 		BaiduPCS-Go config set -appid=266719
 		BaiduPCS-Go config set -enable_https=false
 		BaiduPCS-Go config set -user_agent="netdisk;2.2.51.6;netdisk;10.0.63;PC;android-android"
 		BaiduPCS-Go config set -cache_size 64KB
-		BaiduPCS-Go config set -cache_size 16384 -max_parallel 200 -savedir D:/download`,
+		BaiduPCS-Go config set -cache_size 16384 -max_parallel 200 -savedir D:/download
+		BaiduPCS-Go config set -crypt_dir /加密 -crypt_password 密码 -crypt_filename
+		BaiduPCS-Go config set -encrypt_credentials`,
 					Action: func(c *cli.Context) error {
 						if c.NumFlags() <= 0 || c.NArg() > 0 {
 							cli.ShowCommandHelp(c, c.Command.Name)
 							return nil
 						}
 
-						if c.IsSet("appid") {
-							pcsconfig.Config.SetAppID(c.Int("appid"))
-						}
-						if c.IsSet("enable_https") {
-							pcsconfig.Config.SetEnableHTTPS(c.Bool("enable_https"))
-						}
-						if c.IsSet("ignore_illegal") {
-							pcsconfig.Config.SetIgnoreIllegal(c.Bool("ignore_illegal"))
-						}
-						if c.IsSet("force_login_username") {
-							pcsconfig.Config.SetForceLogin(c.String("force_login_username"))
-						}
-						if c.IsSet("user_agent") {
-							pcsconfig.Config.SetUserAgent(c.String("user_agent"))
-						}
-						if c.IsSet("pcs_ua") {
-							pcsconfig.Config.SetPCSUA(c.String("pcs_ua"))
-						}
-						if c.IsSet("pcs_addr") {
-							match := pcsconfig.Config.SETPCSAddr(c.String("pcs_addr"))
-							if !match {
-								fmt.Println("设置 pcs_addr 错误: pcs服务器地址不合法")
-								return nil
-							}
-						}
-						if c.IsSet("fix_pcs_addr") {
-							pcsconfig.Config.SetStaticPCSAddr(c.Bool("fix_pcs_addr"))
-						}
-						if c.IsSet("upload_policy") {
-							pcsconfig.Config.SetUploadPolicy(c.String("upload_policy"))
-						}
-						if c.IsSet("pan_ua") {
-							pcsconfig.Config.SetPanUA(c.String("pan_ua"))
-						}
-						if c.IsSet("cache_size") {
-							err := pcsconfig.Config.SetCacheSizeByStr(c.String("cache_size"))
-							if err != nil {
-								fmt.Printf("设置 cache_size 错误: %s\n", err)
-								return nil
-							}
-						}
-						if c.IsSet("max_parallel") {
-							pcsconfig.Config.MaxParallel = c.Int("max_parallel")
-						}
-						if c.IsSet("max_upload_parallel") {
-							pcsconfig.Config.MaxUploadParallel = c.Int("max_upload_parallel")
-						}
-						if c.IsSet("max_download_load") {
-							pcsconfig.Config.MaxDownloadLoad = c.Int("max_download_load")
-						}
-						if c.IsSet("max_upload_load") {
-							pcsconfig.Config.MaxUploadLoad = c.Int("max_upload_load")
-						}
-						if c.IsSet("max_download_rate") {
-							err := pcsconfig.Config.SetMaxDownloadRateByStr(c.String("max_download_rate"))
-							if err != nil {
-								fmt.Printf("设置 max_download_rate 错误: %s\n", err)
-								return nil
+						values := map[string]string{}
+						for _, item := range pcsconfig.Schema {
+							if !c.IsSet(item.Name) {
+								continue
 							}
-						}
-						if c.IsSet("max_upload_rate") {
-							err := pcsconfig.Config.SetMaxUploadRateByStr(c.String("max_upload_rate"))
-							if err != nil {
-								fmt.Printf("设置 max_upload_rate 错误: %s\n", err)
-								return nil
+							value := c.String(item.Name)
+							if item.Type == pcsconfig.ItemTypeBool {
+								value = strconv.FormatBool(c.Bool(item.Name))
 							}
+							values[item.Name] = value
 						}
-						if c.IsSet("savedir") {
-							pcsconfig.Config.SaveDir = c.String("savedir")
-						}
-						if c.IsSet("proxy") {
-							pcsconfig.Config.SetProxy(c.String("proxy"))
-						}
-						if c.IsSet("proxy_hostnames") {
-							pcsconfig.Config.SetProxyHostnames(c.String("proxy_hostnames"))
-						}
-						if c.IsSet("local_addrs") {
-							pcsconfig.Config.SetLocalAddrs(c.String("local_addrs"))
+						err := pcsconfig.Config.SetItems(values)
+						if err != nil {
+							fmt.Println(err)
+							return nil
 						}
 
-						err := pcsconfig.Config.Save()
+						err = pcsconfig.Config.Save()
 						if err != nil {
 							fmt.Println(err)
 							return err
 						}
 
 						pcsconfig.Config.PrintTable()
-						fmt.Printf("\n保存配置成功!\n\n")
+						if pcsconfig.ReadOnly() {
+							fmt.Printf("\n修改配置成功, 只读配置模式下不会保存到配置文件\n\n")
+						} else {
+							fmt.Printf("\n保存配置成功!\n\n")
+						}
 
 						return nil
 					},
-					Flags: []cli.Flag{
-						cli.IntFlag{
-							Name:  "appid",
-							Usage: "百度 PCS 应用ID",
-						},
-						cli.StringFlag{
-							Name:  "cache_size",
-							Usage: "下载缓存",
-						},
-						cli.IntFlag{
-							Name:  "max_parallel",
-							Usage: "下载网络全部连接的最大并发量",
-						},
-						cli.IntFlag{
-							Name:  "max_upload_parallel",
-							Usage: "上传网络单个连接的最大并发量",
-						},
-						cli.IntFlag{
-							Name:  "max_download_load",
-							Usage: "同时进行下载文件的最大数量",
-						},
-						cli.IntFlag{
-							Name:  "max_upload_load",
-							Usage: "同时进行上传文件的最大数量",
-						},
-						cli.StringFlag{
-							Name:  "max_download_rate",
-							Usage: "限制最大下载速度, 0代表不限制",
-						},
-						cli.StringFlag{
-							Name:  "max_upload_rate",
-							Usage: "限制最大上传速度, 0代表不限制",
-						},
-						cli.StringFlag{
-							Name:  "savedir",
-							Usage: "下载文件的储存目录",
-						},
-						cli.BoolFlag{
-							Name:  "enable_https",
-							Usage: "启用 https",
-						},
-						cli.BoolFlag{
-							Name:  "ignore_illegal",
-							Usage: "忽略上传时文件名中的非法字符",
-						},
-						cli.StringFlag{
-							Name:  "force_login_username",
-							Usage: "强制登录指定用户名, 只适用于tieba接口失效的情况",
-						},
-						cli.BoolFlag{
-							Name:  "no_check",
-							Usage: "关闭下载文件md5校验",
-						},
-						cli.StringFlag{
-							Name:  "upload_policy",
-							Usage: "设置上传遇到同名文件时的策略",
-						},
-						cli.StringFlag{
-							Name:  "user_agent",
-							Usage: "浏览器标识",
-						},
-						cli.StringFlag{
-							Name:  "pcs_ua",
-							Usage: "PCS 浏览器标识",
-						},
-						cli.StringFlag{
-							Name:  "pcs_addr",
-							Usage: "PCS 服务器地址",
-						},
-						cli.BoolFlag{
-							Name:  "fix_pcs_addr",
-							Usage: "使用静态PCS 服务器",
-						},
-						cli.StringFlag{
-							Name:  "pan_ua",
-							Usage: "Pan 浏览器标识",
-						},
-						cli.StringFlag{
-							Name:  "proxy",
-							Usage: "设置代理, 支持 http/socks5 代理",
-						},
-						cli.StringFlag{
-							Name:  "proxy_hostnames",
-							Usage: "设置走代理的域名范围, 多个域名用逗号隔开, 留空则所有域名均代理",
-						},
-						cli.StringFlag{
-							Name:  "local_addrs",
-							Usage: "设置本地网卡地址, 多个地址用逗号隔开",
-						},
-					},
+					Flags: configSetFlags(),
 				},
 				{
 					Name:        "reset",
@@ -2234,3 +2889,22 @@ func main() {
 
 	app.Run(os.Args)
 }
+
+// configSetFlags 由配置项定义生成 config set 的参数
+func configSetFlags() []cli.Flag {
+	flags := make([]cli.Flag, 0, len(pcsconfig.Schema))
+	for _, item := range pcsconfig.Schema {
+		if item.Type == pcsconfig.ItemTypeBool {
+			flags = append(flags, cli.BoolFlag{
+				Name:  item.Name,
+				Usage: item.Description,
+			})
+			continue
+		}
+		flags = append(flags, cli.StringFlag{
+			Name:  item.Name,
+			Usage: item.Description,
+		})
+	}
+	return flags
+}
@@ -38,13 +38,25 @@ type (
 		ModTime    int64    `json:"modtime"`   // 修改日期
 	}
 
+	// File 计算摘要时读取的文件内容
+	File interface {
+		io.Reader
+		io.ReaderAt
+		io.Seeker
+		io.Closer
+	}
+
 	// LocalFileChecksum 校验本地文件
 	LocalFileChecksum struct {
 		LocalFileMeta
+		// Transform 打开文件后对文件内容进行变换, 例如加密, 返回变换后的内容和大小, 摘要按变换后的内容计算
+		Transform func(f *os.File, size int64) (File, int64, error)
+
 		bufSize   int
 		sliceSize int
 		buf       []byte
 		file      *os.File // 文件
+		rd        File     // 读取的内容
 	}
 )
 
@@ -81,6 +93,15 @@ func (lfc *LocalFileChecksum) OpenPath() error {
 
 	lfc.Length = info.Size()
 	lfc.ModTime = info.ModTime().Unix()
+	lfc.rd = lfc.file
+	if lfc.Transform != nil {
+		lfc.rd, lfc.Length, err = lfc.Transform(lfc.file, lfc.Length)
+		if err != nil {
+			lfc.file.Close()
+			lfc.file, lfc.rd = nil, nil
+			return err
+		}
+	}
 	return nil
 }
 
@@ -89,13 +110,18 @@ func (lfc *LocalFileChecksum) GetFile() *os.File {
 	return lfc.file
 }
 
+// GetReader 获取读取的内容, 设置了 Transform 时为变换后的内容
+func (lfc *LocalFileChecksum) GetReader() File {
+	return lfc.rd
+}
+
 // Close 关闭文件
 func (lfc *LocalFileChecksum) Close() error {
-	if lfc.file == nil {
+	if lfc.rd == nil {
 		return ErrFileIsNil
 	}
 
-	return lfc.file.Close()
+	return lfc.rd.Close()
 }
 
 func (lfc *LocalFileChecksum) initBuf() {
@@ -125,7 +151,7 @@ func (lfc *LocalFileChecksum) writeChecksum(data []byte, wus ...*ChecksumWriteUn
 
 func (lfc *LocalFileChecksum) GetSliceDataContent(offset, length int64) (dataContent []byte, readLength int64, err error) {
 	dataContent = make([]byte, length)
-	ret, err := lfc.file.ReadAt(dataContent, offset)
+	ret, err := lfc.rd.ReadAt(dataContent, offset)
 	if err != nil && err != io.EOF {
 		return
 	}
@@ -135,14 +161,14 @@ func (lfc *LocalFileChecksum) GetSliceDataContent(offset, length int64) (dataCon
 }
 
 func (lfc *LocalFileChecksum) repeatRead(wus ...*ChecksumWriteUnit) (err error) {
-	if lfc.file == nil {
+	if lfc.rd == nil {
 		return ErrFileIsNil
 	}
 
 	lfc.initBuf()
 
 	defer func() {
-		_, err = lfc.file.Seek(0, os.SEEK_SET) // 恢复文件指针
+		_, err = lfc.rd.Seek(0, os.SEEK_SET) // 恢复文件指针
 		if err != nil {
 			return
 		}
@@ -154,7 +180,7 @@ func (lfc *LocalFileChecksum) repeatRead(wus ...*ChecksumWriteUnit) (err error)
 	)
 read:
 	for {
-		n, err = lfc.file.Read(lfc.buf)
+		n, err = lfc.rd.Read(lfc.buf)
 		switch err {
 		case io.EOF:
 			err = lfc.writeChecksum(lfc.buf[:n], wus...)
@@ -243,12 +269,11 @@ func (lfc *LocalFileChecksum) CalculateChunkedSum(chunkSize int64) (err error) {
 		return fmt.Errorf("invalid block size: %d", chunkSize)
 	}
 
-	// 获取文件信息（需要知道总大小来计算分块数）
-	fileInfo, err := lfc.file.Stat()
-	if err != nil {
-		return err
+	if lfc.rd == nil {
+		return ErrFileIsNil
 	}
-	fileSize := fileInfo.Size()
+	// 总大小用于计算分块数, 设置了 Transform 时为变换后的大小
+	fileSize := lfc.Length
 	if fileSize == 0 {
 		return nil // 空文件
 	}
@@ -279,7 +304,7 @@ func (lfc *LocalFileChecksum) CalculateChunkedSum(chunkSize int64) (err error) {
 				readSize = currentChunkSize - bytesRead
 			}
 
-			n, err := lfc.file.ReadAt(buffer[:readSize], offset+bytesRead)
+			n, err := lfc.rd.ReadAt(buffer[:readSize], offset+bytesRead)
 			if err != nil && err != io.EOF {
 				return err
 			}
@@ -1,13 +1,23 @@
 package pcsutil
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"github.com/qjfoidnh/Baidu-Login/bdcrypto"
+	"golang.org/x/crypto/scrypt"
 	"io"
 	"os"
 	"strings"
 )
 
+var (
+	// ErrCiphertextTooShort 密文长度不足
+	ErrCiphertextTooShort = errors.New("ciphertext too short")
+)
+
 // CryptoMethodSupport 检测是否支持加密解密方法
 func CryptoMethodSupport(method string) bool {
 	switch method {
@@ -168,3 +178,47 @@ func DecryptFile(method string, key []byte, filePath string, isGzip bool) (decry
 
 	return decryptedFilePath, nil
 }
+
+// DeriveKey 使用 scrypt 由口令和盐生成 32 字节的密钥, 耗时较长, 调用方应复用返回的密钥
+func DeriveKey(passphrase, salt []byte) (key [32]byte, err error) {
+	k, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, len(key))
+	if err != nil {
+		return
+	}
+	copy(key[:], k)
+	return
+}
+
+// AESGCMEncrypt 使用 AES-256-GCM 加密, 返回随机的 nonce 与密文拼接的结果
+func AESGCMEncrypt(key [32]byte, plaintext []byte) (ciphertext []byte, err error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// AESGCMDecrypt 解密 AESGCMEncrypt 加密的数据, 密钥不正确或数据被篡改时返回错误
+func AESGCMDecrypt(key [32]byte, ciphertext []byte) (plaintext []byte, err error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+	return aead.Open(nil, ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():], nil)
+}
+
+func newAESGCM(key [32]byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
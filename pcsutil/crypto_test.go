@@ -0,0 +1,32 @@
+package pcsutil_test
+
+import (
+	"bytes"
+	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil"
+	"testing"
+)
+
+func TestAESGCM(t *testing.T) {
+	key, err := pcsutil.DeriveKey([]byte("passphrase"), []byte("salt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("BDUSS=xxx")
+	ciphertext, err := pcsutil.AESGCMEncrypt(key, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := pcsutil.AESGCMDecrypt(key, ciphertext)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("decrypt: %q, %v", got, err)
+	}
+
+	wrongKey, _ := pcsutil.DeriveKey([]byte("wrong"), []byte("salt"))
+	if _, err = pcsutil.AESGCMDecrypt(wrongKey, ciphertext); err == nil {
+		t.Fatal("decrypt with wrong key should fail")
+	}
+	if _, err = pcsutil.AESGCMDecrypt(key, ciphertext[:4]); err == nil {
+		t.Fatal("decrypt short ciphertext should fail")
+	}
+}
@@ -1,18 +1,25 @@
 package taskframework
 
 import (
+	"context"
+	"errors"
 	"github.com/GeertJohan/go.incremental"
 	"github.com/oleiade/lane"
-	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/waitgroup"
 	"strconv"
+	"sync"
 	"time"
 )
 
 type (
 	TaskExecutor struct {
 		incr     *incremental.Int // 任务id生成
-		deque    *lane.Deque      // 队列
-		parallel int              // 任务的最大并发量
+		mu       sync.Mutex
+		cond     *sync.Cond               // 队列或并发量变化时唤醒调度
+		queue    []*TaskInfoItem          // 等待队列, 按优先级从高到低排列
+		running  map[string]*TaskInfoItem // 正在执行的任务
+		parallel int                      // 任务的最大并发量
+		cancel   context.CancelFunc       // 取消本次执行
+		stopped  bool                     // 已调用 Stop
 
 		// 是否统计失败队列
 		IsFailedDeque bool
@@ -20,13 +27,22 @@ type (
 	}
 )
 
+var (
+	// ErrTaskNotFound 任务不存在, 或已执行结束
+	ErrTaskNotFound = errors.New("task not found")
+)
+
 func NewTaskExecutor() *TaskExecutor {
 	return &TaskExecutor{}
 }
 
+// lazyInit 初始化, 调用前需加锁
 func (te *TaskExecutor) lazyInit() {
-	if te.deque == nil {
-		te.deque = lane.NewDeque()
+	if te.cond == nil {
+		te.cond = sync.NewCond(&te.mu)
+	}
+	if te.running == nil {
+		te.running = map[string]*TaskInfoItem{}
 	}
 	if te.incr == nil {
 		te.incr = &incremental.Int{}
@@ -34,133 +50,327 @@ func (te *TaskExecutor) lazyInit() {
 	if te.parallel < 1 {
 		te.parallel = 1
 	}
-	if te.IsFailedDeque {
+	if te.IsFailedDeque && te.failedDeque == nil {
 		te.failedDeque = lane.NewDeque()
 	}
 }
 
-// 设置任务的最大并发量
+// SetParallel 设置任务的最大并发量, 执行中修改立即生效
 func (te *TaskExecutor) SetParallel(parallel int) {
+	te.mu.Lock()
+	defer te.mu.Unlock()
 	te.parallel = parallel
+	if te.parallel < 1 {
+		te.parallel = 1
+	}
+	if te.cond != nil {
+		te.cond.Broadcast()
+	}
+}
+
+// Parallel 返回任务的最大并发量
+func (te *TaskExecutor) Parallel() int {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	return te.parallel
 }
 
-//Append 将任务加到任务队列末尾
+// Append 将任务加到任务队列末尾
 func (te *TaskExecutor) Append(unit TaskUnit, maxRetry int) *TaskInfo {
+	return te.AppendPriority(unit, maxRetry, PriorityNormal)
+}
+
+// AppendPriority 将任务加到同优先级任务的末尾, 优先级高的任务先执行
+func (te *TaskExecutor) AppendPriority(unit TaskUnit, maxRetry int, priority Priority) *TaskInfo {
+	te.mu.Lock()
 	te.lazyInit()
 	taskInfo := &TaskInfo{
 		id:       strconv.Itoa(te.incr.Next()),
 		maxRetry: maxRetry,
+		priority: priority,
 	}
+	te.mu.Unlock()
+
 	unit.SetTaskInfo(taskInfo)
-	te.deque.Append(&TaskInfoItem{
+
+	te.mu.Lock()
+	te.insert(&TaskInfoItem{
 		Info: taskInfo,
 		Unit: unit,
 	})
+	te.mu.Unlock()
 	return taskInfo
 }
 
-//AppendNoRetry 将任务加到任务队列末尾, 不重试
+// AppendNoRetry 将任务加到任务队列末尾, 不重试
 func (te *TaskExecutor) AppendNoRetry(unit TaskUnit) {
 	te.Append(unit, 0)
 }
 
-//Count 返回任务数量
+// insert 按优先级插入等待队列, 调用前需加锁
+func (te *TaskExecutor) insert(task *TaskInfoItem) {
+	i := len(te.queue)
+	for i > 0 && te.queue[i-1].Info.priority < task.Info.priority {
+		i--
+	}
+	te.queue = append(te.queue, nil)
+	copy(te.queue[i+1:], te.queue[i:])
+	te.queue[i] = task
+	te.cond.Broadcast()
+}
+
+// remove 从等待队列移除任务, 调用前需加锁
+func (te *TaskExecutor) remove(id string) *TaskInfoItem {
+	for i, task := range te.queue {
+		if task.Info.id == id {
+			te.queue = append(te.queue[:i], te.queue[i+1:]...)
+			return task
+		}
+	}
+	return nil
+}
+
+// Count 返回等待中的任务数量
 func (te *TaskExecutor) Count() int {
-	if te.deque == nil {
-		return 0
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	return len(te.queue)
+}
+
+// SetPriority 修改等待中任务的优先级, 正在执行的任务将在重试时生效
+func (te *TaskExecutor) SetPriority(id string, priority Priority) error {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	if task, ok := te.running[id]; ok {
+		task.Info.priority = priority
+		return nil
+	}
+	task := te.remove(id)
+	if task == nil {
+		return ErrTaskNotFound
+	}
+	task.Info.priority = priority
+	te.insert(task)
+	return nil
+}
+
+// MoveToFront 将等待中的任务移到队列最前面, 下一个执行
+func (te *TaskExecutor) MoveToFront(id string) error {
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	task := te.remove(id)
+	if task == nil {
+		return ErrTaskNotFound
+	}
+	if len(te.queue) > 0 && te.queue[0].Info.priority > task.Info.priority {
+		task.Info.priority = te.queue[0].Info.priority
 	}
-	return te.deque.Size()
+	te.queue = append([]*TaskInfoItem{task}, te.queue...)
+	te.cond.Broadcast()
+	return nil
 }
 
-//Execute 执行任务
+// Cancel 取消任务, 等待中的任务直接移除, 正在执行的任务通过 context 通知中断
+func (te *TaskExecutor) Cancel(id string) error {
+	te.mu.Lock()
+	if task, ok := te.running[id]; ok {
+		task.Info.setCanceled()
+		if task.Info.cancel != nil {
+			task.Info.cancel()
+		}
+		te.mu.Unlock()
+		return nil
+	}
+	task := te.remove(id)
+	te.mu.Unlock()
+	if task == nil {
+		return ErrTaskNotFound
+	}
+
+	te.onCanceled(task, nil)
+	return nil
+}
+
+// Execute 执行任务
 func (te *TaskExecutor) Execute() {
+	te.ExecuteContext(context.Background())
+}
+
+// ExecuteContext 执行任务, ctx 取消时中断所有任务, 阻塞直到全部任务结束
+func (te *TaskExecutor) ExecuteContext(ctx context.Context) {
+	te.mu.Lock()
 	te.lazyInit()
+	execCtx, cancel := context.WithCancel(ctx)
+	te.cancel = cancel
+	if te.stopped {
+		cancel()
+	}
+	te.mu.Unlock()
+	defer cancel()
+
+	// 取消时唤醒调度
+	stop := context.AfterFunc(execCtx, func() {
+		te.mu.Lock()
+		te.cond.Broadcast()
+		te.mu.Unlock()
+	})
+	defer stop()
 
 	for {
-		wg := waitgroup.NewWaitGroup(te.parallel)
-		for {
-			e := te.deque.Shift()
-			if e == nil { // 任务为空
-				break
+		te.mu.Lock()
+		for execCtx.Err() == nil && len(te.running) > 0 && (len(te.queue) == 0 || len(te.running) >= te.parallel) {
+			te.cond.Wait()
+		}
+
+		if execCtx.Err() != nil {
+			// 已停止, 取消剩余的任务
+			for len(te.running) > 0 {
+				te.cond.Wait()
 			}
+			canceled := te.queue
+			te.queue = nil
+			te.stopped = false
+			te.mu.Unlock()
+			for _, task := range canceled {
+				te.onCanceled(task, nil)
+			}
+			return
+		}
 
-			// 获取任务
-			task := e.(*TaskInfoItem)
-			wg.AddDelta()
-
-			go func(task *TaskInfoItem) {
-				defer wg.Done()
-
-				result := task.Unit.Run()
-
-				// 返回结果为空
-				if result == nil {
-					task.Unit.OnComplete(result)
-					return
-				}
-
-				if result.Succeed {
-					task.Unit.OnSuccess(result)
-					task.Unit.OnComplete(result)
-					return
-				}
-
-				// 需要进行重试
-				if result.NeedRetry {
-					// 重试次数超出限制
-					// 执行失败
-					if task.Info.IsExceedRetry() {
-						task.Unit.OnFailed(result)
-						if te.IsFailedDeque {
-							// 加入失败队列
-							te.failedDeque.Append(task)
-						}
-						task.Unit.OnComplete(result)
-						return
-					}
-					task.Info.retry++         // 增加重试次数
-					task.Unit.OnRetry(result) // 调用重试
-					task.Unit.OnComplete(result)
-
-					time.Sleep(task.Unit.RetryWait()) // 等待
-					te.deque.Append(task)             // 重新加入队列末尾
-					return
-				}
-
-				// 执行失败
-				task.Unit.OnFailed(result)
-				if te.IsFailedDeque && result.Extra != "skip" {
-					// 加入失败队列
-					te.failedDeque.Append(task)
-				}
-				task.Unit.OnComplete(result)
-			}(task)
+		// 没有任务了
+		if len(te.queue) == 0 {
+			te.mu.Unlock()
+			return
 		}
 
-		wg.Wait()
+		// 获取任务
+		task := te.queue[0]
+		te.queue = te.queue[1:]
+		taskCtx, taskCancel := context.WithCancel(execCtx)
+		task.Info.cancel = taskCancel
+		te.running[task.Info.id] = task
+		te.mu.Unlock()
 
-		// 没有任务了
-		if te.deque.Size() == 0 {
-			break
+		go te.run(taskCtx, taskCancel, task)
+	}
+}
+
+// run 执行单个任务, 结束后移出执行列表, 需要重试的重新加入队列
+func (te *TaskExecutor) run(ctx context.Context, cancel context.CancelFunc, task *TaskInfoItem) {
+	isRetry := te.runTask(ctx, task)
+	cancel()
+
+	te.mu.Lock()
+	delete(te.running, task.Info.id)
+	task.Info.cancel = nil
+	if isRetry {
+		// 重新加入同优先级队列末尾
+		te.insert(task)
+	}
+	te.cond.Broadcast()
+	te.mu.Unlock()
+}
+
+// runTask 执行任务并调用回调, 返回是否需要重新加入队列
+func (te *TaskExecutor) runTask(ctx context.Context, task *TaskInfoItem) (isRetry bool) {
+	result := task.Unit.Run(ctx)
+
+	// 已取消
+	if ctx.Err() != nil && (result == nil || !result.Succeed) {
+		te.onCanceled(task, result)
+		return
+	}
+
+	// 返回结果为空
+	if result == nil {
+		task.Unit.OnComplete(result)
+		return
+	}
+
+	if result.Succeed {
+		task.Unit.OnSuccess(result)
+		task.Unit.OnComplete(result)
+		return
+	}
+
+	// 需要进行重试
+	if result.NeedRetry {
+		// 重试次数超出限制
+		// 执行失败
+		if task.Info.IsExceedRetry() {
+			task.Unit.OnFailed(result)
+			if te.IsFailedDeque {
+				// 加入失败队列
+				te.failedDeque.Append(task)
+			}
+			task.Unit.OnComplete(result)
+			return
+		}
+		task.Info.retry++         // 增加重试次数
+		task.Unit.OnRetry(result) // 调用重试
+		task.Unit.OnComplete(result)
+
+		// 等待, 等待期间可被取消
+		timer := time.NewTimer(task.Unit.RetryWait())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			te.onCanceled(task, result)
+			return
+		case <-timer.C:
 		}
+		return true
 	}
+
+	// 执行失败
+	task.Unit.OnFailed(result)
+	if te.IsFailedDeque && result.Extra != "skip" {
+		// 加入失败队列
+		te.failedDeque.Append(task)
+	}
+	task.Unit.OnComplete(result)
+	return
+}
+
+// onCanceled 任务被取消, 视为执行失败, 不加入失败队列
+func (te *TaskExecutor) onCanceled(task *TaskInfoItem, lastRunResult *TaskUnitRunResult) {
+	task.Info.setCanceled()
+	result := &TaskUnitRunResult{}
+	if lastRunResult != nil {
+		*result = *lastRunResult
+	}
+	result.Succeed = false
+	result.NeedRetry = false
+	if result.Err == nil {
+		result.Err = context.Canceled
+	}
+	if result.ResultMessage == "" {
+		result.ResultMessage = "任务已取消"
+	}
+	task.Unit.OnFailed(result)
+	task.Unit.OnComplete(result)
 }
 
-//FailedDeque 获取失败队列
+// FailedDeque 获取失败队列
 func (te *TaskExecutor) FailedDeque() *lane.Deque {
 	return te.failedDeque
 }
 
-//Stop 停止执行
+// Stop 停止执行, 取消正在执行和等待中的全部任务
 func (te *TaskExecutor) Stop() {
-
+	te.mu.Lock()
+	defer te.mu.Unlock()
+	te.stopped = true
+	if te.cancel != nil {
+		te.cancel()
+	}
 }
 
-//Pause 暂停执行
+// Pause 暂停执行
 func (te *TaskExecutor) Pause() {
 
 }
 
-//Resume 恢复执行
+// Resume 恢复执行
 func (te *TaskExecutor) Resume() {
 }
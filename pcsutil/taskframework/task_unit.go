@@ -1,12 +1,15 @@
 package taskframework
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type (
 	TaskUnit interface {
 		SetTaskInfo(info *TaskInfo)
-		// 执行任务
-		Run() (result *TaskUnitRunResult)
+		// 执行任务, ctx 取消时应尽快中断并返回
+		Run(ctx context.Context) (result *TaskUnitRunResult)
 		// 重试任务执行的方法
 		// 当达到最大重试次数, 执行失败
 		OnRetry(lastRunResult *TaskUnitRunResult)
@@ -1,8 +1,10 @@
 package taskframework_test
 
 import (
+	"context"
 	"fmt"
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsutil/taskframework"
+	"sync"
 	"testing"
 	"time"
 )
@@ -12,6 +14,16 @@ type (
 		retry    bool
 		taskInfo *taskframework.TaskInfo
 	}
+
+	// OrderUnit 记录执行顺序, 阻塞直到 ctx 取消或 release 关闭
+	OrderUnit struct {
+		TestUnit
+		name    string
+		mu      *sync.Mutex
+		order   *[]string
+		release chan struct{}
+		failed  bool
+	}
 )
 
 func (tu *TestUnit) SetTaskInfo(taskInfo *taskframework.TaskInfo) {
@@ -30,7 +42,7 @@ func (tu *TestUnit) OnComplete(lastRunResult *taskframework.TaskUnitRunResult) {
 	fmt.Printf("[%s] complete\n", tu.taskInfo.Id())
 }
 
-func (tu *TestUnit) Run() (result *taskframework.TaskUnitRunResult) {
+func (tu *TestUnit) Run(ctx context.Context) (result *taskframework.TaskUnitRunResult) {
 	fmt.Printf("[%s] running...\n", tu.taskInfo.Id())
 	return &taskframework.TaskUnitRunResult{
 		//Succeed:   true,
@@ -57,3 +69,86 @@ func TestTaskExecutor(t *testing.T) {
 	}
 	te.Execute()
 }
+
+func (ou *OrderUnit) Run(ctx context.Context) (result *taskframework.TaskUnitRunResult) {
+	ou.mu.Lock()
+	*ou.order = append(*ou.order, ou.name)
+	ou.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return &taskframework.TaskUnitRunResult{Err: ctx.Err()}
+	case <-ou.release:
+	}
+	return &taskframework.TaskUnitRunResult{Succeed: true}
+}
+
+func (ou *OrderUnit) OnFailed(lastRunResult *taskframework.TaskUnitRunResult) {
+	ou.failed = true
+}
+
+func TestTaskExecutorPriority(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		order   []string
+		release = make(chan struct{})
+		te      = taskframework.NewTaskExecutor()
+		units   = map[string]*OrderUnit{}
+		infos   = map[string]*taskframework.TaskInfo{}
+	)
+	add := func(name string, priority taskframework.Priority) {
+		ou := &OrderUnit{name: name, mu: &mu, order: &order, release: release}
+		units[name] = ou
+		infos[name] = te.AppendPriority(ou, 0, priority)
+	}
+	add("a", taskframework.PriorityNormal)
+	add("b", taskframework.PriorityLow)
+	add("c", taskframework.PriorityHigh)
+	add("d", taskframework.PriorityNormal)
+	add("e", taskframework.PriorityNormal)
+
+	if err := te.MoveToFront(infos["b"].Id()); err != nil {
+		t.Fatal(err)
+	}
+	if err := te.Cancel(infos["d"].Id()); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	te.Execute()
+
+	expected := "b c a e"
+	if got := fmt.Sprint(order); got != "["+expected+"]" {
+		t.Fatalf("order: %s, expected: [%s]", got, expected)
+	}
+	if !units["d"].failed || !infos["d"].IsCanceled() {
+		t.Fatalf("task d not canceled")
+	}
+}
+
+func TestTaskExecutorCancel(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+		te    = taskframework.NewTaskExecutor()
+	)
+	te.SetParallel(1)
+	ou1 := &OrderUnit{name: "1", mu: &mu, order: &order, release: make(chan struct{})}
+	ou2 := &OrderUnit{name: "2", mu: &mu, order: &order, release: make(chan struct{})}
+	info1 := te.Append(ou1, 0)
+	te.Append(ou2, 0)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		// 取消正在执行的任务, 并提高并发量
+		te.Cancel(info1.Id())
+		te.SetParallel(2)
+		close(ou2.release)
+	}()
+	te.Execute()
+
+	if !ou1.failed || !info1.IsCanceled() {
+		t.Fatalf("task 1 not canceled")
+	}
+	if ou2.failed {
+		t.Fatalf("task 2 failed")
+	}
+}
@@ -1,10 +1,21 @@
 package taskframework
 
+import (
+	"context"
+	"sync/atomic"
+)
+
 type (
+	// Priority 任务优先级, 数值大的先执行
+	Priority int
+
 	TaskInfo struct {
 		id       string
 		maxRetry int
 		retry    int
+		priority Priority
+		cancel   context.CancelFunc
+		canceled int32
 	}
 
 	TaskInfoItem struct {
@@ -13,6 +24,15 @@ type (
 	}
 )
 
+const (
+	// PriorityLow 低优先级
+	PriorityLow Priority = -1
+	// PriorityNormal 默认优先级
+	PriorityNormal Priority = 0
+	// PriorityHigh 高优先级
+	PriorityHigh Priority = 1
+)
+
 // IsExceedRetry 重试次数达到限制
 func (t *TaskInfo) IsExceedRetry() bool {
 	return t.retry >= t.maxRetry
@@ -33,3 +53,17 @@ func (t *TaskInfo) SetMaxRetry(maxRetry int) {
 func (t *TaskInfo) Retry() int {
 	return t.retry
 }
+
+// Priority 返回任务优先级
+func (t *TaskInfo) Priority() Priority {
+	return t.priority
+}
+
+// IsCanceled 任务是否已被取消
+func (t *TaskInfo) IsCanceled() bool {
+	return atomic.LoadInt32(&t.canceled) == 1
+}
+
+func (t *TaskInfo) setCanceled() {
+	atomic.StoreInt32(&t.canceled, 1)
+}
@@ -0,0 +1,308 @@
+// Package cookiefile 解析浏览器导出的 cookies 文件,
+// 支持 Netscape cookies.txt, 浏览器扩展 (EditThisCookie, Cookie-Editor 等) 导出的 JSON 和 HAR
+package cookiefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// jsonCookie 浏览器扩展导出的 cookie
+	jsonCookie struct {
+		Name           string  `json:"name"`
+		Value          string  `json:"value"`
+		Domain         string  `json:"domain"`
+		Path           string  `json:"path"`
+		Secure         bool    `json:"secure"`
+		HTTPOnly       bool    `json:"httpOnly"`
+		ExpirationDate float64 `json:"expirationDate"` // EditThisCookie, Cookie-Editor
+		Expires        float64 `json:"expires"`        // Playwright, Puppeteer
+	}
+
+	harCookie struct {
+		Name     string `json:"name"`
+		Value    string `json:"value"`
+		Domain   string `json:"domain"`
+		Path     string `json:"path"`
+		Expires  string `json:"expires"`
+		HTTPOnly bool   `json:"httpOnly"`
+		Secure   bool   `json:"secure"`
+	}
+
+	harHeader struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	harFile struct {
+		Log *struct {
+			Entries []struct {
+				Request struct {
+					URL     string      `json:"url"`
+					Cookies []harCookie `json:"cookies"`
+					Headers []harHeader `json:"headers"`
+				} `json:"request"`
+				Response struct {
+					Cookies []harCookie `json:"cookies"`
+				} `json:"response"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+)
+
+var (
+	// ErrUnknownFormat 无法识别的文件格式
+	ErrUnknownFormat = errors.New("unknown cookies file format")
+	// ErrNoCookies 文件中没有 cookie
+	ErrNoCookies = errors.New("no cookies found")
+)
+
+// Parse 自动识别格式并解析 cookies, 同名同域的 cookie 保留最后出现的
+func Parse(data []byte) (cookies []*http.Cookie, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) == 0:
+		return nil, ErrNoCookies
+	case trimmed[0] == '[':
+		cookies, err = ParseJSON(trimmed)
+	case trimmed[0] == '{':
+		if bytes.Contains(trimmed, []byte(`"log"`)) {
+			cookies, err = ParseHAR(trimmed)
+		} else {
+			cookies, err = ParseJSON(trimmed)
+		}
+	default:
+		cookies, err = ParseNetscape(trimmed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cookies = dedup(cookies)
+	if len(cookies) == 0 {
+		return nil, ErrNoCookies
+	}
+	return cookies, nil
+}
+
+// ParseNetscape 解析 Netscape cookies.txt, 忽略已过期的 cookie
+func ParseNetscape(data []byte) ([]*http.Cookie, error) {
+	var (
+		cookies []*http.Cookie
+		now     = time.Now()
+		scanner = bufio.NewScanner(bytes.NewReader(data))
+		valid   bool
+	)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		} else if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 6 {
+			continue
+		}
+		valid = true
+		// domain, include subdomains, path, secure, expires, name, value
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			HttpOnly: httpOnly,
+		}
+		if len(fields) > 6 {
+			cookie.Value = fields[6]
+		}
+		if expires, _ := strconv.ParseInt(fields[4], 10, 64); expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+			if cookie.Expires.Before(now) {
+				continue
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrUnknownFormat
+	}
+	return cookies, nil
+}
+
+// ParseJSON 解析浏览器扩展导出的 JSON, 支持 cookie 数组或包含 cookies 字段的对象
+func ParseJSON(data []byte) ([]*http.Cookie, error) {
+	var list []jsonCookie
+	if err := json.Unmarshal(data, &list); err != nil {
+		var state struct {
+			Cookies []jsonCookie `json:"cookies"`
+		}
+		if json.Unmarshal(data, &state) != nil {
+			return nil, ErrUnknownFormat
+		}
+		list = state.Cookies
+	}
+
+	cookies := make([]*http.Cookie, 0, len(list))
+	for _, jc := range list {
+		if jc.Name == "" {
+			continue
+		}
+		cookie := &http.Cookie{
+			Name:     jc.Name,
+			Value:    jc.Value,
+			Domain:   jc.Domain,
+			Path:     jc.Path,
+			Secure:   jc.Secure,
+			HttpOnly: jc.HTTPOnly,
+		}
+		expires := jc.ExpirationDate
+		if expires <= 0 {
+			expires = jc.Expires
+		}
+		if expires > 0 {
+			cookie.Expires = time.Unix(int64(expires), 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// ParseHAR 解析 HAR 文件中请求携带和响应设置的 cookie, 请求的 cookie 以请求的域名为 domain
+func ParseHAR(data []byte) ([]*http.Cookie, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil || har.Log == nil {
+		return nil, ErrUnknownFormat
+	}
+
+	var cookies []*http.Cookie
+	for _, entry := range har.Log.Entries {
+		var host string
+		if u, err := url.Parse(entry.Request.URL); err == nil {
+			host = u.Hostname()
+		}
+
+		reqCookies := entry.Request.Cookies
+		if len(reqCookies) == 0 {
+			// 部分浏览器不填充 cookies 字段, 从请求头读取
+			for _, header := range entry.Request.Headers {
+				if !strings.EqualFold(header.Name, "Cookie") {
+					continue
+				}
+				for _, cookie := range (&http.Request{Header: http.Header{"Cookie": {header.Value}}}).Cookies() {
+					reqCookies = append(reqCookies, harCookie{Name: cookie.Name, Value: cookie.Value})
+				}
+			}
+		}
+		for _, hc := range reqCookies {
+			cookies = append(cookies, &http.Cookie{
+				Name:   hc.Name,
+				Value:  hc.Value,
+				Domain: host,
+				Path:   "/",
+			})
+		}
+
+		for _, hc := range entry.Response.Cookies {
+			cookie := &http.Cookie{
+				Name:     hc.Name,
+				Value:    hc.Value,
+				Domain:   hc.Domain,
+				Path:     hc.Path,
+				HttpOnly: hc.HTTPOnly,
+				Secure:   hc.Secure,
+			}
+			if cookie.Domain == "" {
+				cookie.Domain = host
+			}
+			if t, err := time.Parse(time.RFC3339, hc.Expires); err == nil {
+				cookie.Expires = t
+			}
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies, nil
+}
+
+// domainMatch cookie 的 domain 是否匹配 host
+func domainMatch(domain, host string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	host = strings.ToLower(host)
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+// ForHost 返回发送到 host 的 cookie, 同名的 cookie 保留 domain 最具体的
+func ForHost(cookies []*http.Cookie, host string) []*http.Cookie {
+	var (
+		result []*http.Cookie
+		index  = map[string]int{}
+	)
+	for _, cookie := range cookies {
+		if !domainMatch(cookie.Domain, host) {
+			continue
+		}
+		i, ok := index[cookie.Name]
+		if !ok {
+			index[cookie.Name] = len(result)
+			result = append(result, cookie)
+			continue
+		}
+		if len(strings.TrimPrefix(cookie.Domain, ".")) >= len(strings.TrimPrefix(result[i].Domain, ".")) {
+			result[i] = cookie
+		}
+	}
+	return result
+}
+
+// Lookup 查找发送到 host 的名为 name 的 cookie 的值
+func Lookup(cookies []*http.Cookie, name, host string) string {
+	for _, cookie := range ForHost(cookies, host) {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// String 将 cookies 拼接为请求头格式, 如 "BDUSS=xxx; STOKEN=yyy"
+func String(cookies []*http.Cookie) string {
+	parts := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		parts = append(parts, cookie.Name+"="+cookie.Value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// dedup 同名同域同路径的 cookie 保留最后出现的
+func dedup(cookies []*http.Cookie) []*http.Cookie {
+	var (
+		result []*http.Cookie
+		index  = map[string]int{}
+	)
+	for _, cookie := range cookies {
+		key := strings.ToLower(strings.TrimPrefix(cookie.Domain, ".")) + "\x00" + cookie.Path + "\x00" + cookie.Name
+		if i, ok := index[key]; ok {
+			result[i] = cookie
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, cookie)
+	}
+	return result
+}
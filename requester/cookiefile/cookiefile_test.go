@@ -0,0 +1,76 @@
+package cookiefile_test
+
+import (
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/cookiefile"
+)
+
+const (
+	netscapeFile = "# Netscape HTTP Cookie File\n" +
+		"#HttpOnly_.baidu.com\tTRUE\t/\tFALSE\t4102444800\tBDUSS\tbduss1\n" +
+		".baidu.com\tTRUE\t/\tFALSE\t0\tBAIDUID\tbaiduid1\n" +
+		"pan.baidu.com\tFALSE\t/\tFALSE\t4102444800\tSTOKEN\tstoken_pan\n" +
+		"passport.baidu.com\tFALSE\t/\tFALSE\t4102444800\tSTOKEN\tstoken_passport\n" +
+		".baidu.com\tTRUE\t/\tFALSE\t1\tEXPIRED\tx\n" +
+		".example.com\tTRUE\t/\tFALSE\t0\tOTHER\ty\n"
+
+	jsonFile = `[
+		{"domain": ".baidu.com", "name": "BDUSS", "value": "bduss1", "path": "/", "expirationDate": 4102444800},
+		{"domain": "pan.baidu.com", "name": "STOKEN", "value": "stoken_pan", "path": "/"},
+		{"domain": "passport.baidu.com", "name": "PTOKEN", "value": "ptoken1", "path": "/"}
+	]`
+
+	harFile = `{"log": {"entries": [
+		{"request": {"url": "https://pan.baidu.com/disk/home", "cookies": [], "headers": [{"name": "Cookie", "value": "BDUSS=bduss0; STOKEN=stoken_pan"}]},
+		 "response": {"cookies": []}},
+		{"request": {"url": "https://pan.baidu.com/api/list", "cookies": [{"name": "BDUSS", "value": "bduss1"}]},
+		 "response": {"cookies": [{"name": "BAIDUID", "value": "baiduid1", "domain": ".baidu.com", "path": "/"}]}}
+	]}}`
+)
+
+func TestParse(t *testing.T) {
+	for name, data := range map[string]string{
+		"netscape": netscapeFile,
+		"json":     jsonFile,
+		"har":      harFile,
+	} {
+		cookies, err := cookiefile.Parse([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if v := cookiefile.Lookup(cookies, "BDUSS", "pan.baidu.com"); v != "bduss1" {
+			t.Errorf("%s: BDUSS: %q", name, v)
+		}
+		if v := cookiefile.Lookup(cookies, "STOKEN", "pan.baidu.com"); v != "stoken_pan" {
+			t.Errorf("%s: STOKEN: %q", name, v)
+		}
+		if v := cookiefile.Lookup(cookies, "EXPIRED", "pan.baidu.com"); v != "" {
+			t.Errorf("%s: expired cookie: %q", name, v)
+		}
+		if v := cookiefile.Lookup(cookies, "OTHER", "pan.baidu.com"); v != "" {
+			t.Errorf("%s: other domain cookie: %q", name, v)
+		}
+	}
+}
+
+func TestForHost(t *testing.T) {
+	cookies, err := cookiefile.Parse([]byte(netscapeFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := cookiefile.String(cookiefile.ForHost(cookies, "pan.baidu.com")); s != "BDUSS=bduss1; BAIDUID=baiduid1; STOKEN=stoken_pan" {
+		t.Errorf("pan.baidu.com: %s", s)
+	}
+	if v := cookiefile.Lookup(cookies, "STOKEN", "passport.baidu.com"); v != "stoken_passport" {
+		t.Errorf("passport STOKEN: %s", v)
+	}
+}
+
+func TestParseUnknown(t *testing.T) {
+	for _, data := range []string{"", "hello world", `{"foo": 1}`} {
+		if _, err := cookiefile.Parse([]byte(data)); err == nil {
+			t.Errorf("%q: expected error", data)
+		}
+	}
+}
@@ -284,6 +284,14 @@ func (der *Downloader) checkLoadBalancers() *LoadBalancerResponseList {
 
 // Execute 开始任务
 func (der *Downloader) Execute() error {
+	return der.ExecuteContext(context.Background())
+}
+
+// ExecuteContext 开始任务, ctx 取消时中断下载并保存断点信息, 返回 context.Canceled
+func (der *Downloader) ExecuteContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	der.lazyInit()
 	var (
 		resp *http.Response
@@ -448,7 +456,7 @@ func (der *Downloader) Execute() error {
 	// 服务器不支持断点续传, 或者单线程下载, 都不重载worker
 	der.monitor.SetReloadWorker(parallel > 1)
 
-	moniterCtx, moniterCancelFunc := context.WithCancel(context.Background())
+	moniterCtx, moniterCancelFunc := context.WithCancel(ctx)
 	der.monitorCancelFunc = moniterCancelFunc
 
 	der.monitor.SetInstanceState(der.instanceState)
@@ -461,6 +469,10 @@ func (der *Downloader) Execute() error {
 
 	// 检查错误
 	err = der.monitor.Err()
+	if err == nil && ctx.Err() != nil {
+		// 已取消
+		err = ctx.Err()
+	}
 	if err == nil { // 成功
 		pcsutil.Trigger(der.onSuccessEvent)
 		if !single {
@@ -512,6 +524,14 @@ func (der *Downloader) Resume() {
 	der.monitor.Resume()
 }
 
+// IsPaused 是否已暂停
+func (der *Downloader) IsPaused() bool {
+	if der.monitor == nil {
+		return false
+	}
+	return der.monitor.IsPaused()
+}
+
 // Cancel 取消
 func (der *Downloader) Cancel() {
 	if der.monitor == nil {
@@ -6,6 +6,7 @@ import (
 	"github.com/qjfoidnh/BaiduPCS-Go/pcsverbose"
 	"github.com/qjfoidnh/BaiduPCS-Go/requester/transfer"
 	"sort"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,7 +24,8 @@ type (
 		completed       chan struct{}
 		err             error
 		resetController *ResetController
-		isReloadWorker  bool //是否重载worker, 单线程模式不重载
+		isReloadWorker  bool  //是否重载worker, 单线程模式不重载
+		paused          int32 //是否已暂停
 
 		// 临时变量
 		lastAvaliableIndex int
@@ -217,20 +219,43 @@ func (mt *Monitor) RangeWorker(f RangeWorkerFunc) {
 	}
 }
 
-//Pause 暂停所有的下载
+//Pause 暂停所有的下载, 并保存断点信息
 func (mt *Monitor) Pause() {
+	if !atomic.CompareAndSwapInt32(&mt.paused, 0, 1) {
+		return
+	}
 	for k := range mt.workers {
 		mt.workers[k].Pause()
 	}
+	mt.saveInstanceState()
 }
 
 //Resume 恢复所有的下载
 func (mt *Monitor) Resume() {
+	if !atomic.CompareAndSwapInt32(&mt.paused, 1, 0) {
+		return
+	}
 	for k := range mt.workers {
 		mt.workers[k].Resume()
 	}
 }
 
+// IsPaused 是否已暂停
+func (mt *Monitor) IsPaused() bool {
+	return atomic.LoadInt32(&mt.paused) == 1
+}
+
+// saveInstanceState 保存断点信息到文件
+func (mt *Monitor) saveInstanceState() {
+	if mt.instanceState == nil || mt.status == nil || len(mt.workers) == 0 {
+		return
+	}
+	mt.instanceState.Put(&transfer.DownloadInstanceInfo{
+		DownloadStatus: mt.status,
+		Ranges:         mt.GetAllWorkersRange(),
+	})
+}
+
 // TryAddNewWork 尝试加入新range
 func (mt *Monitor) TryAddNewWork() {
 	if mt.status == nil {
@@ -351,6 +376,11 @@ func (mt *Monitor) Execute(cancelCtx context.Context) {
 	mt.lazyInit()
 	for _, worker := range mt.workers {
 		worker.SetDownloadStatus(mt.status)
+		if mt.IsPaused() {
+			// 开始前已暂停, 等待恢复时再执行
+			worker.status.statusCode = StatusCodePaused
+			continue
+		}
 		go worker.Execute()
 	}
 
@@ -364,6 +394,8 @@ func (mt *Monitor) Execute(cancelCtx context.Context) {
 	for {
 		select {
 		case <-cancelCtx.Done():
+			// 保存断点信息, 便于之后继续下载
+			mt.saveInstanceState()
 			for _, worker := range mt.workers {
 				err := worker.Cancel()
 				if err != nil {
@@ -375,21 +407,17 @@ func (mt *Monitor) Execute(cancelCtx context.Context) {
 			return
 		case <-ticker.C:
 			// 初始化监控工作
-			mt.ResetFailedAndNetErrorWorkers()
+			if !mt.IsPaused() {
+				mt.ResetFailedAndNetErrorWorkers()
+			}
 
 			mt.status.UpdateSpeeds() // 更新速度
 
 			// 保存断点信息到文件
-			if mt.instanceState != nil {
-				mt.instanceState.Put(&transfer.DownloadInstanceInfo{
-					DownloadStatus: mt.status,
-					Ranges:         mt.GetAllWorkersRange(),
-				})
-			}
-
+			mt.saveInstanceState()
 
-			// 不重载worker
-			if !mt.isReloadWorker {
+			// 不重载worker, 暂停时也不重载
+			if !mt.isReloadWorker || mt.IsPaused() {
 				continue
 			}
 
@@ -420,6 +448,9 @@ func (mt *Monitor) Execute(cancelCtx context.Context) {
 				}
 			} // end if 2
 		case <-ticker2.C:
+			if mt.IsPaused() {
+				continue
+			}
 			// 加入新range
 			mt.TryAddNewWork()
 		} //end select
@@ -69,7 +69,7 @@ func (wer *Worker) lazyInit() {
 		wer.client = requester.NewHTTPClient()
 	}
 	if wer.pauseChan == nil {
-		wer.pauseChan = make(chan struct{})
+		wer.pauseChan = make(chan struct{}, 1)
 	}
 	if wer.wrange == nil {
 		wer.wrange = &transfer.Range{}
@@ -148,10 +148,14 @@ func (wer *Worker) Pause() {
 		return
 	}
 
-	if wer.status.statusCode == StatusCodePaused {
+	if wer.status.statusCode == StatusCodePaused || wer.Completed() {
 		return
 	}
-	wer.pauseChan <- struct{}{}
+	// 不阻塞, worker 未在下载时, 信号留到下次执行前清除
+	select {
+	case wer.pauseChan <- struct{}{}:
+	default:
+	}
 	wer.status.statusCode = StatusCodePaused
 }
 
@@ -231,6 +235,12 @@ func (wer *Worker) Execute() {
 	wer.execMu.Lock()
 	defer wer.execMu.Unlock()
 
+	// 清除之前遗留的暂停信号
+	select {
+	case <-wer.pauseChan:
+	default:
+	}
+
 	wer.status.statusCode = StatusCodeInit
 	single := wer.acceptRanges == ""
 
@@ -353,6 +363,7 @@ func (wer *Worker) Execute() {
 			wer.status.statusCode = StatusCodeReseted
 			return
 		case <-wer.pauseChan: //暂停
+			wer.status.statusCode = StatusCodePaused
 			return
 		default:
 			wer.status.statusCode = StatusCodeDownloading
@@ -18,6 +18,11 @@ type (
 		size   int64
 		rd     io.Reader
 	}
+
+	readerAtLen64 struct {
+		io.ReaderAt
+		size int64
+	}
 )
 
 // NewFileReaderLen64 *os.File 实现 ReadedLen64 接口
@@ -42,6 +47,18 @@ func NewFileReaderAtLen64(f *os.File) ReaderAtLen64 {
 	}
 }
 
+// NewReaderAtLen64 io.ReaderAt 和已知的大小实现 ReaderAtLen64 接口
+func NewReaderAtLen64(r io.ReaderAt, size int64) ReaderAtLen64 {
+	if r == nil {
+		return nil
+	}
+
+	return &readerAtLen64{
+		ReaderAt: r,
+		size:     size,
+	}
+}
+
 func NewCryptoRandReaderAtLen64(size int64) ReaderAtLen64 {
 	return &rdReadedlen64{
 		rd:   cryptorand.Reader,
@@ -85,3 +102,8 @@ func (rr *rdReadedlen64) ReadAt(b []byte, off int64) (n int, err error) {
 func (rr *rdReadedlen64) Len() int64 {
 	return rr.size - rr.readed
 }
+
+// Len 返回数据的大小
+func (rl *readerAtLen64) Len() int64 {
+	return rl.size
+}
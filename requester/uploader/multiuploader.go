@@ -26,6 +26,8 @@ type (
 		onSuccessEvent      requester.Event        //成功上传事件
 		onFinishEvent       requester.Event        //结束上传事件
 		onCancelEvent       requester.Event        //取消上传事件
+		onPauseEvent        requester.Event        //暂停上传事件
+		onResumeEvent       requester.Event        //恢复上传事件
 		onErrorEvent        requester.EventOnError //上传出错事件
 		onUploadStatusEvent UploadStatusFunc       //上传状态事件
 
@@ -44,6 +46,11 @@ type (
 		canceled                chan struct{}
 		closeCanceledOnce       sync.Once
 		updateInstanceStateChan chan struct{}
+
+		pauseMu    sync.Mutex
+		paused     bool
+		pauseChan  chan struct{} // 暂停时关闭
+		resumeChan chan struct{} // 恢复时关闭
 	}
 
 	// MultiUploaderConfig 多线程上传配置
@@ -62,6 +69,7 @@ func NewMultiUploader(multiUpload MultiUpload, file rio.ReaderAtLen64, config *M
 		file:        file,
 		config:      config,
 		targetPath:  targetPath,
+		canceled:    make(chan struct{}),
 	}
 }
 
@@ -92,6 +100,20 @@ func (muer *MultiUploader) lazyInit() {
 	if muer.speedsStat == nil {
 		muer.speedsStat = &speeds.Speeds{}
 	}
+	muer.pauseMu.Lock()
+	muer.lazyInitPause()
+	muer.pauseMu.Unlock()
+}
+
+// lazyInitPause 初始化暂停相关的chan, 调用前需加锁
+func (muer *MultiUploader) lazyInitPause() {
+	if muer.pauseChan == nil {
+		muer.pauseChan = make(chan struct{})
+	}
+	if muer.resumeChan == nil {
+		muer.resumeChan = make(chan struct{})
+		close(muer.resumeChan)
+	}
 }
 
 func (muer *MultiUploader) check() {
@@ -165,9 +187,64 @@ func (muer *MultiUploader) InstanceState() *InstanceState {
 	}
 }
 
-// Cancel 取消上传
+// Cancel 取消上传, 可重复调用
 func (muer *MultiUploader) Cancel() {
-	close(muer.canceled)
+	muer.closeCanceledOnce.Do(func() {
+		close(muer.canceled)
+	})
+}
+
+// Pause 暂停上传, 正在上传的分片将中断, 恢复后重新上传
+func (muer *MultiUploader) Pause() {
+	muer.pauseMu.Lock()
+	defer muer.pauseMu.Unlock()
+	muer.lazyInitPause()
+	if muer.paused {
+		return
+	}
+	muer.paused = true
+	close(muer.pauseChan)
+	muer.resumeChan = make(chan struct{})
+	pcsutil.Trigger(muer.onPauseEvent)
+}
+
+// Resume 恢复上传
+func (muer *MultiUploader) Resume() {
+	muer.pauseMu.Lock()
+	defer muer.pauseMu.Unlock()
+	muer.lazyInitPause()
+	if !muer.paused {
+		return
+	}
+	muer.paused = false
+	muer.pauseChan = make(chan struct{})
+	close(muer.resumeChan)
+	pcsutil.Trigger(muer.onResumeEvent)
+}
+
+// IsPaused 是否已暂停
+func (muer *MultiUploader) IsPaused() bool {
+	muer.pauseMu.Lock()
+	defer muer.pauseMu.Unlock()
+	return muer.paused
+}
+
+// pauseState 返回当前的暂停chan和恢复chan
+func (muer *MultiUploader) pauseState() (pauseChan, resumeChan <-chan struct{}) {
+	muer.pauseMu.Lock()
+	defer muer.pauseMu.Unlock()
+	return muer.pauseChan, muer.resumeChan
+}
+
+// waitResume 暂停时阻塞, 直到恢复或取消, 取消时返回 false
+func (muer *MultiUploader) waitResume() bool {
+	_, resumeChan := muer.pauseState()
+	select {
+	case <-resumeChan:
+		return true
+	case <-muer.canceled:
+		return false
+	}
 }
 
 // OnExecute 设置开始上传事件
@@ -190,6 +267,16 @@ func (muer *MultiUploader) OnCancel(onCancelEvent requester.Event) {
 	muer.onCancelEvent = onCancelEvent
 }
 
+// OnPause 设置暂停上传事件
+func (muer *MultiUploader) OnPause(onPauseEvent requester.Event) {
+	muer.onPauseEvent = onPauseEvent
+}
+
+// OnResume 设置恢复上传事件
+func (muer *MultiUploader) OnResume(onResumeEvent requester.Event) {
+	muer.onResumeEvent = onResumeEvent
+}
+
 // OnError 设置上传发生错误事件
 func (muer *MultiUploader) OnError(onErrorEvent requester.EventOnError) {
 	muer.onErrorEvent = onErrorEvent
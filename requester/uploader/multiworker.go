@@ -39,6 +39,9 @@ func (werl *workerList) Readed() int64 {
 }
 
 func (muer *MultiUploader) upload() (uperr error) {
+	if muer.isCanceled() {
+		return context.Canceled
+	}
 	originPCSHost, err := muer.multiUpload.Precreate()
 	if err != nil {
 		return err
@@ -68,6 +71,11 @@ func (muer *MultiUploader) upload() (uperr error) {
 			}
 
 			wer := e.(*worker)
+			// 已暂停, 等待恢复
+			if !muer.waitResume() {
+				break
+			}
+			pauseChan, _ := muer.pauseState()
 			wg.AddDelta()
 			go func() {
 				defer wg.Done()
@@ -86,6 +94,16 @@ func (muer *MultiUploader) upload() (uperr error) {
 				case <-muer.canceled:
 					cancel()
 					return
+				case <-pauseChan:
+					// 暂停, 中断当前分片
+					cancel()
+					<-doneChan
+					if terr != nil {
+						// 放回队列, 恢复后重新上传
+						wer.splitUnit.Seek(0, os.SEEK_SET)
+						uploadDeque.Prepend(wer)
+						return
+					}
 				case <-doneChan:
 					// continue
 				}
@@ -94,9 +112,7 @@ func (muer *MultiUploader) upload() (uperr error) {
 					var me *MultiError
 					if errors.As(terr, &me) {
 						if me.Terminated { // 终止
-							muer.closeCanceledOnce.Do(func() { // 只关闭一次
-								close(muer.canceled)
-							})
+							muer.Cancel()
 							uperr = me.Err
 							return
 						}
@@ -120,8 +136,8 @@ func (muer *MultiUploader) upload() (uperr error) {
 		}
 		wg.Wait()
 
-		// 没有任务了
-		if uploadDeque.Size() == 0 {
+		// 没有任务了, 或已取消
+		if uploadDeque.Size() == 0 || muer.isCanceled() {
 			break
 		}
 	}
@@ -142,3 +158,13 @@ func (muer *MultiUploader) upload() (uperr error) {
 
 	return
 }
+
+// isCanceled 是否已取消
+func (muer *MultiUploader) isCanceled() bool {
+	select {
+	case <-muer.canceled:
+		return true
+	default:
+		return false
+	}
+}
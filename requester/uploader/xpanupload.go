@@ -0,0 +1,121 @@
+package uploader
+
+import (
+	"context"
+	"sort"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/pcserror"
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/rio"
+)
+
+type (
+	// XpanUpload 使用开放平台 (xpan) 的 precreate, superfile2, create 接口上传, 实现 MultiUpload.
+	// 分片大小需与计算 blockList 时使用的 xpan.BlockSize 一致
+	XpanUpload struct {
+		client     *xpan.Client
+		targetPath string
+		size       int64
+		blockList  []string // 本地计算的分片 md5
+		rtype      xpan.Rtype
+
+		uploadID string
+		rapid    bool         // 网盘中已存在相同内容的文件, 预上传即完成
+		needed   map[int]bool // 需要上传的分片序号, 为 nil 时全部上传
+	}
+)
+
+// NewXpanUpload 初始化 XpanUpload
+func NewXpanUpload(client *xpan.Client, targetPath string, size int64, blockList []string, rtype xpan.Rtype) *XpanUpload {
+	return &XpanUpload{
+		client:     client,
+		targetPath: targetPath,
+		size:       size,
+		blockList:  blockList,
+		rtype:      rtype,
+	}
+}
+
+// Precreate 预上传, 获取 uploadid, 返回的 pcsHost 为空
+func (xu *XpanUpload) Precreate() (pcsHost string, err pcserror.Error) {
+	res, err := xu.client.Precreate(context.Background(), xu.targetPath, xu.size, xu.blockList, xu.rtype)
+	if err != nil {
+		return "", err
+	}
+	xu.uploadID = res.UploadID
+	xu.rapid = res.ReturnType == xpan.ReturnTypeRapid
+	xu.needed = nil
+	if len(res.BlockList) > 0 {
+		xu.needed = make(map[int]bool, len(res.BlockList))
+		for _, partseq := range res.BlockList {
+			xu.needed[partseq] = true
+		}
+	}
+	uploaderVerbose.Infof("xpan precreate: uploadid: %s, return_type: %d, block_list: %v\n", res.UploadID, res.ReturnType, res.BlockList)
+	return "", nil
+}
+
+// TmpFile 上传分片, 秒传成功或服务器已有的分片直接返回本地计算的 md5
+func (xu *XpanUpload) TmpFile(ctx context.Context, uploadid, targetPath string, partseq int, partOffset int64, readerlen64 rio.ReaderLen64) (checksum string, terr error) {
+	if xu.skip(partseq) {
+		return xu.blockList[partseq], nil
+	}
+	if xu.uploadID != "" {
+		uploadid = xu.uploadID
+	}
+
+	checksum, pcsError := xu.client.UploadTmpFile(ctx, xu.targetPath, uploadid, partseq, readerlen64)
+	if pcsError != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if xpan.IsAccessTokenInvalid(pcsError) || pcsError.GetRemoteErrCode() == xpan.ErrnoNoPermission {
+			// 不可恢复的错误
+			return "", &MultiError{
+				Err:        pcsError,
+				Terminated: true,
+			}
+		}
+		return "", pcsError
+	}
+	return checksum, nil
+}
+
+// CreateSuperFile 合并分片, 秒传成功时无需合并
+func (xu *XpanUpload) CreateSuperFile(pcsHost, policy, uploadId string, fileSize int64, checksumMap map[int]string) (cerr error) {
+	if xu.rapid {
+		return nil
+	}
+	if xu.uploadID != "" {
+		uploadId = xu.uploadID
+	}
+
+	// 优先使用上传分片返回的 md5, 空文件没有分片, 使用本地计算的 md5
+	blockList := append([]string(nil), xu.blockList...)
+	partseqs := make([]int, 0, len(checksumMap))
+	for partseq := range checksumMap {
+		partseqs = append(partseqs, partseq)
+	}
+	sort.Ints(partseqs)
+	for _, partseq := range partseqs {
+		if partseq < len(blockList) {
+			blockList[partseq] = checksumMap[partseq]
+		} else {
+			blockList = append(blockList, checksumMap[partseq])
+		}
+	}
+
+	_, pcsError := xu.client.Create(context.Background(), xu.targetPath, fileSize, uploadId, blockList, xu.rtype)
+	if pcsError != nil {
+		return pcsError
+	}
+	return nil
+}
+
+// skip 分片是否无需上传
+func (xu *XpanUpload) skip(partseq int) bool {
+	if partseq < 0 || partseq >= len(xu.blockList) {
+		return false
+	}
+	return xu.rapid || (xu.needed != nil && !xu.needed[partseq])
+}
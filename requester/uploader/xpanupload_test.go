@@ -0,0 +1,99 @@
+package uploader_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/qjfoidnh/BaiduPCS-Go/baidupcs/xpan"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/rio"
+	"github.com/qjfoidnh/BaiduPCS-Go/requester/uploader"
+)
+
+func md5Hex(b []byte) string {
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func testXpanUpload(t *testing.T, returnType int) (uploaded int, created bool) {
+	const blockSize = 4096
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	var blockList []string
+	for i := 0; i < len(data); i += blockSize {
+		blockList = append(blockList, md5Hex(data[i:min(i+blockSize, len(data))]))
+	}
+
+	var mu sync.Mutex
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/rest/2.0/xpan/file" && q.Get("method") == "precreate":
+			if r.PostFormValue("block_list") != `["`+strings.Join(blockList, `","`)+`"]` {
+				t.Errorf("bad precreate block_list: %s", r.PostFormValue("block_list"))
+			}
+			fmt.Fprintf(w, `{"errno":0,"uploadid":"u1","return_type":%d,"block_list":[0,2]}`, returnType)
+		case r.URL.Path == "/rest/2.0/pcs/superfile2":
+			if q.Get("uploadid") != "u1" {
+				t.Errorf("bad uploadid: %s", q.Get("uploadid"))
+			}
+			f, _, err := r.FormFile("file")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			b, _ := io.ReadAll(f)
+			mu.Lock()
+			uploaded++
+			mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]string{"md5": md5Hex(b)})
+		case r.URL.Path == "/rest/2.0/xpan/file" && q.Get("method") == "create":
+			if r.PostFormValue("block_list") != `["`+strings.Join(blockList, `","`)+`"]` || r.PostFormValue("size") != fmt.Sprint(len(data)) {
+				t.Errorf("bad create: %s", r.PostForm)
+			}
+			created = true
+			fmt.Fprint(w, `{"errno":0,"fs_id":1,"path":"/a","size":10000}`)
+		default:
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	client := xpan.NewClient("token", nil)
+	client.SetAddr(strings.TrimPrefix(srv.URL, "https://"))
+	client.SetPCSAddr(strings.TrimPrefix(srv.URL, "https://"))
+
+	xu := uploader.NewXpanUpload(client, "/a", int64(len(data)), blockList, xpan.RtypeOverwrite)
+	muer := uploader.NewMultiUploader(xu, rio.NewReaderAtLen64(bytes.NewReader(data), int64(len(data))), &uploader.MultiUploaderConfig{
+		Parallel:  2,
+		BlockSize: blockSize,
+	}, "/a")
+	muer.SetInstanceState(&uploader.InstanceState{})
+	muer.OnError(func(err error) {
+		t.Errorf("upload error: %s", err)
+	})
+	muer.Execute()
+	return
+}
+
+func TestXpanUpload(t *testing.T) {
+	uploaded, created := testXpanUpload(t, 1)
+	// 只上传预上传返回的分片
+	if uploaded != 2 || !created {
+		t.Fatalf("uploaded: %d, created: %v", uploaded, created)
+	}
+}
+
+func TestXpanUploadRapid(t *testing.T) {
+	uploaded, created := testXpanUpload(t, xpan.ReturnTypeRapid)
+	if uploaded != 0 || created {
+		t.Fatalf("uploaded: %d, created: %v", uploaded, created)
+	}
+}